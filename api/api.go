@@ -0,0 +1,9 @@
+// Package api содержит контракт REST API в формате OpenAPI 3.
+package api
+
+import _ "embed"
+
+// OpenAPI — спецификация openapi.yaml, встроенная в бинарник.
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
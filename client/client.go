@@ -0,0 +1,870 @@
+// Package client — типизированный Go-клиент REST API банковского сервиса.
+//
+//	c, err := client.New("http://localhost:8080")
+//	if err != nil { ... }
+//	if _, err := c.Login(ctx, "test@example.com", "pass123"); err != nil { ... }
+//	err = c.Transfer(ctx, client.TransferRequest{FromAccount: "RU53GOBK012345678901", ToAccount: "RU68GOBK000000000042", Amount: 100})
+//	if errors.Is(err, client.ErrInsufficientFunds) { ... }
+//
+// После Login клиент сам подставляет токен и получает новый, когда старый
+// истекает или отвергается сервером. Переводы отправляются с ключом
+// идемпотентности, поэтому их безопасно повторять после сетевых ошибок.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// IdempotencyKeyHeader — заголовок ключа идемпотентности перевода.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// refreshMargin — за сколько до истечения токена клиент получает новый.
+	refreshMargin = time.Minute
+)
+
+// Client — клиент API. Безопасен для одновременного использования из нескольких горутин.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	maxRetries int
+	retryDelay time.Duration
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	email     string
+	password  string
+}
+
+// Option настраивает Client.
+type Option func(*Client)
+
+// WithHTTPClient задаёт HTTP-клиент, например с таймаутом или своим транспортом.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithToken задаёт готовый JWT. Без WithCredentials или Login клиент не сможет
+// обновить его по истечении.
+func WithToken(token string) Option {
+	return func(c *Client) { c.setToken(token) }
+}
+
+// WithCredentials задаёт email и пароль, по которым клиент сам получает и
+// обновляет токен, не дожидаясь явного Login.
+func WithCredentials(email, password string) Option {
+	return func(c *Client) { c.email, c.password = email, password }
+}
+
+// WithRetries задаёт число повторов безопасных запросов (чтение и переводы
+// с ключом идемпотентности) после сетевых ошибок и ответов 502, 503, 504.
+// По умолчанию 2.
+func WithRetries(n int, delay time.Duration) Option {
+	return func(c *Client) { c.maxRetries, c.retryDelay = n, delay }
+}
+
+// New создаёт клиент для сервера по адресу baseURL, например http://localhost:8080.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("некорректный адрес сервера: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("некорректный адрес сервера %q: нужна схема http или https", baseURL)
+	}
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		maxRetries: 2,
+		retryDelay: 200 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Token возвращает текущий JWT (пустую строку до входа).
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// NewIdempotencyKey генерирует случайный ключ идемпотентности (UUID v4).
+// Сохраните ключ, чтобы повторить перевод после перезапуска процесса.
+func NewIdempotencyKey() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Register регистрирует пользователя. Токен не выдаётся — вызовите Login.
+func (c *Client) Register(ctx context.Context, email, username, password string) (*User, error) {
+	var user User
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/register",
+		body:   map[string]string{"email": email, "username": username, "password": password},
+	}, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Login получает токен и запоминает email и пароль, чтобы обновлять токен
+// автоматически.
+func (c *Client) Login(ctx context.Context, email, password string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.login(ctx, email, password); err != nil {
+		return "", err
+	}
+	c.email, c.password = email, password
+	return c.token, nil
+}
+
+// login выполняет вход; вызывается под c.mu.
+func (c *Client) login(ctx context.Context, email, password string) error {
+	var resp struct {
+		Token string `json:"token"`
+	}
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/login",
+		body:   map[string]string{"email": email, "password": password},
+	}, &resp)
+	if err != nil {
+		return err
+	}
+	c.setTokenLocked(resp.Token)
+	return nil
+}
+
+// CreateAccount открывает новый расчётный счёт с нулевым балансом.
+func (c *Client) CreateAccount(ctx context.Context) (*Account, error) {
+	return c.OpenAccount(ctx, AccountChecking)
+}
+
+// OpenAccount открывает счёт типа accountType (AccountChecking, AccountSavings
+// или AccountCredit) с нулевым балансом.
+func (c *Client) OpenAccount(ctx context.Context, accountType string) (*Account, error) {
+	var account Account
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/accounts",
+		auth:   true,
+		body:   map[string]string{"type": accountType},
+	}, &account)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// TopUp пополняет свой счёт.
+func (c *Client) TopUp(ctx context.Context, account string, amount float64) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/accounts/topup",
+		auth:   true,
+		body:   map[string]interface{}{"account": account, "amount": amount},
+	}, nil)
+}
+
+// SetLowBalanceAlert задаёт порог уведомления о низком остатке своего счёта, 0 отключает уведомление.
+func (c *Client) SetLowBalanceAlert(ctx context.Context, account string, threshold float64) error {
+	return c.do(ctx, request{
+		method: http.MethodPut,
+		path:   accountPath(account, "/low-balance-alert"),
+		auth:   true,
+		body:   map[string]interface{}{"threshold": threshold},
+	}, nil)
+}
+
+// Transfer переводит деньги со своего счёта на любой счёт.
+func (c *Client) Transfer(ctx context.Context, req TransferRequest) error {
+	key := req.IdempotencyKey
+	if key == "" {
+		key = NewIdempotencyKey()
+	}
+	return c.transfer(ctx, request{
+		method:         http.MethodPost,
+		path:           "/transfer",
+		auth:           true,
+		body:           req,
+		idempotencyKey: key,
+	})
+}
+
+// TransferByUsernames переводит деньги с первого счёта пользователя клиента на первый счёт получателя.
+func (c *Client) TransferByUsernames(ctx context.Context, req TransferByUsernamesRequest) error {
+	key := req.IdempotencyKey
+	if key == "" {
+		key = NewIdempotencyKey()
+	}
+	return c.transfer(ctx, request{
+		method:         http.MethodPost,
+		path:           "/transfer/by-usernames",
+		auth:           true,
+		body:           req,
+		idempotencyKey: key,
+	})
+}
+
+// TransferToPayee переводит деньги получателю из адресной книги.
+func (c *Client) TransferToPayee(ctx context.Context, req PayeeTransferRequest) error {
+	key := req.IdempotencyKey
+	if key == "" {
+		key = NewIdempotencyKey()
+	}
+	return c.transfer(ctx, request{
+		method:         http.MethodPost,
+		path:           payeePath(req.PayeeID) + "/transfer",
+		auth:           true,
+		body:           req,
+		idempotencyKey: key,
+	})
+}
+
+// transfer выполняет перевод. Ответ 202 (сумма больше порога одобрения
+// счёта) возвращается как *ApprovalPendingError.
+func (c *Client) transfer(ctx context.Context, req request) error {
+	resp, err := c.roundTrip(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= http.StatusBadRequest:
+		return decodeError(resp)
+	case resp.StatusCode == http.StatusAccepted:
+		var approval TransferApproval
+		if err := json.NewDecoder(resp.Body).Decode(&approval); err != nil {
+			return fmt.Errorf("banking api: ответ %s %s: %w", req.method, req.path, err)
+		}
+		return &ApprovalPendingError{Approval: &approval}
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Transactions возвращает страницу истории своего счёта, начиная с последних переводов.
+func (c *Client) Transactions(ctx context.Context, account string, opts ListOptions) ([]Transaction, error) {
+	query := url.Values{}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		query.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	var transactions []Transaction
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   accountPath(account, "/transactions"),
+		query:  query,
+		auth:   true,
+	}, &transactions)
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// Refund возвращает отправителю amount (0 — весь остаток) перевода
+// transactionID, пришедшего на свой счёт.
+func (c *Client) Refund(ctx context.Context, transactionID int64, amount float64) (*Transaction, error) {
+	var refund Transaction
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/transactions/" + strconv.FormatInt(transactionID, 10) + "/refund",
+		auth:   true,
+		body:   map[string]float64{"amount": amount},
+	}, &refund)
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// Interest возвращает начисления процентов по своему счёту за месяц month
+// (YYYY-MM, пустая строка — текущий месяц).
+func (c *Client) Interest(ctx context.Context, account string, month string) (*InterestStatement, error) {
+	query := url.Values{}
+	if month != "" {
+		query.Set("month", month)
+	}
+	var statement InterestStatement
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   accountPath(account, "/interest"),
+		query:  query,
+		auth:   true,
+	}, &statement)
+	if err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// Loans возвращает свои кредиты без графиков платежей.
+func (c *Client) Loans(ctx context.Context) ([]Loan, error) {
+	var loans []Loan
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/loans", auth: true}, &loans); err != nil {
+		return nil, err
+	}
+	return loans, nil
+}
+
+// Loan возвращает свой кредит с графиком платежей.
+func (c *Client) Loan(ctx context.Context, loanID int64) (*Loan, error) {
+	var loan Loan
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/loans/" + strconv.FormatInt(loanID, 10),
+		auth:   true,
+	}, &loan)
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// RepayLoan досрочно погашает кредит со счёта кредита. Запрос не повторяется
+// автоматически: повтор списал бы деньги дважды.
+func (c *Client) RepayLoan(ctx context.Context, loanID int64, amount float64) (*LoanRepayment, error) {
+	var repayment LoanRepayment
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/loans/" + strconv.FormatInt(loanID, 10) + "/repay",
+		auth:   true,
+		body:   map[string]interface{}{"amount": amount},
+	}, &repayment)
+	if err != nil {
+		return nil, err
+	}
+	return &repayment, nil
+}
+
+// RequestMoney запрашивает деньги у другого пользователя. Запрос не повторяется
+// автоматически: повтор создал бы второй запрос.
+func (c *Client) RequestMoney(ctx context.Context, req NewPaymentRequest) (*PaymentRequest, error) {
+	var created PaymentRequest
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/payment-requests", auth: true, body: req}, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// PaymentRequests возвращает запросы денег, начиная с последних.
+func (c *Client) PaymentRequests(ctx context.Context, filter PaymentRequestFilter) ([]PaymentRequest, error) {
+	query := url.Values{}
+	if filter.Outgoing {
+		query.Set("direction", "outgoing")
+	}
+	if filter.Status != "" {
+		query.Set("status", filter.Status)
+	}
+	var requests []PaymentRequest
+	err := c.do(ctx, request{method: http.MethodGet, path: "/payment-requests", query: query, auth: true}, &requests)
+	if err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// PaymentRequest возвращает запрос денег, в котором пользователь — одна из сторон.
+func (c *Client) PaymentRequest(ctx context.Context, requestID int64) (*PaymentRequest, error) {
+	return c.paymentRequest(ctx, http.MethodGet, requestID, "", nil)
+}
+
+// AcceptPaymentRequest оплачивает входящий запрос со своего счёта fromAccount.
+func (c *Client) AcceptPaymentRequest(ctx context.Context, requestID int64, fromAccount string) (*PaymentRequest, error) {
+	return c.paymentRequest(ctx, http.MethodPost, requestID, "/accept", map[string]string{"from_account": fromAccount})
+}
+
+// DeclinePaymentRequest отклоняет входящий запрос.
+func (c *Client) DeclinePaymentRequest(ctx context.Context, requestID int64) (*PaymentRequest, error) {
+	return c.paymentRequest(ctx, http.MethodPost, requestID, "/decline", nil)
+}
+
+// CancelPaymentRequest отменяет свой запрос.
+func (c *Client) CancelPaymentRequest(ctx context.Context, requestID int64) (*PaymentRequest, error) {
+	return c.paymentRequest(ctx, http.MethodPost, requestID, "/cancel", nil)
+}
+
+func (c *Client) paymentRequest(ctx context.Context, method string, requestID int64, action string, body interface{}) (*PaymentRequest, error) {
+	var req PaymentRequest
+	err := c.do(ctx, request{
+		method: method,
+		path:   "/payment-requests/" + strconv.FormatInt(requestID, 10) + action,
+		auth:   true,
+		body:   body,
+	}, &req)
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// Balance возвращает учтённый, заблокированный и доступный остатки своего счёта.
+func (c *Client) Balance(ctx context.Context, account string) (*Balance, error) {
+	var balance Balance
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   accountPath(account, "/balance"),
+		auth:   true,
+	}, &balance)
+	if err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// AuthorizeHold блокирует сумму на своём счёте. Запрос не повторяется
+// автоматически: повтор заблокировал бы сумму дважды.
+func (c *Client) AuthorizeHold(ctx context.Context, hold NewHold) (*Hold, error) {
+	var created Hold
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/holds", auth: true, body: hold}, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Holds возвращает блокировки на своих счетах и в пользу своих счетов, начиная
+// с последних; непустой status оставляет блокировки в этом статусе.
+func (c *Client) Holds(ctx context.Context, status string) ([]Hold, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	var holds []Hold
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/holds", query: query, auth: true}, &holds); err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+// Hold возвращает блокировку.
+func (c *Client) Hold(ctx context.Context, holdID int64) (*Hold, error) {
+	return c.hold(ctx, http.MethodGet, holdID, "", nil)
+}
+
+// CaptureHold списывает amount (0 — всю сумму) с блокировки в пользу своего счёта.
+func (c *Client) CaptureHold(ctx context.Context, holdID int64, amount float64) (*Hold, error) {
+	return c.hold(ctx, http.MethodPost, holdID, "/capture", map[string]float64{"amount": amount})
+}
+
+// ReleaseHold снимает блокировку в пользу своего счёта без списания.
+func (c *Client) ReleaseHold(ctx context.Context, holdID int64) (*Hold, error) {
+	return c.hold(ctx, http.MethodPost, holdID, "/release", nil)
+}
+
+func (c *Client) hold(ctx context.Context, method string, holdID int64, action string, body interface{}) (*Hold, error) {
+	var hold Hold
+	err := c.do(ctx, request{
+		method: method,
+		path:   "/holds/" + strconv.FormatInt(holdID, 10) + action,
+		auth:   true,
+		body:   body,
+	}, &hold)
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// SubmitBatch отправляет пакет переводов со своего счёта fromAccount.
+// Небольшой пакет выполняется сразу, большой возвращается в статусе pending —
+// результат по строкам даёт Batch. Запрос не повторяется автоматически.
+func (c *Client) SubmitBatch(ctx context.Context, fromAccount string, allOrNothing bool, lines []BatchLine) (*Batch, error) {
+	query := url.Values{"from_account": {fromAccount}}
+	if allOrNothing {
+		query.Set("all_or_nothing", "true")
+	}
+	var batch Batch
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/batches", query: query, auth: true, body: lines}, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// Batches возвращает свои пакеты переводов со сводкой, начиная с последних.
+func (c *Client) Batches(ctx context.Context) ([]Batch, error) {
+	var batches []Batch
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/batches", auth: true}, &batches); err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+// Batch возвращает статус пакета и результат по каждой строке.
+func (c *Client) Batch(ctx context.Context, batchID int64) (*Batch, error) {
+	var batch Batch
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/batches/" + strconv.FormatInt(batchID, 10), auth: true}, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// Approvals возвращает переводы на одобрении, которые пользователь создал или
+// может рассмотреть; непустой status оставляет переводы в этом статусе.
+func (c *Client) Approvals(ctx context.Context, status string) ([]TransferApproval, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	var approvals []TransferApproval
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/approvals", query: query, auth: true}, &approvals); err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
+
+// Approval возвращает перевод на одобрении.
+func (c *Client) Approval(ctx context.Context, approvalID int64) (*TransferApproval, error) {
+	return c.approval(ctx, http.MethodGet, approvalID, "", nil)
+}
+
+// ApproveTransfer одобряет и выполняет перевод, ожидающий одобрения.
+func (c *Client) ApproveTransfer(ctx context.Context, approvalID int64) (*TransferApproval, error) {
+	return c.approval(ctx, http.MethodPost, approvalID, "/approve", nil)
+}
+
+// RejectTransfer отклоняет перевод с необязательной причиной.
+func (c *Client) RejectTransfer(ctx context.Context, approvalID int64, reason string) (*TransferApproval, error) {
+	return c.approval(ctx, http.MethodPost, approvalID, "/reject", map[string]string{"reason": reason})
+}
+
+func (c *Client) approval(ctx context.Context, method string, approvalID int64, action string, body interface{}) (*TransferApproval, error) {
+	var approval TransferApproval
+	err := c.do(ctx, request{
+		method: method,
+		path:   "/approvals/" + strconv.FormatInt(approvalID, 10) + action,
+		auth:   true,
+		body:   body,
+	}, &approval)
+	if err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+// InviteAccess приглашает пользователя username к счёту с уровнем доступа
+// level (AccessView, AccessTransact или AccessManage).
+func (c *Client) InviteAccess(ctx context.Context, account, username, level string) (*AccountAccess, error) {
+	return c.access(ctx, http.MethodPost, account, "", map[string]string{"username": username, "level": level})
+}
+
+// AccountAccess возвращает доступы и приглашения к счёту, начиная с владельца.
+func (c *Client) AccountAccess(ctx context.Context, account string) ([]AccountAccess, error) {
+	var access []AccountAccess
+	if err := c.do(ctx, request{method: http.MethodGet, path: accessPath(account, ""), auth: true}, &access); err != nil {
+		return nil, err
+	}
+	return access, nil
+}
+
+// AcceptAccess принимает приглашение к счёту.
+func (c *Client) AcceptAccess(ctx context.Context, account string) (*AccountAccess, error) {
+	return c.access(ctx, http.MethodPost, account, "/accept", nil)
+}
+
+// RevokeAccess удаляет доступ или приглашение пользователя username к счёту.
+// Со своим username — отказ от приглашения или выход из счёта.
+func (c *Client) RevokeAccess(ctx context.Context, account, username string) (*AccountAccess, error) {
+	return c.access(ctx, http.MethodDelete, account, "/"+url.PathEscape(username), nil)
+}
+
+// Invitations возвращает свои приглашения к счетам, ждущие принятия.
+func (c *Client) Invitations(ctx context.Context) ([]AccountAccess, error) {
+	var invitations []AccountAccess
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/invitations", auth: true}, &invitations); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (c *Client) access(ctx context.Context, method, account, action string, body interface{}) (*AccountAccess, error) {
+	var access AccountAccess
+	if err := c.do(ctx, request{method: method, path: accessPath(account, action), auth: true, body: body}, &access); err != nil {
+		return nil, err
+	}
+	return &access, nil
+}
+
+// accountPath возвращает путь ресурса счёта account.
+func accountPath(account, resource string) string {
+	return "/accounts/" + url.PathEscape(account) + resource
+}
+
+func accessPath(account, action string) string {
+	return accountPath(account, "/access"+action)
+}
+
+// CreatePayee добавляет получателя в адресную книгу.
+func (c *Client) CreatePayee(ctx context.Context, req PayeeRequest) (*Payee, error) {
+	return c.payee(ctx, request{method: http.MethodPost, path: "/payees", auth: true, body: req})
+}
+
+// Payees возвращает адресную книгу.
+func (c *Client) Payees(ctx context.Context) ([]Payee, error) {
+	var payees []Payee
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/payees", auth: true}, &payees); err != nil {
+		return nil, err
+	}
+	return payees, nil
+}
+
+// Payee возвращает получателя из адресной книги.
+func (c *Client) Payee(ctx context.Context, payeeID int64) (*Payee, error) {
+	return c.payee(ctx, request{method: http.MethodGet, path: payeePath(payeeID), auth: true})
+}
+
+// UpdatePayee заменяет имя, получателя и назначение получателя payeeID.
+func (c *Client) UpdatePayee(ctx context.Context, payeeID int64, req PayeeRequest) (*Payee, error) {
+	return c.payee(ctx, request{method: http.MethodPut, path: payeePath(payeeID), auth: true, body: req})
+}
+
+// DeletePayee удаляет получателя из адресной книги.
+func (c *Client) DeletePayee(ctx context.Context, payeeID int64) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: payeePath(payeeID), auth: true}, nil)
+}
+
+func (c *Client) payee(ctx context.Context, req request) (*Payee, error) {
+	var payee Payee
+	if err := c.do(ctx, req, &payee); err != nil {
+		return nil, err
+	}
+	return &payee, nil
+}
+
+func payeePath(payeeID int64) string {
+	return "/payees/" + strconv.FormatInt(payeeID, 10)
+}
+
+// Healthz проверяет, что процесс сервера жив.
+func (c *Client) Healthz(ctx context.Context) error {
+	return c.do(ctx, request{method: http.MethodGet, path: "/healthz"}, nil)
+}
+
+// Readyz возвращает готовность сервера. Статус not_ready (ответ 503) не
+// считается ошибкой — проверяйте Readiness.Status.
+func (c *Client) Readyz(ctx context.Context) (*Readiness, error) {
+	resp, err := c.roundTrip(ctx, request{method: http.MethodGet, path: "/readyz"})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return nil, decodeError(resp)
+	}
+	var readiness Readiness
+	if err := json.NewDecoder(resp.Body).Decode(&readiness); err != nil {
+		return nil, fmt.Errorf("banking api: ответ /readyz: %w", err)
+	}
+	return &readiness, nil
+}
+
+// Version возвращает сведения о сборке сервера.
+func (c *Client) Version(ctx context.Context) (*BuildInfo, error) {
+	var info BuildInfo
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/version"}, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// request описывает вызов API.
+type request struct {
+	method         string
+	path           string
+	query          url.Values
+	body           interface{}
+	auth           bool
+	idempotencyKey string
+}
+
+// retryable сообщает, можно ли повторить запрос без риска выполнить его дважды.
+func (r request) retryable() bool {
+	return r.method == http.MethodGet || r.method == http.MethodPut || r.idempotencyKey != ""
+}
+
+// do выполняет запрос и декодирует ответ 2xx в out (если out не nil), а
+// ответ с ошибкой — в *Error.
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	resp, err := c.roundTrip(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("banking api: ответ %s %s: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// roundTrip отправляет запрос с токеном, повторяя его после сетевых ошибок
+// (если запрос безопасно повторять) и один раз после 401 с новым токеном.
+func (c *Client) roundTrip(ctx context.Context, req request) (*http.Response, error) {
+	var payload []byte
+	if req.body != nil {
+		var err error
+		if payload, err = json.Marshal(req.body); err != nil {
+			return nil, err
+		}
+	}
+
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		token := ""
+		if req.auth {
+			var err error
+			if token, err = c.validToken(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.send(ctx, req, payload, token)
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && req.auth && !refreshed && c.canRefresh() {
+			// Токен отозван или подписан другим секретом: входим заново и повторяем
+			resp.Body.Close()
+			c.invalidateToken(token)
+			refreshed = true
+			attempt--
+			continue
+		}
+		if !req.retryable() || attempt >= c.maxRetries || (err == nil && !retryableStatus(resp.StatusCode)) {
+			return resp, err
+		}
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(c.retryDelay * time.Duration(attempt+1)):
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, req request, payload []byte, token string) (*http.Response, error) {
+	u := *c.baseURL
+	u.Path += req.path
+	u.RawQuery = req.query.Encode()
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	if req.idempotencyKey != "" {
+		httpReq.Header.Set(IdempotencyKeyHeader, req.idempotencyKey)
+	}
+	return c.httpClient.Do(httpReq)
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
+
+// validToken возвращает действующий токен, при необходимости входя заново.
+func (c *Client) validToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fresh := c.token != "" && (c.expiresAt.IsZero() || time.Until(c.expiresAt) > refreshMargin)
+	if fresh || c.email == "" {
+		// Без учётных данных отправляем то, что есть; сервер ответит 401
+		return c.token, nil
+	}
+	if err := c.login(ctx, c.email, c.password); err != nil {
+		return "", err
+	}
+	return c.token, nil
+}
+
+func (c *Client) canRefresh() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.email != ""
+}
+
+// invalidateToken сбрасывает token, если его ещё не заменил другой запрос.
+func (c *Client) invalidateToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == token {
+		c.token = ""
+		c.expiresAt = time.Time{}
+	}
+}
+
+func (c *Client) setToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setTokenLocked(token)
+}
+
+// setTokenLocked запоминает токен и срок его действия из claim exp. Подпись
+// не проверяется — это дело сервера.
+func (c *Client) setTokenLocked(token string) {
+	c.token = token
+	c.expiresAt = time.Time{}
+	claims := &jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err == nil && claims.ExpiresAt != nil {
+		c.expiresAt = claims.ExpiresAt.Time
+	}
+}
+
+// decodeError читает тело ответа с ошибкой.
+func decodeError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	if err := json.Unmarshal(data, apiErr); err != nil || apiErr.Message == "" {
+		// Ответ не от API, например от прокси
+		apiErr.Code = ""
+		apiErr.Message = strings.TrimSpace(string(data))
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,551 @@
+package client_test
+
+import (
+	"banking-api/api"
+	"banking-api/client"
+	"banking-api/internal/accountno"
+	"banking-api/internal/apierr"
+	"banking-api/internal/config"
+	"banking-api/internal/handler"
+	"banking-api/internal/middleware"
+	"banking-api/internal/models"
+	"banking-api/internal/repository/memory"
+	"banking-api/internal/service"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+)
+
+const jwtSecret = "test-secret"
+
+func TestMain(m *testing.M) {
+	config.InitLogger()
+	config.Log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// newServer поднимает настоящий роутер API поверх репозиториев в памяти.
+// Ответы проверяются по спецификации OpenAPI.
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv, _ := newServerWithStore(t)
+	return srv
+}
+
+func newServerWithStore(t *testing.T) (*httptest.Server, *memory.Store) {
+	t.Helper()
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+	accounts := memory.NewAccountRepository(store)
+	approvals := service.NewApprovalService(memory.NewApprovalRepository(store), users, nil)
+	accountService := service.NewAccountService(accounts, users, nil)
+	accountService.Approvals = approvals
+
+	validate, err := middleware.OpenAPIValidator(api.OpenAPI, func(r *http.Request, err error) {
+		t.Errorf("ответ %s %s не соответствует спецификации: %v", r.Method, r.URL.Path, err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := mux.NewRouter()
+	router.Use(validate)
+	handler.RegisterServiceRoutes(router, handler.NewHealthHandler(nil, nil, nil), handler.NewDocsHandler(api.OpenAPI))
+	handler.RegisterRoutes(router,
+		handler.NewAuthHandler(service.NewAuthService(users), jwtSecret),
+		&handler.AccountHandler{
+			AccountService:        accountService,
+			InterestService:       service.NewInterestService(memory.NewInterestRepository(store), accounts),
+			LoanService:           service.NewLoanService(memory.NewLoanRepository(store), accounts),
+			PaymentRequestService: service.NewPaymentRequestService(memory.NewPaymentRequestRepository(store), users, nil),
+			HoldService:           service.NewHoldService(memory.NewHoldRepository(store)),
+			BatchService:          service.NewBatchService(memory.NewBatchRepository(store), accounts, users),
+			ApprovalService:       approvals,
+			AccessService:         service.NewAccessService(memory.NewAccessRepository(store), users, nil),
+			PayeeService:          service.NewPayeeService(memory.NewPayeeRepository(store), users, accountService, nil),
+		},
+		jwtSecret,
+	)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv, store
+}
+
+func newClient(t *testing.T, srv *httptest.Server, opts ...client.Option) *client.Client {
+	t.Helper()
+	c, err := client.New(srv.URL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// signup регистрирует пользователя и возвращает клиент, вошедший под ним.
+func signup(t *testing.T, srv *httptest.Server, username string, opts ...client.Option) *client.Client {
+	t.Helper()
+	ctx := context.Background()
+	c := newClient(t, srv, opts...)
+	if _, err := c.Register(ctx, username+"@example.com", username, "secret"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := c.Login(ctx, username+"@example.com", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return c
+}
+
+func TestClientEndToEnd(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+
+	aliceAcc, err := alice.CreateAccount(ctx)
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	if accountno.Validate(aliceAcc.Number) != nil || aliceAcc.Type != client.AccountChecking || aliceAcc.Balance != 0 || aliceAcc.CreatedAt.IsZero() {
+		t.Errorf("счёт = %+v", aliceAcc)
+	}
+	if credit, err := alice.OpenAccount(ctx, client.AccountCredit); err != nil || credit.Type != client.AccountCredit || credit.CreditLimit <= 0 {
+		t.Errorf("OpenAccount(credit) = %+v, %v", credit, err)
+	}
+	bobAcc, err := bob.CreateAccount(ctx)
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+
+	if err := alice.TopUp(ctx, aliceAcc.Number, 100); err != nil {
+		t.Fatalf("TopUp: %v", err)
+	}
+	if err := alice.Transfer(ctx, client.TransferRequest{FromAccount: aliceAcc.Number, ToAccount: bobAcc.Number, Amount: 30}); err != nil {
+		t.Fatalf("Transfer: %v", err)
+	}
+	if err := alice.TransferByUsernames(ctx, client.TransferByUsernamesRequest{ToUsername: "bob", Amount: 20}); err != nil {
+		t.Fatalf("TransferByUsernames: %v", err)
+	}
+
+	history, err := bob.Transactions(ctx, bobAcc.Number, client.ListOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("Transactions: %v", err)
+	}
+	if len(history) != 2 || history[0].Amount != 20 || history[1].Amount != 30 || history[1].FromAccount != aliceAcc.Number {
+		t.Errorf("история = %+v", history)
+	}
+	page, err := bob.Transactions(ctx, bobAcc.Number, client.ListOptions{Limit: 1, Offset: 1})
+	if err != nil || len(page) != 1 || page[0].Amount != 30 {
+		t.Errorf("вторая страница = %+v, %v", page, err)
+	}
+	if err := bob.SetLowBalanceAlert(ctx, bobAcc.Number, 10); err != nil {
+		t.Errorf("SetLowBalanceAlert: %v", err)
+	}
+	if statement, err := bob.Interest(ctx, bobAcc.Number, "2025-03"); err != nil || statement.Month != "2025-03" || statement.Accruals == nil {
+		t.Errorf("Interest = %+v, %v", statement, err)
+	}
+	if loans, err := bob.Loans(ctx); err != nil || len(loans) != 0 {
+		t.Errorf("Loans = %+v, %v", loans, err)
+	}
+	if _, err := bob.Loan(ctx, 1); !errors.Is(err, client.ErrLoanNotFound) {
+		t.Errorf("Loan(1): %v", err)
+	}
+
+	moneyReq, err := bob.RequestMoney(ctx, client.NewPaymentRequest{Payer: "alice", ToAccount: bobAcc.Number, Amount: 5, Note: "кофе"})
+	if err != nil || moneyReq.Status != "pending" || moneyReq.Requester != "bob" {
+		t.Fatalf("RequestMoney = %+v, %v", moneyReq, err)
+	}
+	if incoming, err := alice.PaymentRequests(ctx, client.PaymentRequestFilter{Status: "pending"}); err != nil || len(incoming) != 1 || incoming[0].Note != "кофе" {
+		t.Errorf("PaymentRequests = %+v, %v", incoming, err)
+	}
+	if paid, err := alice.AcceptPaymentRequest(ctx, moneyReq.ID, aliceAcc.Number); err != nil || paid.Status != "accepted" || paid.ResolvedAt == nil {
+		t.Errorf("AcceptPaymentRequest = %+v, %v", paid, err)
+	}
+	if _, err := bob.CancelPaymentRequest(ctx, moneyReq.ID); !errors.Is(err, client.ErrPaymentRequestClosed) {
+		t.Errorf("CancelPaymentRequest: %v", err)
+	}
+	if _, err := bob.DeclinePaymentRequest(ctx, moneyReq.ID); !errors.Is(err, client.ErrPaymentRequestNotFound) {
+		t.Errorf("DeclinePaymentRequest запросившим: %v", err)
+	}
+
+	hold, err := alice.AuthorizeHold(ctx, client.NewHold{Account: aliceAcc.Number, ToAccount: bobAcc.Number, Amount: 15})
+	if err != nil || hold.Status != "active" {
+		t.Fatalf("AuthorizeHold = %+v, %v", hold, err)
+	}
+	if balance, err := alice.Balance(ctx, aliceAcc.Number); err != nil || balance.Ledger != 45 || balance.Held != 15 || balance.Available != 30 {
+		t.Errorf("Balance = %+v, %v", balance, err)
+	}
+	if holds, err := bob.Holds(ctx, "active"); err != nil || len(holds) != 1 || holds[0].ID != hold.ID {
+		t.Errorf("Holds = %+v, %v", holds, err)
+	}
+	if _, err := alice.CaptureHold(ctx, hold.ID, 0); !errors.Is(err, client.ErrHoldNotFound) {
+		t.Errorf("CaptureHold плательщиком: %v", err)
+	}
+	if captured, err := bob.CaptureHold(ctx, hold.ID, 10); err != nil || captured.CapturedAmount != 10 || captured.TransactionID == 0 {
+		t.Errorf("CaptureHold = %+v, %v", captured, err)
+	}
+	if _, err := bob.ReleaseHold(ctx, hold.ID); !errors.Is(err, client.ErrHoldClosed) {
+		t.Errorf("ReleaseHold: %v", err)
+	}
+	if got, err := alice.Hold(ctx, hold.ID); err != nil || got.Status != "captured" {
+		t.Errorf("Hold = %+v, %v", got, err)
+	}
+
+	if _, err := alice.Refund(ctx, history[1].ID, 5); !errors.Is(err, client.ErrTransactionNotFound) {
+		t.Errorf("возврат отправителем: %v", err)
+	}
+	refund, err := bob.Refund(ctx, history[1].ID, 5)
+	if err != nil || refund.Kind != "refund" || refund.OriginalTransactionID != history[1].ID || refund.ToAccount != aliceAcc.Number {
+		t.Errorf("Refund = %+v, %v", refund, err)
+	}
+
+	if _, err := alice.SubmitBatch(ctx, aliceAcc.Number, false, []client.BatchLine{{ToUsername: "nobody", Amount: 1}}); !errors.Is(err, client.ErrInvalidBatch) {
+		t.Errorf("SubmitBatch с неизвестным получателем: %v", err)
+	}
+	batch, err := alice.SubmitBatch(ctx, aliceAcc.Number, false, []client.BatchLine{
+		{ToUsername: "bob", Amount: 5, Reference: "обед"},
+		{ToAccount: bobAcc.Number, Amount: 1000},
+	})
+	if err != nil || batch.Status != "completed" || batch.Succeeded != 1 || len(batch.Items) != 2 || batch.Items[1].Status != "failed" {
+		t.Fatalf("SubmitBatch = %+v, %v", batch, err)
+	}
+	if batches, err := alice.Batches(ctx); err != nil || len(batches) != 1 || batches[0].Amount != 1005 {
+		t.Errorf("Batches = %+v, %v", batches, err)
+	}
+	if _, err := bob.Batch(ctx, batch.ID); !errors.Is(err, client.ErrBatchNotFound) {
+		t.Errorf("Batch чужой: %v", err)
+	}
+
+	if err := alice.Healthz(ctx); err != nil {
+		t.Errorf("Healthz: %v", err)
+	}
+	if info, err := alice.Version(ctx); err != nil || info.GoVersion == "" {
+		t.Errorf("Version = %+v, %v", info, err)
+	}
+}
+
+func TestClientApprovals(t *testing.T) {
+	srv, store := newServerWithStore(t)
+	ctx := context.Background()
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	carol := signup(t, srv, "carol")
+	aliceAcc, _ := alice.CreateAccount(ctx)
+	carolAcc, _ := carol.CreateAccount(ctx)
+	if err := alice.TopUp(ctx, aliceAcc.Number, 1000); err != nil {
+		t.Fatal(err)
+	}
+	bobID, err := memory.NewUserRepository(store).GetUserIDByUsername(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliceID, err := memory.NewAccountRepository(store).GetAccountIDByNumber(ctx, aliceAcc.Number)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := models.AuditEntry{Actor: "ops", Action: models.AuditApprovals, AccountID: aliceID, Amount: 100, Reason: "политика"}
+	if err := memory.NewAdminRepository(store).SetApprovalPolicy(ctx, aliceID, 100, []int64{bobID}, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := alice.Transfer(ctx, client.TransferRequest{FromAccount: aliceAcc.Number, ToAccount: carolAcc.Number, Amount: 100}); err != nil {
+		t.Errorf("перевод до порога: %v", err)
+	}
+	err = alice.Transfer(ctx, client.TransferRequest{FromAccount: aliceAcc.Number, ToAccount: carolAcc.Number, Amount: 300})
+	var pending *client.ApprovalPendingError
+	if !errors.As(err, &pending) || pending.Approval.Status != "pending" || pending.Approval.Maker != "alice" {
+		t.Fatalf("перевод сверх порога: %v", err)
+	}
+	if approvals, err := bob.Approvals(ctx, "pending"); err != nil || len(approvals) != 1 || approvals[0].ID != pending.Approval.ID {
+		t.Errorf("Approvals = %+v, %v", approvals, err)
+	}
+	if _, err := carol.Approval(ctx, pending.Approval.ID); !errors.Is(err, client.ErrApprovalNotFound) {
+		t.Errorf("Approval постороннего: %v", err)
+	}
+	approved, err := bob.ApproveTransfer(ctx, pending.Approval.ID)
+	if err != nil || approved.Status != "approved" || approved.Checker != "bob" || approved.TransactionID == 0 {
+		t.Fatalf("ApproveTransfer = %+v, %v", approved, err)
+	}
+	if _, err := bob.RejectTransfer(ctx, approved.ID, "поздно"); !errors.Is(err, client.ErrApprovalClosed) {
+		t.Errorf("RejectTransfer одобренного: %v", err)
+	}
+	if balance, err := carol.Balance(ctx, carolAcc.Number); err != nil || balance.Ledger != 400 {
+		t.Errorf("Balance = %+v, %v", balance, err)
+	}
+}
+
+func TestClientAccess(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	carol := signup(t, srv, "carol")
+	aliceAcc, _ := alice.CreateAccount(ctx)
+	carolAcc, _ := carol.CreateAccount(ctx)
+	if err := alice.TopUp(ctx, aliceAcc.Number, 500); err != nil {
+		t.Fatal(err)
+	}
+
+	invited, err := alice.InviteAccess(ctx, aliceAcc.Number, "bob", client.AccessTransact)
+	if err != nil || invited.Status != "pending" || invited.InvitedBy != "alice" {
+		t.Fatalf("InviteAccess = %+v, %v", invited, err)
+	}
+	if _, err := alice.InviteAccess(ctx, aliceAcc.Number, "bob", client.AccessView); !errors.Is(err, client.ErrAccessExists) {
+		t.Errorf("повторное приглашение: %v", err)
+	}
+	if invitations, err := bob.Invitations(ctx); err != nil || len(invitations) != 1 || invitations[0].Account != aliceAcc.Number {
+		t.Errorf("Invitations = %+v, %v", invitations, err)
+	}
+	if err := bob.Transfer(ctx, client.TransferRequest{FromAccount: aliceAcc.Number, ToAccount: carolAcc.Number, Amount: 100}); !errors.Is(err, client.ErrAccountNotFound) {
+		t.Errorf("перевод до принятия: %v", err)
+	}
+	accepted, err := bob.AcceptAccess(ctx, aliceAcc.Number)
+	if err != nil || accepted.Status != "active" || accepted.AcceptedAt == nil {
+		t.Fatalf("AcceptAccess = %+v, %v", accepted, err)
+	}
+	if err := bob.Transfer(ctx, client.TransferRequest{FromAccount: aliceAcc.Number, ToAccount: carolAcc.Number, Amount: 100}); err != nil {
+		t.Errorf("перевод совладельца: %v", err)
+	}
+	access, err := bob.AccountAccess(ctx, aliceAcc.Number)
+	if err != nil || len(access) != 2 || !access[0].Owner || access[0].Username != "alice" || access[1].Username != "bob" {
+		t.Errorf("AccountAccess = %+v, %v", access, err)
+	}
+	if _, err := bob.RevokeAccess(ctx, aliceAcc.Number, "alice"); !errors.Is(err, client.ErrAccessNotFound) {
+		t.Errorf("отзыв без manage: %v", err)
+	}
+	if _, err := alice.RevokeAccess(ctx, aliceAcc.Number, "alice"); !errors.Is(err, client.ErrOwnerAccess) {
+		t.Errorf("отзыв владельца: %v", err)
+	}
+	if _, err := alice.RevokeAccess(ctx, aliceAcc.Number, "bob"); err != nil {
+		t.Fatalf("RevokeAccess: %v", err)
+	}
+	if _, err := bob.Balance(ctx, aliceAcc.Number); !errors.Is(err, client.ErrAccountNotFound) {
+		t.Errorf("баланс после отзыва: %v", err)
+	}
+}
+
+func TestClientPayees(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	aliceAcc, _ := alice.CreateAccount(ctx)
+	bobAcc, _ := bob.CreateAccount(ctx)
+	if err := alice.TopUp(ctx, aliceAcc.Number, 5000); err != nil {
+		t.Fatal(err)
+	}
+
+	payee, err := alice.CreatePayee(ctx, client.PayeeRequest{Nickname: "боб", Account: bobAcc.Number, Reference: "долг"})
+	if err != nil || payee.Account != bobAcc.Number || payee.Reference != "долг" || payee.CoolingOffUntil.IsZero() {
+		t.Fatalf("CreatePayee = %+v, %v", payee, err)
+	}
+	if _, err := alice.CreatePayee(ctx, client.PayeeRequest{Nickname: "боб", Username: "bob"}); !errors.Is(err, client.ErrPayeeExists) {
+		t.Errorf("повторное имя: %v", err)
+	}
+	if err := alice.TransferToPayee(ctx, client.PayeeTransferRequest{PayeeID: payee.ID, FromAccount: aliceAcc.Number, Amount: 2000}); !errors.Is(err, client.ErrPayeeCoolingOff) {
+		t.Errorf("крупный перевод новому получателю: %v", err)
+	}
+	if err := alice.TransferToPayee(ctx, client.PayeeTransferRequest{PayeeID: payee.ID, FromAccount: aliceAcc.Number, Amount: 150}); err != nil {
+		t.Errorf("TransferToPayee: %v", err)
+	}
+	if balance, err := bob.Balance(ctx, bobAcc.Number); err != nil || balance.Ledger != 150 {
+		t.Errorf("Balance = %+v, %v", balance, err)
+	}
+
+	updated, err := alice.UpdatePayee(ctx, payee.ID, client.PayeeRequest{Nickname: "Боб", Username: "bob"})
+	if err != nil || updated.Username != "bob" || updated.Account != "" {
+		t.Errorf("UpdatePayee = %+v, %v", updated, err)
+	}
+	if payees, err := alice.Payees(ctx); err != nil || len(payees) != 1 || payees[0].Nickname != "Боб" {
+		t.Errorf("Payees = %+v, %v", payees, err)
+	}
+	if _, err := bob.Payee(ctx, payee.ID); !errors.Is(err, client.ErrPayeeNotFound) {
+		t.Errorf("чужой получатель: %v", err)
+	}
+	if err := alice.DeletePayee(ctx, payee.ID); err != nil {
+		t.Fatalf("DeletePayee: %v", err)
+	}
+	if _, err := alice.Payee(ctx, payee.ID); !errors.Is(err, client.ErrPayeeNotFound) {
+		t.Errorf("удалённый получатель: %v", err)
+	}
+}
+
+func TestClientTypedErrors(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	aliceAcc, _ := alice.CreateAccount(ctx)
+	bobAcc, _ := bob.CreateAccount(ctx)
+
+	tests := []struct {
+		name   string
+		err    error
+		want   error
+		status int
+	}{
+		{"недостаточно средств", alice.Transfer(ctx, client.TransferRequest{FromAccount: aliceAcc.Number, ToAccount: bobAcc.Number, Amount: 10}), client.ErrInsufficientFunds, http.StatusBadRequest},
+		{"перевод себе", alice.Transfer(ctx, client.TransferRequest{FromAccount: aliceAcc.Number, ToAccount: aliceAcc.Number, Amount: 10}), client.ErrSelfTransfer, http.StatusBadRequest},
+		{"чужой счёт", bob.Transfer(ctx, client.TransferRequest{FromAccount: aliceAcc.Number, ToAccount: bobAcc.Number, Amount: 10}), client.ErrAccountNotFound, http.StatusBadRequest},
+		{"некорректный номер", alice.Transfer(ctx, client.TransferRequest{FromAccount: aliceAcc.Number, ToAccount: "RU00GOBK000000000000", Amount: 10}), client.ErrInvalidAccountNumber, http.StatusBadRequest},
+		{"неизвестный номер", func() error { _, err := alice.Balance(ctx, accountno.New()); return err }(), client.ErrAccountNotFound, http.StatusNotFound},
+		{"чужая история", func() error { _, err := bob.Transactions(ctx, aliceAcc.Number, client.ListOptions{}); return err }(), client.ErrAccountNotFound, http.StatusNotFound},
+		{"нет получателя", alice.TransferByUsernames(ctx, client.TransferByUsernamesRequest{FromUsername: "alice", ToUsername: "nobody", Amount: 1}), client.ErrUserNotFound, http.StatusBadRequest},
+		{"невалидная сумма", alice.TopUp(ctx, aliceAcc.Number, -1), client.ErrInvalidRequest, http.StatusBadRequest},
+		{"занятый username", func() error { _, err := alice.Register(ctx, "other@example.com", "alice", "x"); return err }(), client.ErrUserExists, http.StatusBadRequest},
+		{"неверный пароль", func() error { _, err := newClient(t, srv).Login(ctx, "alice@example.com", "wrong"); return err }(), client.ErrInvalidCredentials, http.StatusUnauthorized},
+		{"без токена", func() error { _, err := newClient(t, srv).CreateAccount(ctx); return err }(), client.ErrUnauthorized, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: ожидалась %v, получено %v", tt.name, tt.want, tt.err)
+			continue
+		}
+		var apiErr *client.Error
+		if !errors.As(tt.err, &apiErr) || apiErr.StatusCode != tt.status || apiErr.Message == "" {
+			t.Errorf("%s: ошибка %#v, ожидался статус %d и текст", tt.name, apiErr, tt.status)
+		}
+	}
+}
+
+// flakyTransport теряет ответ на первый запрос, хотя сервер его выполнил, —
+// как при обрыве соединения после отправки запроса.
+type flakyTransport struct {
+	dropped atomic.Bool
+	keys    []string
+}
+
+func (f *flakyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	f.keys = append(f.keys, r.Header.Get(client.IdempotencyKeyHeader))
+	resp, err := http.DefaultTransport.RoundTrip(r)
+	if err == nil && f.dropped.CompareAndSwap(false, true) {
+		resp.Body.Close()
+		return nil, errors.New("соединение разорвано")
+	}
+	return resp, err
+}
+
+func TestClientRetriesTransferWithSameKey(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	aliceAcc, _ := alice.CreateAccount(ctx)
+	bobAcc, _ := bob.CreateAccount(ctx)
+	alice.TopUp(ctx, aliceAcc.Number, 100)
+
+	transport := &flakyTransport{}
+	flaky := newClient(t, srv,
+		client.WithHTTPClient(&http.Client{Transport: transport}),
+		client.WithToken(alice.Token()),
+		client.WithRetries(2, time.Millisecond),
+	)
+	if err := flaky.Transfer(ctx, client.TransferRequest{FromAccount: aliceAcc.Number, ToAccount: bobAcc.Number, Amount: 40}); err != nil {
+		t.Fatalf("Transfer: %v", err)
+	}
+	if len(transport.keys) != 2 || transport.keys[0] == "" || transport.keys[0] != transport.keys[1] {
+		t.Errorf("ключи попыток = %q, ожидались две попытки с одним ключом", transport.keys)
+	}
+
+	history, err := alice.Transactions(ctx, aliceAcc.Number, client.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Кроме перевода в истории есть пополнение
+	if len(history) != 2 || history[0].Kind != "transfer" {
+		t.Errorf("движения %+v, ожидался один перевод несмотря на повтор", history)
+	}
+
+	// Тот же ключ для другого перевода — конфликт
+	req := client.TransferRequest{FromAccount: aliceAcc.Number, ToAccount: bobAcc.Number, Amount: 1, IdempotencyKey: transport.keys[0]}
+	if err := alice.Transfer(ctx, req); !errors.Is(err, client.ErrIdempotencyConflict) {
+		t.Errorf("повтор ключа с другой суммой: %v", err)
+	}
+}
+
+func TestClientRefreshesToken(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	signup(t, srv, "alice")
+
+	expired := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   "1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+	})
+	expiredToken, err := expired.SignedString([]byte(jwtSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Истёкший токен заменяется до запроса
+	c := newClient(t, srv, client.WithToken(expiredToken), client.WithCredentials("alice@example.com", "secret"))
+	if _, err := c.CreateAccount(ctx); err != nil {
+		t.Fatalf("CreateAccount с истёкшим токеном: %v", err)
+	}
+	if c.Token() == expiredToken {
+		t.Error("токен не обновлён")
+	}
+
+	// Отвергнутый сервером токен заменяется после 401
+	c = newClient(t, srv, client.WithToken("garbage"), client.WithCredentials("alice@example.com", "secret"))
+	if _, err := c.CreateAccount(ctx); err != nil {
+		t.Fatalf("CreateAccount с невалидным токеном: %v", err)
+	}
+
+	// Без учётных данных обновить нечем
+	c = newClient(t, srv, client.WithToken(expiredToken))
+	if _, err := c.CreateAccount(ctx); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("ожидалась ErrUnauthorized, получено %v", err)
+	}
+}
+
+// TestCodesMatchServer проверяет, что коды клиента совпадают с кодами сервера
+// и спецификации.
+func TestCodesMatchServer(t *testing.T) {
+	clientCodes := []client.Code{
+		client.CodeInvalidRequest, client.CodeUnauthorized, client.CodeInvalidCredentials,
+		client.CodeUserExists, client.CodeUserNotFound, client.CodeInvalidAccountNumber, client.CodeAccountNotFound, client.CodeLoanNotFound,
+		client.CodePaymentRequestNotFound, client.CodePaymentRequestClosed,
+		client.CodeHoldNotFound, client.CodeHoldClosed, client.CodeTransactionNotFound, client.CodeTransferRefunded,
+		client.CodeBatchNotFound, client.CodeInvalidBatch, client.CodeBatchExists,
+		client.CodeApprovalNotFound, client.CodeApprovalClosed, client.CodeApprovalRequired,
+		client.CodeAccessNotFound, client.CodeAccessExists, client.CodeOwnerAccess,
+		client.CodePayeeNotFound, client.CodePayeeExists, client.CodePayeeCoolingOff,
+		client.CodeInvalidAmount, client.CodeSelfTransfer, client.CodeInsufficientFunds,
+		client.CodeAccountFrozen, client.CodeTransferLimitExceeded, client.CodeTransferNotAllowed,
+		client.CodeIdempotencyConflict, client.CodeInternal,
+	}
+	var got, server, spec []string
+	for _, c := range clientCodes {
+		got = append(got, string(c))
+	}
+	for _, c := range apierr.Codes {
+		server = append(server, string(c))
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(api.OpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range doc.Components.Schemas["Error"].Value.Properties["code"].Value.Enum {
+		spec = append(spec, v.(string))
+	}
+
+	slices.Sort(got)
+	slices.Sort(server)
+	slices.Sort(spec)
+	if !slices.Equal(got, server) {
+		t.Errorf("коды клиента %v не совпадают с кодами сервера %v", got, server)
+	}
+	if !slices.Equal(spec, server) {
+		t.Errorf("коды в openapi.yaml %v не совпадают с кодами сервера %v", spec, server)
+	}
+}
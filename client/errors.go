@@ -0,0 +1,117 @@
+package client
+
+import "fmt"
+
+// Code — машинно-читаемый код ошибки API. Значения совпадают с кодами сервера.
+type Code string
+
+const (
+	CodeInvalidRequest         Code = "invalid_request"
+	CodeUnauthorized           Code = "unauthorized"
+	CodeInvalidCredentials     Code = "invalid_credentials"
+	CodeUserExists             Code = "user_exists"
+	CodeUserNotFound           Code = "user_not_found"
+	CodeInvalidAccountNumber   Code = "invalid_account_number"
+	CodeAccountNotFound        Code = "account_not_found"
+	CodeLoanNotFound           Code = "loan_not_found"
+	CodePaymentRequestNotFound Code = "payment_request_not_found"
+	CodePaymentRequestClosed   Code = "payment_request_closed"
+	CodeHoldNotFound           Code = "hold_not_found"
+	CodeHoldClosed             Code = "hold_closed"
+	CodeTransactionNotFound    Code = "transaction_not_found"
+	CodeTransferRefunded       Code = "transfer_refunded"
+	CodeBatchNotFound          Code = "batch_not_found"
+	CodeInvalidBatch           Code = "invalid_batch"
+	CodeBatchExists            Code = "batch_exists"
+	CodeApprovalNotFound       Code = "approval_not_found"
+	CodeApprovalClosed         Code = "approval_closed"
+	CodeApprovalRequired       Code = "approval_required"
+	CodeAccessNotFound         Code = "access_not_found"
+	CodeAccessExists           Code = "access_exists"
+	CodeOwnerAccess            Code = "owner_access"
+	CodePayeeNotFound          Code = "payee_not_found"
+	CodePayeeExists            Code = "payee_exists"
+	CodePayeeCoolingOff        Code = "payee_cooling_off"
+	CodeInvalidAmount          Code = "invalid_amount"
+	CodeSelfTransfer           Code = "self_transfer"
+	CodeInsufficientFunds      Code = "insufficient_funds"
+	CodeAccountFrozen          Code = "account_frozen"
+	CodeTransferLimitExceeded  Code = "transfer_limit_exceeded"
+	CodeTransferNotAllowed     Code = "transfer_not_allowed"
+	CodeIdempotencyConflict    Code = "idempotency_conflict"
+	CodeInternal               Code = "internal"
+)
+
+// Error — ошибка, которую вернул сервер. Сравнивается с ErrXxx через errors.Is
+// по коду:
+//
+//	if errors.Is(err, client.ErrInsufficientFunds) { ... }
+type Error struct {
+	StatusCode int    `json:"-"`
+	Code       Code   `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("banking api: HTTP %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("banking api: %s: %s", e.Code, e.Message)
+}
+
+// Is сравнивает ошибки по коду.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
+// Ошибки для сравнения через errors.Is.
+var (
+	ErrInvalidRequest         = &Error{Code: CodeInvalidRequest}
+	ErrUnauthorized           = &Error{Code: CodeUnauthorized}
+	ErrInvalidCredentials     = &Error{Code: CodeInvalidCredentials}
+	ErrUserExists             = &Error{Code: CodeUserExists}
+	ErrUserNotFound           = &Error{Code: CodeUserNotFound}
+	ErrInvalidAccountNumber   = &Error{Code: CodeInvalidAccountNumber}
+	ErrAccountNotFound        = &Error{Code: CodeAccountNotFound}
+	ErrLoanNotFound           = &Error{Code: CodeLoanNotFound}
+	ErrPaymentRequestNotFound = &Error{Code: CodePaymentRequestNotFound}
+	ErrPaymentRequestClosed   = &Error{Code: CodePaymentRequestClosed}
+	ErrHoldNotFound           = &Error{Code: CodeHoldNotFound}
+	ErrHoldClosed             = &Error{Code: CodeHoldClosed}
+	ErrTransactionNotFound    = &Error{Code: CodeTransactionNotFound}
+	ErrTransferRefunded       = &Error{Code: CodeTransferRefunded}
+	ErrBatchNotFound          = &Error{Code: CodeBatchNotFound}
+	ErrInvalidBatch           = &Error{Code: CodeInvalidBatch}
+	ErrBatchExists            = &Error{Code: CodeBatchExists}
+	ErrApprovalNotFound       = &Error{Code: CodeApprovalNotFound}
+	ErrApprovalClosed         = &Error{Code: CodeApprovalClosed}
+	ErrApprovalRequired       = &Error{Code: CodeApprovalRequired}
+	ErrAccessNotFound         = &Error{Code: CodeAccessNotFound}
+	ErrAccessExists           = &Error{Code: CodeAccessExists}
+	ErrOwnerAccess            = &Error{Code: CodeOwnerAccess}
+	ErrPayeeNotFound          = &Error{Code: CodePayeeNotFound}
+	ErrPayeeExists            = &Error{Code: CodePayeeExists}
+	ErrPayeeCoolingOff        = &Error{Code: CodePayeeCoolingOff}
+	ErrInvalidAmount          = &Error{Code: CodeInvalidAmount}
+	ErrSelfTransfer           = &Error{Code: CodeSelfTransfer}
+	ErrInsufficientFunds      = &Error{Code: CodeInsufficientFunds}
+	ErrAccountFrozen          = &Error{Code: CodeAccountFrozen}
+	ErrTransferLimitExceeded  = &Error{Code: CodeTransferLimitExceeded}
+	ErrTransferNotAllowed     = &Error{Code: CodeTransferNotAllowed}
+	ErrIdempotencyConflict    = &Error{Code: CodeIdempotencyConflict}
+	ErrInternal               = &Error{Code: CodeInternal}
+)
+
+// ApprovalPendingError — перевод больше порога одобрения счёта не выполнен,
+// а поставлен на одобрение (ответ 202). Решение можно узнать через Approval:
+//
+//	var pending *client.ApprovalPendingError
+//	if errors.As(err, &pending) { ... pending.Approval.ID ... }
+type ApprovalPendingError struct {
+	Approval *TransferApproval
+}
+
+func (e *ApprovalPendingError) Error() string {
+	return fmt.Sprintf("banking api: перевод %d ждёт одобрения", e.Approval.ID)
+}
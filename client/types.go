@@ -0,0 +1,302 @@
+package client
+
+import "time"
+
+type User struct {
+	ID        int64     `json:"id"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Типы счетов
+const (
+	AccountChecking = "checking"
+	AccountSavings  = "savings"
+	AccountCredit   = "credit"
+)
+
+// Account — счёт. Number — номер счёта в формате IBAN, им счёт указывается во
+// всех остальных вызовах.
+type Account struct {
+	Number               string    `json:"number"`
+	Type                 string    `json:"type"`
+	Balance              float64   `json:"balance"`
+	CreditLimit          float64   `json:"credit_limit"`
+	MonthlyTransferLimit int       `json:"monthly_transfer_limit"`
+	CreatedAt            time.Time `json:"createdAt"`
+}
+
+// Balance — остатки счёта: учтённый баланс, сумма блокировок и сколько можно списать сейчас.
+type Balance struct {
+	Account   string  `json:"account"`
+	Ledger    float64 `json:"ledger"`
+	Held      float64 `json:"held"`
+	Available float64 `json:"available"`
+}
+
+type Transaction struct {
+	ID   int64  `json:"id"`
+	Kind string `json:"kind"`
+	// FromAccount и ToAccount — номера счетов; пусты у корректировок без второй стороны.
+	FromAccount string    `json:"from_account,omitempty"`
+	ToAccount   string    `json:"to_account,omitempty"`
+	Amount      float64   `json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+	// OriginalTransactionID — исходное движение возврата (refund) или сторно (reversal).
+	OriginalTransactionID int64 `json:"original_transaction_id,omitempty"`
+	// ReasonCode — код причины сторно.
+	ReasonCode string `json:"reason_code,omitempty"`
+}
+
+// InterestAccrual — начисление процентов за один день.
+type InterestAccrual struct {
+	Day         time.Time `json:"day"`
+	Balance     float64   `json:"balance"`
+	AnnualRate  float64   `json:"annual_rate"`
+	Amount      float64   `json:"amount"`
+	Capitalized bool      `json:"capitalized"`
+}
+
+// InterestStatement — начисления процентов по счёту за месяц.
+type InterestStatement struct {
+	Account  string            `json:"account"`
+	Month    string            `json:"month"`
+	Total    float64           `json:"total"`
+	Accruals []InterestAccrual `json:"accruals"`
+}
+
+// Loan — кредит. Schedule заполняется только в ответе Client.Loan.
+type Loan struct {
+	ID          int64             `json:"id"`
+	Account     string            `json:"account"`
+	Principal   float64           `json:"principal"`
+	AnnualRate  float64           `json:"annual_rate"`
+	TermMonths  int               `json:"term_months"`
+	Method      string            `json:"method"`
+	LateFee     float64           `json:"late_fee"`
+	StartDate   time.Time         `json:"start_date"`
+	Outstanding float64           `json:"outstanding"`
+	Arrears     float64           `json:"arrears"`
+	Status      string            `json:"status"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Schedule    []LoanInstallment `json:"schedule"`
+}
+
+// LoanInstallment — платёж по графику кредита.
+type LoanInstallment struct {
+	Number        int       `json:"number"`
+	DueDate       time.Time `json:"due_date"`
+	Principal     float64   `json:"principal"`
+	Interest      float64   `json:"interest"`
+	LateFee       float64   `json:"late_fee"`
+	PaidPrincipal float64   `json:"paid_principal"`
+	PaidInterest  float64   `json:"paid_interest"`
+	Status        string    `json:"status"`
+}
+
+// LoanRepayment — итог досрочного погашения кредита.
+type LoanRepayment struct {
+	LoanID      int64   `json:"loan_id"`
+	Amount      float64 `json:"amount"`
+	Scheduled   float64 `json:"scheduled"`
+	Principal   float64 `json:"principal"`
+	Outstanding float64 `json:"outstanding"`
+	Status      string  `json:"status"`
+}
+
+// PaymentRequest — запрос денег: Requester просит Payer перевести Amount на счёт ToAccount.
+type PaymentRequest struct {
+	ID            int64      `json:"id"`
+	Requester     string     `json:"requester"`
+	Payer         string     `json:"payer"`
+	ToAccount     string     `json:"to_account"`
+	Amount        float64    `json:"amount"`
+	Note          string     `json:"note"`
+	Status        string     `json:"status"`
+	TransactionID int64      `json:"transaction_id"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ResolvedAt    *time.Time `json:"resolved_at"`
+}
+
+// NewPaymentRequest — запрос денег у пользователя Payer на свой счёт ToAccount.
+type NewPaymentRequest struct {
+	Payer     string  `json:"payer"`
+	ToAccount string  `json:"to_account"`
+	Amount    float64 `json:"amount"`
+	Note      string  `json:"note,omitempty"`
+}
+
+// PaymentRequestFilter выбирает запросы денег: входящие (по умолчанию) или
+// исходящие (Outgoing), непустой Status оставляет запросы в этом статусе.
+type PaymentRequestFilter struct {
+	Outgoing bool
+	Status   string
+}
+
+// Hold — блокировка Amount на счёте Account в пользу счёта ToAccount.
+type Hold struct {
+	ID             int64      `json:"id"`
+	Account        string     `json:"account"`
+	ToAccount      string     `json:"to_account"`
+	Amount         float64    `json:"amount"`
+	CapturedAmount float64    `json:"captured_amount"`
+	Status         string     `json:"status"`
+	TransactionID  int64      `json:"transaction_id"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+}
+
+// NewHold — блокировка Amount на своём счёте Account в пользу счёта ToAccount.
+type NewHold struct {
+	Account   string  `json:"account"`
+	ToAccount string  `json:"to_account"`
+	Amount    float64 `json:"amount"`
+}
+
+// BatchLine — строка пакета переводов: получатель по username или по номеру
+// счёта (ровно одно из двух), сумма и назначение платежа.
+type BatchLine struct {
+	ToUsername string  `json:"to_username,omitempty"`
+	ToAccount  string  `json:"to_account,omitempty"`
+	Amount     float64 `json:"amount"`
+	Reference  string  `json:"reference,omitempty"`
+}
+
+// Batch — пакет переводов со счёта FromAccount. Items заполнены только
+// в ответах SubmitBatch и Batch.
+type Batch struct {
+	ID           int64       `json:"id"`
+	FromAccount  string      `json:"from_account"`
+	AllOrNothing bool        `json:"all_or_nothing"`
+	Status       string      `json:"status"`
+	Total        int         `json:"total"`
+	Amount       float64     `json:"amount"`
+	Succeeded    int         `json:"succeeded"`
+	Failed       int         `json:"failed"`
+	CreatedAt    time.Time   `json:"created_at"`
+	CompletedAt  *time.Time  `json:"completed_at"`
+	Items        []BatchItem `json:"items"`
+}
+
+// BatchItem — строка пакета с результатом: Status succeeded, failed (причина
+// в Error) или skipped.
+type BatchItem struct {
+	Line          int     `json:"line"`
+	ToUsername    string  `json:"to_username"`
+	ToAccount     string  `json:"to_account"`
+	Amount        float64 `json:"amount"`
+	Reference     string  `json:"reference"`
+	Status        string  `json:"status"`
+	Error         string  `json:"error"`
+	TransactionID int64   `json:"transaction_id"`
+}
+
+// TransferApproval — перевод больше порога одобрения счёта: Maker создал его,
+// и он выполняется, только когда его одобрит одобряющий счёта (Checker).
+// Status — pending, approved, rejected или expired.
+type TransferApproval struct {
+	ID            int64      `json:"id"`
+	FromAccount   string     `json:"from_account"`
+	ToAccount     string     `json:"to_account"`
+	Amount        float64    `json:"amount"`
+	Maker         string     `json:"maker"`
+	Checker       string     `json:"checker"`
+	Status        string     `json:"status"`
+	Reason        string     `json:"reason"`
+	TransactionID int64      `json:"transaction_id"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ResolvedAt    *time.Time `json:"resolved_at"`
+}
+
+// Уровни доступа к совместному счёту: каждый следующий включает права предыдущих.
+const (
+	AccessView     = "view"
+	AccessTransact = "transact"
+	AccessManage   = "manage"
+)
+
+// AccountAccess — доступ пользователя к счёту. Status — pending (приглашение
+// ещё не принято) или active; доступ владельца (Owner) не отзывается.
+type AccountAccess struct {
+	Account    string     `json:"account"`
+	Username   string     `json:"username"`
+	Level      string     `json:"level"`
+	Status     string     `json:"status"`
+	Owner      bool       `json:"owner"`
+	InvitedBy  string     `json:"invited_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+}
+
+// Payee — получатель из адресной книги: пользователь (Username) или счёт
+// (Account). До CoolingOffUntil крупные переводы ему не выполняются.
+type Payee struct {
+	ID              int64     `json:"id"`
+	Nickname        string    `json:"nickname"`
+	Username        string    `json:"username"`
+	Account         string    `json:"account"`
+	Reference       string    `json:"reference"`
+	CoolingOffUntil time.Time `json:"cooling_off_until"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// PayeeRequest — получатель для добавления или изменения: Username или номер счёта Account.
+type PayeeRequest struct {
+	Nickname  string `json:"nickname"`
+	Username  string `json:"username,omitempty"`
+	Account   string `json:"account,omitempty"`
+	Reference string `json:"reference,omitempty"`
+}
+
+// PayeeTransferRequest — перевод получателю из адресной книги. Пустой
+// Reference заменяется назначением получателя.
+type PayeeTransferRequest struct {
+	PayeeID        int64   `json:"-"`
+	FromAccount    string  `json:"from_account"`
+	Amount         float64 `json:"amount"`
+	Reference      string  `json:"reference,omitempty"`
+	IdempotencyKey string  `json:"-"`
+}
+
+// TransferRequest — перевод со своего счёта. Если IdempotencyKey пуст, клиент
+// генерирует ключ сам и использует его во всех повторах этого вызова.
+type TransferRequest struct {
+	FromAccount    string  `json:"from_account"`
+	ToAccount      string  `json:"to_account"`
+	Amount         float64 `json:"amount"`
+	IdempotencyKey string  `json:"-"`
+}
+
+// TransferByUsernamesRequest — перевод с первого счёта пользователя клиента
+// на первый счёт получателя.
+type TransferByUsernamesRequest struct {
+	// FromUsername необязателен: сервер переводит от пользователя токена и
+	// отклоняет запрос, если имя с ним не совпадает.
+	FromUsername   string  `json:"from_username,omitempty"`
+	ToUsername     string  `json:"to_username"`
+	Amount         float64 `json:"amount"`
+	IdempotencyKey string  `json:"-"`
+}
+
+// ListOptions — страница истории. Нулевые значения — значения сервера по умолчанию.
+type ListOptions struct {
+	Limit  int
+	Offset int
+}
+
+// Readiness — ответ /readyz.
+type Readiness struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// BuildInfo — ответ /version.
+type BuildInfo struct {
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
@@ -0,0 +1,598 @@
+// bankctl — CLI для службы поддержки: поиск пользователей, просмотр счетов и
+// истории, заморозка счетов, ручные корректировки с аудитом и сверка. Работает
+// напрямую с БД через те же сервисы, что и API.
+package main
+
+import (
+	"banking-api/internal/accountno"
+	"banking-api/internal/config"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/service"
+	"banking-api/internal/tracing"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+const usage = `Использование: bankctl [-db URL] [-operator имя] [-json] [-v] <команда> [аргументы]
+
+Команды:
+  user <id|email|username>                     найти пользователя
+  accounts <user>                              счета пользователя
+  transactions [-limit N] [-offset N] <счёт>   история счёта
+  freeze -reason "..." <счёт>                  заморозить счёт
+  unfreeze -reason "..." <счёт>                разморозить счёт
+  adjust -reason "..." <счёт> <сумма>          корректировка: сумма > 0 — зачисление, < 0 — списание
+  reverse -reason "..." -code КОД <транзакция>
+                                               сторно ещё не возвращённого остатка перевода или комиссии;
+                                               КОД — duplicate, fraud, error или dispute
+  overdraft -reason "..." <счёт> <лимит> [ставка] [комиссия]
+                                               овердрафт расчётного счёта: лимит, годовая ставка в %,
+                                               комиссия за уход в минус; лимит 0 — отключить
+  approvals -reason "..." [-approvers a,b] <счёт> <порог>
+                                               переводы со счёта больше порога выполняются после
+                                               одобрения одним из пользователей -approvers; порог 0 — отключить
+  audit [-limit N] [счёт]                      журнал действий операторов
+  reconcile                                    сверка; код выхода 1 при нарушениях
+  rates                                        расписание процентных ставок
+  set-rate -reason "..." [-from ДАТА] <тип> <ставка>
+                                               годовая ставка в % для типа счёта с даты (по умолчанию сегодня)
+  interest [-date ДАТА]                        начислить проценты за день (по умолчанию вчера);
+                                               в последний день месяца — капитализация. Повтор безопасен
+  loan -reason "..." [-method annuity|linear] [-fee N] <счёт> <сумма> <ставка> <месяцев>
+                                               выдать кредит владельцу счёта и зачислить сумму на счёт;
+                                               -fee — пени за каждый просроченный платёж
+  loans <user>                                 кредиты пользователя
+  loans-collect [-date ДАТА]                   списать платежи по кредитам за день (по умолчанию вчера).
+                                               Повтор безопасен
+
+Счёт — номер из bankctl accounts (например, RU53GOBK012345678901).
+Даты — в формате YYYY-MM-DD (UTC).
+
+Флаги:
+`
+
+// errViolations — сверка нашла нарушения (код выхода 1 без сообщения об ошибке).
+var errViolations = errors.New("сверка выявила нарушения")
+
+type cli struct {
+	admin    *service.AdminService
+	interest *service.InterestService
+	loans    *service.LoanService
+	operator string
+	json     bool
+	out      io.Writer
+}
+
+func main() {
+	// .env необязателен: bankctl часто запускают с явным -db
+	_ = godotenv.Load()
+
+	fs := flag.NewFlagSet("bankctl", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	dbURL := fs.String("db", os.Getenv("DB_URL"), "адрес БД (по умолчанию DB_URL)")
+	operator := fs.String("operator", os.Getenv("USER"), "имя оператора для журнала аудита")
+	asJSON := fs.Bool("json", false, "вывод в JSON")
+	verbose := fs.Bool("v", false, "выводить журнал сервисов в stderr")
+	fs.Parse(os.Args[1:])
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	config.InitLogger()
+	config.Log.SetOutput(os.Stderr)
+	if !*verbose {
+		// Ошибки и так выводятся самой командой
+		config.Log.SetLevel(logrus.FatalLevel)
+	}
+
+	// Системные счета, которые открывают interest и loan, нумеруются по схеме сервиса
+	if v := os.Getenv("ACCOUNT_NUMBER_COUNTRY"); v != "" {
+		accountno.Default.Country = v
+	}
+	if v := os.Getenv("ACCOUNT_NUMBER_BANK"); v != "" {
+		accountno.Default.Bank = v
+	}
+	if err := accountno.Default.Validate(); err != nil {
+		fatal(fmt.Errorf("некорректная схема номеров счетов: %w", err))
+	}
+
+	db, dialect, err := openDB(*dbURL)
+	if err != nil {
+		fatal(err)
+	}
+	defer db.Close()
+
+	c := &cli{
+		admin: service.NewAdminService(
+			repository.NewSQLAdminRepository(db, dialect),
+			repository.NewSQLAccountRepository(db, dialect),
+			repository.NewSQLUserRepository(db, dialect),
+		),
+		interest: service.NewInterestService(
+			repository.NewSQLInterestRepository(db, dialect),
+			repository.NewSQLAccountRepository(db, dialect),
+		),
+		loans: service.NewLoanService(
+			repository.NewSQLLoanRepository(db, dialect),
+			repository.NewSQLAccountRepository(db, dialect),
+		),
+		operator: *operator,
+		json:     *asJSON,
+		out:      os.Stdout,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	err = c.run(ctx, fs.Arg(0), fs.Args()[1:])
+	cancel()
+	if errors.Is(err, errViolations) {
+		os.Exit(1)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func openDB(dbURL string) (*sql.DB, repository.Dialect, error) {
+	driver, dsn, err := config.ParseDBURL(dbURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("некорректный адрес БД: %w", err)
+	}
+	dialect, err := repository.DialectFor(driver)
+	if err != nil {
+		return nil, dialect, err
+	}
+	db, err := tracing.OpenDB(driver, dsn)
+	if err != nil {
+		return nil, dialect, err
+	}
+	if driver == "sqlite" {
+		db.SetMaxOpenConns(1)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, dialect, fmt.Errorf("БД недоступна: %w", err)
+	}
+	return db, dialect, nil
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "bankctl:", err)
+	os.Exit(1)
+}
+
+func (c *cli) run(ctx context.Context, cmd string, args []string) error {
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	reason := fs.String("reason", "", "причина (обязательна для freeze, unfreeze, adjust, reverse, overdraft, approvals, set-rate, loan)")
+	limit := fs.Int("limit", 0, "размер страницы")
+	offset := fs.Int("offset", 0, "смещение")
+	from := fs.String("from", "", "дата начала действия ставки")
+	date := fs.String("date", "", "день начисления или списания")
+	method := fs.String("method", models.LoanAnnuity, "способ погашения кредита: annuity или linear")
+	fee := fs.Float64("fee", 0, "пени за просроченный платёж")
+	code := fs.String("code", "", "код причины сторно")
+	approvers := fs.String("approvers", "", "username одобряющих переводы через запятую")
+	pos := parseInterspersed(fs, args)
+
+	switch cmd {
+	case "user":
+		if len(pos) != 1 {
+			return fmt.Errorf("использование: bankctl user <id|email|username>")
+		}
+		user, err := c.admin.FindUser(ctx, pos[0])
+		if err != nil {
+			return err
+		}
+		return c.print(user, func(w io.Writer) {
+			fmt.Fprintln(w, "ID\tUSERNAME\tEMAIL\tСОЗДАН")
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", user.ID, user.Username, user.Email, formatTime(user.CreatedAt))
+		})
+
+	case "accounts":
+		if len(pos) != 1 {
+			return fmt.Errorf("использование: bankctl accounts <id|email|username>")
+		}
+		user, err := c.admin.FindUser(ctx, pos[0])
+		if err != nil {
+			return err
+		}
+		accounts, err := c.admin.ListAccounts(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		return c.print(accounts, func(w io.Writer) {
+			fmt.Fprintln(w, "НОМЕР\tТИП\tБАЛАНС\tКРЕДИТНЫЙ ЛИМИТ\tОВЕРДРАФТ\tПЕРЕВОДОВ В МЕСЯЦ\tПОРОГ ОДОБРЕНИЯ\tЗАМОРОЖЕН\tСОЗДАН")
+			for _, a := range accounts {
+				overdraft := "-"
+				if a.OverdraftLimit > 0 {
+					overdraft = fmt.Sprintf("%.2f под %.4g%%, комиссия %.2f", a.OverdraftLimit, a.OverdraftRate, a.OverdraftFee)
+				}
+				approval := "-"
+				if a.ApprovalThreshold > 0 {
+					approval = fmt.Sprintf("%.2f", a.ApprovalThreshold)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%s\t%d\t%s\t%t\t%s\n",
+					a.Number, a.Type, a.Balance, a.CreditLimit, overdraft, a.MonthlyTransferLimit, approval, a.Frozen, formatTime(a.CreatedAt))
+			}
+		})
+
+	case "transactions":
+		accountID, _, err := c.accountArg(ctx, pos, "transactions [-limit N] [-offset N] <счёт>")
+		if err != nil {
+			return err
+		}
+		transactions, err := c.admin.ListTransactions(ctx, accountID, *limit, *offset)
+		if err != nil {
+			return err
+		}
+		return c.print(transactions, func(w io.Writer) {
+			fmt.Fprintln(w, "ID\tВИД\tОТКУДА\tКУДА\tСУММА\tИСХОДНАЯ\tСОЗДАН")
+			for _, t := range transactions {
+				original := "-"
+				if t.OriginalTransactionID != 0 {
+					original = strconv.FormatInt(t.OriginalTransactionID, 10)
+					if t.ReasonCode != "" {
+						original += " (" + t.ReasonCode + ")"
+					}
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%.2f\t%s\t%s\n", t.ID, t.Kind, orDash(t.FromAccount), orDash(t.ToAccount), t.Amount, original, formatTime(t.CreatedAt))
+			}
+		})
+
+	case "freeze", "unfreeze":
+		accountID, number, err := c.accountArg(ctx, pos, cmd+` -reason "..." <счёт>`)
+		if err != nil {
+			return err
+		}
+		if cmd == "freeze" {
+			err = c.admin.Freeze(ctx, c.operator, accountID, *reason)
+		} else {
+			err = c.admin.Unfreeze(ctx, c.operator, accountID, *reason)
+		}
+		if err != nil {
+			return err
+		}
+		return c.print(map[string]any{"account": number, "frozen": cmd == "freeze"}, func(w io.Writer) {
+			fmt.Fprintf(w, "Счёт %s: %s выполнено\n", number, cmd)
+		})
+
+	case "adjust":
+		if len(pos) != 2 {
+			return fmt.Errorf(`использование: bankctl adjust -reason "..." <счёт> <сумма>`)
+		}
+		accountID, number, err := c.accountArg(ctx, pos[:1], "")
+		if err != nil {
+			return err
+		}
+		amount, err := strconv.ParseFloat(pos[1], 64)
+		if err != nil {
+			return fmt.Errorf("некорректная сумма %q", pos[1])
+		}
+		t, err := c.admin.Adjust(ctx, c.operator, accountID, amount, *reason)
+		if err != nil {
+			return err
+		}
+		return c.print(t, func(w io.Writer) {
+			fmt.Fprintf(w, "Корректировка %+.2f по счёту %s записана (транзакция %d)\n", amount, number, t.ID)
+		})
+
+	case "reverse":
+		if len(pos) != 1 {
+			return fmt.Errorf(`использование: bankctl reverse -reason "..." -code КОД <транзакция>`)
+		}
+		transactionID, err := strconv.ParseInt(pos[0], 10, 64)
+		if err != nil || transactionID <= 0 {
+			return fmt.Errorf("некорректный ID транзакции %q", pos[0])
+		}
+		t, err := c.admin.Reverse(ctx, c.operator, transactionID, *code, *reason)
+		if err != nil {
+			return err
+		}
+		return c.print(t, func(w io.Writer) {
+			fmt.Fprintf(w, "Сторно %.2f по транзакции %d на счёт %s записано (транзакция %d)\n", t.Amount, transactionID, t.ToAccount, t.ID)
+		})
+
+	case "overdraft":
+		if len(pos) < 2 || len(pos) > 4 {
+			return fmt.Errorf(`использование: bankctl overdraft -reason "..." <счёт> <лимит> [ставка] [комиссия]`)
+		}
+		accountID, number, err := c.accountArg(ctx, pos[:1], "")
+		if err != nil {
+			return err
+		}
+		var terms [3]float64
+		for i, v := range pos[1:] {
+			if terms[i], err = strconv.ParseFloat(v, 64); err != nil {
+				return fmt.Errorf("некорректное число %q", v)
+			}
+		}
+		overdraft := models.Overdraft{Limit: terms[0], Rate: terms[1], Fee: terms[2]}
+		if err := c.admin.SetOverdraft(ctx, c.operator, accountID, overdraft, *reason); err != nil {
+			return err
+		}
+		return c.print(overdraft, func(w io.Writer) {
+			fmt.Fprintf(w, "Овердрафт счёта %s: лимит %.2f, ставка %.4g%%, комиссия %.2f\n", number, overdraft.Limit, overdraft.Rate, overdraft.Fee)
+		})
+
+	case "approvals":
+		if len(pos) != 2 {
+			return fmt.Errorf(`использование: bankctl approvals -reason "..." [-approvers a,b] <счёт> <порог>`)
+		}
+		accountID, number, err := c.accountArg(ctx, pos[:1], "")
+		if err != nil {
+			return err
+		}
+		threshold, err := strconv.ParseFloat(pos[1], 64)
+		if err != nil {
+			return fmt.Errorf("некорректный порог %q", pos[1])
+		}
+		var usernames []string
+		for _, username := range strings.Split(*approvers, ",") {
+			if username = strings.TrimSpace(username); username != "" {
+				usernames = append(usernames, username)
+			}
+		}
+		if err := c.admin.SetApprovalPolicy(ctx, c.operator, accountID, threshold, usernames, *reason); err != nil {
+			return err
+		}
+		return c.print(map[string]any{"account": number, "threshold": threshold, "approvers": usernames}, func(w io.Writer) {
+			if threshold == 0 {
+				fmt.Fprintf(w, "Одобрение переводов со счёта %s отключено\n", number)
+				return
+			}
+			fmt.Fprintf(w, "Переводы со счёта %s больше %.2f одобряют: %s\n", number, threshold, strings.Join(usernames, ", "))
+		})
+
+	case "audit":
+		var accountID int64
+		if len(pos) > 0 {
+			id, _, err := c.accountArg(ctx, pos, "audit [-limit N] [счёт]")
+			if err != nil {
+				return err
+			}
+			accountID = id
+		}
+		entries, err := c.admin.AuditLog(ctx, accountID, *limit)
+		if err != nil {
+			return err
+		}
+		return c.print(entries, func(w io.Writer) {
+			fmt.Fprintln(w, "ID\tВРЕМЯ\tОПЕРАТОР\tДЕЙСТВИЕ\tСЧЁТ\tСУММА\tПРИЧИНА")
+			for _, e := range entries {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%.2f\t%s\n", e.ID, formatTime(e.CreatedAt), e.Actor, e.Action, orDash(e.Account), e.Amount, e.Reason)
+			}
+		})
+
+	case "reconcile":
+		report, err := c.admin.Reconcile(ctx)
+		if err != nil {
+			return err
+		}
+		err = c.print(report, func(w io.Writer) {
+			fmt.Fprintf(w, "Счетов:\t%d\n", report.Accounts)
+			fmt.Fprintf(w, "Сумма балансов:\t%.2f\n", report.TotalBalance)
+			fmt.Fprintf(w, "Внешние движения:\t%.2f\n", report.ExternalNet)
+			fmt.Fprintf(w, "Дисбаланс:\t%.2f\n", report.Imbalance)
+			fmt.Fprintf(w, "Транзакций:\t%d\n", report.Transactions)
+			fmt.Fprintf(w, "Отрицательные балансы:\t%v\n", report.NegativeBalances)
+			fmt.Fprintf(w, "Некорректные транзакции:\t%v\n", report.InvalidTransactions)
+			fmt.Fprintf(w, "Расхождения с историей:\t%d\n", len(report.BalanceMismatches))
+			if len(report.BalanceMismatches) > 0 {
+				fmt.Fprintln(w, "\nСЧЁТ\tБАЛАНС\tПО ДВИЖЕНИЯМ\tРАЗНИЦА")
+				for _, m := range report.BalanceMismatches {
+					fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%.2f\n", m.Account, m.Balance, m.Expected, m.Difference)
+				}
+			}
+		})
+		if err == nil && !report.OK() {
+			return errViolations
+		}
+		return err
+
+	case "rates":
+		rates, err := c.interest.Rates(ctx)
+		if err != nil {
+			return err
+		}
+		return c.print(rates, func(w io.Writer) {
+			fmt.Fprintln(w, "ТИП\tС\tСТАВКА, %")
+			for _, r := range rates {
+				fmt.Fprintf(w, "%s\t%s\t%.4g\n", r.AccountType, r.EffectiveFrom.Format(repository.DayLayout), r.AnnualRate)
+			}
+		})
+
+	case "set-rate":
+		if len(pos) != 2 {
+			return fmt.Errorf(`использование: bankctl set-rate -reason "..." [-from ДАТА] <тип> <ставка>`)
+		}
+		rate, err := strconv.ParseFloat(pos[1], 64)
+		if err != nil {
+			return fmt.Errorf("некорректная ставка %q", pos[1])
+		}
+		day, err := parseDay(*from, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := c.interest.SetRate(ctx, c.operator, pos[0], rate, day, *reason); err != nil {
+			return err
+		}
+		return c.print(map[string]any{"account_type": pos[0], "annual_rate": rate, "effective_from": day}, func(w io.Writer) {
+			fmt.Fprintf(w, "Ставка %s: %.4g%% с %s\n", pos[0], rate, day.Format(repository.DayLayout))
+		})
+
+	case "interest":
+		day, err := parseDay(*date, time.Now().AddDate(0, 0, -1))
+		if err != nil {
+			return err
+		}
+		accrued, capitalization, err := c.interest.RunDay(ctx, day)
+		if err != nil {
+			return err
+		}
+		return c.print(map[string]any{"day": day, "accrued": accrued, "capitalization": capitalization}, func(w io.Writer) {
+			fmt.Fprintf(w, "Начислено за %s:\t%d счетов\n", day.Format(repository.DayLayout), accrued)
+			if capitalization != nil {
+				fmt.Fprintf(w, "Капитализировано за %s:\t%d счетов, %.2f\n", capitalization.Month.Format("2006-01"), capitalization.Accounts, capitalization.Total)
+			}
+		})
+
+	case "loan":
+		if len(pos) != 4 {
+			return fmt.Errorf(`использование: bankctl loan -reason "..." [-method annuity|linear] [-fee N] <счёт> <сумма> <ставка> <месяцев>`)
+		}
+		accountID, _, err := c.accountArg(ctx, pos[:1], "")
+		if err != nil {
+			return err
+		}
+		var terms [2]float64
+		for i, v := range pos[1:3] {
+			if terms[i], err = strconv.ParseFloat(v, 64); err != nil {
+				return fmt.Errorf("некорректное число %q", v)
+			}
+		}
+		months, err := strconv.Atoi(pos[3])
+		if err != nil {
+			return fmt.Errorf("некорректный срок %q", pos[3])
+		}
+		loan, err := c.loans.Issue(ctx, c.operator, accountID, models.LoanTerms{
+			Principal:  terms[0],
+			AnnualRate: terms[1],
+			TermMonths: months,
+			Method:     *method,
+			LateFee:    *fee,
+		}, *reason)
+		if err != nil {
+			return err
+		}
+		return c.print(loan, func(w io.Writer) {
+			fmt.Fprintf(w, "Кредит %d: %.2f под %.4g%% на %d мес. (%s) зачислен на счёт %s\n",
+				loan.ID, loan.Principal, loan.AnnualRate, loan.TermMonths, loan.Method, loan.Account)
+		})
+
+	case "loans":
+		if len(pos) != 1 {
+			return fmt.Errorf("использование: bankctl loans <id|email|username>")
+		}
+		user, err := c.admin.FindUser(ctx, pos[0])
+		if err != nil {
+			return err
+		}
+		loans, err := c.loans.Loans(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		return c.print(loans, func(w io.Writer) {
+			fmt.Fprintln(w, "ID\tСЧЁТ\tСУММА\tСТАВКА, %\tМЕС.\tСПОСОБ\tОСТАТОК\tПРОСРОЧКА\tСТАТУС\tВЫДАН")
+			for _, l := range loans {
+				fmt.Fprintf(w, "%d\t%s\t%.2f\t%.4g\t%d\t%s\t%.2f\t%.2f\t%s\t%s\n",
+					l.ID, l.Account, l.Principal, l.AnnualRate, l.TermMonths, l.Method, l.Outstanding, l.Arrears, l.Status, l.StartDate.Format(repository.DayLayout))
+			}
+		})
+
+	case "loans-collect":
+		day, err := parseDay(*date, time.Now().AddDate(0, 0, -1))
+		if err != nil {
+			return err
+		}
+		collection, err := c.loans.Collect(ctx, day)
+		if err != nil {
+			return err
+		}
+		return c.print(collection, func(w io.Writer) {
+			fmt.Fprintf(w, "Списано за %s:\t%.2f по %d кредитам\n", day.Format(repository.DayLayout), collection.Amount, collection.Loans)
+			fmt.Fprintf(w, "Новых просрочек:\t%d\n", collection.Overdue)
+			fmt.Fprintf(w, "Погашено кредитов:\t%d\n", collection.Closed)
+		})
+	}
+	return fmt.Errorf("неизвестная команда %q, см. bankctl -h", cmd)
+}
+
+// parseInterspersed разбирает флаги, стоящие в любом месте среди позиционных
+// аргументов. Отрицательные числа (суммы списания) считаются аргументами, а не флагами.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var pos []string
+	for len(args) > 0 {
+		if _, err := strconv.ParseFloat(args[0], 64); err == nil {
+			pos = append(pos, args[0])
+			args = args[1:]
+			continue
+		}
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) > 0 {
+			pos = append(pos, args[0])
+			args = args[1:]
+		}
+	}
+	return pos
+}
+
+// accountArg находит счёт по номеру из единственного аргумента и возвращает
+// его ID и нормализованный номер.
+func (c *cli) accountArg(ctx context.Context, pos []string, syntax string) (int64, string, error) {
+	if len(pos) != 1 {
+		return 0, "", fmt.Errorf("использование: bankctl %s", syntax)
+	}
+	number := accountno.Normalize(pos[0])
+	id, err := c.admin.AccountID(ctx, number)
+	if err != nil {
+		return 0, "", err
+	}
+	return id, number, nil
+}
+
+// orDash заменяет пустое значение прочерком.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// parseDay разбирает дату YYYY-MM-DD; пустая строка — день def.
+func parseDay(v string, def time.Time) (time.Time, error) {
+	if v == "" {
+		return service.Day(def), nil
+	}
+	day, err := time.Parse(repository.DayLayout, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("некорректная дата %q, ожидается YYYY-MM-DD", v)
+	}
+	return day, nil
+}
+
+// print выводит v в JSON или таблицей, которую рисует table.
+func (c *cli) print(v any, table func(w io.Writer)) error {
+	if c.json {
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05")
+}
@@ -1,19 +1,34 @@
 package main
 
 import (
+	"banking-api/api"
+	"banking-api/internal/accountno"
 	"banking-api/internal/config"
+	"banking-api/internal/grpcserver"
 	"banking-api/internal/handler"
+	"banking-api/internal/metrics"
 	"banking-api/internal/middleware"
+	"banking-api/internal/migrate"
 	"banking-api/internal/repository"
+	"banking-api/internal/scheduler"
 	"banking-api/internal/service"
-	"database/sql"
+	"banking-api/internal/tracing"
+	"banking-api/migrations"
+	"context"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
 func main() {
@@ -29,21 +44,79 @@ func main() {
 	// Конфигурация
 	cfg := config.LoadConfig()
 
+	// Схема номеров новых счетов
+	accountno.Default = accountno.Scheme{Country: cfg.AccountNumberCountry, Bank: cfg.AccountNumberBank}
+	if err := accountno.Default.Validate(); err != nil {
+		log.Fatalf("Некорректная схема номеров счетов: %v", err)
+	}
+
+	// Трассировка
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.TracingExporter)
+	if err != nil {
+		log.Fatalf("Ошибка настройки трассировки: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
 	// Подключение к БД
-	db, err := sql.Open("postgres", cfg.DBUrl)
+	db, err := tracing.OpenDB(cfg.DBDriver, cfg.DBDSN)
 	if err != nil {
 		log.Fatalf("Ошибка подключения к БД: %v", err)
 	}
 	defer db.Close()
+	if cfg.DBDriver == "sqlite" {
+		// SQLite допускает одного писателя, а :memory: существует только в рамках соединения
+		db.SetMaxOpenConns(1)
+	}
+	dialect, err := repository.DialectFor(cfg.DBDriver)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	if err := db.Ping(); err != nil {
 		log.Fatalf("БД недоступна: %v", err)
 	}
 	config.Log.Info("Подключение к базе данных успешно")
+	metrics.RegisterDB(db)
+
+	// Подкоманда migrate up|down|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, cfg.DBDriver, os.Args[2:]); err != nil {
+			log.Fatalf("Ошибка миграции: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 {
+		log.Fatalf("Неизвестная команда %q", os.Args[1])
+	}
+
+	migrationsFS, err := migrations.For(cfg.DBDriver)
+	if err != nil {
+		log.Fatal(err)
+	}
+	migrator, err := migrate.New(db, cfg.DBDriver, migrationsFS)
+	if err != nil {
+		log.Fatalf("Ошибка чтения миграций: %v", err)
+	}
+
+	// Миграции при старте (MIGRATE_ON_START=true)
+	if cfg.MigrateOnStart {
+		if err := migrateOnStart(migrator); err != nil {
+			log.Fatalf("Ошибка миграции: %v", err)
+		}
+	}
 
 	// Репозитории
-	userRepo := repository.NewUserRepository(db)
-	accountRepo := repository.NewAccountRepository(db)
+	userRepo := repository.NewSQLUserRepository(db, dialect)
+	accountRepo := repository.NewSQLAccountRepository(db, dialect)
+	interestRepo := repository.NewSQLInterestRepository(db, dialect)
+	loanRepo := repository.NewSQLLoanRepository(db, dialect)
+	paymentRequestRepo := repository.NewSQLPaymentRequestRepository(db, dialect)
+	holdRepo := repository.NewSQLHoldRepository(db, dialect)
+	batchRepo := repository.NewSQLBatchRepository(db, dialect)
+	approvalRepo := repository.NewSQLApprovalRepository(db, dialect)
+	accessRepo := repository.NewSQLAccessRepository(db, dialect)
+	payeeRepo := repository.NewSQLPayeeRepository(db, dialect)
+	adminRepo := repository.NewSQLAdminRepository(db, dialect)
 
 	// Email-сервис
 	emailService := service.NewEmailService(
@@ -56,28 +129,159 @@ func main() {
 	// Сервисы
 	authService := service.NewAuthService(userRepo)
 	accountService := service.NewAccountService(accountRepo, userRepo, emailService)
+	accountService.CreditLimit = cfg.CreditLimit
+	accountService.SavingsTransfersPerMonth = cfg.SavingsTransfersPerMonth
+	approvalService := service.NewApprovalService(approvalRepo, userRepo, emailService)
+	approvalService.TTL = cfg.ApprovalTTL
+	accountService.Approvals = approvalService
+	interestService := service.NewInterestService(interestRepo, accountRepo)
+	loanService := service.NewLoanService(loanRepo, accountRepo)
+	paymentRequestService := service.NewPaymentRequestService(paymentRequestRepo, userRepo, emailService)
+	paymentRequestService.TTL = cfg.PaymentRequestTTL
+	holdService := service.NewHoldService(holdRepo)
+	holdService.TTL = cfg.HoldTTL
+	batchService := service.NewBatchService(batchRepo, accountRepo, userRepo)
+	batchService.SyncLimit = cfg.BatchSyncLimit
+	accessService := service.NewAccessService(accessRepo, userRepo, emailService)
+	payeeService := service.NewPayeeService(payeeRepo, userRepo, accountService, emailService)
+	payeeService.CoolingOff = cfg.PayeeCoolingOff
+	payeeService.CoolingOffAmount = cfg.PayeeCoolingOffAmount
+	adminService := service.NewAdminService(adminRepo, accountRepo, userRepo)
 
 	// Хендлеры
-	authHandler := handler.NewAuthHandler(authService)
+	authHandler := handler.NewAuthHandler(authService, cfg.JWTSecret)
 	accountHandler := handler.NewAccountHandler(accountService)
+	accountHandler.InterestService = interestService
+	accountHandler.LoanService = loanService
+	accountHandler.PaymentRequestService = paymentRequestService
+	accountHandler.HoldService = holdService
+	accountHandler.BatchService = batchService
+	accountHandler.ApprovalService = approvalService
+	accountHandler.AccessService = accessService
+	accountHandler.PayeeService = payeeService
+	adminHandler := handler.NewAdminHandler(adminService)
+	healthHandler := handler.NewHealthHandler(db, migrator, emailService)
 
 	// Роутинг
+	validateRequests, err := middleware.OpenAPIValidator(api.OpenAPI, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
 	router := mux.NewRouter()
+	router.Use(middleware.MetricsMiddleware)
+	router.Use(middleware.TracingMiddleware)
+	router.Use(validateRequests)
 
-	// Публичные
-	router.HandleFunc("/register", authHandler.Register).Methods("POST")
-	router.HandleFunc("/login", authHandler.Login).Methods("POST")
+	// Служебные и документация
+	handler.RegisterServiceRoutes(router, healthHandler, handler.NewDocsHandler(api.OpenAPI))
 
-	// Защищённые
-	protected := router.PathPrefix("/").Subrouter()
-	protected.Use(middleware.AuthMiddleware(cfg.JWTSecret))
+	// Администрирование
+	handler.RegisterAdminRoutes(router, adminHandler, cfg.AdminToken)
 
-	protected.HandleFunc("/accounts", accountHandler.Create).Methods("POST")
-	protected.HandleFunc("/accounts/topup", accountHandler.TopUp).Methods("POST")
-	protected.HandleFunc("/transfer", accountHandler.Transfer).Methods("POST")
-	protected.HandleFunc("/transfer/by-usernames", accountHandler.TransferByUsernames).Methods("POST")
+	// API
+	handler.RegisterRoutes(router, authHandler, accountHandler, cfg.JWTSecret)
 
 	// Запуск сервера
-	config.Log.Infof("Сервер запущен на порту %s", cfg.Port)
-	log.Fatal(http.ListenAndServe(":"+cfg.Port, router))
+	srv := &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      otelhttp.NewHandler(router, "http.server"),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+
+	// gRPC API на отдельном порту
+	grpcSrv := grpcserver.NewGRPCServer(grpcserver.NewServer(authService, accountService, cfg.JWTSecret))
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		log.Fatalf("Не удалось открыть порт gRPC %s: %v", cfg.GRPCPort, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 2)
+	go func() {
+		config.Log.Infof("Сервер запущен на порту %s", cfg.Port)
+		serverErr <- srv.ListenAndServe()
+	}()
+	go func() {
+		config.Log.Infof("gRPC-сервер запущен на порту %s", cfg.GRPCPort)
+		serverErr <- grpcSrv.Serve(grpcListener)
+	}()
+
+	// Начисление процентов за прошедший день и за дни, пропущенные из-за ошибок
+	// или остановки сервера; повторный запуск безопасен, поэтому задание может
+	// работать на нескольких экземплярах
+	if cfg.InterestJob {
+		go scheduler.Daily(ctx, "interest", cfg.InterestJobAt, func(ctx context.Context, day time.Time) error {
+			_, err := interestService.CatchUp(ctx, day)
+			return err
+		})
+	}
+	// Списание платежей по кредитам, наступивших за прошедший день
+	if cfg.LoanJob {
+		go scheduler.Daily(ctx, "loans", cfg.LoanJobAt, func(ctx context.Context, day time.Time) error {
+			_, err := loanService.Collect(ctx, day)
+			return err
+		})
+	}
+	// Закрытие запросов денег с истёкшим сроком и уведомление запросивших
+	go scheduler.Every(ctx, "payment_requests", cfg.PaymentRequestExpiry, func(ctx context.Context, now time.Time) error {
+		_, err := paymentRequestService.Expire(ctx, now)
+		return err
+	})
+	// Снятие истёкших блокировок: средства снова становятся доступны
+	go scheduler.Every(ctx, "holds", cfg.HoldExpiry, func(ctx context.Context, now time.Time) error {
+		_, err := holdService.Expire(ctx, now)
+		return err
+	})
+	// Выполнение больших пакетов переводов, принятых в очередь
+	go scheduler.Every(ctx, "batches", cfg.BatchInterval, func(ctx context.Context, now time.Time) error {
+		_, err := batchService.ProcessPending(ctx, now)
+		return err
+	})
+	// Закрытие не одобренных вовремя переводов и уведомление авторов
+	go scheduler.Every(ctx, "approvals", cfg.ApprovalExpiry, func(ctx context.Context, now time.Time) error {
+		_, err := approvalService.Expire(ctx, now)
+		return err
+	})
+	// Сверка балансов с историей движений; нарушения попадают в лог и метрики
+	if cfg.ReconcileInterval > 0 {
+		go scheduler.Every(ctx, "reconcile", cfg.ReconcileInterval, func(ctx context.Context, now time.Time) error {
+			_, err := adminService.Reconcile(ctx)
+			return err
+		})
+	}
+
+	select {
+	case err := <-serverErr:
+		log.Fatalf("Ошибка сервера: %v", err)
+	case <-ctx.Done():
+	}
+
+	// Корректное завершение: перестаём принимать соединения и ждём активные запросы
+	config.Log.Info("Получен сигнал завершения, останавливаем сервер")
+	healthHandler.SetReady(false)
+	time.Sleep(cfg.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// gRPC: ждём активные вызовы, но не дольше SHUTDOWN_TIMEOUT
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		config.Log.Errorf("Ошибка при остановке сервера: %v", err)
+	}
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcSrv.Stop()
+	}
+	config.Log.Info("Сервер остановлен")
 }
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"banking-api/internal/config"
+	"banking-api/internal/migrate"
+	"banking-api/migrations"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+)
+
+// runMigrate обрабатывает подкоманду `migrate up|down|status`.
+func runMigrate(db *sql.DB, driver string, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("использование: %s migrate up|down|status", os.Args[0])
+	}
+
+	fsys, err := migrations.For(driver)
+	if err != nil {
+		return err
+	}
+	m, err := migrate.New(db, driver, fsys)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			config.Log.Info("Схема БД актуальна, новых миграций нет")
+		}
+		for _, mig := range applied {
+			config.Log.Infof("Применена миграция %04d_%s", mig.Version, mig.Name)
+		}
+	case "down":
+		reverted, err := m.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if reverted == nil {
+			config.Log.Info("Нет применённых миграций для отката")
+			return nil
+		}
+		config.Log.Infof("Откачена миграция %04d_%s", reverted.Version, reverted.Name)
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			state := "pending"
+			if st.Applied {
+				state = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", st.Version, st.Name, state)
+		}
+	default:
+		return fmt.Errorf("неизвестная команда migrate %q: ожидается up, down или status", args[0])
+	}
+	return nil
+}
+
+// migrateOnStart применяет недостающие миграции перед запуском сервера.
+func migrateOnStart(m *migrate.Migrator) error {
+	applied, err := m.Up(context.Background())
+	for _, mig := range applied {
+		config.Log.Infof("Применена миграция %04d_%s", mig.Version, mig.Name)
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+// Package accountno — номера счетов в формате, похожем на IBAN (ISO 13616):
+// код страны, две контрольные цифры mod-97 (ISO 7064), код банка и
+// случайный номер счёта, например RU53GOBK012345678901. Номер проверяется
+// по контрольным цифрам без обращения к хранилищу, поэтому опечатка в номере
+// отклоняется сразу.
+package accountno
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Digits — длина случайной части номера.
+const Digits = 12
+
+const pow10Digits = 1_000_000_000_000
+
+// Ограничения длины номера по ISO 13616
+const (
+	minLen = 15
+	maxLen = 34
+)
+
+// Ошибки проверки номера
+var (
+	ErrFormat   = errors.New("номер счёта должен начинаться с кода страны и контрольных цифр и содержать только латинские буквы и цифры")
+	ErrLength   = fmt.Errorf("номер счёта должен быть длиной от %d до %d символов", minLen, maxLen)
+	ErrChecksum = errors.New("неверные контрольные цифры номера счёта")
+)
+
+// Scheme — схема новых номеров: код страны из двух букв и код банка из
+// четырёх латинских букв или цифр.
+type Scheme struct {
+	Country string
+	Bank    string
+}
+
+// Default — схема номеров новых счетов; cmd задаёт её из конфигурации при старте.
+var Default = Scheme{Country: "RU", Bank: "GOBK"}
+
+// Validate проверяет коды страны и банка схемы.
+func (s Scheme) Validate() error {
+	if len(s.Country) != 2 || !isUpper(s.Country) {
+		return fmt.Errorf("код страны %q: ожидаются две латинские заглавные буквы", s.Country)
+	}
+	if len(s.Bank) != 4 || !isAlnum(s.Bank) {
+		return fmt.Errorf("код банка %q: ожидаются четыре латинские заглавные буквы или цифры", s.Bank)
+	}
+	return nil
+}
+
+// New возвращает новый случайный номер счёта по схеме. Уникальность номера
+// обеспечивает хранилище.
+func (s Scheme) New() string {
+	var b [8]byte
+	rand.Read(b[:]) // crypto/rand не возвращает ошибок начиная с Go 1.24
+	return s.Number(fmt.Sprintf("%0*d", Digits, binary.BigEndian.Uint64(b[:])%pow10Digits))
+}
+
+// Number возвращает номер счёта account банка схемы с контрольными цифрами.
+// account — латинские заглавные буквы и цифры.
+func (s Scheme) Number(account string) string {
+	bban := s.Bank + account
+	return fmt.Sprintf("%s%02d%s", s.Country, 98-mod97(bban+s.Country+"00"), bban)
+}
+
+// New возвращает новый случайный номер счёта по схеме Default.
+func New() string {
+	return Default.New()
+}
+
+// Normalize убирает пробелы и приводит номер к верхнему регистру: номер
+// можно вводить группами по четыре символа, как его печатают.
+func Normalize(number string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(number), ""))
+}
+
+// Validate проверяет формат и контрольные цифры нормализованного номера.
+// Номер любой схемы с верными контрольными цифрами считается валидным:
+// смена схемы не делает недействительными уже выданные номера.
+func Validate(number string) error {
+	if len(number) < minLen || len(number) > maxLen {
+		return ErrLength
+	}
+	if !isUpper(number[:2]) || !isDigits(number[2:4]) || !isAlnum(number[4:]) {
+		return ErrFormat
+	}
+	if mod97(number[4:]+number[:4]) != 1 {
+		return ErrChecksum
+	}
+	return nil
+}
+
+// mod97 возвращает остаток от деления на 97 числа, в котором буквы
+// заменены на 10..35 (A=10, ..., Z=35).
+func mod97(s string) int {
+	rem := 0
+	for _, c := range s {
+		if c >= 'A' && c <= 'Z' {
+			rem = (rem*100 + int(c-'A'+10)) % 97
+		} else {
+			rem = (rem*10 + int(c-'0')) % 97
+		}
+	}
+	return rem
+}
+
+func isUpper(s string) bool {
+	for _, c := range s {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	for _, c := range s {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
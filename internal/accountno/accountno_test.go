@@ -0,0 +1,72 @@
+package accountno
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		number string
+		want   error
+	}{
+		// Примеры IBAN из реестра SWIFT
+		{"GB82WEST12345698765432", nil},
+		{"DE89370400440532013000", nil},
+		{"GB82WEST12345698765433", ErrChecksum},
+		{"GB28WEST12345698765432", ErrChecksum},
+		{"GB82WEST1234569876543!", ErrFormat},
+		{"1282WEST12345698765432", ErrFormat},
+		{"GB8", ErrLength},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.number); !errors.Is(err, tt.want) {
+			t.Errorf("Validate(%q) = %v, ожидалось %v", tt.number, err, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize(" gb82 west 1234 5698 7654 32 "); got != "GB82WEST12345698765432" {
+		t.Errorf("Normalize = %q", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	scheme := Scheme{Country: "RU", Bank: "GOBK"}
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		number := scheme.New()
+		if len(number) != 2+2+4+Digits || number[:2] != "RU" || number[4:8] != "GOBK" {
+			t.Fatalf("New = %q", number)
+		}
+		if err := Validate(number); err != nil {
+			t.Fatalf("Validate(%q): %v", number, err)
+		}
+		if seen[number] {
+			t.Fatalf("повтор номера %q", number)
+		}
+		seen[number] = true
+	}
+}
+
+func TestNumber(t *testing.T) {
+	// Тот же расчёт выполняют миграции при выдаче номеров существующим счетам
+	if got := (Scheme{Country: "RU", Bank: "GOBK"}).Number("000000000001"); Validate(got) != nil {
+		t.Errorf("Number = %q", got)
+	}
+	if got := (Scheme{Country: "GB", Bank: "WEST"}).Number("12345698765432"); got != "GB82WEST12345698765432" {
+		t.Errorf("Number = %q", got)
+	}
+}
+
+func TestSchemeValidate(t *testing.T) {
+	if err := Default.Validate(); err != nil {
+		t.Errorf("Default: %v", err)
+	}
+	for _, s := range []Scheme{{Country: "ru", Bank: "GOBK"}, {Country: "RUS", Bank: "GOBK"}, {Country: "RU", Bank: "GO"}, {Country: "RU", Bank: "go-b"}} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("Validate(%+v) без ошибки", s)
+		}
+	}
+}
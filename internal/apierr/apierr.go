@@ -0,0 +1,75 @@
+// Package apierr описывает формат ошибок REST API. Тело ошибки —
+// {"code": "...", "message": "..."}: code стабилен и предназначен для
+// программной обработки, message — для людей и может меняться.
+package apierr
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Code — машинно-читаемый код ошибки.
+type Code string
+
+const (
+	InvalidRequest         Code = "invalid_request"           // невалидное тело или параметры запроса
+	Unauthorized           Code = "unauthorized"              // нет токена или он невалиден
+	InvalidCredentials     Code = "invalid_credentials"       // неверный email или пароль
+	UserExists             Code = "user_exists"               // email или username занят
+	UserNotFound           Code = "user_not_found"            // пользователь не найден
+	InvalidAccountNumber   Code = "invalid_account_number"    // номер счёта некорректен: неверный формат или контрольные цифры
+	AccountNotFound        Code = "account_not_found"         // счёт не найден или чужой
+	LoanNotFound           Code = "loan_not_found"            // кредит не найден или чужой
+	PaymentRequestNotFound Code = "payment_request_not_found" // запрос денег не найден или чужой
+	PaymentRequestClosed   Code = "payment_request_closed"    // запрос денег уже оплачен, отклонён, отменён или истёк
+	HoldNotFound           Code = "hold_not_found"            // блокировка не найдена или чужая
+	HoldClosed             Code = "hold_closed"               // блокировка уже списана, снята или истекла
+	TransactionNotFound    Code = "transaction_not_found"     // перевод не найден или пришёл не на счёт пользователя
+	TransferRefunded       Code = "transfer_refunded"         // перевод уже возвращён или сторнирован полностью
+	BatchNotFound          Code = "batch_not_found"           // пакет переводов не найден или чужой
+	InvalidBatch           Code = "invalid_batch"             // в пакете переводов есть невалидные строки
+	BatchExists            Code = "batch_exists"              // платёж из этого сообщения pain.001 уже загружен
+	ApprovalNotFound       Code = "approval_not_found"        // перевод на одобрении не найден или недоступен
+	ApprovalClosed         Code = "approval_closed"           // перевод уже одобрен, отклонён или истёк
+	ApprovalRequired       Code = "approval_required"         // сумма больше порога одобрения счёта
+	AccessNotFound         Code = "access_not_found"          // доступ к счёту или приглашение не найдены
+	AccessExists           Code = "access_exists"             // у пользователя уже есть доступ или приглашение к счёту
+	OwnerAccess            Code = "owner_access"              // доступ владельца счёта нельзя отозвать
+	PayeeNotFound          Code = "payee_not_found"           // получатель не найден в адресной книге
+	PayeeExists            Code = "payee_exists"              // получатель с таким именем уже есть
+	PayeeCoolingOff        Code = "payee_cooling_off"         // крупные переводы новому получателю пока недоступны
+	InvalidAmount          Code = "invalid_amount"            // сумма не положительна
+	SelfTransfer           Code = "self_transfer"             // перевод на тот же счёт или самому себе
+	InsufficientFunds      Code = "insufficient_funds"        // недостаточно средств
+	AccountFrozen          Code = "account_frozen"            // счёт заморожен администратором
+	TransferLimitExceeded  Code = "transfer_limit_exceeded"   // исчерпан месячный лимит переводов по счёту
+	TransferNotAllowed     Code = "transfer_not_allowed"      // перевод запрещён правилами типа счёта
+	IdempotencyConflict    Code = "idempotency_conflict"      // ключ идемпотентности использован для другого перевода
+	Internal               Code = "internal"                  // внутренняя ошибка
+)
+
+// Codes — все коды ошибок API.
+var Codes = []Code{
+	InvalidRequest, Unauthorized, InvalidCredentials, UserExists, UserNotFound,
+	InvalidAccountNumber, AccountNotFound, LoanNotFound, PaymentRequestNotFound, PaymentRequestClosed,
+	HoldNotFound, HoldClosed, TransactionNotFound, TransferRefunded,
+	BatchNotFound, InvalidBatch, BatchExists, ApprovalNotFound, ApprovalClosed, ApprovalRequired,
+	AccessNotFound, AccessExists, OwnerAccess, PayeeNotFound, PayeeExists, PayeeCoolingOff,
+	InvalidAmount, SelfTransfer, InsufficientFunds,
+	AccountFrozen, TransferLimitExceeded, TransferNotAllowed,
+	IdempotencyConflict, Internal,
+}
+
+// Error — тело ответа с ошибкой.
+type Error struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+}
+
+// Write отвечает ошибкой в JSON.
+func Write(w http.ResponseWriter, status int, code Code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Error{Code: code, Message: message})
+}
@@ -0,0 +1,20 @@
+package config
+
+import "testing"
+
+func TestParseDBURLPostgresUTC(t *testing.T) {
+	for _, dbURL := range []string{
+		"postgres://u:p@localhost:5432/banking?sslmode=disable",
+		"postgresql://u:p@localhost/banking?sslmode=disable&TimeZone=Europe%2FMoscow",
+	} {
+		driver, dsn, err := ParseDBURL(dbURL)
+		if err != nil || driver != "postgres" {
+			t.Fatalf("ParseDBURL(%q) = %q, %v", dbURL, driver, err)
+		}
+		// Сессия всегда в UTC, даже если в DB_URL указан другой пояс
+		want := "sslmode=disable&timezone=UTC"
+		if got := dsn[len(dsn)-len(want):]; got != want {
+			t.Errorf("ParseDBURL(%q) = %q, ожидался суффикс %q", dbURL, dsn, want)
+		}
+	}
+}
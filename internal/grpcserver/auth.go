@@ -0,0 +1,41 @@
+package grpcserver
+
+import (
+	bankingv1 "banking-api/api/banking/v1"
+	"banking-api/internal/middleware"
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods не требуют JWT — как /register и /login в REST API.
+var publicMethods = map[string]bool{
+	bankingv1.BankingService_Register_FullMethodName: true,
+	bankingv1.BankingService_Login_FullMethodName:    true,
+}
+
+// AuthInterceptor — аналог middleware.AuthMiddleware: проверяет JWT из метаданных
+// authorization: Bearer <token> и кладёт userID в контекст.
+func AuthInterceptor(secret string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 || !strings.HasPrefix(values[0], "Bearer ") {
+			return nil, status.Error(codes.Unauthenticated, "отсутствует authorization")
+		}
+
+		userID, err := middleware.ParseToken(strings.TrimPrefix(values[0], "Bearer "), secret)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "невалидный токен")
+		}
+		return handler(middleware.WithUserID(ctx, userID), req)
+	}
+}
@@ -0,0 +1,219 @@
+// Package grpcserver — gRPC API поверх тех же AuthService и AccountService, что и REST.
+package grpcserver
+
+import (
+	bankingv1 "banking-api/api/banking/v1"
+	"banking-api/internal/middleware"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/service"
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type Server struct {
+	bankingv1.UnimplementedBankingServiceServer
+
+	AuthService    *service.AuthService
+	AccountService *service.AccountService
+	JWTSecret      string
+}
+
+func NewServer(authService *service.AuthService, accountService *service.AccountService, jwtSecret string) *Server {
+	return &Server{
+		AuthService:    authService,
+		AccountService: accountService,
+		JWTSecret:      jwtSecret,
+	}
+}
+
+// NewGRPCServer создаёт grpc.Server с JWT-интерсептором, трассировкой и reflection.
+func NewGRPCServer(srv *Server) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(AuthInterceptor(srv.JWTSecret)),
+	)
+	bankingv1.RegisterBankingServiceServer(s, srv)
+	reflection.Register(s)
+	return s
+}
+
+func (s *Server) Register(ctx context.Context, req *bankingv1.RegisterRequest) (*bankingv1.RegisterResponse, error) {
+	user, err := s.AuthService.RegisterUser(ctx, &models.RegisterRequest{
+		Email:    req.GetEmail(),
+		Username: req.GetUsername(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &bankingv1.RegisterResponse{User: &bankingv1.User{
+		Id:        user.ID,
+		Email:     user.Email,
+		Username:  user.Username,
+		CreatedAt: timestamp(user.CreatedAt),
+	}}, nil
+}
+
+func (s *Server) Login(ctx context.Context, req *bankingv1.LoginRequest) (*bankingv1.LoginResponse, error) {
+	token, err := s.AuthService.LoginUser(ctx, &models.LoginRequest{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	}, s.JWTSecret)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return &bankingv1.LoginResponse{Token: token}, nil
+}
+
+func (s *Server) CreateAccount(ctx context.Context, req *bankingv1.CreateAccountRequest) (*bankingv1.CreateAccountResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.AccountService.CreateAccount(ctx, userID, req.GetType())
+	if errors.Is(err, service.ErrInvalidAccountType) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "не удалось создать счёт")
+	}
+	return &bankingv1.CreateAccountResponse{Account: &bankingv1.Account{
+		Number:               account.Number,
+		Type:                 account.Type,
+		Balance:              account.Balance,
+		CreditLimit:          account.CreditLimit,
+		MonthlyTransferLimit: int32(account.MonthlyTransferLimit),
+		CreatedAt:            timestamp(account.CreatedAt),
+	}}, nil
+}
+
+func (s *Server) TopUp(ctx context.Context, req *bankingv1.TopUpRequest) (*bankingv1.TopUpResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accountID, err := s.accountID(ctx, req.GetAccount())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.AccountService.TopUp(ctx, userID, accountID, req.GetAmount()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &bankingv1.TopUpResponse{}, nil
+}
+
+func (s *Server) Transfer(ctx context.Context, req *bankingv1.TransferRequest) (*bankingv1.TransferResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fromAccountID, err := s.accountID(ctx, req.GetFromAccount())
+	if err != nil {
+		return nil, err
+	}
+	toAccountID, err := s.accountID(ctx, req.GetToAccount())
+	if err != nil {
+		return nil, err
+	}
+	err = s.AccountService.TransferFunds(ctx, userID, fromAccountID, toAccountID, req.GetAmount(), req.GetIdempotencyKey())
+	var pending *service.ApprovalPendingError
+	if errors.As(err, &pending) {
+		// Как 202 в REST: перевод принят и выполнится после одобрения
+		return &bankingv1.TransferResponse{Approval: &bankingv1.TransferApproval{
+			Id:        pending.Approval.ID,
+			Status:    pending.Approval.Status,
+			ExpiresAt: timestamp(pending.Approval.ExpiresAt),
+		}}, nil
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &bankingv1.TransferResponse{}, nil
+}
+
+func (s *Server) ListTransactions(ctx context.Context, req *bankingv1.ListTransactionsRequest) (*bankingv1.ListTransactionsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accountID, err := s.accountID(ctx, req.GetAccount())
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := s.AccountService.GetTransactions(ctx, userID, accountID, int(req.GetLimit()), int(req.GetOffset()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &bankingv1.ListTransactionsResponse{}
+	for _, t := range transactions {
+		resp.Transactions = append(resp.Transactions, &bankingv1.Transaction{
+			Id:                    t.ID,
+			FromAccount:           t.FromAccount,
+			ToAccount:             t.ToAccount,
+			Amount:                t.Amount,
+			CreatedAt:             timestamp(t.CreatedAt),
+			Kind:                  t.Kind,
+			OriginalTransactionId: t.OriginalTransactionID,
+			ReasonCode:            t.ReasonCode,
+		})
+	}
+	return resp, nil
+}
+
+// accountID возвращает ID счёта по номеру number: некорректный номер —
+// InvalidArgument без обращения к базе, неизвестный — NotFound.
+func (s *Server) accountID(ctx context.Context, number string) (int64, error) {
+	id, err := s.AccountService.AccountID(ctx, number)
+	if errors.Is(err, service.ErrAccountNotFound) {
+		return 0, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return 0, toStatus(err)
+	}
+	return id, nil
+}
+
+func userIDFromContext(ctx context.Context) (int64, error) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(ctx), 10, 64)
+	if err != nil {
+		return 0, status.Error(codes.Unauthenticated, "ошибка токена")
+	}
+	return userID, nil
+}
+
+// toStatus переводит ошибки сервисов в коды gRPC. Ошибки валидации, как и в REST
+// (400 Bad Request), становятся InvalidArgument.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return status.Error(codes.NotFound, "счёт не найден")
+	case errors.Is(err, repository.ErrInsufficientFunds), errors.Is(err, repository.ErrAccountFrozen),
+		errors.Is(err, repository.ErrSavingsExternalTransfer), errors.Is(err, repository.ErrApprovalRequired):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, repository.ErrTransferLimitExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, repository.ErrIdempotencyConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	return timestamppb.New(t)
+}
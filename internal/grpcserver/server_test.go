@@ -0,0 +1,184 @@
+package grpcserver_test
+
+import (
+	bankingv1 "banking-api/api/banking/v1"
+	"banking-api/internal/config"
+	"banking-api/internal/grpcserver"
+	"banking-api/internal/models"
+	"banking-api/internal/repository/memory"
+	"banking-api/internal/service"
+	"context"
+	"io"
+	"net"
+	"os"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestMain(m *testing.M) {
+	config.InitLogger()
+	config.Log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func newClient(t *testing.T) bankingv1.BankingServiceClient {
+	t.Helper()
+	client, _ := newClientWithStore(t)
+	return client
+}
+
+// newClientWithStore поднимает сервер и возвращает хранилище, чтобы тест мог
+// выполнить операции, недоступные через gRPC (например, задать порог одобрения).
+func newClientWithStore(t *testing.T) (bankingv1.BankingServiceClient, *memory.Store) {
+	t.Helper()
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+	accountService := service.NewAccountService(memory.NewAccountRepository(store), users, nil)
+	accountService.Approvals = service.NewApprovalService(memory.NewApprovalRepository(store), users, nil)
+	srv := grpcserver.NewGRPCServer(grpcserver.NewServer(
+		service.NewAuthService(users),
+		accountService,
+		"test-secret",
+	))
+
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return bankingv1.NewBankingServiceClient(conn), store
+}
+
+func login(t *testing.T, client bankingv1.BankingServiceClient, username string) context.Context {
+	t.Helper()
+	ctx := context.Background()
+	email := username + "@example.com"
+	if _, err := client.Register(ctx, &bankingv1.RegisterRequest{Email: email, Username: username, Password: "secret"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	resp, err := client.Login(ctx, &bankingv1.LoginRequest{Email: email, Password: "secret"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+resp.GetToken())
+}
+
+func TestRequiresToken(t *testing.T) {
+	client := newClient(t)
+
+	_, err := client.CreateAccount(context.Background(), &bankingv1.CreateAccountRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("без токена: %v, ожидался Unauthenticated", err)
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer garbage")
+	if _, err := client.CreateAccount(ctx, &bankingv1.CreateAccountRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("с невалидным токеном: %v, ожидался Unauthenticated", err)
+	}
+}
+
+func TestTransferFlow(t *testing.T) {
+	client := newClient(t)
+	alice := login(t, client, "alice")
+	bob := login(t, client, "bob")
+
+	from, err := client.CreateAccount(alice, &bankingv1.CreateAccountRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := client.CreateAccount(bob, &bankingv1.CreateAccountRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromAcc, toAcc := from.GetAccount().GetNumber(), to.GetAccount().GetNumber()
+
+	if _, err := client.TopUp(alice, &bankingv1.TopUpRequest{Account: fromAcc, Amount: 100}); err != nil {
+		t.Fatalf("TopUp: %v", err)
+	}
+	if _, err := client.Transfer(alice, &bankingv1.TransferRequest{FromAccount: fromAcc, ToAccount: toAcc, Amount: 70}); err != nil {
+		t.Fatalf("Transfer: %v", err)
+	}
+
+	_, err = client.Transfer(alice, &bankingv1.TransferRequest{FromAccount: fromAcc, ToAccount: toAcc, Amount: 70})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("перевод сверх баланса: %v, ожидался FailedPrecondition", err)
+	}
+	_, err = client.Transfer(bob, &bankingv1.TransferRequest{FromAccount: fromAcc, ToAccount: toAcc, Amount: 1})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("перевод с чужого счёта: %v, ожидался NotFound", err)
+	}
+	_, err = client.Transfer(alice, &bankingv1.TransferRequest{FromAccount: fromAcc, ToAccount: "RU00GOBK000000000000", Amount: 1})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("перевод на некорректный номер: %v, ожидался InvalidArgument", err)
+	}
+
+	history, err := client.ListTransactions(bob, &bankingv1.ListTransactionsRequest{Account: toAcc})
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	if len(history.GetTransactions()) != 1 || history.GetTransactions()[0].GetAmount() != 70 || history.GetTransactions()[0].GetKind() != models.KindTransfer ||
+		history.GetTransactions()[0].GetFromAccount() != fromAcc {
+		t.Errorf("история = %v", history.GetTransactions())
+	}
+}
+
+func TestTransferPendingApproval(t *testing.T) {
+	client, store := newClientWithStore(t)
+	ctx := context.Background()
+	alice := login(t, client, "alice")
+	bob := login(t, client, "bob")
+	from, err := client.CreateAccount(alice, &bankingv1.CreateAccountRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := client.CreateAccount(bob, &bankingv1.CreateAccountRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromAcc, toAcc := from.GetAccount().GetNumber(), to.GetAccount().GetNumber()
+	if _, err := client.TopUp(alice, &bankingv1.TopUpRequest{Account: fromAcc, Amount: 1000}); err != nil {
+		t.Fatalf("TopUp: %v", err)
+	}
+	accounts := memory.NewAccountRepository(store)
+	fromID, err := accounts.GetAccountIDByNumber(ctx, fromAcc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobID, err := memory.NewUserRepository(store).GetUserIDByUsername(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := models.AuditEntry{Actor: "ops", Action: models.AuditApprovals, AccountID: fromID, Amount: 100, Reason: "политика"}
+	if err := memory.NewAdminRepository(store).SetApprovalPolicy(ctx, fromID, 100, []int64{bobID}, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.Transfer(alice, &bankingv1.TransferRequest{FromAccount: fromAcc, ToAccount: toAcc, Amount: 300, IdempotencyKey: "big"})
+	if err != nil {
+		t.Fatalf("перевод сверх порога: %v", err)
+	}
+	approval := resp.GetApproval()
+	if approval.GetId() == 0 || approval.GetStatus() != models.ApprovalPending || approval.GetExpiresAt() == nil {
+		t.Fatalf("перевод на одобрении = %v", approval)
+	}
+	// Повтор с тем же ключом возвращает то же одобрение, а не создаёт новое
+	again, err := client.Transfer(alice, &bankingv1.TransferRequest{FromAccount: fromAcc, ToAccount: toAcc, Amount: 300, IdempotencyKey: "big"})
+	if err != nil || again.GetApproval().GetId() != approval.GetId() {
+		t.Errorf("повтор перевода: %v %v", again.GetApproval(), err)
+	}
+	if resp, err := client.Transfer(alice, &bankingv1.TransferRequest{FromAccount: fromAcc, ToAccount: toAcc, Amount: 50}); err != nil || resp.GetApproval() != nil {
+		t.Errorf("перевод в пределах порога: %v %v", resp, err)
+	}
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"banking-api/internal/apierr"
+	"banking-api/internal/middleware"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// InviteAccess приглашает пользователя к счёту с уровнем доступа. Нужен
+// доступ manage к счёту.
+func (h *AccountHandler) InviteAccess(w http.ResponseWriter, r *http.Request) {
+	var req models.InviteAccessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
+		return
+	}
+	h.resolveAccess(w, r, http.StatusCreated, func(ctx context.Context, userID, accountID int64) (any, error) {
+		return h.AccessService.Invite(ctx, userID, accountID, req.Username, req.Level)
+	})
+}
+
+// AccountAccess возвращает доступы и приглашения к счёту, начиная с владельца.
+func (h *AccountHandler) AccountAccess(w http.ResponseWriter, r *http.Request) {
+	h.resolveAccess(w, r, http.StatusOK, func(ctx context.Context, userID, accountID int64) (any, error) {
+		return h.AccessService.List(ctx, userID, accountID)
+	})
+}
+
+// AcceptAccess принимает приглашение к счёту.
+func (h *AccountHandler) AcceptAccess(w http.ResponseWriter, r *http.Request) {
+	h.resolveAccess(w, r, http.StatusOK, func(ctx context.Context, userID, accountID int64) (any, error) {
+		return h.AccessService.Accept(ctx, userID, accountID)
+	})
+}
+
+// RevokeAccess удаляет доступ или приглашение пользователя к счёту. Для
+// своего username — отказ от приглашения или выход из счёта.
+func (h *AccountHandler) RevokeAccess(w http.ResponseWriter, r *http.Request) {
+	h.resolveAccess(w, r, http.StatusOK, func(ctx context.Context, userID, accountID int64) (any, error) {
+		return h.AccessService.Revoke(ctx, userID, accountID, mux.Vars(r)["username"])
+	})
+}
+
+// Invitations возвращает приглашения пользователя к счетам, ждущие принятия.
+func (h *AccountHandler) Invitations(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+
+	invitations, err := h.AccessService.Invitations(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, invitations)
+}
+
+// resolveAccess находит счёт по номеру из пути, выполняет над ним action и
+// отвечает результатом со статусом status или ошибкой.
+func (h *AccountHandler) resolveAccess(w http.ResponseWriter, r *http.Request, status int,
+	action func(ctx context.Context, userID, accountID int64) (any, error)) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	accountID, ok := h.accountID(w, r, mux.Vars(r)["number"])
+	if !ok {
+		return
+	}
+
+	result, err := action(r.Context(), userID, accountID)
+	switch {
+	case errors.Is(err, service.ErrAccountNotFound), errors.Is(err, service.ErrAccessNotFound), errors.Is(err, service.ErrUserNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, service.ErrAccessExists), errors.Is(err, repository.ErrOwnerAccess):
+		writeError(w, http.StatusConflict, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, status, result)
+}
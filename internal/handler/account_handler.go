@@ -1,16 +1,39 @@
 package handler
 
 import (
+	"banking-api/internal/accountno"
+	"banking-api/internal/apierr"
 	"banking-api/internal/middleware"
 	"banking-api/internal/models"
+	"banking-api/internal/repository"
 	"banking-api/internal/service"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
 )
 
+// IdempotencyKeyHeader — заголовок с ключом идемпотентности перевода. Повтор
+// перевода с тем же ключом не списывает деньги повторно.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
 type AccountHandler struct {
-	AccountService *service.AccountService
+	AccountService        *service.AccountService
+	InterestService       *service.InterestService
+	LoanService           *service.LoanService
+	PaymentRequestService *service.PaymentRequestService
+	HoldService           *service.HoldService
+	BatchService          *service.BatchService
+	ApprovalService       *service.ApprovalService
+	AccessService         *service.AccessService
+	PayeeService          *service.PayeeService
 }
 
 func NewAccountHandler(service *service.AccountService) *AccountHandler {
@@ -21,20 +44,34 @@ func (h *AccountHandler) Create(w http.ResponseWriter, r *http.Request) {
 	userIDStr := middleware.GetUserID(r.Context())
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "ошибка токена", http.StatusUnauthorized)
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
 		return
 	}
 
-	account, err := h.AccountService.CreateAccount(userID)
+	// Тело необязательно: без него открывается расчётный счёт
+	var req models.CreateAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
+		return
+	}
+
+	account, err := h.AccountService.CreateAccount(r.Context(), userID, req.Type)
+	if errors.Is(err, service.ErrInvalidAccountType) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
 	if err != nil {
-		http.Error(w, "не удалось создать счёт", http.StatusInternalServerError)
+		apierr.Write(w, http.StatusInternalServerError, apierr.Internal, "не удалось создать счёт")
 		return
 	}
 
 	resp := map[string]interface{}{
-		"id":        account.ID,
-		"balance":   account.Balance,
-		"createdAt": account.CreatedAt,
+		"number":                 account.Number,
+		"type":                   account.Type,
+		"balance":                account.Balance,
+		"credit_limit":           account.CreditLimit,
+		"monthly_transfer_limit": account.MonthlyTransferLimit,
+		"createdAt":              account.CreatedAt,
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
@@ -43,63 +80,260 @@ func (h *AccountHandler) Create(w http.ResponseWriter, r *http.Request) {
 func (h *AccountHandler) TopUp(w http.ResponseWriter, r *http.Request) {
 	var req models.TopUpRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "невалидный JSON", http.StatusBadRequest)
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
 		return
 	}
 
 	userIDStr := middleware.GetUserID(r.Context())
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "ошибка токена", http.StatusUnauthorized)
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
 		return
 	}
 
-	if err := h.AccountService.TopUp(userID, req.AccountID, req.Amount); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	accountID, ok := h.accountID(w, r, req.Account)
+	if !ok {
+		return
+	}
+	if err := h.AccountService.TopUp(r.Context(), userID, accountID, req.Amount); err != nil {
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"ok"}`))
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
 func (h *AccountHandler) Transfer(w http.ResponseWriter, r *http.Request) {
 	var req models.TransferRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "невалидный JSON", http.StatusBadRequest)
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
 		return
 	}
 
 	userIDStr := middleware.GetUserID(r.Context())
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "ошибка токена", http.StatusUnauthorized)
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
 		return
 	}
 
-	err = h.AccountService.TransferFunds(userID, req.FromAccountID, req.ToAccountID, req.Amount)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	fromAccountID, ok := h.accountID(w, r, req.FromAccount)
+	if !ok {
 		return
 	}
-
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"ok"}`))
+	toAccountID, ok := h.accountID(w, r, req.ToAccount)
+	if !ok {
+		return
+	}
+	err = h.AccountService.TransferFunds(r.Context(), userID, fromAccountID, toAccountID, req.Amount, r.Header.Get(IdempotencyKeyHeader))
+	writeTransferResult(w, err)
 }
 
+// TransferByUsernames переводит с первого счёта пользователя токена на
+// первый счёт получателя to_username.
 func (h *AccountHandler) TransferByUsernames(w http.ResponseWriter, r *http.Request) {
 	var req models.TransferByUsernamesRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "невалидный JSON", http.StatusBadRequest)
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
 		return
 	}
 
-	err := h.AccountService.TransferBetweenUsers(req.FromUsername, req.ToUsername, req.Amount)
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"ok"}`))
+	err = h.AccountService.TransferBetweenUsers(r.Context(), userID, req.FromUsername, req.ToUsername, req.Amount, r.Header.Get(IdempotencyKeyHeader))
+	writeTransferResult(w, err)
+}
+
+// writeTransferResult отвечает на перевод: 200 при выполнении, 202 с
+// переводом на одобрении, если сумма больше порога одобрения счёта.
+func writeTransferResult(w http.ResponseWriter, err error) {
+	var pending *service.ApprovalPendingError
+	switch {
+	case errors.As(err, &pending):
+		w.Header().Set("Location", fmt.Sprintf("/approvals/%d", pending.Approval.ID))
+		writeJSON(w, http.StatusAccepted, pending.Approval)
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+	default:
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
+
+// Refund возвращает отправителю перевод (весь или часть), пришедший на счёт пользователя.
+func (h *AccountHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	transactionID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "некорректный ID перевода")
+		return
+	}
+	// Тело необязательно: без него возвращается весь остаток перевода
+	var req models.RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
+		return
+	}
+
+	refund, err := h.AccountService.Refund(r.Context(), userID, transactionID, req.Amount)
+	switch {
+	case errors.Is(err, service.ErrTransactionNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, repository.ErrTransferRefunded):
+		writeError(w, http.StatusConflict, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, refund)
+}
+
+func (h *AccountHandler) Transactions(w http.ResponseWriter, r *http.Request) {
+	userIDStr := middleware.GetUserID(r.Context())
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+
+	accountID, ok := h.accountID(w, r, mux.Vars(r)["number"])
+	if !ok {
+		return
+	}
+	limit, offset, err := pagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	transactions, err := h.AccountService.GetTransactions(r.Context(), userID, accountID, limit, offset)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, transactions)
+}
+
+// LowBalanceAlert задаёт порог уведомления о низком остатке счёта.
+func (h *AccountHandler) LowBalanceAlert(w http.ResponseWriter, r *http.Request) {
+	userIDStr := middleware.GetUserID(r.Context())
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+
+	accountID, ok := h.accountID(w, r, mux.Vars(r)["number"])
+	if !ok {
+		return
+	}
+	var req models.LowBalanceAlertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
+		return
+	}
+
+	err = h.AccountService.SetLowBalanceAlert(r.Context(), userID, accountID, req.Threshold)
+	if errors.Is(err, service.ErrAccountNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Interest возвращает начисления процентов по счёту за месяц (?month=YYYY-MM, по умолчанию текущий).
+func (h *AccountHandler) Interest(w http.ResponseWriter, r *http.Request) {
+	userIDStr := middleware.GetUserID(r.Context())
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+
+	accountID, ok := h.accountID(w, r, mux.Vars(r)["number"])
+	if !ok {
+		return
+	}
+	month := time.Now()
+	if v := r.URL.Query().Get("month"); v != "" {
+		if month, err = time.Parse("2006-01", v); err != nil {
+			apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "некорректный month, ожидается YYYY-MM")
+			return
+		}
+	}
+
+	accruals, err := h.InterestService.Accruals(r.Context(), userID, accountID, month)
+	if errors.Is(err, service.ErrAccountNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	var total float64
+	for _, a := range accruals {
+		total += a.Amount
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"account":  accountno.Normalize(mux.Vars(r)["number"]),
+		"month":    service.Month(month).Format("2006-01"),
+		"total":    math.Round(total*100) / 100,
+		"accruals": accruals,
+	})
+}
+
+// accountID возвращает ID счёта по номеру number. Номер с неверной
+// контрольной суммой отклоняется с 400 без обращения к базе, неизвестный —
+// с 404; при ошибке ответ уже записан и ok = false.
+func (h *AccountHandler) accountID(w http.ResponseWriter, r *http.Request, number string) (id int64, ok bool) {
+	id, err := h.AccountService.AccountID(r.Context(), number)
+	switch {
+	case errors.Is(err, service.ErrInvalidAccountNumber):
+		writeError(w, http.StatusBadRequest, err)
+		return 0, false
+	case errors.Is(err, service.ErrAccountNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return 0, false
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+		return 0, false
+	}
+	return id, true
+}
+
+// pagination читает необязательные параметры limit и offset из query-строки.
+func pagination(r *http.Request) (limit, offset int, err error) {
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			return 0, 0, errors.New("некорректный limit")
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil {
+			return 0, 0, errors.New("некорректный offset")
+		}
+	}
+	return limit, offset, nil
 }
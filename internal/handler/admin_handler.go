@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"banking-api/internal/service"
+	"net/http"
+)
+
+// AdminHandler — административные маршруты /admin, защищённые ADMIN_TOKEN.
+type AdminHandler struct {
+	AdminService *service.AdminService
+}
+
+func NewAdminHandler(admin *service.AdminService) *AdminHandler {
+	return &AdminHandler{AdminService: admin}
+}
+
+// Reconciliation возвращает результат последней сверки; если сверка ещё не
+// запускалась, выполняет её.
+func (h *AdminHandler) Reconciliation(w http.ResponseWriter, r *http.Request) {
+	report := h.AdminService.LastReconciliation()
+	if report == nil {
+		h.Reconcile(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// Reconcile выполняет сверку сразу и возвращает её результат.
+func (h *AdminHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	report, err := h.AdminService.Reconcile(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
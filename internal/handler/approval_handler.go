@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"banking-api/internal/apierr"
+	"banking-api/internal/middleware"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Approvals возвращает переводы на одобрении, которые пользователь создал или
+// может рассмотреть; ?status= оставляет переводы в этом статусе.
+func (h *AccountHandler) Approvals(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+
+	approvals, err := h.ApprovalService.List(r.Context(), userID, r.URL.Query().Get("status"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, approvals)
+}
+
+// Approval возвращает перевод на одобрении.
+func (h *AccountHandler) Approval(w http.ResponseWriter, r *http.Request) {
+	h.resolveApproval(w, r, h.ApprovalService.Get)
+}
+
+// ApproveTransfer одобряет и выполняет перевод.
+func (h *AccountHandler) ApproveTransfer(w http.ResponseWriter, r *http.Request) {
+	h.resolveApproval(w, r, h.ApprovalService.Approve)
+}
+
+// RejectTransfer отклоняет перевод. Тело с причиной необязательно.
+func (h *AccountHandler) RejectTransfer(w http.ResponseWriter, r *http.Request) {
+	var req models.RejectApprovalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
+		return
+	}
+	h.resolveApproval(w, r, func(ctx context.Context, userID, approvalID int64) (*models.TransferApproval, error) {
+		return h.ApprovalService.Reject(ctx, userID, approvalID, req.Reason)
+	})
+}
+
+// resolveApproval разбирает ID перевода из пути, выполняет над ним action
+// и отвечает переводом или ошибкой.
+func (h *AccountHandler) resolveApproval(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, userID, approvalID int64) (*models.TransferApproval, error)) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	approvalID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "некорректный ID перевода")
+		return
+	}
+
+	approval, err := action(r.Context(), userID, approvalID)
+	switch {
+	case errors.Is(err, service.ErrApprovalNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, repository.ErrApprovalClosed):
+		writeError(w, http.StatusConflict, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, approval)
+}
@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"banking-api/internal/config"
+	"banking-api/internal/apierr"
 	"banking-api/internal/models"
 	"banking-api/internal/service"
 	"encoding/json"
@@ -10,22 +10,23 @@ import (
 
 type AuthHandler struct {
 	AuthService *service.AuthService
+	JWTSecret   string
 }
 
-func NewAuthHandler(authService *service.AuthService) *AuthHandler {
-	return &AuthHandler{AuthService: authService}
+func NewAuthHandler(authService *service.AuthService, jwtSecret string) *AuthHandler {
+	return &AuthHandler{AuthService: authService, JWTSecret: jwtSecret}
 }
 
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req models.RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "невалидный JSON", http.StatusBadRequest)
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
 		return
 	}
 
-	user, err := h.AuthService.RegisterUser(&req)
+	user, err := h.AuthService.RegisterUser(r.Context(), &req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
@@ -43,13 +44,13 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req models.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "невалидный JSON", http.StatusBadRequest)
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
 		return
 	}
 
-	token, err := h.AuthService.LoginUser(&req, config.LoadConfig().JWTSecret)
+	token, err := h.AuthService.LoginUser(r.Context(), &req, h.JWTSecret)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		writeError(w, http.StatusUnauthorized, err)
 		return
 	}
 
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"banking-api/internal/apierr"
+	"banking-api/internal/middleware"
+	"banking-api/internal/models"
+	"banking-api/internal/service"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// batchColumns — допустимые колонки CSV пакета переводов.
+var batchColumns = []string{"to_username", "to_account", "amount", "reference", "end_to_end_id"}
+
+// Ограничения размера тела пакетов: число строк проверяется только после
+// разбора, поэтому неограниченное тело разбирать нельзя.
+const (
+	maxBatchBodyBytes   = 2 << 20 // CSV или JSON из service.MaxBatchLines строк
+	maxPain001BodyBytes = 8 << 20 // XML многословнее, и платежей PmtInf в сообщении может быть несколько
+)
+
+// SubmitBatch принимает пакет переводов со счёта from_account в JSON
+// (массив строк) или CSV (с заголовком из batchColumns). Выполненный сразу
+// пакет возвращается со статусом 201, поставленный в очередь — с 202.
+func (h *AccountHandler) SubmitBatch(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	query := r.URL.Query()
+	fromAccountID, ok := h.accountID(w, r, query.Get("from_account"))
+	if !ok {
+		return
+	}
+	var allOrNothing bool
+	if v := query.Get("all_or_nothing"); v != "" {
+		if allOrNothing, err = strconv.ParseBool(v); err != nil {
+			apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "некорректный all_or_nothing")
+			return
+		}
+	}
+
+	body, ok := readBatchBody(w, r, maxBatchBodyBytes)
+	if !ok {
+		return
+	}
+	var lines []models.BatchLine
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		lines, err = parseBatchCSV(bytes.NewReader(body))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	} else if err := json.Unmarshal(body, &lines); err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
+		return
+	}
+
+	batch, err := h.BatchService.Submit(r.Context(), userID, fromAccountID, allOrNothing, lines)
+	switch {
+	case errors.Is(err, service.ErrInvalidBatch):
+		writeError(w, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, service.ErrAccountNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if batch.Status == models.BatchPending {
+		w.Header().Set("Location", fmt.Sprintf("/batches/%d", batch.ID))
+		writeJSON(w, http.StatusAccepted, batch)
+		return
+	}
+	writeJSON(w, http.StatusCreated, batch)
+}
+
+// readBatchBody читает тело пакета не больше limit байт. Если тело больше,
+// отвечает invalid_batch и возвращает false.
+func readBatchBody(w http.ResponseWriter, r *http.Request, limit int64) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: тело запроса больше %d байт", service.ErrInvalidBatch, limit))
+		return nil, false
+	case err != nil:
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "не удалось прочитать тело запроса")
+		return nil, false
+	}
+	return body, true
+}
+
+// parseBatchCSV разбирает CSV пакета. Первая строка — заголовок, порядок
+// колонок произвольный, отсутствующие колонки считаются пустыми.
+func parseBatchCSV(body io.Reader) ([]models.BatchLine, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: пакет пуст", service.ErrInvalidBatch)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBatch, err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(batchColumns, name) {
+			return nil, fmt.Errorf("%w: неизвестная колонка %q, ожидаются %s", service.ErrInvalidBatch, name, strings.Join(batchColumns, ", "))
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["amount"]; !ok {
+		return nil, fmt.Errorf("%w: нет колонки amount", service.ErrInvalidBatch)
+	}
+
+	lines := []models.BatchLine{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", service.ErrInvalidBatch, err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		n := len(lines) + 1
+		line := models.BatchLine{ToUsername: field("to_username"), ToAccount: field("to_account"), Reference: field("reference"),
+			EndToEndID: field("end_to_end_id")}
+		if line.Amount, err = strconv.ParseFloat(field("amount"), 64); err != nil {
+			return nil, fmt.Errorf("%w: строка %d: некорректная сумма %q", service.ErrInvalidBatch, n, field("amount"))
+		}
+		lines = append(lines, line)
+	}
+}
+
+// Batches возвращает пакеты переводов пользователя со сводкой по строкам.
+func (h *AccountHandler) Batches(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+
+	batches, err := h.BatchService.List(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, batches)
+}
+
+// Batch возвращает статус пакета переводов и результат по каждой строке.
+func (h *AccountHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	batchID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "некорректный ID пакета")
+		return
+	}
+
+	batch, err := h.BatchService.Get(r.Context(), userID, batchID)
+	switch {
+	case errors.Is(err, service.ErrBatchNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, batch)
+}
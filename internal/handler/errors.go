@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"banking-api/internal/apierr"
+	"banking-api/internal/repository"
+	"banking-api/internal/service"
+	"database/sql"
+	"errors"
+	"net/http"
+)
+
+// errorCodes сопоставляет ошибки сервисов и репозиториев с кодами API.
+var errorCodes = []struct {
+	err  error
+	code apierr.Code
+}{
+	{service.ErrInvalidAmount, apierr.InvalidAmount},
+	{service.ErrSelfTransfer, apierr.SelfTransfer},
+	{service.ErrSenderMismatch, apierr.InvalidRequest},
+	{service.ErrUserExists, apierr.UserExists},
+	{service.ErrInvalidCredentials, apierr.InvalidCredentials},
+	{service.ErrUserNotFound, apierr.UserNotFound},
+	{service.ErrInvalidAccountNumber, apierr.InvalidAccountNumber},
+	{service.ErrAccountNotFound, apierr.AccountNotFound},
+	{service.ErrLoanNotFound, apierr.LoanNotFound},
+	{service.ErrInvalidLoanTerms, apierr.InvalidRequest},
+	{repository.ErrLoanOverpayment, apierr.InvalidAmount},
+	{repository.ErrLoanClosed, apierr.InvalidRequest},
+	{service.ErrPaymentRequestNotFound, apierr.PaymentRequestNotFound},
+	{service.ErrInvalidPaymentRequest, apierr.InvalidRequest},
+	{repository.ErrPaymentRequestClosed, apierr.PaymentRequestClosed},
+	{service.ErrHoldNotFound, apierr.HoldNotFound},
+	{service.ErrInvalidHold, apierr.InvalidRequest},
+	{repository.ErrHoldClosed, apierr.HoldClosed},
+	{repository.ErrCaptureExceedsHold, apierr.InvalidAmount},
+	{service.ErrTransactionNotFound, apierr.TransactionNotFound},
+	{repository.ErrTransferRefunded, apierr.TransferRefunded},
+	{repository.ErrRefundExceedsTransfer, apierr.InvalidAmount},
+	{repository.ErrNotRefundable, apierr.InvalidRequest},
+	{service.ErrBatchNotFound, apierr.BatchNotFound},
+	{service.ErrInvalidBatch, apierr.InvalidBatch},
+	{service.ErrBatchExists, apierr.BatchExists},
+	{service.ErrStatementNotReady, apierr.InvalidRequest},
+	{service.ErrApprovalNotFound, apierr.ApprovalNotFound},
+	{service.ErrInvalidApproval, apierr.InvalidRequest},
+	{repository.ErrApprovalClosed, apierr.ApprovalClosed},
+	{repository.ErrApprovalRequired, apierr.ApprovalRequired},
+	{service.ErrAccessNotFound, apierr.AccessNotFound},
+	{service.ErrAccessExists, apierr.AccessExists},
+	{service.ErrInvalidAccess, apierr.InvalidRequest},
+	{repository.ErrOwnerAccess, apierr.OwnerAccess},
+	{service.ErrPayeeNotFound, apierr.PayeeNotFound},
+	{service.ErrPayeeExists, apierr.PayeeExists},
+	{service.ErrInvalidPayee, apierr.InvalidRequest},
+	{service.ErrPayeeCoolingOff, apierr.PayeeCoolingOff},
+	{service.ErrInvalidPagination, apierr.InvalidRequest},
+	{service.ErrInvalidAccountType, apierr.InvalidRequest},
+	{repository.ErrTransferLimitExceeded, apierr.TransferLimitExceeded},
+	{repository.ErrSavingsExternalTransfer, apierr.TransferNotAllowed},
+	{repository.ErrInsufficientFunds, apierr.InsufficientFunds},
+	{repository.ErrAccountFrozen, apierr.AccountFrozen},
+	{repository.ErrIdempotencyConflict, apierr.IdempotencyConflict},
+	{sql.ErrNoRows, apierr.AccountNotFound},
+}
+
+// writeError отвечает ошибкой err со статусом status. Код берётся из errorCodes,
+// для неизвестных ошибок — по статусу.
+func writeError(w http.ResponseWriter, status int, err error) {
+	code := apierr.InvalidRequest
+	switch {
+	case status == http.StatusUnauthorized:
+		code = apierr.Unauthorized
+	case status >= http.StatusInternalServerError:
+		code = apierr.Internal
+	}
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			code = c.code
+			break
+		}
+	}
+
+	message := err.Error()
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// Не раскрываем текст ошибки драйвера
+		message = service.ErrAccountNotFound.Error()
+	case errors.Is(err, repository.ErrIdempotencyConflict):
+		status = http.StatusConflict
+	}
+	apierr.Write(w, status, code, message)
+}
@@ -0,0 +1,937 @@
+package handler_test
+
+import (
+	"banking-api/api"
+	"banking-api/internal/accountno"
+	"banking-api/internal/config"
+	"banking-api/internal/handler"
+	"banking-api/internal/middleware"
+	"banking-api/internal/models"
+	"banking-api/internal/repository/memory"
+	"banking-api/internal/service"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	jwtSecret  = "test-secret"
+	adminToken = "test-admin-token"
+)
+
+func TestMain(m *testing.M) {
+	config.InitLogger()
+	config.Log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv, _ := newServerWithStore(t)
+	return srv
+}
+
+// newServerWithStore поднимает сервер и возвращает хранилище, чтобы тест мог
+// выполнить операции, недоступные через API (например, выдать кредит).
+func newServerWithStore(t *testing.T) (*httptest.Server, *memory.Store) {
+	t.Helper()
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+	accounts := memory.NewAccountRepository(store)
+
+	validate, err := middleware.OpenAPIValidator(api.OpenAPI, func(r *http.Request, err error) {
+		t.Errorf("ответ %s %s не соответствует спецификации: %v", r.Method, r.URL.Path, err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	approvals := service.NewApprovalService(memory.NewApprovalRepository(store), users, nil)
+	accountService := service.NewAccountService(accounts, users, nil)
+	accountService.Approvals = approvals
+
+	router := mux.NewRouter()
+	router.Use(validate)
+	handler.RegisterServiceRoutes(router, handler.NewHealthHandler(nil, nil, nil), handler.NewDocsHandler(api.OpenAPI))
+	handler.RegisterAdminRoutes(router,
+		handler.NewAdminHandler(service.NewAdminService(memory.NewAdminRepository(store), accounts, users)),
+		adminToken,
+	)
+	handler.RegisterRoutes(router,
+		handler.NewAuthHandler(service.NewAuthService(users), jwtSecret),
+		&handler.AccountHandler{
+			AccountService:        accountService,
+			InterestService:       service.NewInterestService(memory.NewInterestRepository(store), accounts),
+			LoanService:           service.NewLoanService(memory.NewLoanRepository(store), accounts),
+			PaymentRequestService: service.NewPaymentRequestService(memory.NewPaymentRequestRepository(store), users, nil),
+			HoldService:           service.NewHoldService(memory.NewHoldRepository(store)),
+			BatchService:          service.NewBatchService(memory.NewBatchRepository(store), accounts, users),
+			ApprovalService:       approvals,
+			AccessService:         service.NewAccessService(memory.NewAccessRepository(store), users, nil),
+			PayeeService:          service.NewPayeeService(memory.NewPayeeRepository(store), users, accountService, nil),
+		},
+		jwtSecret,
+	)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv, store
+}
+
+func do(t *testing.T, srv *httptest.Server, path, token string, body interface{}) (*http.Response, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(http.MethodPost, srv.URL+path, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp, data
+}
+
+func signup(t *testing.T, srv *httptest.Server, username string) string {
+	t.Helper()
+	email := username + "@example.com"
+	if resp, body := do(t, srv, "/register", "", map[string]string{
+		"email": email, "username": username, "password": "secret",
+	}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("register: %d %s", resp.StatusCode, body)
+	}
+	resp, body := do(t, srv, "/login", "", map[string]string{"email": email, "password": "secret"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("login: %d %s", resp.StatusCode, body)
+	}
+	var out struct{ Token string }
+	if err := json.Unmarshal(body, &out); err != nil || out.Token == "" {
+		t.Fatalf("login: нет токена в ответе %s", body)
+	}
+	return out.Token
+}
+
+// createAccount открывает счёт и возвращает его номер.
+func createAccount(t *testing.T, srv *httptest.Server, token string) string {
+	t.Helper()
+	resp, body := do(t, srv, "/accounts", token, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("create account: %d %s", resp.StatusCode, body)
+	}
+	var out struct{ Number string }
+	if err := json.Unmarshal(body, &out); err != nil || out.Number == "" {
+		t.Fatalf("create account: нет номера в ответе %s", body)
+	}
+	return out.Number
+}
+
+// accountID возвращает внутренний ID счёта по номеру — для операций через хранилище.
+func accountID(t *testing.T, store *memory.Store, number string) int64 {
+	t.Helper()
+	id, err := memory.NewAccountRepository(store).GetAccountIDByNumber(context.Background(), number)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	srv := newServer(t)
+
+	if resp, _ := do(t, srv, "/accounts", "", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("без токена: код %d, ожидался 401", resp.StatusCode)
+	}
+	if resp, _ := do(t, srv, "/accounts", "garbage", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("с невалидным токеном: код %d, ожидался 401", resp.StatusCode)
+	}
+}
+
+func TestLoginWrongPassword(t *testing.T) {
+	srv := newServer(t)
+	signup(t, srv, "alice")
+
+	resp, _ := do(t, srv, "/login", "", map[string]string{"email": "alice@example.com", "password": "wrong"})
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("код %d, ожидался 401", resp.StatusCode)
+	}
+}
+
+func TestTopUpAndTransfer(t *testing.T) {
+	srv := newServer(t)
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	aliceAcc := createAccount(t, srv, alice)
+	bobAcc := createAccount(t, srv, bob)
+
+	if resp, body := do(t, srv, "/accounts/topup", alice, map[string]interface{}{
+		"account": aliceAcc, "amount": 100,
+	}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("topup: %d %s", resp.StatusCode, body)
+	}
+
+	transfer := map[string]interface{}{"from_account": aliceAcc, "to_account": bobAcc, "amount": 60}
+	if resp, body := do(t, srv, "/transfer", alice, transfer); resp.StatusCode != http.StatusOK {
+		t.Fatalf("transfer: %d %s", resp.StatusCode, body)
+	}
+	// Остатка 40 не хватает на повторный перевод
+	if resp, _ := do(t, srv, "/transfer", alice, transfer); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("перевод сверх баланса: код %d, ожидался 400", resp.StatusCode)
+	}
+	// Bob не может списать со счёта Alice
+	if resp, _ := do(t, srv, "/transfer", bob, map[string]interface{}{
+		"from_account": aliceAcc, "to_account": bobAcc, "amount": 1,
+	}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("перевод с чужого счёта: код %d, ожидался 400", resp.StatusCode)
+	}
+}
+
+func TestAccountNumbers(t *testing.T) {
+	srv := newServer(t)
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	aliceAcc := createAccount(t, srv, alice)
+	bobAcc := createAccount(t, srv, bob)
+	if err := accountno.Validate(aliceAcc); err != nil {
+		t.Fatalf("номер %s: %v", aliceAcc, err)
+	}
+
+	// Регистр при вводе не важен
+	if resp, body := get(t, srv, "/accounts/"+strings.ToLower(aliceAcc)+"/balance", alice); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"account":"`+aliceAcc+`"`)) {
+		t.Errorf("остатки по номеру в нижнем регистре: %d %s", resp.StatusCode, body)
+	}
+	// Опечатка в последней цифре ловится контрольными цифрами
+	last := aliceAcc[len(aliceAcc)-1] - '0'
+	typo := aliceAcc[:len(aliceAcc)-1] + strconv.Itoa(int(last+1)%10)
+	if resp, body := get(t, srv, "/accounts/"+typo+"/balance", alice); resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"invalid_account_number"`)) {
+		t.Errorf("номер с опечаткой: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, "/transfer", alice, map[string]any{"from_account": aliceAcc, "to_account": "42", "amount": 1}); resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"invalid_account_number"`)) {
+		t.Errorf("перевод на некорректный номер: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, "/transfer", alice, map[string]any{"from_account": aliceAcc, "to_account": accountno.New(), "amount": 1}); resp.StatusCode != http.StatusNotFound || !bytes.Contains(body, []byte(`"account_not_found"`)) {
+		t.Errorf("перевод на несуществующий счёт: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, "/accounts/"+aliceAcc+"/balance", bob); resp.StatusCode != http.StatusNotFound || !bytes.Contains(body, []byte(`"account_not_found"`)) {
+		t.Errorf("остатки чужого счёта: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, "/accounts/topup", bob, map[string]any{"account": bobAcc, "amount": 10}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("topup: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, "/transfer", bob, map[string]any{"from_account": bobAcc, "to_account": aliceAcc, "amount": 10}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("перевод: %d %s", resp.StatusCode, body)
+	}
+	resp, body := get(t, srv, "/accounts/"+aliceAcc+"/transactions", alice)
+	if resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"from_account":"`+bobAcc+`"`)) || bytes.Contains(body, []byte(`_id"`)) {
+		t.Errorf("история по номерам счетов: %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestAccountTypes(t *testing.T) {
+	srv := newServer(t)
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	bobAcc := createAccount(t, srv, bob)
+
+	resp, body := do(t, srv, "/accounts", alice, map[string]string{"type": "credit"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("create credit account: %d %s", resp.StatusCode, body)
+	}
+	var credit struct {
+		Number      string
+		Type        string
+		CreditLimit float64 `json:"credit_limit"`
+	}
+	if err := json.Unmarshal(body, &credit); err != nil {
+		t.Fatal(err)
+	}
+	if credit.Type != "credit" || credit.CreditLimit != service.DefaultCreditLimit {
+		t.Errorf("кредитный счёт = %s", body)
+	}
+	if resp, body := do(t, srv, "/transfer", alice, map[string]interface{}{
+		"from_account": credit.Number, "to_account": bobAcc, "amount": 100,
+	}); resp.StatusCode != http.StatusOK {
+		t.Errorf("перевод с кредитного счёта в минус: %d %s", resp.StatusCode, body)
+	}
+
+	resp, body = do(t, srv, "/accounts", alice, map[string]string{"type": "savings"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("create savings account: %d %s", resp.StatusCode, body)
+	}
+	var savings struct{ Number string }
+	json.Unmarshal(body, &savings)
+	do(t, srv, "/accounts/topup", alice, map[string]interface{}{"account": savings.Number, "amount": 100})
+	resp, body = do(t, srv, "/transfer", alice, map[string]interface{}{
+		"from_account": savings.Number, "to_account": bobAcc, "amount": 10,
+	})
+	if resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"transfer_not_allowed"`)) {
+		t.Errorf("перевод со сберегательного на чужой счёт: %d %s", resp.StatusCode, body)
+	}
+
+	if resp, body := do(t, srv, "/accounts", alice, map[string]string{"type": "deposit"}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("неизвестный тип счёта: %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestInterestStatement(t *testing.T) {
+	srv := newServer(t)
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	acc := createAccount(t, srv, alice)
+	path := "/accounts/" + acc + "/interest"
+
+	resp, body := get(t, srv, path+"?month=2025-03", alice)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("interest: %d %s", resp.StatusCode, body)
+	}
+	var statement struct {
+		Month    string
+		Total    float64
+		Accruals []json.RawMessage
+	}
+	if err := json.Unmarshal(body, &statement); err != nil {
+		t.Fatal(err)
+	}
+	if statement.Month != "2025-03" || statement.Total != 0 || statement.Accruals == nil || len(statement.Accruals) != 0 {
+		t.Errorf("выписка по процентам = %s", body)
+	}
+
+	if resp, body := get(t, srv, path+"?month=2025-13", alice); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("некорректный месяц: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, path, bob); resp.StatusCode != http.StatusNotFound || !bytes.Contains(body, []byte(`"account_not_found"`)) {
+		t.Errorf("проценты по чужому счёту: %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestLowBalanceAlert(t *testing.T) {
+	srv := newServer(t)
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	acc := createAccount(t, srv, alice)
+	path := srv.URL + "/accounts/" + acc + "/low-balance-alert"
+
+	put := func(token, body string) (int, string) {
+		req, err := http.NewRequest(http.MethodPut, path, bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(data)
+	}
+
+	if code, body := put(alice, `{"threshold": 100}`); code != http.StatusOK {
+		t.Errorf("порог: %d %s", code, body)
+	}
+	if code, body := put(alice, `{"threshold": -1}`); code != http.StatusBadRequest {
+		t.Errorf("отрицательный порог: %d %s", code, body)
+	}
+	if code, body := put(bob, `{"threshold": 100}`); code != http.StatusNotFound {
+		t.Errorf("порог для чужого счёта: %d %s", code, body)
+	}
+}
+
+func TestLoans(t *testing.T) {
+	srv, store := newServerWithStore(t)
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	acc := createAccount(t, srv, alice)
+
+	loans := service.NewLoanService(memory.NewLoanRepository(store), memory.NewAccountRepository(store))
+	loan, err := loans.Issue(context.Background(), "ops", accountID(t, store, acc), models.LoanTerms{Principal: 1200, AnnualRate: 12, TermMonths: 12, Method: models.LoanLinear}, "заявка")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := "/loans/" + strconv.FormatInt(loan.ID, 10)
+
+	resp, body := get(t, srv, "/loans", alice)
+	if resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"outstanding":1200`)) || bytes.Contains(body, []byte(`"schedule"`)) {
+		t.Errorf("список кредитов: %d %s", resp.StatusCode, body)
+	}
+	resp, body = get(t, srv, path, alice)
+	var got models.Loan
+	if err := json.Unmarshal(body, &got); err != nil || resp.StatusCode != http.StatusOK || len(got.Schedule) != 12 || got.Schedule[0].Interest != 12 {
+		t.Fatalf("кредит: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, path, bob); resp.StatusCode != http.StatusNotFound || !bytes.Contains(body, []byte(`"loan_not_found"`)) {
+		t.Errorf("чужой кредит: %d %s", resp.StatusCode, body)
+	}
+
+	if resp, body := do(t, srv, path+"/repay", alice, map[string]float64{"amount": 5000}); resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"invalid_amount"`)) {
+		t.Errorf("погашение сверх долга: %d %s", resp.StatusCode, body)
+	}
+	resp, body = do(t, srv, path+"/repay", alice, map[string]float64{"amount": 1200})
+	if resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"status":"closed"`)) {
+		t.Fatalf("полное погашение: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, path+"/repay", alice, map[string]float64{"amount": 1}); resp.StatusCode != http.StatusConflict {
+		t.Errorf("погашение закрытого кредита: %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestPaymentRequests(t *testing.T) {
+	srv := newServer(t)
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	aliceAcc := createAccount(t, srv, alice)
+	bobAcc := createAccount(t, srv, bob)
+	if resp, body := do(t, srv, "/accounts/topup", bob, map[string]any{"account": bobAcc, "amount": 100}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("topup: %d %s", resp.StatusCode, body)
+	}
+
+	if resp, body := do(t, srv, "/payment-requests", alice, map[string]any{"payer": "dave", "to_account": aliceAcc, "amount": 10}); resp.StatusCode != http.StatusNotFound || !bytes.Contains(body, []byte(`"user_not_found"`)) {
+		t.Errorf("запрос неизвестному: %d %s", resp.StatusCode, body)
+	}
+	resp, body := do(t, srv, "/payment-requests", alice, map[string]any{"payer": "bob", "to_account": aliceAcc, "amount": 40, "note": "кино"})
+	var req models.PaymentRequest
+	if err := json.Unmarshal(body, &req); err != nil || resp.StatusCode != http.StatusCreated || req.Status != models.PaymentRequestPending || req.Requester != "alice" {
+		t.Fatalf("создание запроса: %d %s", resp.StatusCode, body)
+	}
+	path := "/payment-requests/" + strconv.FormatInt(req.ID, 10)
+
+	if resp, body := get(t, srv, "/payment-requests", bob); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"note":"кино"`)) {
+		t.Errorf("входящие: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, "/payment-requests?direction=incoming", alice); resp.StatusCode != http.StatusOK || string(body) != "[]\n" {
+		t.Errorf("входящие запросившего: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, "/payment-requests?direction=outgoing&status=pending", alice); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"payer":"bob"`)) {
+		t.Errorf("исходящие: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, path+"/accept", alice, map[string]any{"from_account": aliceAcc}); resp.StatusCode != http.StatusNotFound || !bytes.Contains(body, []byte(`"payment_request_not_found"`)) {
+		t.Errorf("оплата запросившим: %d %s", resp.StatusCode, body)
+	}
+	resp, body = do(t, srv, path+"/accept", bob, map[string]any{"from_account": bobAcc})
+	if resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"status":"accepted"`)) || !bytes.Contains(body, []byte(`"transaction_id"`)) {
+		t.Fatalf("оплата: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, path+"/decline", bob, nil); resp.StatusCode != http.StatusConflict || !bytes.Contains(body, []byte(`"payment_request_closed"`)) {
+		t.Errorf("отклонение оплаченного: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, path, alice); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"resolved_at"`)) {
+		t.Errorf("запрос: %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestHolds(t *testing.T) {
+	srv := newServer(t)
+	alice := signup(t, srv, "alice")
+	shop := signup(t, srv, "shop")
+	aliceAcc := createAccount(t, srv, alice)
+	shopAcc := createAccount(t, srv, shop)
+	if resp, body := do(t, srv, "/accounts/topup", alice, map[string]any{"account": aliceAcc, "amount": 100}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("topup: %d %s", resp.StatusCode, body)
+	}
+
+	resp, body := do(t, srv, "/holds", alice, map[string]any{"account": aliceAcc, "to_account": shopAcc, "amount": 70})
+	var hold models.Hold
+	if err := json.Unmarshal(body, &hold); err != nil || resp.StatusCode != http.StatusCreated || hold.Status != models.HoldActive {
+		t.Fatalf("блокировка: %d %s", resp.StatusCode, body)
+	}
+	path := "/holds/" + strconv.FormatInt(hold.ID, 10)
+	balancePath := "/accounts/" + aliceAcc + "/balance"
+	if resp, body := get(t, srv, balancePath, alice); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"ledger":100,"held":70,"available":30`)) {
+		t.Errorf("остатки: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, "/transfer", alice, map[string]any{"from_account": aliceAcc, "to_account": shopAcc, "amount": 50}); resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"insufficient_funds"`)) {
+		t.Errorf("перевод заблокированных: %d %s", resp.StatusCode, body)
+	}
+
+	if resp, body := get(t, srv, "/holds?status=active", shop); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"amount":70`)) {
+		t.Errorf("блокировки получателя: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, path+"/capture", alice, nil); resp.StatusCode != http.StatusNotFound || !bytes.Contains(body, []byte(`"hold_not_found"`)) {
+		t.Errorf("списание плательщиком: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, path+"/capture", shop, map[string]any{"amount": 80}); resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"invalid_amount"`)) {
+		t.Errorf("списание сверх блокировки: %d %s", resp.StatusCode, body)
+	}
+	resp, body = do(t, srv, path+"/capture", shop, map[string]any{"amount": 45.5})
+	if resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"captured_amount":45.5`)) || !bytes.Contains(body, []byte(`"transaction_id"`)) {
+		t.Fatalf("списание: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, path+"/release", shop, nil); resp.StatusCode != http.StatusConflict || !bytes.Contains(body, []byte(`"hold_closed"`)) {
+		t.Errorf("снятие списанной: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, balancePath, alice); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"ledger":54.5,"held":0,"available":54.5`)) {
+		t.Errorf("остатки после списания: %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestRefunds(t *testing.T) {
+	srv := newServer(t)
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	aliceAcc := createAccount(t, srv, alice)
+	bobAcc := createAccount(t, srv, bob)
+	if resp, body := do(t, srv, "/accounts/topup", alice, map[string]any{"account": aliceAcc, "amount": 100}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("topup: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, "/transfer", alice, map[string]any{"from_account": aliceAcc, "to_account": bobAcc, "amount": 40}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("перевод: %d %s", resp.StatusCode, body)
+	}
+	resp, body := get(t, srv, "/accounts/"+bobAcc+"/transactions", bob)
+	var history []models.Transaction
+	if err := json.Unmarshal(body, &history); err != nil || resp.StatusCode != http.StatusOK || len(history) != 1 {
+		t.Fatalf("история: %d %s", resp.StatusCode, body)
+	}
+	path := "/transactions/" + strconv.FormatInt(history[0].ID, 10) + "/refund"
+
+	if resp, body := do(t, srv, path, alice, nil); resp.StatusCode != http.StatusNotFound || !bytes.Contains(body, []byte(`"transaction_not_found"`)) {
+		t.Errorf("возврат отправителем: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, path, bob, map[string]any{"amount": 50}); resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"invalid_amount"`)) {
+		t.Errorf("возврат сверх перевода: %d %s", resp.StatusCode, body)
+	}
+	resp, body = do(t, srv, path, bob, map[string]any{"amount": 15})
+	if resp.StatusCode != http.StatusCreated || !bytes.Contains(body, []byte(`"kind":"refund"`)) || !bytes.Contains(body, []byte(`"original_transaction_id"`)) {
+		t.Fatalf("частичный возврат: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, path, bob, nil); resp.StatusCode != http.StatusCreated || !bytes.Contains(body, []byte(`"amount":25`)) {
+		t.Errorf("возврат остатка: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, path, bob, nil); resp.StatusCode != http.StatusConflict || !bytes.Contains(body, []byte(`"transfer_refunded"`)) {
+		t.Errorf("повторный возврат: %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestBatches(t *testing.T) {
+	srv := newServer(t)
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	aliceAcc := createAccount(t, srv, alice)
+	bobAcc := createAccount(t, srv, bob)
+	if resp, body := do(t, srv, "/accounts/topup", alice, map[string]any{"account": aliceAcc, "amount": 100}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("topup: %d %s", resp.StatusCode, body)
+	}
+	from := aliceAcc
+	postCSV := func(query, csv string) (*http.Response, []byte) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/batches?"+query, strings.NewReader(csv))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "text/csv")
+		req.Header.Set("Authorization", "Bearer "+alice)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, data
+	}
+
+	if resp, body := postCSV("from_account="+from, "to_username,amount\nbob,abc\n"); resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"invalid_batch"`)) {
+		t.Errorf("некорректная сумма в CSV: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := postCSV("from_account="+from, "to_username,amount\nbob,10\nnobody,5\n"); resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`строка 2`)) {
+		t.Errorf("неизвестный получатель: %d %s", resp.StatusCode, body)
+	}
+	// Тело больше ограничения отклоняется до разбора строк
+	if resp, body := postCSV("from_account="+from, "to_username,amount\n"+strings.Repeat("bob,1\n", 1<<20)); resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"invalid_batch"`)) {
+		t.Errorf("слишком большой CSV: %d %s", resp.StatusCode, body)
+	}
+	resp, body := postCSV("from_account="+from, "amount,to_username,to_account,reference\n30,bob,,аренда\n90,,"+bobAcc+",\n")
+	var batch models.Batch
+	if err := json.Unmarshal(body, &batch); err != nil || resp.StatusCode != http.StatusCreated || batch.Succeeded != 1 || batch.Failed != 1 {
+		t.Fatalf("пакет CSV: %d %s", resp.StatusCode, body)
+	}
+	if batch.Items[1].Status != models.BatchItemFailed || batch.Items[1].Error == "" {
+		t.Errorf("строка без средств: %+v", batch.Items[1])
+	}
+
+	if resp, body := postCSV("from_account="+from+"&all_or_nothing=true", "to_username,amount\nbob,50\nbob,50\n"); resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"insufficient_funds"`)) {
+		t.Errorf("all_or_nothing больше остатка: %d %s", resp.StatusCode, body)
+	}
+
+	// Пакет больше порога синхронного выполнения ставится в очередь
+	lines := make([]map[string]any, service.DefaultBatchSyncLimit+1)
+	for i := range lines {
+		lines[i] = map[string]any{"to_account": bobAcc, "amount": 0.5}
+	}
+	resp, body = do(t, srv, "/batches?from_account="+from, alice, lines)
+	if resp.StatusCode != http.StatusAccepted || !bytes.Contains(body, []byte(`"status":"pending"`)) {
+		t.Fatalf("большой пакет: %d %s", resp.StatusCode, body)
+	}
+	location := resp.Header.Get("Location")
+	if resp, body := get(t, srv, location, alice); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"status":"pending"`)) {
+		t.Errorf("статус пакета: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, location, bob); resp.StatusCode != http.StatusNotFound || !bytes.Contains(body, []byte(`"batch_not_found"`)) {
+		t.Errorf("чужой пакет: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, "/batches", alice); resp.StatusCode != http.StatusOK || bytes.Contains(body, []byte(`"items"`)) {
+		t.Errorf("пакеты: %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestApprovals(t *testing.T) {
+	srv, store := newServerWithStore(t)
+	ctx := context.Background()
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	carol := signup(t, srv, "carol")
+	aliceAcc := createAccount(t, srv, alice)
+	carolAcc := createAccount(t, srv, carol)
+	if resp, body := do(t, srv, "/accounts/topup", alice, map[string]any{"account": aliceAcc, "amount": 1000}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("topup: %d %s", resp.StatusCode, body)
+	}
+	bobID, err := memory.NewUserRepository(store).GetUserIDByUsername(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliceID := accountID(t, store, aliceAcc)
+	entry := models.AuditEntry{Actor: "ops", Action: models.AuditApprovals, AccountID: aliceID, Amount: 100, Reason: "политика"}
+	if err := memory.NewAdminRepository(store).SetApprovalPolicy(ctx, aliceID, 100, []int64{bobID}, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, body := do(t, srv, "/transfer", alice, map[string]any{"from_account": aliceAcc, "to_account": carolAcc, "amount": 300})
+	var approval models.TransferApproval
+	if err := json.Unmarshal(body, &approval); err != nil || resp.StatusCode != http.StatusAccepted || approval.Status != models.ApprovalPending {
+		t.Fatalf("перевод сверх порога: %d %s", resp.StatusCode, body)
+	}
+	location := resp.Header.Get("Location")
+	if location != "/approvals/"+strconv.FormatInt(approval.ID, 10) {
+		t.Errorf("Location = %q", location)
+	}
+	if resp, body := get(t, srv, location, carol); resp.StatusCode != http.StatusNotFound || !bytes.Contains(body, []byte(`"approval_not_found"`)) {
+		t.Errorf("перевод глазами получателя: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, "/approvals?status=pending", bob); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"maker":"alice"`)) {
+		t.Errorf("переводы одобряющего: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, location+"/approve", alice, nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("одобрение автором: %d %s", resp.StatusCode, body)
+	}
+	resp, body = do(t, srv, location+"/approve", bob, nil)
+	if resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"status":"approved"`)) || !bytes.Contains(body, []byte(`"transaction_id"`)) {
+		t.Fatalf("одобрение: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, location+"/reject", bob, map[string]any{"reason": "поздно"}); resp.StatusCode != http.StatusConflict || !bytes.Contains(body, []byte(`"approval_closed"`)) {
+		t.Errorf("отклонение одобренного: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, "/accounts/"+carolAcc+"/balance", carol); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"ledger":300`)) {
+		t.Errorf("баланс получателя: %d %s", resp.StatusCode, body)
+	}
+
+	resp, body = do(t, srv, "/transfer", alice, map[string]any{"from_account": aliceAcc, "to_account": carolAcc, "amount": 200})
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("второй перевод: %d %s", resp.StatusCode, body)
+	}
+	resp, body = do(t, srv, resp.Header.Get("Location")+"/reject", bob, map[string]any{"reason": "нет договора"})
+	if resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"reason":"нет договора"`)) {
+		t.Errorf("отклонение: %d %s", resp.StatusCode, body)
+	}
+
+	// Блокировка сверх порога не обходит одобрение
+	resp, body = do(t, srv, "/holds", alice, map[string]any{"account": aliceAcc, "to_account": carolAcc, "amount": 200})
+	if resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"approval_required"`)) {
+		t.Errorf("блокировка сверх порога: %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestJointAccounts(t *testing.T) {
+	srv := newServer(t)
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	carol := signup(t, srv, "carol")
+	aliceAcc := createAccount(t, srv, alice)
+	carolAcc := createAccount(t, srv, carol)
+	accessPath := "/accounts/" + aliceAcc + "/access"
+	if resp, body := do(t, srv, "/accounts/topup", alice, map[string]any{"account": aliceAcc, "amount": 500}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("topup: %d %s", resp.StatusCode, body)
+	}
+
+	resp, body := do(t, srv, accessPath, alice, map[string]any{"username": "bob", "level": "transact"})
+	if resp.StatusCode != http.StatusCreated || !bytes.Contains(body, []byte(`"status":"pending"`)) || !bytes.Contains(body, []byte(`"invited_by":"alice"`)) {
+		t.Fatalf("приглашение: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, accessPath, alice, map[string]any{"username": "bob", "level": "view"}); resp.StatusCode != http.StatusConflict || !bytes.Contains(body, []byte(`"access_exists"`)) {
+		t.Errorf("повторное приглашение: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, accessPath, bob, map[string]any{"username": "carol", "level": "view"}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("приглашение без доступа: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, "/invitations", bob); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"level":"transact"`)) {
+		t.Errorf("приглашения: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, accessPath+"/accept", carol, nil); resp.StatusCode != http.StatusNotFound || !bytes.Contains(body, []byte(`"access_not_found"`)) {
+		t.Errorf("принятие без приглашения: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, accessPath+"/accept", bob, nil); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"status":"active"`)) {
+		t.Fatalf("принятие: %d %s", resp.StatusCode, body)
+	}
+
+	// Совладелец с доступом transact переводит с общего счёта
+	if resp, body := do(t, srv, "/transfer", bob, map[string]any{"from_account": aliceAcc, "to_account": carolAcc, "amount": 100}); resp.StatusCode != http.StatusOK {
+		t.Errorf("перевод совладельцем: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, accessPath, bob); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"owner":true`)) {
+		t.Errorf("доступ к счёту: %d %s", resp.StatusCode, body)
+	}
+
+	revoke := func(username, token string) (*http.Response, []byte) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodDelete, srv.URL+accessPath+"/"+username, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, data
+	}
+	if resp, body := revoke("alice", alice); resp.StatusCode != http.StatusConflict || !bytes.Contains(body, []byte(`"owner_access"`)) {
+		t.Errorf("отзыв доступа владельца: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := revoke("bob", alice); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"username":"bob"`)) {
+		t.Errorf("отзыв: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, "/transfer", bob, map[string]any{"from_account": aliceAcc, "to_account": carolAcc, "amount": 100}); !bytes.Contains(body, []byte(`"account_not_found"`)) {
+		t.Errorf("перевод после отзыва: %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestPayees(t *testing.T) {
+	srv := newServer(t)
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	aliceAcc := createAccount(t, srv, alice)
+	createAccount(t, srv, bob)
+	if resp, body := do(t, srv, "/accounts/topup", alice, map[string]any{"account": aliceAcc, "amount": 5000}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("topup: %d %s", resp.StatusCode, body)
+	}
+
+	resp, body := do(t, srv, "/payees", alice, map[string]any{"nickname": "боб", "username": "bob", "reference": "долг"})
+	if resp.StatusCode != http.StatusCreated || !bytes.Contains(body, []byte(`"username":"bob"`)) {
+		t.Fatalf("добавление получателя: %d %s", resp.StatusCode, body)
+	}
+	var payee struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal(body, &payee); err != nil {
+		t.Fatal(err)
+	}
+	payeePath := "/payees/" + strconv.FormatInt(payee.ID, 10)
+	if resp, body := do(t, srv, "/payees", alice, map[string]any{"nickname": "боб", "username": "bob"}); resp.StatusCode != http.StatusConflict || !bytes.Contains(body, []byte(`"payee_exists"`)) {
+		t.Errorf("повторное имя: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, "/payees", alice, map[string]any{"nickname": "никто", "username": "nobody"}); resp.StatusCode != http.StatusNotFound || !bytes.Contains(body, []byte(`"user_not_found"`)) {
+		t.Errorf("неизвестный пользователь: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, "/payees", alice); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"nickname":"боб"`)) {
+		t.Errorf("адресная книга: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, payeePath, bob); resp.StatusCode != http.StatusNotFound || !bytes.Contains(body, []byte(`"payee_not_found"`)) {
+		t.Errorf("чужой получатель: %d %s", resp.StatusCode, body)
+	}
+
+	if resp, body := do(t, srv, payeePath+"/transfer", alice, map[string]any{"from_account": aliceAcc, "amount": 2000}); resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"payee_cooling_off"`)) {
+		t.Errorf("крупный перевод новому получателю: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, payeePath+"/transfer", alice, map[string]any{"from_account": aliceAcc, "amount": 200}); resp.StatusCode != http.StatusOK {
+		t.Errorf("перевод получателю: %d %s", resp.StatusCode, body)
+	}
+
+	send := func(method, path string, body any) (*http.Response, []byte) {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			if err := json.NewEncoder(&buf).Encode(body); err != nil {
+				t.Fatal(err)
+			}
+		}
+		req, err := http.NewRequest(method, srv.URL+path, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+alice)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, data
+	}
+	if resp, body := send(http.MethodPut, payeePath, map[string]any{"nickname": "Боб", "username": "bob"}); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"nickname":"Боб"`)) {
+		t.Errorf("изменение получателя: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := send(http.MethodDelete, payeePath, nil); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("удаление получателя: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, payeePath+"/transfer", alice, map[string]any{"from_account": aliceAcc, "amount": 10}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("перевод удалённому получателю: %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestISO20022(t *testing.T) {
+	srv, store := newServerWithStore(t)
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	aliceAcc := createAccount(t, srv, alice)
+	bobAcc := createAccount(t, srv, bob)
+	if resp, body := do(t, srv, "/accounts/topup", alice, map[string]any{"account": aliceAcc, "amount": 100}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("topup: %d %s", resp.StatusCode, body)
+	}
+	pain001 := func(msgID string, amounts ...string) string {
+		var txs strings.Builder
+		for i, amount := range amounts {
+			fmt.Fprintf(&txs, `<CdtTrfTxInf><PmtId><EndToEndId>E2E-%d</EndToEndId></PmtId><Amt><InstdAmt Ccy="RUB">%s</InstdAmt></Amt>`+
+				`<CdtrAcct><Id><IBAN>%s</IBAN></Id></CdtrAcct><RmtInf><Ustrd>счёт %d</Ustrd></RmtInf></CdtTrfTxInf>`, i+1, amount, bobAcc, i+1)
+		}
+		return `<?xml version="1.0"?><Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"><CstmrCdtTrfInitn>` +
+			`<GrpHdr><MsgId>` + msgID + `</MsgId><CreDtTm>2025-03-10T12:00:00</CreDtTm><NbOfTxs>` + strconv.Itoa(len(amounts)) + `</NbOfTxs></GrpHdr>` +
+			`<PmtInf><PmtInfId>PMT-1</PmtInfId><PmtMtd>TRF</PmtMtd><DbtrAcct><Id><IBAN>` + aliceAcc + `</IBAN></Id></DbtrAcct>` +
+			txs.String() + `</PmtInf></CstmrCdtTrfInitn></Document>`
+	}
+	post := func(doc string) (*http.Response, []byte) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/batches/pain.001", strings.NewReader(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/xml")
+		req.Header.Set("Authorization", "Bearer "+alice)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, data
+	}
+
+	// Переводы выполняются вчерашним днём, чтобы попасть в выписку
+	yesterday := time.Now().UTC().AddDate(0, 0, -1)
+	store.Now = func() time.Time { return yesterday }
+	resp, body := post(pain001("MSG-1", "30.50", "90"))
+	store.Now = time.Now
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("Content-Type") != "application/xml" {
+		t.Fatalf("pain.001: %d %s", resp.StatusCode, body)
+	}
+	for _, want := range []string{"<OrgnlMsgId>MSG-1</OrgnlMsgId>", "<PmtInfSts>PART</PmtInfSts>", "<TxSts>ACSC</TxSts>", "<Cd>AM04</Cd>"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("в pain.002 нет %s:\n%s", want, body)
+		}
+	}
+	if resp, body := post(pain001("MSG-2", "1") + strings.Repeat(" ", 8<<20)); resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"invalid_batch"`)) {
+		t.Errorf("слишком большое сообщение: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := post(pain001("MSG-1", "1")); resp.StatusCode != http.StatusConflict || !bytes.Contains(body, []byte(`"batch_exists"`)) {
+		t.Errorf("повторная загрузка: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := post(strings.Replace(pain001("MSG-2", "1"), "<NbOfTxs>1<", "<NbOfTxs>2<", 1)); resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"invalid_batch"`)) {
+		t.Errorf("неверный NbOfTxs: %d %s", resp.StatusCode, body)
+	}
+
+	resp, body = get(t, srv, "/batches", alice)
+	var batches []models.Batch
+	if err := json.Unmarshal(body, &batches); err != nil || len(batches) != 1 || batches[0].MessageID != "MSG-1" {
+		t.Fatalf("пакеты: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, fmt.Sprintf("/batches/%d/pain.002", batches[0].ID), alice); resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte("<OrgnlEndToEndId>E2E-2</OrgnlEndToEndId>")) {
+		t.Errorf("отчёт pain.002: %d %s", resp.StatusCode, body)
+	}
+
+	path := "/accounts/" + aliceAcc + "/camt.053"
+	resp, body = get(t, srv, path, alice)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("camt.053: %d %s", resp.StatusCode, body)
+	}
+	for _, want := range []string{"<Cd>CLBD</Cd>", "<EndToEndId>E2E-1</EndToEndId>", "<Ustrd>счёт 1</Ustrd>", "<NbOfNtries>1</NbOfNtries>"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("в camt.053 нет %s:\n%s", want, body)
+		}
+	}
+	if resp, body := get(t, srv, path+"?date="+time.Now().UTC().Format(time.DateOnly), alice); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("выписка за сегодня: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, srv, path, bob); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("выписка по чужому счёту: %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestAdminReconciliation(t *testing.T) {
+	srv := newServer(t)
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	aliceAcc := createAccount(t, srv, alice)
+	bobAcc := createAccount(t, srv, bob)
+	if resp, body := do(t, srv, "/accounts/topup", alice, map[string]any{"account": aliceAcc, "amount": 100}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("topup: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, "/transfer", alice, map[string]any{"from_account": aliceAcc, "to_account": bobAcc, "amount": 40}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("transfer: %d %s", resp.StatusCode, body)
+	}
+	reconcile := func(method, token string) (*http.Response, models.Reconciliation) {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+"/admin/reconciliation", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var report models.Reconciliation
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return resp, report
+	}
+
+	// JWT клиента не даёт доступа к административным маршрутам
+	if resp, _ := reconcile(http.MethodGet, alice); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("с токеном клиента: код %d, ожидался 401", resp.StatusCode)
+	}
+	resp, first := reconcile(http.MethodGet, adminToken)
+	if resp.StatusCode != http.StatusOK || !first.OK() || first.Accounts != 2 || first.Transactions != 2 ||
+		first.TotalBalance != 100 || first.ExternalNet != 100 {
+		t.Fatalf("сверка: %d %+v", resp.StatusCode, first)
+	}
+	if _, again := reconcile(http.MethodGet, adminToken); !again.CheckedAt.Equal(first.CheckedAt) {
+		t.Errorf("GET выполнил сверку повторно: %v, ожидалось %v", again.CheckedAt, first.CheckedAt)
+	}
+	if resp, report := reconcile(http.MethodPost, adminToken); resp.StatusCode != http.StatusOK || !report.CheckedAt.After(first.CheckedAt) {
+		t.Errorf("POST: %d %+v", resp.StatusCode, report)
+	}
+}
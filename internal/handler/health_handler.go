@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"banking-api/internal/buildinfo"
+	"banking-api/internal/migrate"
+	"banking-api/internal/service"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// checkTimeout ограничивает время каждой проверки готовности.
+const checkTimeout = 2 * time.Second
+
+type HealthHandler struct {
+	DB           *sql.DB
+	Migrator     *migrate.Migrator
+	EmailService *service.EmailService
+
+	ready atomic.Bool
+}
+
+func NewHealthHandler(db *sql.DB, migrator *migrate.Migrator, email *service.EmailService) *HealthHandler {
+	h := &HealthHandler{DB: db, Migrator: migrator, EmailService: email}
+	h.ready.Store(true)
+	return h
+}
+
+// SetReady переключает готовность, например при корректном завершении сервера.
+func (h *HealthHandler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+// Healthz отвечает 200, пока процесс жив.
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readyz проверяет БД, версию схемы и SMTP. Недоступный SMTP не снимает готовность,
+// а переводит сервис в состояние degraded.
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]string{}
+	status := "ready"
+
+	if !h.ready.Load() {
+		status = "not_ready"
+		checks["server"] = "shutting down"
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+	defer cancel()
+
+	if err := h.DB.PingContext(ctx); err != nil {
+		status = "not_ready"
+		checks["database"] = err.Error()
+	} else {
+		checks["database"] = "ok"
+
+		version, err := h.Migrator.Version(ctx)
+		switch {
+		case err != nil:
+			status = "not_ready"
+			checks["migrations"] = err.Error()
+		case version != h.Migrator.Latest():
+			status = "not_ready"
+			checks["migrations"] = fmt.Sprintf("версия схемы %d, ожидается %d", version, h.Migrator.Latest())
+		default:
+			checks["migrations"] = "ok"
+		}
+	}
+
+	if err := h.EmailService.Ping(ctx); err != nil {
+		if status == "ready" {
+			status = "degraded"
+		}
+		checks["smtp"] = err.Error()
+	} else {
+		checks["smtp"] = "ok"
+	}
+
+	code := http.StatusOK
+	if status == "not_ready" {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, map[string]interface{}{
+		"status": status,
+		"checks": checks,
+	})
+}
+
+// Version возвращает коммит и время сборки.
+func (h *HealthHandler) Version(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, buildinfo.Get())
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
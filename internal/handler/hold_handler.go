@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"banking-api/internal/apierr"
+	"banking-api/internal/middleware"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Balance возвращает учтённый, заблокированный и доступный остатки счёта.
+func (h *AccountHandler) Balance(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	accountID, ok := h.accountID(w, r, mux.Vars(r)["number"])
+	if !ok {
+		return
+	}
+
+	balance, err := h.AccountService.Balance(r.Context(), userID, accountID)
+	switch {
+	case errors.Is(err, service.ErrAccountNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, balance)
+}
+
+// CreateHold блокирует сумму на счёте пользователя в пользу другого счёта.
+func (h *AccountHandler) CreateHold(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	var req models.CreateHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
+		return
+	}
+
+	accountID, ok := h.accountID(w, r, req.Account)
+	if !ok {
+		return
+	}
+	toAccountID, ok := h.accountID(w, r, req.ToAccount)
+	if !ok {
+		return
+	}
+	hold, err := h.HoldService.Authorize(r.Context(), userID, accountID, toAccountID, req.Amount)
+	switch {
+	case errors.Is(err, service.ErrAccountNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, hold)
+}
+
+// Holds возвращает блокировки на счетах пользователя и в пользу его счетов,
+// с фильтром по status.
+func (h *AccountHandler) Holds(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+
+	holds, err := h.HoldService.List(r.Context(), userID, r.URL.Query().Get("status"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, holds)
+}
+
+// Hold возвращает блокировку, в которой пользователь владеет одним из счетов.
+func (h *AccountHandler) Hold(w http.ResponseWriter, r *http.Request) {
+	h.resolveHold(w, r, h.HoldService.Get)
+}
+
+// CaptureHold списывает блокировку (всю или часть суммы) на счёт получателя.
+func (h *AccountHandler) CaptureHold(w http.ResponseWriter, r *http.Request) {
+	// Тело необязательно: без него списывается вся сумма
+	var req models.CaptureHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
+		return
+	}
+	h.resolveHold(w, r, func(ctx context.Context, userID, holdID int64) (*models.Hold, error) {
+		return h.HoldService.Capture(ctx, userID, holdID, req.Amount)
+	})
+}
+
+// ReleaseHold снимает блокировку без списания.
+func (h *AccountHandler) ReleaseHold(w http.ResponseWriter, r *http.Request) {
+	h.resolveHold(w, r, h.HoldService.Release)
+}
+
+// resolveHold разбирает ID блокировки из пути, выполняет над ней action и
+// отвечает блокировкой или ошибкой.
+func (h *AccountHandler) resolveHold(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, userID, holdID int64) (*models.Hold, error)) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	holdID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "некорректный ID блокировки")
+		return
+	}
+
+	hold, err := action(r.Context(), userID, holdID)
+	switch {
+	case errors.Is(err, service.ErrHoldNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, repository.ErrHoldClosed):
+		writeError(w, http.StatusConflict, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, hold)
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"banking-api/internal/apierr"
+	"banking-api/internal/iso20022"
+	"banking-api/internal/middleware"
+	"banking-api/internal/models"
+	"banking-api/internal/service"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// SubmitPain001 принимает поручение на перевод ISO 20022 pain.001: каждый
+// платёж (PmtInf) сохраняется пакетом переводов со счёта DbtrAcct, как в
+// SubmitBatch. Ответ — отчёт о статусе pain.002 по всем платежам сообщения.
+func (h *AccountHandler) SubmitPain001(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	var allOrNothing bool
+	if v := r.URL.Query().Get("all_or_nothing"); v != "" {
+		if allOrNothing, err = strconv.ParseBool(v); err != nil {
+			apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "некорректный all_or_nothing")
+			return
+		}
+	}
+
+	body, ok := readBatchBody(w, r, maxPain001BodyBytes)
+	if !ok {
+		return
+	}
+	requests, err := iso20022.ParsePain001(bytes.NewReader(body), time.Now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: %v", service.ErrInvalidBatch, err))
+		return
+	}
+	for i := range requests {
+		requests[i].AllOrNothing = allOrNothing
+	}
+
+	created, err := h.BatchService.SubmitMessage(r.Context(), userID, requests)
+	switch {
+	case errors.Is(err, service.ErrInvalidBatch), errors.Is(err, service.ErrInvalidAccountNumber):
+		writeError(w, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, service.ErrAccountNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, service.ErrBatchExists):
+		writeError(w, http.StatusConflict, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	batches := make([]models.Batch, len(created))
+	for i, batch := range created {
+		batches[i] = *batch
+	}
+	writePain002(w, http.StatusCreated, batches)
+}
+
+// BatchPain002 возвращает отчёт о статусе pain.002 по платежу, загруженному
+// из pain.001 пакетом id.
+func (h *AccountHandler) BatchPain002(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	batchID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "некорректный ID пакета")
+		return
+	}
+
+	batch, err := h.BatchService.Get(r.Context(), userID, batchID)
+	switch {
+	case errors.Is(err, service.ErrBatchNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if batch.MessageID == "" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%w: пакет %d загружен не из pain.001", service.ErrBatchNotFound, batchID))
+		return
+	}
+	writePain002(w, http.StatusOK, []models.Batch{*batch})
+}
+
+// Camt053 возвращает выписку ISO 20022 camt.053 по счёту за день date
+// (YYYY-MM-DD, по UTC), по умолчанию — за вчера.
+func (h *AccountHandler) Camt053(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	accountID, ok := h.accountID(w, r, mux.Vars(r)["number"])
+	if !ok {
+		return
+	}
+	day := time.Now().UTC().AddDate(0, 0, -1)
+	if v := r.URL.Query().Get("date"); v != "" {
+		if day, err = time.Parse(time.DateOnly, v); err != nil {
+			apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "некорректный date, ожидается YYYY-MM-DD")
+			return
+		}
+	}
+
+	statement, err := h.AccountService.Statement(r.Context(), userID, accountID, day)
+	switch {
+	case errors.Is(err, service.ErrStatementNotReady):
+		writeError(w, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, service.ErrAccountNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	var buf bytes.Buffer
+	if err := iso20022.WriteCamt053(&buf, statement, time.Now()); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeXML(w, http.StatusOK, buf.Bytes())
+}
+
+func writePain002(w http.ResponseWriter, code int, batches []models.Batch) {
+	var buf bytes.Buffer
+	if err := iso20022.WritePain002(&buf, batches, time.Now()); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeXML(w, code, buf.Bytes())
+}
+
+func writeXML(w http.ResponseWriter, code int, body []byte) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(code)
+	w.Write(body)
+}
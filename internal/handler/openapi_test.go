@@ -0,0 +1,172 @@
+package handler_test
+
+import (
+	"banking-api/api"
+	"banking-api/internal/handler"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+)
+
+// routeVarPattern убирает регулярные выражения из переменных пути mux: {id:[0-9]+} -> {id}.
+var routeVarPattern = regexp.MustCompile(`\{([^}:]+):[^}]+\}`)
+
+func get(t *testing.T, srv *httptest.Server, path, token string) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp, data
+}
+
+// TestSpecMatchesRoutes ловит расхождение контракта: каждый маршрут роутера
+// описан в openapi.yaml, и каждая операция спецификации зарегистрирована.
+func TestSpecMatchesRoutes(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData(api.OpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("спецификация невалидна: %v", err)
+	}
+
+	router := mux.NewRouter()
+	handler.RegisterServiceRoutes(router, handler.NewHealthHandler(nil, nil, nil), handler.NewDocsHandler(api.OpenAPI))
+	handler.RegisterAdminRoutes(router, &handler.AdminHandler{}, adminToken)
+	handler.RegisterRoutes(router, &handler.AuthHandler{}, &handler.AccountHandler{}, jwtSecret)
+
+	registered := map[string]bool{}
+	err = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// PathPrefix-подроутер без своих методов
+			return nil
+		}
+		path := routeVarPattern.ReplaceAllString(tpl, "{$1}")
+		for _, method := range methods {
+			registered[method+" "+path] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	documented := map[string]bool{}
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented[method+" "+path] = true
+		}
+	}
+
+	var missing, stale []string
+	for op := range registered {
+		if !documented[op] {
+			missing = append(missing, op)
+		}
+	}
+	for op := range documented {
+		if !registered[op] {
+			stale = append(stale, op)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	if len(missing) > 0 {
+		t.Errorf("маршруты не описаны в openapi.yaml: %s", strings.Join(missing, ", "))
+	}
+	if len(stale) > 0 {
+		t.Errorf("в openapi.yaml есть операции без маршрута: %s", strings.Join(stale, ", "))
+	}
+}
+
+func TestRequestValidation(t *testing.T) {
+	srv := newServer(t)
+	alice := signup(t, srv, "alice")
+	acc := createAccount(t, srv, alice)
+
+	cases := []struct {
+		name string
+		path string
+		body interface{}
+	}{
+		{"сумма строкой", "/accounts/topup", map[string]interface{}{"account": acc, "amount": "100"}},
+		{"отрицательная сумма", "/accounts/topup", map[string]interface{}{"account": acc, "amount": -5}},
+		{"нет счёта", "/accounts/topup", map[string]interface{}{"amount": 5}},
+		{"нет получателя", "/transfer", map[string]interface{}{"from_account": acc, "amount": 5}},
+		{"нет пароля", "/register", map[string]string{"email": "x@example.com", "username": "x"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, body := do(t, srv, tc.path, alice, tc.body)
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Fatalf("код %d, ожидался 400: %s", resp.StatusCode, body)
+			}
+			if !strings.Contains(string(body), "не соответствует спецификации") {
+				t.Errorf("ошибка не от валидатора: %s", body)
+			}
+		})
+	}
+
+	for _, query := range []string{"?limit=0", "?limit=501", "?offset=-1", "?limit=abc"} {
+		path := fmt.Sprintf("/accounts/%s/transactions%s", acc, query)
+		if resp, body := get(t, srv, path, alice); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: код %d, ожидался 400: %s", path, resp.StatusCode, body)
+		}
+	}
+}
+
+// TestResponsesMatchSpec прогоняет основные сценарии; newServer проверяет
+// каждый ответ по спецификации.
+func TestResponsesMatchSpec(t *testing.T) {
+	srv := newServer(t)
+	alice := signup(t, srv, "alice")
+	bob := signup(t, srv, "bob")
+	aliceAcc := createAccount(t, srv, alice)
+	bobAcc := createAccount(t, srv, bob)
+
+	do(t, srv, "/accounts/topup", alice, map[string]interface{}{"account": aliceAcc, "amount": 100})
+	do(t, srv, "/transfer", alice, map[string]interface{}{"from_account": aliceAcc, "to_account": bobAcc, "amount": 10})
+	do(t, srv, "/transfer/by-usernames", alice, map[string]interface{}{"from_username": "alice", "to_username": "bob", "amount": 5})
+	// Списать с чужого счёта, указав чужое имя отправителя, нельзя
+	if resp, body := do(t, srv, "/transfer/by-usernames", bob, map[string]interface{}{"from_username": "alice", "to_username": "bob", "amount": 5}); resp.StatusCode != http.StatusBadRequest || !bytes.Contains(body, []byte(`"invalid_request"`)) {
+		t.Errorf("перевод от чужого имени: %d %s", resp.StatusCode, body)
+	}
+	do(t, srv, "/transfer", alice, map[string]interface{}{"from_account": aliceAcc, "to_account": bobAcc, "amount": 1000})
+
+	if resp, body := get(t, srv, fmt.Sprintf("/accounts/%s/transactions?limit=10", aliceAcc), alice); resp.StatusCode != http.StatusOK {
+		t.Errorf("история: код %d %s", resp.StatusCode, body)
+	}
+	if resp, _ := get(t, srv, fmt.Sprintf("/accounts/%s/transactions", aliceAcc), bob); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("чужая история: код %d, ожидался 404", resp.StatusCode)
+	}
+	for _, path := range []string{"/healthz", "/version", "/openapi.yaml", "/docs"} {
+		if resp, _ := get(t, srv, path, ""); resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: код %d", path, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"banking-api/internal/apierr"
+	"banking-api/internal/middleware"
+	"banking-api/internal/models"
+	"banking-api/internal/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// CreatePayee добавляет получателя в адресную книгу пользователя.
+func (h *AccountHandler) CreatePayee(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	var req models.PayeeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
+		return
+	}
+
+	payee, err := h.PayeeService.Create(r.Context(), userID, req)
+	if err != nil {
+		writePayeeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, payee)
+}
+
+// Payees возвращает адресную книгу пользователя.
+func (h *AccountHandler) Payees(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+
+	payees, err := h.PayeeService.List(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, payees)
+}
+
+// Payee возвращает получателя из адресной книги.
+func (h *AccountHandler) Payee(w http.ResponseWriter, r *http.Request) {
+	h.resolvePayee(w, r, func(ctx context.Context, userID, payeeID int64) (any, error) {
+		return h.PayeeService.Get(ctx, userID, payeeID)
+	})
+}
+
+// UpdatePayee заменяет имя, получателя и назначение получателя.
+func (h *AccountHandler) UpdatePayee(w http.ResponseWriter, r *http.Request) {
+	var req models.PayeeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
+		return
+	}
+	h.resolvePayee(w, r, func(ctx context.Context, userID, payeeID int64) (any, error) {
+		return h.PayeeService.Update(ctx, userID, payeeID, req)
+	})
+}
+
+// DeletePayee удаляет получателя из адресной книги.
+func (h *AccountHandler) DeletePayee(w http.ResponseWriter, r *http.Request) {
+	h.resolvePayee(w, r, func(ctx context.Context, userID, payeeID int64) (any, error) {
+		return nil, h.PayeeService.Delete(ctx, userID, payeeID)
+	})
+}
+
+// TransferToPayee переводит получателю из адресной книги. Ответ — как у /transfer.
+func (h *AccountHandler) TransferToPayee(w http.ResponseWriter, r *http.Request) {
+	var req models.PayeeTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
+		return
+	}
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	payeeID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "некорректный ID получателя")
+		return
+	}
+
+	fromAccountID, ok := h.accountID(w, r, req.FromAccount)
+	if !ok {
+		return
+	}
+	err = h.PayeeService.Transfer(r.Context(), userID, payeeID, fromAccountID, req.Amount, req.Reference, r.Header.Get(IdempotencyKeyHeader))
+	if errors.Is(err, service.ErrPayeeNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeTransferResult(w, err)
+}
+
+// resolvePayee разбирает ID получателя из пути, выполняет над ним action и
+// отвечает результатом или ошибкой; пустой результат — 204 No Content.
+func (h *AccountHandler) resolvePayee(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, userID, payeeID int64) (any, error)) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	payeeID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "некорректный ID получателя")
+		return
+	}
+
+	result, err := action(r.Context(), userID, payeeID)
+	switch {
+	case err != nil:
+		writePayeeError(w, err)
+	case result == nil:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// writePayeeError отвечает ошибкой операции с адресной книгой.
+func writePayeeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrPayeeNotFound), errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrAccountNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrPayeeExists):
+		writeError(w, http.StatusConflict, err)
+	default:
+		writeError(w, http.StatusBadRequest, err)
+	}
+}
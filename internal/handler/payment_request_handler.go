@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"banking-api/internal/apierr"
+	"banking-api/internal/middleware"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// CreatePaymentRequest запрашивает деньги у другого пользователя.
+func (h *AccountHandler) CreatePaymentRequest(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	var req models.CreatePaymentRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
+		return
+	}
+
+	toAccountID, ok := h.accountID(w, r, req.ToAccount)
+	if !ok {
+		return
+	}
+	created, err := h.PaymentRequestService.Request(r.Context(), userID, req.Payer, toAccountID, req.Amount, req.Note)
+	switch {
+	case errors.Is(err, service.ErrAccountNotFound), errors.Is(err, service.ErrUserNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// PaymentRequests возвращает входящие (direction=incoming, по умолчанию) или
+// исходящие (direction=outgoing) запросы денег, с фильтром по status.
+func (h *AccountHandler) PaymentRequests(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	query := r.URL.Query()
+	var incoming bool
+	switch query.Get("direction") {
+	case "", "incoming":
+		incoming = true
+	case "outgoing":
+	default:
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "direction должен быть incoming или outgoing")
+		return
+	}
+
+	requests, err := h.PaymentRequestService.List(r.Context(), userID, incoming, query.Get("status"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, requests)
+}
+
+// PaymentRequest возвращает запрос денег, в котором пользователь — одна из сторон.
+func (h *AccountHandler) PaymentRequest(w http.ResponseWriter, r *http.Request) {
+	h.resolvePaymentRequest(w, r, func(ctx context.Context, userID, requestID int64) (*models.PaymentRequest, error) {
+		return h.PaymentRequestService.Get(ctx, userID, requestID)
+	})
+}
+
+// AcceptPaymentRequest оплачивает входящий запрос со счёта пользователя.
+func (h *AccountHandler) AcceptPaymentRequest(w http.ResponseWriter, r *http.Request) {
+	var req models.AcceptPaymentRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "невалидный JSON")
+		return
+	}
+	h.resolvePaymentRequest(w, r, func(ctx context.Context, userID, requestID int64) (*models.PaymentRequest, error) {
+		fromAccountID, err := h.AccountService.AccountID(ctx, req.FromAccount)
+		if err != nil {
+			return nil, err
+		}
+		return h.PaymentRequestService.Accept(ctx, userID, requestID, fromAccountID)
+	})
+}
+
+// DeclinePaymentRequest отклоняет входящий запрос.
+func (h *AccountHandler) DeclinePaymentRequest(w http.ResponseWriter, r *http.Request) {
+	h.resolvePaymentRequest(w, r, h.PaymentRequestService.Decline)
+}
+
+// CancelPaymentRequest отменяет исходящий запрос.
+func (h *AccountHandler) CancelPaymentRequest(w http.ResponseWriter, r *http.Request) {
+	h.resolvePaymentRequest(w, r, h.PaymentRequestService.Cancel)
+}
+
+// resolvePaymentRequest разбирает ID запроса из пути, выполняет над ним action
+// и отвечает запросом или ошибкой.
+func (h *AccountHandler) resolvePaymentRequest(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, userID, requestID int64) (*models.PaymentRequest, error)) {
+	userID, err := strconv.ParseInt(middleware.GetUserID(r.Context()), 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "ошибка токена")
+		return
+	}
+	requestID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		apierr.Write(w, http.StatusBadRequest, apierr.InvalidRequest, "некорректный ID запроса")
+		return
+	}
+
+	req, err := action(r.Context(), userID, requestID)
+	switch {
+	case errors.Is(err, service.ErrPaymentRequestNotFound), errors.Is(err, service.ErrAccountNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, repository.ErrPaymentRequestClosed):
+		writeError(w, http.StatusConflict, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, req)
+}
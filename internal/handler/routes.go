@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"banking-api/internal/middleware"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// RegisterServiceRoutes регистрирует служебные маршруты: проверки здоровья,
+// метрики и документацию API.
+func RegisterServiceRoutes(router *mux.Router, health *HealthHandler, docs *DocsHandler) {
+	router.HandleFunc("/healthz", health.Healthz).Methods("GET")
+	router.HandleFunc("/readyz", health.Readyz).Methods("GET")
+	router.HandleFunc("/version", health.Version).Methods("GET")
+	router.Handle("/metrics", promhttp.Handler()).Methods("GET")
+	router.HandleFunc("/openapi.yaml", docs.OpenAPI).Methods("GET")
+	router.HandleFunc("/docs", docs.UI).Methods("GET")
+}
+
+// RegisterAdminRoutes регистрирует административные маршруты /admin,
+// доступные по токену администратора.
+func RegisterAdminRoutes(router *mux.Router, admin *AdminHandler, adminToken string) {
+	protected := router.PathPrefix("/admin").Subrouter()
+	protected.Use(middleware.AdminMiddleware(adminToken))
+
+	protected.HandleFunc("/reconciliation", admin.Reconciliation).Methods("GET")
+	protected.HandleFunc("/reconciliation", admin.Reconcile).Methods("POST")
+}
+
+// RegisterRoutes регистрирует публичные и защищённые JWT маршруты API.
+func RegisterRoutes(router *mux.Router, auth *AuthHandler, account *AccountHandler, jwtSecret string) {
+	// Публичные
+	router.HandleFunc("/register", auth.Register).Methods("POST")
+	router.HandleFunc("/login", auth.Login).Methods("POST")
+
+	// Защищённые
+	protected := router.PathPrefix("/").Subrouter()
+	protected.Use(middleware.AuthMiddleware(jwtSecret))
+
+	protected.HandleFunc("/accounts", account.Create).Methods("POST")
+	protected.HandleFunc("/accounts/topup", account.TopUp).Methods("POST")
+	protected.HandleFunc("/accounts/{number}/balance", account.Balance).Methods("GET")
+	protected.HandleFunc("/accounts/{number}/transactions", account.Transactions).Methods("GET")
+	protected.HandleFunc("/accounts/{number}/interest", account.Interest).Methods("GET")
+	protected.HandleFunc("/accounts/{number}/camt.053", account.Camt053).Methods("GET")
+	protected.HandleFunc("/accounts/{number}/low-balance-alert", account.LowBalanceAlert).Methods("PUT")
+	protected.HandleFunc("/transfer", account.Transfer).Methods("POST")
+	protected.HandleFunc("/transfer/by-usernames", account.TransferByUsernames).Methods("POST")
+	protected.HandleFunc("/transactions/{id:[0-9]+}/refund", account.Refund).Methods("POST")
+	protected.HandleFunc("/loans", account.Loans).Methods("GET")
+	protected.HandleFunc("/loans/{id:[0-9]+}", account.Loan).Methods("GET")
+	protected.HandleFunc("/loans/{id:[0-9]+}/repay", account.RepayLoan).Methods("POST")
+	protected.HandleFunc("/payment-requests", account.CreatePaymentRequest).Methods("POST")
+	protected.HandleFunc("/payment-requests", account.PaymentRequests).Methods("GET")
+	protected.HandleFunc("/payment-requests/{id:[0-9]+}", account.PaymentRequest).Methods("GET")
+	protected.HandleFunc("/payment-requests/{id:[0-9]+}/accept", account.AcceptPaymentRequest).Methods("POST")
+	protected.HandleFunc("/payment-requests/{id:[0-9]+}/decline", account.DeclinePaymentRequest).Methods("POST")
+	protected.HandleFunc("/payment-requests/{id:[0-9]+}/cancel", account.CancelPaymentRequest).Methods("POST")
+	protected.HandleFunc("/holds", account.CreateHold).Methods("POST")
+	protected.HandleFunc("/holds", account.Holds).Methods("GET")
+	protected.HandleFunc("/holds/{id:[0-9]+}", account.Hold).Methods("GET")
+	protected.HandleFunc("/holds/{id:[0-9]+}/capture", account.CaptureHold).Methods("POST")
+	protected.HandleFunc("/holds/{id:[0-9]+}/release", account.ReleaseHold).Methods("POST")
+	protected.HandleFunc("/batches", account.SubmitBatch).Methods("POST")
+	protected.HandleFunc("/batches", account.Batches).Methods("GET")
+	protected.HandleFunc("/batches/{id:[0-9]+}", account.Batch).Methods("GET")
+	protected.HandleFunc("/batches/pain.001", account.SubmitPain001).Methods("POST")
+	protected.HandleFunc("/batches/{id:[0-9]+}/pain.002", account.BatchPain002).Methods("GET")
+	protected.HandleFunc("/approvals", account.Approvals).Methods("GET")
+	protected.HandleFunc("/approvals/{id:[0-9]+}", account.Approval).Methods("GET")
+	protected.HandleFunc("/approvals/{id:[0-9]+}/approve", account.ApproveTransfer).Methods("POST")
+	protected.HandleFunc("/approvals/{id:[0-9]+}/reject", account.RejectTransfer).Methods("POST")
+	protected.HandleFunc("/accounts/{number}/access", account.InviteAccess).Methods("POST")
+	protected.HandleFunc("/accounts/{number}/access", account.AccountAccess).Methods("GET")
+	protected.HandleFunc("/accounts/{number}/access/accept", account.AcceptAccess).Methods("POST")
+	protected.HandleFunc("/accounts/{number}/access/{username}", account.RevokeAccess).Methods("DELETE")
+	protected.HandleFunc("/invitations", account.Invitations).Methods("GET")
+	protected.HandleFunc("/payees", account.CreatePayee).Methods("POST")
+	protected.HandleFunc("/payees", account.Payees).Methods("GET")
+	protected.HandleFunc("/payees/{id:[0-9]+}", account.Payee).Methods("GET")
+	protected.HandleFunc("/payees/{id:[0-9]+}", account.UpdatePayee).Methods("PUT")
+	protected.HandleFunc("/payees/{id:[0-9]+}", account.DeletePayee).Methods("DELETE")
+	protected.HandleFunc("/payees/{id:[0-9]+}/transfer", account.TransferToPayee).Methods("POST")
+}
@@ -0,0 +1,164 @@
+package iso20022
+
+import (
+	"banking-api/internal/models"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type camt053Document struct {
+	XMLName   xml.Name `xml:"Document"`
+	Xmlns     string   `xml:"xmlns,attr"`
+	Statement struct {
+		GroupHeader groupHeader      `xml:"GrpHdr"`
+		Statement   camt053Statement `xml:"Stmt"`
+	} `xml:"BkToCstmrStmt"`
+}
+
+type camt053Statement struct {
+	ID      string `xml:"Id"`
+	Created string `xml:"CreDtTm"`
+	Period  struct {
+		From string `xml:"FrDtTm"`
+		To   string `xml:"ToDtTm"`
+	} `xml:"FrToDt"`
+	Account struct {
+		accountID
+		Currency string `xml:"Ccy"`
+		Owner    string `xml:"Ownr>Nm,omitempty"`
+	} `xml:"Acct"`
+	Balances []camt053Balance `xml:"Bal"`
+	Summary  struct {
+		Entries int    `xml:"TtlNtries>NbOfNtries"`
+		Net     string `xml:"TtlNtries>TtlNetNtryAmt"`
+		NetSign string `xml:"TtlNtries>CdtDbtInd"`
+		Credits struct {
+			Count int    `xml:"NbOfNtries"`
+			Sum   string `xml:"Sum"`
+		} `xml:"TtlCdtNtries"`
+		Debits struct {
+			Count int    `xml:"NbOfNtries"`
+			Sum   string `xml:"Sum"`
+		} `xml:"TtlDbtNtries"`
+	} `xml:"TxsSummry"`
+	Entries []camt053Entry `xml:"Ntry"`
+}
+
+type camt053Balance struct {
+	Code   string `xml:"Tp>CdOrPrtry>Cd"`
+	Amount amount `xml:"Amt"`
+	Sign   string `xml:"CdtDbtInd"`
+	Date   string `xml:"Dt>Dt"`
+}
+
+type camt053Entry struct {
+	Reference   string `xml:"NtryRef"`
+	Amount      amount `xml:"Amt"`
+	Sign        string `xml:"CdtDbtInd"`
+	Reversal    bool   `xml:"RvslInd,omitempty"`
+	Status      string `xml:"Sts"`
+	BookingDate string `xml:"BookgDt>DtTm"`
+	ValueDate   string `xml:"ValDt>Dt"`
+	ServicerRef string `xml:"AcctSvcrRef"`
+	Kind        string `xml:"BkTxCd>Prtry>Cd"`
+	Details     struct {
+		Refs struct {
+			ServicerRef string `xml:"AcctSvcrRef"`
+			EndToEndID  string `xml:"EndToEndId,omitempty"`
+		} `xml:"Refs"`
+		Parties    *camt053Parties `xml:"RltdPties,omitempty"`
+		Remittance string          `xml:"RmtInf>Ustrd,omitempty"`
+	} `xml:"NtryDtls>TxDtls"`
+}
+
+// camt053Parties — счета второй стороны движения.
+type camt053Parties struct {
+	Debtor   *accountID `xml:"DbtrAcct,omitempty"`
+	Creditor *accountID `xml:"CdtrAcct,omitempty"`
+}
+
+// WriteCamt053 пишет выписку по счёту camt.053 за период statement: остатки
+// OPBD и CLBD на начало и конец периода, сводку и движения. Код операции
+// BkTxCd — вид движения (models.TransactionKinds).
+func WriteCamt053(w io.Writer, statement *models.Statement, now time.Time) error {
+	var doc camt053Document
+	doc.Xmlns = Camt053Namespace
+	id := fmt.Sprintf("%s-%s", statement.Account, statement.From.UTC().Format("20060102"))
+	doc.Statement.GroupHeader = newGroupHeader("STMT-"+id, now)
+
+	stmt := &doc.Statement.Statement
+	stmt.ID = truncate(id, maxIDLen)
+	stmt.Created = now.UTC().Format(dateTimeLayout)
+	stmt.Period.From = statement.From.UTC().Format(dateTimeLayout)
+	stmt.Period.To = statement.To.UTC().Format(dateTimeLayout)
+	stmt.Account.IBAN = statement.Account
+	stmt.Account.Currency = Currency
+	stmt.Account.Owner = statement.Owner
+	// Остаток на конец — на последний день периода [From, To)
+	stmt.Balances = []camt053Balance{
+		newBalance("OPBD", statement.OpeningBalance, statement.From),
+		newBalance("CLBD", statement.ClosingBalance, statement.To.Add(-time.Nanosecond)),
+	}
+
+	var credits, debits float64
+	for _, e := range statement.Entries {
+		entry := newEntry(statement.AccountID, e)
+		if entry.Sign == "CRDT" {
+			stmt.Summary.Credits.Count++
+			credits += e.Amount
+		} else {
+			stmt.Summary.Debits.Count++
+			debits += e.Amount
+		}
+		stmt.Entries = append(stmt.Entries, entry)
+	}
+	stmt.Summary.Entries = len(stmt.Entries)
+	stmt.Summary.Net = formatAmount(credits - debits)
+	stmt.Summary.NetSign = creditDebit(credits - debits)
+	stmt.Summary.Credits.Sum = formatAmount(credits)
+	stmt.Summary.Debits.Sum = formatAmount(debits)
+	return writeDocument(w, doc)
+}
+
+func newBalance(code string, balance float64, date time.Time) camt053Balance {
+	return camt053Balance{
+		Code:   code,
+		Amount: newAmount(balance),
+		Sign:   creditDebit(balance),
+		Date:   date.UTC().Format(time.DateOnly),
+	}
+}
+
+func newEntry(account int64, e models.StatementEntry) camt053Entry {
+	ref := strconv.FormatInt(e.ID, 10)
+	entry := camt053Entry{
+		Reference:   ref,
+		Amount:      newAmount(e.Amount),
+		Sign:        "DBIT",
+		Reversal:    e.Kind == models.KindReversal,
+		Status:      "BOOK",
+		BookingDate: e.CreatedAt.UTC().Format(dateTimeLayout),
+		ValueDate:   e.CreatedAt.UTC().Format(time.DateOnly),
+		ServicerRef: ref,
+		Kind:        e.Kind,
+	}
+	if e.ToAccountID == account {
+		entry.Sign = "CRDT"
+	}
+	entry.Details.Refs.ServicerRef = ref
+	entry.Details.Refs.EndToEndID = e.EndToEndID
+	if e.FromAccount != "" || e.ToAccount != "" {
+		entry.Details.Parties = &camt053Parties{}
+		if e.FromAccount != "" {
+			entry.Details.Parties.Debtor = &accountID{IBAN: e.FromAccount}
+		}
+		if e.ToAccount != "" {
+			entry.Details.Parties.Creditor = &accountID{IBAN: e.ToAccount}
+		}
+	}
+	entry.Details.Remittance = e.Reference
+	return entry
+}
@@ -0,0 +1,179 @@
+package iso20022
+
+import (
+	"banking-api/internal/models"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const samplePain001 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>MSG-1</MsgId>
+      <CreDtTm>2026-03-01T10:00:00</CreDtTm>
+      <NbOfTxs>3</NbOfTxs>
+      <CtrlSum>350.50</CtrlSum>
+    </GrpHdr>
+    <PmtInf>
+      <PmtInfId>PMT-1</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <NbOfTxs>2</NbOfTxs>
+      <CtrlSum>300.50</CtrlSum>
+      <ReqdExctnDt><Dt>2026-03-01</Dt></ReqdExctnDt>
+      <DbtrAcct><Id><IBAN>GB82WEST12345698765432</IBAN></Id><Ccy>RUB</Ccy></DbtrAcct>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-1</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="RUB">100.50</InstdAmt></Amt>
+        <CdtrAcct><Id><IBAN>DE89370400440532013000</IBAN></Id></CdtrAcct>
+        <RmtInf><Ustrd>Счёт 15</Ustrd><Ustrd>за март</Ustrd></RmtInf>
+      </CdtTrfTxInf>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-2</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="RUB">200</InstdAmt></Amt>
+        <CdtrAcct><Id><IBAN>DE89370400440532013000</IBAN></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+    <PmtInf>
+      <PmtInfId>PMT-2</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <ReqdExctnDt>2026-02-28</ReqdExctnDt>
+      <DbtrAcct><Id><IBAN>DE89370400440532013000</IBAN></Id></DbtrAcct>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-3</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="RUB">50.00</InstdAmt></Amt>
+        <CdtrAcct><Id><IBAN>GB82WEST12345698765432</IBAN></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+  </CstmrCdtTrfInitn>
+</Document>`
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestParsePain001(t *testing.T) {
+	requests, err := ParsePain001(strings.NewReader(samplePain001), testNow)
+	if err != nil {
+		t.Fatalf("ParsePain001: %v", err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("платежей %d, ожидалось 2", len(requests))
+	}
+	first := requests[0]
+	if first.MessageID != "MSG-1" || first.PaymentInfoID != "PMT-1" || first.FromAccount != "GB82WEST12345698765432" || len(first.Lines) != 2 {
+		t.Fatalf("первый платёж разобран неверно: %+v", first)
+	}
+	want := models.BatchLine{ToAccount: "DE89370400440532013000", Amount: 100.5, Reference: "Счёт 15 за март", EndToEndID: "E2E-1"}
+	if first.Lines[0] != want {
+		t.Errorf("строка %+v, ожидалось %+v", first.Lines[0], want)
+	}
+	if requests[1].PaymentInfoID != "PMT-2" || requests[1].Lines[0].Amount != 50 {
+		t.Errorf("второй платёж разобран неверно: %+v", requests[1])
+	}
+}
+
+func TestParsePain001Invalid(t *testing.T) {
+	tests := []struct {
+		name, old, new string
+	}{
+		{"другое сообщение", "pain.001.001.09", "camt.053.001.02"},
+		{"нет MsgId", "<MsgId>MSG-1</MsgId>", ""},
+		{"длинный MsgId", "MSG-1", strings.Repeat("M", 36)},
+		{"NbOfTxs сообщения", "<NbOfTxs>3</NbOfTxs>", "<NbOfTxs>4</NbOfTxs>"},
+		{"CtrlSum сообщения", "<CtrlSum>350.50</CtrlSum>", "<CtrlSum>350.51</CtrlSum>"},
+		{"CtrlSum платежа", "<CtrlSum>300.50</CtrlSum>", "<CtrlSum>300</CtrlSum>"},
+		{"способ платежа", "<PmtMtd>TRF</PmtMtd>", "<PmtMtd>CHK</PmtMtd>"},
+		{"валюта", `Ccy="RUB">200`, `Ccy="EUR">200`},
+		{"сумма с копейками", ">200<", ">200.001<"},
+		{"отложенный платёж", "2026-03-01</Dt>", "2026-03-02</Dt>"},
+		{"нет счёта получателя", "<CdtrAcct><Id><IBAN>GB82WEST12345698765432</IBAN></Id></CdtrAcct>", ""},
+		{"нет EndToEndId", "<EndToEndId>E2E-3</EndToEndId>", ""},
+		{"повтор PmtInfId", "PMT-2", "PMT-1"},
+		{"не XML", "<Document", "Document"},
+	}
+	for _, tt := range tests {
+		doc := strings.Replace(samplePain001, tt.old, tt.new, 1)
+		if _, err := ParsePain001(strings.NewReader(doc), testNow); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("%s: ошибка %v, ожидалось ErrInvalidMessage", tt.name, err)
+		}
+	}
+}
+
+func TestWritePain002(t *testing.T) {
+	batches := []models.Batch{
+		{ID: 7, MessageID: "MSG-1", PaymentInfoID: "PMT-1", Status: models.BatchCompleted, Total: 2, Amount: 300.5, Succeeded: 1, Failed: 1,
+			Items: []models.BatchItem{
+				{Line: 1, Amount: 100.5, EndToEndID: "E2E-1", Status: models.BatchItemSucceeded},
+				{Line: 2, Amount: 200, EndToEndID: "E2E-2", Status: models.BatchItemFailed, Error: "недостаточно средств"},
+			}},
+		{ID: 8, MessageID: "MSG-1", PaymentInfoID: "PMT-2", Status: models.BatchPending, Total: 1, Amount: 50,
+			Items: []models.BatchItem{{Line: 1, Amount: 50, Status: models.BatchItemPending}}},
+	}
+	var buf bytes.Buffer
+	if err := WritePain002(&buf, batches, testNow); err != nil {
+		t.Fatalf("WritePain002: %v", err)
+	}
+	var doc pain002Document
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("отчёт не разобран: %v\n%s", err, buf.String())
+	}
+	original := doc.Report.Original
+	if doc.XMLName.Space != Pain002Namespace || original.MessageID != "MSG-1" || original.NumberOfTxs != 3 ||
+		original.ControlSum != "350.50" || original.Status != StatusPending {
+		t.Errorf("неверный заголовок отчёта: %+v", original)
+	}
+	if len(doc.Report.Payments) != 2 || doc.Report.Payments[0].Status != StatusPartial || doc.Report.Payments[1].Status != StatusPending {
+		t.Fatalf("неверные статусы платежей: %+v", doc.Report.Payments)
+	}
+	txs := doc.Report.Payments[0].Transactions
+	if txs[0].Status != StatusSettled || txs[1].Status != StatusRejected || txs[1].Reason == nil || txs[1].Reason.Code != "AM04" {
+		t.Errorf("неверные статусы переводов: %+v", txs)
+	}
+	if got := doc.Report.Payments[1].Transactions[0].EndToEndID; got != notProvidedID {
+		t.Errorf("OrgnlEndToEndId = %q, ожидалось %s", got, notProvidedID)
+	}
+}
+
+func TestWriteCamt053(t *testing.T) {
+	from := time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)
+	statement := &models.Statement{
+		AccountID: 1, Account: "GB82WEST12345698765432", Owner: "alice",
+		From: from, To: from.AddDate(0, 0, 1), OpeningBalance: 1000, ClosingBalance: 850,
+		Entries: []models.StatementEntry{
+			{Transaction: models.Transaction{ID: 11, Kind: models.KindTransfer, FromAccountID: 1, ToAccountID: 2,
+				FromAccount: "GB82WEST12345698765432", ToAccount: "DE89370400440532013000", Amount: 200, CreatedAt: from.Add(time.Hour)},
+				Reference: "Счёт 15", EndToEndID: "E2E-1"},
+			{Transaction: models.Transaction{ID: 12, Kind: models.KindAdjustment, ToAccountID: 1, Amount: 50, CreatedAt: from.Add(2 * time.Hour)}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteCamt053(&buf, statement, testNow); err != nil {
+		t.Fatalf("WriteCamt053: %v", err)
+	}
+	var doc camt053Document
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("выписка не разобрана: %v\n%s", err, buf.String())
+	}
+	stmt := doc.Statement.Statement
+	if stmt.ID != "GB82WEST12345698765432-20260228" || stmt.Account.IBAN != statement.Account || stmt.Account.Owner != "alice" {
+		t.Errorf("неверный заголовок выписки: %+v", stmt)
+	}
+	if len(stmt.Balances) != 2 || stmt.Balances[0].Amount.Value != "1000.00" || stmt.Balances[1].Amount.Value != "850.00" ||
+		stmt.Balances[1].Date != "2026-02-28" {
+		t.Errorf("неверные остатки: %+v", stmt.Balances)
+	}
+	if stmt.Summary.Entries != 2 || stmt.Summary.Net != "150.00" || stmt.Summary.NetSign != "DBIT" {
+		t.Errorf("неверная сводка: %+v", stmt.Summary)
+	}
+	debit, credit := stmt.Entries[0], stmt.Entries[1]
+	if debit.Sign != "DBIT" || debit.Details.Refs.EndToEndID != "E2E-1" || debit.Details.Remittance != "Счёт 15" ||
+		debit.Details.Parties == nil || debit.Details.Parties.Creditor.IBAN != "DE89370400440532013000" {
+		t.Errorf("неверное списание: %+v", debit)
+	}
+	if credit.Sign != "CRDT" || credit.Kind != models.KindAdjustment || credit.Details.Parties != nil {
+		t.Errorf("неверное зачисление: %+v", credit)
+	}
+}
@@ -0,0 +1,214 @@
+package iso20022
+
+import (
+	"banking-api/internal/models"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pain001Document — поля pain.001 (CustomerCreditTransferInitiation), которые
+// нужны для переводов; остальные игнорируются.
+type pain001Document struct {
+	XMLName    xml.Name `xml:"Document"`
+	Initiation struct {
+		GroupHeader struct {
+			MessageID   string `xml:"MsgId"`
+			NumberOfTxs string `xml:"NbOfTxs"`
+			ControlSum  string `xml:"CtrlSum"`
+		} `xml:"GrpHdr"`
+		Payments []pain001Payment `xml:"PmtInf"`
+	} `xml:"CstmrCdtTrfInitn"`
+}
+
+type pain001Payment struct {
+	ID            string `xml:"PmtInfId"`
+	Method        string `xml:"PmtMtd"`
+	NumberOfTxs   string `xml:"NbOfTxs"`
+	ControlSum    string `xml:"CtrlSum"`
+	ExecutionDate struct {
+		// До версии 08 — дата текстом, с версии 08 — в элементе Dt или DtTm
+		Text     string `xml:",chardata"`
+		Date     string `xml:"Dt"`
+		DateTime string `xml:"DtTm"`
+	} `xml:"ReqdExctnDt"`
+	DebtorAccount struct {
+		accountID
+		Currency string `xml:"Ccy"`
+	} `xml:"DbtrAcct"`
+	Transactions []pain001Transaction `xml:"CdtTrfTxInf"`
+}
+
+type pain001Transaction struct {
+	EndToEndID      string    `xml:"PmtId>EndToEndId"`
+	Amount          amount    `xml:"Amt>InstdAmt"`
+	CreditorAccount accountID `xml:"CdtrAcct"`
+	Remittance      []string  `xml:"RmtInf>Ustrd"`
+}
+
+// amountPattern — сумма в рублях: не больше двух знаков после точки.
+var amountPattern = regexp.MustCompile(`^[0-9]{1,15}(\.[0-9]{1,2})?$`)
+
+// ParsePain001 разбирает поручение на перевод pain.001 в пакеты переводов: по
+// пакету на каждый платёж (PmtInf) со счёта DbtrAcct. Проверяются число
+// переводов и контрольные суммы, валюта и дата исполнения: платежи с датой
+// позже now не принимаются. Счета и суммы проверяет BatchService.
+func ParsePain001(r io.Reader, now time.Time) ([]models.BatchRequest, error) {
+	var doc pain001Document
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+	if !strings.HasPrefix(doc.XMLName.Space, pain001NamespacePrefix) {
+		return nil, fmt.Errorf("%w: ожидается документ pain.001 (%s*), получен %q", ErrInvalidMessage, pain001NamespacePrefix, doc.XMLName.Space)
+	}
+	header := doc.Initiation.GroupHeader
+	messageID := strings.TrimSpace(header.MessageID)
+	if err := checkID("MsgId", messageID); err != nil {
+		return nil, err
+	}
+	if len(doc.Initiation.Payments) == 0 {
+		return nil, fmt.Errorf("%w: в сообщении нет платежей PmtInf", ErrInvalidMessage)
+	}
+
+	today := now.UTC().Format(time.DateOnly)
+	var requests []models.BatchRequest
+	seen := map[string]bool{}
+	count, sum := 0, 0.0
+	for _, p := range doc.Initiation.Payments {
+		req, paymentSum, err := parsePayment(p, today)
+		if err != nil {
+			return nil, err
+		}
+		if seen[req.PaymentInfoID] {
+			return nil, fmt.Errorf("%w: PmtInfId %s повторяется", ErrInvalidMessage, req.PaymentInfoID)
+		}
+		seen[req.PaymentInfoID] = true
+		req.MessageID = messageID
+		requests = append(requests, req)
+		count += len(req.Lines)
+		sum += paymentSum
+	}
+	if err := checkTotals("GrpHdr", header.NumberOfTxs, header.ControlSum, count, sum); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// parsePayment разбирает платёж PmtInf и возвращает его пакет и сумму.
+func parsePayment(p pain001Payment, today string) (models.BatchRequest, float64, error) {
+	req := models.BatchRequest{PaymentInfoID: strings.TrimSpace(p.ID), FromAccount: strings.TrimSpace(p.DebtorAccount.IBAN)}
+	if err := checkID("PmtInfId", req.PaymentInfoID); err != nil {
+		return req, 0, err
+	}
+	fail := func(format string, args ...any) (models.BatchRequest, float64, error) {
+		return req, 0, fmt.Errorf("%w: платёж %s: %s", ErrInvalidMessage, req.PaymentInfoID, fmt.Sprintf(format, args...))
+	}
+	if method := strings.TrimSpace(p.Method); method != "" && method != "TRF" {
+		return fail("способ платежа %s не поддерживается, ожидается TRF", method)
+	}
+	if req.FromAccount == "" {
+		return fail("не указан IBAN счёта плательщика DbtrAcct")
+	}
+	if ccy := strings.TrimSpace(p.DebtorAccount.Currency); ccy != "" && ccy != Currency {
+		return fail("валюта счёта %s не поддерживается, ожидается %s", ccy, Currency)
+	}
+	if date := executionDate(p); date > today {
+		return fail("дата исполнения %s позже текущей: отложенные платежи не поддерживаются", date)
+	}
+	if len(p.Transactions) == 0 {
+		return fail("нет переводов CdtTrfTxInf")
+	}
+
+	sum := 0.0
+	for i, tx := range p.Transactions {
+		line, err := parseTransaction(tx)
+		if err != nil {
+			return fail("перевод %d: %v", i+1, err)
+		}
+		req.Lines = append(req.Lines, line)
+		sum += line.Amount
+	}
+	if err := checkTotals("платёж "+req.PaymentInfoID, p.NumberOfTxs, p.ControlSum, len(req.Lines), sum); err != nil {
+		return req, 0, err
+	}
+	return req, sum, nil
+}
+
+// executionDate возвращает дату исполнения платежа в формате YYYY-MM-DD или
+// пустую строку, если она не указана.
+func executionDate(p pain001Payment) string {
+	date := p.ExecutionDate.Date
+	if date == "" {
+		date = p.ExecutionDate.DateTime
+	}
+	if date == "" {
+		date = p.ExecutionDate.Text
+	}
+	date = strings.TrimSpace(date)
+	if len(date) > len(time.DateOnly) {
+		date = date[:len(time.DateOnly)]
+	}
+	return date
+}
+
+// parseTransaction разбирает перевод CdtTrfTxInf в строку пакета.
+func parseTransaction(tx pain001Transaction) (models.BatchLine, error) {
+	line := models.BatchLine{
+		ToAccount:  strings.TrimSpace(tx.CreditorAccount.IBAN),
+		EndToEndID: strings.TrimSpace(tx.EndToEndID),
+	}
+	if err := checkID("EndToEndId", line.EndToEndID); err != nil {
+		return line, err
+	}
+	if line.ToAccount == "" {
+		return line, fmt.Errorf("не указан IBAN счёта получателя CdtrAcct")
+	}
+	if ccy := strings.TrimSpace(tx.Amount.Currency); ccy != Currency {
+		return line, fmt.Errorf("валюта %q не поддерживается, ожидается %s", ccy, Currency)
+	}
+	value := strings.TrimSpace(tx.Amount.Value)
+	if !amountPattern.MatchString(value) {
+		return line, fmt.Errorf("некорректная сумма %q", value)
+	}
+	line.Amount, _ = strconv.ParseFloat(value, 64)
+	var parts []string
+	for _, s := range tx.Remittance {
+		if s = strings.TrimSpace(s); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	line.Reference = strings.Join(parts, " ")
+	return line, nil
+}
+
+// checkID проверяет обязательный идентификатор Max35Text.
+func checkID(name, id string) error {
+	switch {
+	case id == "":
+		return fmt.Errorf("%w: не указан %s", ErrInvalidMessage, name)
+	case len([]rune(id)) > maxIDLen:
+		return fmt.Errorf("%w: %s длиннее %d символов", ErrInvalidMessage, name, maxIDLen)
+	}
+	return nil
+}
+
+// checkTotals сверяет необязательные NbOfTxs и CtrlSum с фактическими числом
+// переводов и суммой.
+func checkTotals(where, numberOfTxs, controlSum string, count int, sum float64) error {
+	if v := strings.TrimSpace(numberOfTxs); v != "" {
+		if n, err := strconv.Atoi(v); err != nil || n != count {
+			return fmt.Errorf("%w: %s: NbOfTxs %s, а переводов %d", ErrInvalidMessage, where, v, count)
+		}
+	}
+	if v := strings.TrimSpace(controlSum); v != "" {
+		if s, err := strconv.ParseFloat(v, 64); err != nil || math.Round(s*100) != math.Round(sum*100) {
+			return fmt.Errorf("%w: %s: CtrlSum %s, а сумма переводов %.2f", ErrInvalidMessage, where, v, sum)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package iso20022
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Статусы платежей и переводов pain.002 (ExternalPaymentTransactionStatus1Code)
+const (
+	StatusPending   = "PDNG" // принят, ещё не выполнен
+	StatusSettled   = "ACSC" // выполнен
+	StatusPartial   = "PART" // выполнена часть переводов
+	StatusRejected  = "RJCT" // отклонён
+	notProvidedID   = "NOTPROVIDED"
+	maxAddtlInfoLen = 105
+)
+
+type pain002Document struct {
+	XMLName xml.Name `xml:"Document"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Report  struct {
+		GroupHeader groupHeader `xml:"GrpHdr"`
+		Original    struct {
+			MessageID   string `xml:"OrgnlMsgId"`
+			MessageName string `xml:"OrgnlMsgNmId"`
+			NumberOfTxs int    `xml:"OrgnlNbOfTxs"`
+			ControlSum  string `xml:"OrgnlCtrlSum"`
+			Status      string `xml:"GrpSts"`
+		} `xml:"OrgnlGrpInfAndSts"`
+		Payments []pain002Payment `xml:"OrgnlPmtInfAndSts"`
+	} `xml:"CstmrPmtStsRpt"`
+}
+
+type pain002Payment struct {
+	ID           string               `xml:"OrgnlPmtInfId"`
+	NumberOfTxs  int                  `xml:"OrgnlNbOfTxs"`
+	ControlSum   string               `xml:"OrgnlCtrlSum"`
+	Status       string               `xml:"PmtInfSts"`
+	Transactions []pain002Transaction `xml:"TxInfAndSts"`
+}
+
+type pain002Transaction struct {
+	EndToEndID string         `xml:"OrgnlEndToEndId"`
+	Status     string         `xml:"TxSts"`
+	Reason     *pain002Reason `xml:"StsRsnInf,omitempty"`
+}
+
+type pain002Reason struct {
+	Code string `xml:"Rsn>Cd"`
+	Info string `xml:"AddtlInf,omitempty"`
+}
+
+// WritePain002 пишет отчёт о статусе платежей одного сообщения pain.001:
+// batches — пакеты его платежей со строками.
+func WritePain002(w io.Writer, batches []models.Batch, now time.Time) error {
+	if len(batches) == 0 {
+		return fmt.Errorf("нет пакетов для отчёта pain.002")
+	}
+	var doc pain002Document
+	doc.Xmlns = Pain002Namespace
+	doc.Report.GroupHeader = newGroupHeader(fmt.Sprintf("STS-%d-%s", batches[0].ID, now.UTC().Format("20060102150405")), now)
+	original := &doc.Report.Original
+	original.MessageID = batches[0].MessageID
+	original.MessageName = Pain001Name
+
+	total := 0.0
+	statuses := map[string]bool{}
+	for _, batch := range batches {
+		payment := pain002Payment{
+			ID:          batch.PaymentInfoID,
+			NumberOfTxs: len(batch.Items),
+			ControlSum:  formatAmount(batch.Amount),
+			Status:      PaymentStatus(batch),
+		}
+		for _, item := range batch.Items {
+			payment.Transactions = append(payment.Transactions, transactionStatus(item))
+		}
+		doc.Report.Payments = append(doc.Report.Payments, payment)
+		original.NumberOfTxs += payment.NumberOfTxs
+		total += batch.Amount
+		statuses[payment.Status] = true
+	}
+	original.ControlSum = formatAmount(total)
+	switch {
+	case len(statuses) == 1:
+		original.Status = doc.Report.Payments[0].Status
+	case statuses[StatusPending]:
+		original.Status = StatusPending
+	default:
+		original.Status = StatusPartial
+	}
+	return writeDocument(w, doc)
+}
+
+// PaymentStatus возвращает статус платежа pain.002 по статусу пакета и его строк.
+func PaymentStatus(batch models.Batch) string {
+	switch {
+	case batch.Status == models.BatchPending:
+		return StatusPending
+	case batch.Status == models.BatchFailed || batch.Succeeded == 0:
+		return StatusRejected
+	case batch.Succeeded < batch.Total:
+		return StatusPartial
+	}
+	return StatusSettled
+}
+
+func transactionStatus(item models.BatchItem) pain002Transaction {
+	tx := pain002Transaction{EndToEndID: item.EndToEndID}
+	if tx.EndToEndID == "" {
+		tx.EndToEndID = notProvidedID
+	}
+	switch item.Status {
+	case models.BatchItemSucceeded:
+		tx.Status = StatusSettled
+	case models.BatchItemFailed:
+		tx.Status = StatusRejected
+		tx.Reason = &pain002Reason{Code: reasonCode(item.Error), Info: truncate(item.Error, maxAddtlInfoLen)}
+	case models.BatchItemSkipped:
+		tx.Status = StatusRejected
+		tx.Reason = &pain002Reason{Code: "NARR", Info: "не выполнен: платёж отклонён из-за другого перевода"}
+	default:
+		tx.Status = StatusPending
+	}
+	return tx
+}
+
+// reasonCode сопоставляет причину отказа в строке пакета (см.
+// repository.TransferRejection) с кодом ExternalStatusReason1Code.
+func reasonCode(reason string) string {
+	switch reason {
+	case repository.ErrInsufficientFunds.Error():
+		return "AM04" // InsufficientFunds
+	case repository.ErrAccountFrozen.Error():
+		return "AC06" // BlockedAccount
+	case repository.ErrTransferLimitExceeded.Error(), repository.ErrSavingsExternalTransfer.Error(), repository.ErrApprovalRequired.Error():
+		return "AG01" // TransactionForbidden
+	case "счёт не найден":
+		return "AC01" // IncorrectAccountNumber
+	}
+	return "NARR"
+}
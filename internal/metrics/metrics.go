@@ -0,0 +1,114 @@
+// Package metrics описывает метрики Prometheus, которые отдаются на /metrics.
+package metrics
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "banking"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Количество HTTP-запросов по шаблону маршрута, методу и коду ответа.",
+	}, []string{"route", "method", "code"})
+
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Время обработки HTTP-запросов.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	Transfers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Количество переводов по результату.",
+	}, []string{"outcome"})
+
+	TransferAmount = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "transfer_amount",
+		Help:      "Суммы переводов по результату.",
+		Buckets:   []float64{10, 100, 500, 1000, 5000, 10000, 50000, 100000, 500000, 1000000},
+	}, []string{"outcome"})
+
+	Logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Количество попыток входа по результату.",
+	}, []string{"result"})
+
+	EmailsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "emails_sent_total",
+		Help:      "Количество отправленных email по результату.",
+	}, []string{"outcome"})
+
+	JobRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_runs_total",
+		Help:      "Количество запусков фоновых заданий по результату.",
+	}, []string{"job", "outcome"})
+
+	ReconciliationDiscrepancies = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_discrepancies",
+		Help:      "Количество нарушений, найденных последней сверкой, по виду проверки.",
+	}, []string{"check"})
+
+	ReconciliationImbalance = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_imbalance",
+		Help:      "Разница между суммой балансов и внешними движениями по последней сверке.",
+	})
+
+	ReconciliationLastRun = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_last_run_timestamp_seconds",
+		Help:      "Время последней завершённой сверки.",
+	})
+)
+
+// Результаты переводов
+const (
+	TransferSuccess           = "success"
+	TransferInvalid           = "invalid"
+	TransferInsufficientFunds = "insufficient_funds"
+	TransferError             = "error"
+	TransferReplayed          = "replayed"         // повтор по ключу идемпотентности
+	TransferPendingApproval   = "pending_approval" // поставлен на одобрение
+)
+
+// Проверки сверки
+const (
+	CheckBalanceMismatch    = "balance_mismatch"
+	CheckNegativeBalance    = "negative_balance"
+	CheckInvalidTransaction = "invalid_transaction"
+)
+
+// RegisterDB регистрирует статистику пула соединений sql.DB.
+func RegisterDB(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// ObserveTransfer учитывает перевод с заданным результатом.
+func ObserveTransfer(outcome string, amount float64) {
+	Transfers.WithLabelValues(outcome).Inc()
+	TransferAmount.WithLabelValues(outcome).Observe(amount)
+}
+
+// ObserveReconciliation обновляет метрики по результату сверки.
+func ObserveReconciliation(checkedAt time.Time, mismatches, negative, invalid int, imbalance float64) {
+	ReconciliationDiscrepancies.WithLabelValues(CheckBalanceMismatch).Set(float64(mismatches))
+	ReconciliationDiscrepancies.WithLabelValues(CheckNegativeBalance).Set(float64(negative))
+	ReconciliationDiscrepancies.WithLabelValues(CheckInvalidTransaction).Set(float64(invalid))
+	ReconciliationImbalance.Set(imbalance)
+	ReconciliationLastRun.Set(float64(checkedAt.Unix()))
+}
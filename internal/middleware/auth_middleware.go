@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"banking-api/internal/apierr"
 	"context"
+	"crypto/subtle"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -17,29 +20,59 @@ func AuthMiddleware(secret string) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-				http.Error(w, "отсутствует Authorization заголовок", http.StatusUnauthorized)
+				apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "отсутствует Authorization заголовок")
 				return
 			}
 
-			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-			claims := &jwt.RegisteredClaims{}
-
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-				return []byte(secret), nil
-			})
-
-			if err != nil || !token.Valid {
-				http.Error(w, "невалидный токен", http.StatusUnauthorized)
+			userID, err := ParseToken(strings.TrimPrefix(authHeader, "Bearer "), secret)
+			if err != nil {
+				apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "невалидный токен")
 				return
 			}
 
 			// Сохраняем userID из subject
-			ctx := context.WithValue(r.Context(), userIDKey, claims.Subject)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(w, r.WithContext(WithUserID(r.Context(), userID)))
 		})
 	}
 }
 
+// AdminMiddleware пропускает запросы с заголовком Authorization: Bearer token.
+// С пустым token все запросы отклоняются.
+func AdminMiddleware(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" || !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				apierr.Write(w, http.StatusUnauthorized, apierr.Unauthorized, "невалидный токен администратора")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ParseToken проверяет подпись и срок действия JWT и возвращает userID из subject.
+// Используется и HTTP-middleware, и gRPC-интерсептором.
+func ParseToken(tokenString, secret string) (string, error) {
+	claims := &jwt.RegisteredClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if !token.Valid {
+		return "", errors.New("невалидный токен")
+	}
+	return claims.Subject, nil
+}
+
+// WithUserID сохраняет userID в контексте
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
 // Достаём userID из контекста
 func GetUserID(ctx context.Context) string {
 	val := ctx.Value(userIDKey)
@@ -0,0 +1,289 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockKey — ключ advisory lock PostgreSQL, под которым выполняются миграции,
+// чтобы несколько экземпляров сервиса не применяли их одновременно.
+// В SQLite ту же роль играет блокировка БД на запись при BEGIN IMMEDIATE:
+// каждая миграция перед применением перепроверяется внутри своей транзакции.
+const lockKey int64 = 7_265_114_031
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	DB         *sql.DB
+	Driver     string
+	Migrations []Migration
+}
+
+// New читает миграции из fsys. Ожидаются файлы вида 0001_init.up.sql и 0001_init.down.sql.
+// driver — имя драйвера database/sql ("postgres" или "sqlite").
+func New(db *sql.DB, driver string, fsys fs.FS) (*Migrator, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, file := range files {
+		version, name, direction, err := parseFileName(file)
+		if err != nil {
+			return nil, err
+		}
+		body, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("миграция %d: разные имена %q и %q", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("миграция %d_%s: отсутствует up-скрипт", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return &Migrator{DB: db, Driver: driver, Migrations: migrations}, nil
+}
+
+func parseFileName(file string) (version int64, name, direction string, err error) {
+	base := strings.TrimSuffix(path.Base(file), ".sql")
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		direction = "up"
+	case strings.HasSuffix(base, ".down"):
+		direction = "down"
+	default:
+		return 0, "", "", fmt.Errorf("файл миграции %s: ожидается суффикс .up.sql или .down.sql", file)
+	}
+	base = strings.TrimSuffix(base, "."+direction)
+
+	num, name, ok := strings.Cut(base, "_")
+	if !ok {
+		return 0, "", "", fmt.Errorf("файл миграции %s: ожидается имя вида 0001_name", file)
+	}
+	version, err = strconv.ParseInt(num, 10, 64)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("файл миграции %s: некорректный номер версии", file)
+	}
+	return version, name, direction, nil
+}
+
+// Latest возвращает версию последней известной миграции.
+func (m *Migrator) Latest() int64 {
+	if len(m.Migrations) == 0 {
+		return 0
+	}
+	return m.Migrations[len(m.Migrations)-1].Version
+}
+
+// Up применяет все ещё не применённые миграции по порядку.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.Migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			ok, err := apply(ctx, conn, mig.Version, false, mig.Up, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx,
+					`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, mig.Version, mig.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("миграция %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			if ok {
+				done = append(done, mig)
+			}
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down откатывает последнюю применённую миграцию. Если применённых миграций нет, возвращает nil.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	var reverted *Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		var version int64
+		err := conn.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+		if err != nil {
+			return err
+		}
+		if version == 0 {
+			return nil
+		}
+
+		mig, ok := m.find(version)
+		if !ok {
+			return fmt.Errorf("миграция %d применена, но отсутствует в бинарнике", version)
+		}
+		if mig.Down == "" {
+			return fmt.Errorf("миграция %d_%s: отсутствует down-скрипт", mig.Version, mig.Name)
+		}
+		done, err := apply(ctx, conn, mig.Version, true, mig.Down, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("откат миграции %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		if done {
+			reverted = &mig
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status возвращает состояние всех известных миграций.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.Migrations {
+			at, ok := applied[mig.Version]
+			statuses = append(statuses, Status{Migration: mig, Applied: ok, AppliedAt: at})
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Version возвращает текущую версию схемы в БД (0, если миграции не применялись).
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	var version int64
+	err := m.DB.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return version, err
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, mig := range m.Migrations {
+		if mig.Version == version {
+			return mig, true
+		}
+	}
+	return Migration{}, false
+}
+
+// withLock выполняет fn на выделенном соединении под advisory lock (в PostgreSQL).
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.Driver == "postgres" {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+			return fmt.Errorf("не удалось получить блокировку миграций: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+	}
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var at sql.NullTime
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at.Time
+	}
+	return applied, rows.Err()
+}
+
+// apply выполняет скрипт и запись в schema_migrations в одной транзакции.
+// Внутри транзакции перепроверяется, что версия ещё (для отката — уже) применена:
+// если её успел обработать другой экземпляр, apply ничего не делает и возвращает false.
+func apply(ctx context.Context, conn *sql.Conn, version int64, applied bool, script string, record func(tx *sql.Tx) error) (bool, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations WHERE version = $1`, version).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	if (count > 0) != applied {
+		return false, nil
+	}
+
+	if strings.TrimSpace(script) != "" {
+		if _, err := tx.ExecContext(ctx, script); err != nil {
+			return false, err
+		}
+	}
+	if err := record(tx); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
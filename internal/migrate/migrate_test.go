@@ -0,0 +1,103 @@
+package migrate_test
+
+import (
+	"banking-api/internal/config"
+	"banking-api/internal/migrate"
+	"banking-api/migrations"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func newSQLiteMigrator(t *testing.T, path string) *migrate.Migrator {
+	t.Helper()
+	_, dsn, err := config.ParseDBURL("sqlite:" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	fsys, err := migrations.For("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrate.New(db, "sqlite", fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestUpDownUp(t *testing.T) {
+	m := newSQLiteMigrator(t, filepath.Join(t.TempDir(), "banking.db"))
+	ctx := context.Background()
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if v, err := m.Version(ctx); err != nil || v != m.Latest() {
+		t.Fatalf("версия %d, %v; ожидалась %d", v, err, m.Latest())
+	}
+
+	for version := m.Latest(); version > 0; version-- {
+		if _, err := m.Down(ctx); err != nil {
+			t.Fatalf("Down с версии %d: %v", version, err)
+		}
+	}
+	if v, err := m.Version(ctx); err != nil || v != 0 {
+		t.Fatalf("после отката версия %d, %v", v, err)
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("повторный Up: %v", err)
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range statuses {
+		if !st.Applied {
+			t.Errorf("миграция %d не применена", st.Version)
+		}
+	}
+}
+
+// Несколько экземпляров, одновременно применяющих миграции, применяют каждую ровно один раз.
+func TestConcurrentUp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "banking.db")
+	ctx := context.Background()
+
+	const instances = 4
+	applied := make([]int, instances)
+	var wg sync.WaitGroup
+	for i := 0; i < instances; i++ {
+		m := newSQLiteMigrator(t, path)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done, err := m.Up(ctx)
+			if err != nil {
+				t.Errorf("Up: %v", err)
+			}
+			applied[i] = len(done)
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for _, n := range applied {
+		total += n
+	}
+	m := newSQLiteMigrator(t, path)
+	if total != len(m.Migrations) {
+		t.Errorf("применено миграций %d, ожидалось %d", total, len(m.Migrations))
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Уровни доступа к счёту, от меньшего к большему: каждый следующий включает
+// права предыдущих
+const (
+	AccessView     = "view"     // баланс, история, блокировки
+	AccessTransact = "transact" // пополнения, переводы, блокировки и возвраты
+	AccessManage   = "manage"   // настройки счёта и доступ других пользователей
+)
+
+// AccessLevels — уровни доступа по возрастанию.
+var AccessLevels = []string{AccessView, AccessTransact, AccessManage}
+
+// Статусы доступа к счёту
+const (
+	AccessPending = "pending" // приглашение ждёт принятия
+	AccessActive  = "active"
+)
+
+// ValidAccessLevel сообщает, что level — известный уровень доступа.
+func ValidAccessLevel(level string) bool {
+	return slices.Contains(AccessLevels, level)
+}
+
+// AccessAllows сообщает, что уровень have включает права уровня need.
+func AccessAllows(have, need string) bool {
+	return ValidAccessLevel(have) && slices.Index(AccessLevels, have) >= slices.Index(AccessLevels, need)
+}
+
+// AccessAtLeast возвращает уровни, которые включают права уровня level.
+func AccessAtLeast(level string) []string {
+	return AccessLevels[slices.Index(AccessLevels, level):]
+}
+
+// AccountAccess — доступ пользователя к счёту. У владельца счёта (Owner)
+// всегда доступ manage, совладельцы получают его по приглашению.
+type AccountAccess struct {
+	AccountID   int64      `json:"-"`
+	Account     string     `json:"account"`
+	UserID      int64      `json:"-"`
+	Username    string     `json:"username"`
+	Level       string     `json:"level"`
+	Status      string     `json:"status"`
+	Owner       bool       `json:"owner"`
+	InvitedByID int64      `json:"-"`
+	InvitedBy   string     `json:"invited_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+}
+
+type InviteAccessRequest struct {
+	Username string `json:"username"`
+	Level    string `json:"level"`
+}
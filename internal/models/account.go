@@ -2,14 +2,97 @@ package models
 
 import "time"
 
+// Типы счетов
+const (
+	AccountChecking = "checking" // расчётный
+	AccountSavings  = "savings"  // сберегательный: ограниченное число переводов в месяц и только на свои и совместные счета
+	AccountCredit   = "credit"   // кредитный: баланс может уходить в минус до CreditLimit
+	// AccountInternal — счёт банка без владельца (например, расход на проценты).
+	AccountInternal = "internal"
+)
+
+// Счета банка
+const (
+	SystemInterest = "interest" // источник выплаты процентов
+	SystemFees     = "fees"     // доход от комиссий
+	SystemLoans    = "loans"    // выдача и погашение кредитов
+)
+
+// Account — счёт. Внутренние ID счёта и владельца наружу не выдаются:
+// клиенты видят и указывают счёт по номеру Number (см. пакет accountno).
 type Account struct {
-	ID        int64     `json:"id"`
-	UserID    int64     `json:"user_id"`
-	Balance   float64   `json:"balance"`
-	CreatedAt time.Time `json:"created_at"`
+	ID      int64   `json:"-"`
+	Number  string  `json:"number"`
+	UserID  int64   `json:"-"`
+	Type    string  `json:"type"`
+	Balance float64 `json:"balance"`
+	// Held — сумма активных блокировок: она не списана, но недоступна для расходования.
+	Held float64 `json:"held"`
+	// CreditLimit — насколько баланс может уйти в минус.
+	CreditLimit float64 `json:"credit_limit"`
+	// MonthlyTransferLimit — лимит исходящих переводов в календарный месяц, 0 — без лимита.
+	MonthlyTransferLimit int `json:"monthly_transfer_limit"`
+	// Согласованный овердрафт: допустимый минус, годовая ставка (%) на
+	// отрицательный остаток и комиссия за уход в минус.
+	OverdraftLimit float64 `json:"overdraft_limit"`
+	OverdraftRate  float64 `json:"overdraft_rate"`
+	OverdraftFee   float64 `json:"overdraft_fee"`
+	// LowBalanceThreshold — порог уведомления о низком остатке, 0 — не уведомлять.
+	LowBalanceThreshold float64 `json:"low_balance_threshold"`
+	// ApprovalThreshold — переводы больше порога ждут одобрения второго
+	// пользователя, 0 — без одобрения.
+	ApprovalThreshold float64   `json:"approval_threshold"`
+	Frozen            bool      `json:"frozen"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// NeedsApproval сообщает, что списание amount со счёта ждёт одобрения.
+func (a *Account) NeedsApproval(amount float64) bool {
+	return a.ApprovalThreshold > 0 && amount > a.ApprovalThreshold
+}
+
+// Available возвращает сумму, которую можно списать со счёта: баланс с учётом
+// кредитного лимита и овердрафта за вычетом заблокированных средств.
+func (a *Account) Available() float64 {
+	return a.Balance + a.CreditLimit + a.OverdraftLimit - a.Held
+}
+
+// OverdraftFeeFor возвращает комиссию за списание amount: она берётся, когда
+// списание уводит неотрицательный баланс в минус по овердрафту.
+func (a *Account) OverdraftFeeFor(amount float64) float64 {
+	if a.OverdraftLimit > 0 && a.Balance >= 0 && a.Balance-amount < 0 {
+		return a.OverdraftFee
+	}
+	return 0
+}
+
+type CreateAccountRequest struct {
+	Type string `json:"type"`
+}
+
+// Overdraft — условия согласованного овердрафта, нулевой лимит отключает овердрафт.
+type Overdraft struct {
+	Limit float64 `json:"limit"`
+	Rate  float64 `json:"rate"`
+	Fee   float64 `json:"fee"`
+}
+
+// LowBalanceAlertRequest — порог уведомления о низком остатке, 0 отключает уведомление.
+type LowBalanceAlertRequest struct {
+	Threshold float64 `json:"threshold"`
+}
+
+// Balance — остатки счёта: ledger — учтённый баланс, held — заблокированная
+// сумма, available — сколько можно списать сейчас.
+type Balance struct {
+	AccountID int64   `json:"-"`
+	Account   string  `json:"account"`
+	Ledger    float64 `json:"ledger"`
+	Held      float64 `json:"held"`
+	Available float64 `json:"available"`
 }
 
 type TopUpRequest struct {
-	AccountID int64   `json:"account_id"`
-	Amount    float64 `json:"amount"`
+	Account string  `json:"account"`
+	Amount  float64 `json:"amount"`
 }
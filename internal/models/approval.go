@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Статусы перевода, ждущего одобрения
+const (
+	ApprovalPending  = "pending"  // ждёт решения одобряющего
+	ApprovalApproved = "approved" // одобрен и выполнен переводом TransactionID
+	ApprovalRejected = "rejected" // отклонён одобряющим
+	ApprovalExpired  = "expired"  // не рассмотрен до ExpiresAt
+)
+
+// TransferApproval — перевод со счёта с порогом одобрения (maker-checker):
+// Maker создал перевод выше порога, и он выполняется, только когда его
+// одобрит другой пользователь из одобряющих счёта (Checker).
+type TransferApproval struct {
+	ID            int64   `json:"id"`
+	FromAccountID int64   `json:"-"`
+	ToAccountID   int64   `json:"-"`
+	FromAccount   string  `json:"from_account"`
+	ToAccount     string  `json:"to_account"`
+	Amount        float64 `json:"amount"`
+	MakerID       int64   `json:"-"`
+	CheckerID     int64   `json:"-"`
+	Maker         string  `json:"maker"`
+	Checker       string  `json:"checker,omitempty"`
+	Status        string  `json:"status"`
+	// Reason — причина отклонения.
+	Reason         string `json:"reason,omitempty"`
+	IdempotencyKey string `json:"-"`
+	// TransactionID — перевод, выполненный после одобрения.
+	TransactionID int64      `json:"transaction_id,omitempty"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+}
+
+type RejectApprovalRequest struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Действия администраторов в журнале аудита
+const (
+	AuditFreeze    = "freeze"
+	AuditUnfreeze  = "unfreeze"
+	AuditAdjust    = "adjust"
+	AuditSetRate   = "set_rate"
+	AuditOverdraft = "overdraft"
+	AuditLoan      = "loan"
+	AuditReversal  = "reversal"
+	AuditApprovals = "approvals"
+)
+
+// AuditEntry — запись журнала действий администраторов.
+type AuditEntry struct {
+	ID        int64  `json:"id"`
+	Actor     string `json:"actor"`
+	Action    string `json:"action"`
+	AccountID int64  `json:"-"`
+	// Account — номер счёта, пуст для действий без счёта.
+	Account   string    `json:"account,omitempty"`
+	Amount    float64   `json:"amount"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Reconciliation — результат сверки данных. ExternalNet — деньги, пришедшие
+// в банк движениями без второго счёта (пополнения и корректировки), за
+// вычетом ушедших: при сохранении денег сумма балансов всех счетов, включая
+// служебные, равна ему.
+type Reconciliation struct {
+	CheckedAt    time.Time `json:"checked_at"`
+	Accounts     int       `json:"accounts"`
+	TotalBalance float64   `json:"total_balance"`
+	ExternalNet  float64   `json:"external_net"`
+	Transactions int       `json:"transactions"`
+	// Нарушения инвариантов. NegativeBalances — счета, ушедшие в минус
+	// глубже кредитного лимита; BalanceMismatches — счета, баланс которых
+	// расходится с суммой движений; Imbalance — TotalBalance − ExternalNet.
+	NegativeBalances    []string          `json:"negative_balances"`
+	InvalidTransactions []int64           `json:"invalid_transactions"`
+	BalanceMismatches   []BalanceMismatch `json:"balance_mismatches"`
+	Imbalance           float64           `json:"imbalance"`
+}
+
+// BalanceMismatch — расхождение баланса счёта с историей: Expected — сумма
+// зачислений за вычетом списаний, Difference = Balance − Expected.
+type BalanceMismatch struct {
+	AccountID  int64   `json:"-"`
+	Account    string  `json:"account"`
+	Balance    float64 `json:"balance"`
+	Expected   float64 `json:"expected"`
+	Difference float64 `json:"difference"`
+}
+
+// OK сообщает, что нарушений не найдено.
+func (r *Reconciliation) OK() bool {
+	return len(r.NegativeBalances) == 0 && len(r.InvalidTransactions) == 0 &&
+		len(r.BalanceMismatches) == 0 && r.Imbalance == 0
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Статусы пакета переводов
+const (
+	BatchPending   = "pending"   // ждёт выполнения
+	BatchCompleted = "completed" // все строки обработаны, часть могла не пройти
+	BatchFailed    = "failed"    // all_or_nothing: строка не прошла, пакет не выполнен
+)
+
+// Статусы строки пакета
+const (
+	BatchItemPending   = "pending"   // ещё не выполнена
+	BatchItemSucceeded = "succeeded" // переведена переводом TransactionID
+	BatchItemFailed    = "failed"    // перевод не прошёл, причина в Error
+	BatchItemSkipped   = "skipped"   // all_or_nothing: не выполнена из-за ошибки в другой строке
+)
+
+// Batch — пакет переводов со счёта FromAccount пользователя UserID.
+// Total, Amount, Succeeded и Failed — сводка по строкам; Items заполняются
+// только при получении одного пакета. MessageID и PaymentInfoID заданы у
+// пакетов, загруженных из ISO 20022 pain.001.
+type Batch struct {
+	ID            int64       `json:"id"`
+	UserID        int64       `json:"-"`
+	FromAccountID int64       `json:"-"`
+	FromAccount   string      `json:"from_account"`
+	AllOrNothing  bool        `json:"all_or_nothing"`
+	MessageID     string      `json:"message_id,omitempty"`
+	PaymentInfoID string      `json:"payment_info_id,omitempty"`
+	Status        string      `json:"status"`
+	Total         int         `json:"total"`
+	Amount        float64     `json:"amount"`
+	Succeeded     int         `json:"succeeded"`
+	Failed        int         `json:"failed"`
+	CreatedAt     time.Time   `json:"created_at"`
+	CompletedAt   *time.Time  `json:"completed_at,omitempty"`
+	Items         []BatchItem `json:"items,omitempty"`
+}
+
+// Tally пересчитывает сводку пакета по Items.
+func (b *Batch) Tally() {
+	b.Total, b.Amount, b.Succeeded, b.Failed = len(b.Items), 0, 0, 0
+	for _, item := range b.Items {
+		b.Amount += item.Amount
+		switch item.Status {
+		case BatchItemSucceeded:
+			b.Succeeded++
+		case BatchItemFailed, BatchItemSkipped:
+			b.Failed++
+		}
+	}
+	b.Amount = math.Round(b.Amount*100) / 100
+}
+
+// BatchItem — строка пакета с результатом перевода. ToUsername сохраняется,
+// если получатель указан по username.
+type BatchItem struct {
+	Line          int     `json:"line"`
+	ToUsername    string  `json:"to_username,omitempty"`
+	ToAccountID   int64   `json:"-"`
+	ToAccount     string  `json:"to_account"`
+	Amount        float64 `json:"amount"`
+	Reference     string  `json:"reference,omitempty"`
+	EndToEndID    string  `json:"end_to_end_id,omitempty"`
+	Status        string  `json:"status"`
+	Error         string  `json:"error,omitempty"`
+	TransactionID int64   `json:"transaction_id,omitempty"`
+}
+
+// BatchLine — строка загружаемого пакета: получатель по username или по
+// номеру счёта (ровно одно из двух), сумма и назначение платежа. EndToEndID —
+// идентификатор платежа клиента, он возвращается в отчёте и выписке.
+type BatchLine struct {
+	ToUsername string  `json:"to_username,omitempty"`
+	ToAccount  string  `json:"to_account,omitempty"`
+	Amount     float64 `json:"amount"`
+	Reference  string  `json:"reference,omitempty"`
+	EndToEndID string  `json:"end_to_end_id,omitempty"`
+}
+
+// BatchRequest — пакет из платежа pain.001: счёт списания по номеру, режим
+// и строки; MessageID и PaymentInfoID сохраняются в пакете.
+type BatchRequest struct {
+	FromAccount   string
+	AllOrNothing  bool
+	MessageID     string
+	PaymentInfoID string
+	Lines         []BatchLine
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Статусы блокировки
+const (
+	HoldActive   = "active"   // средства зарезервированы
+	HoldCaptured = "captured" // списаны получателю полностью или частично
+	HoldReleased = "released" // сняты получателем без списания
+	HoldExpired  = "expired"  // истёк срок блокировки
+)
+
+// Hold — блокировка (первая фаза двухфазного платежа): Amount зарезервирован
+// на счёте AccountID в пользу счёта ToAccountID. Деньги не двигаются, пока
+// получатель не спишет их (CapturedAmount переводом TransactionID, остаток
+// освобождается) или не снимет блокировку.
+type Hold struct {
+	ID             int64   `json:"id"`
+	AccountID      int64   `json:"-"`
+	ToAccountID    int64   `json:"-"`
+	Account        string  `json:"account"`
+	ToAccount      string  `json:"to_account"`
+	Amount         float64 `json:"amount"`
+	CapturedAmount float64 `json:"captured_amount"`
+	Status         string  `json:"status"`
+	// TransactionID — перевод, которым списана блокировка, 0 — не списана.
+	TransactionID int64      `json:"transaction_id,omitempty"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+}
+
+type CreateHoldRequest struct {
+	Account   string  `json:"account"`
+	ToAccount string  `json:"to_account"`
+	Amount    float64 `json:"amount"`
+}
+
+// CaptureHoldRequest — сумма списания, 0 — вся заблокированная сумма.
+type CaptureHoldRequest struct {
+	Amount float64 `json:"amount"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// InterestRate — годовая ставка в процентах для счетов типа AccountType,
+// действующая с EffectiveFrom до следующей ставки того же типа.
+type InterestRate struct {
+	AccountType   string    `json:"account_type"`
+	AnnualRate    float64   `json:"annual_rate"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+// InterestAccrual — начисление процентов за один день на остаток на конец дня.
+type InterestAccrual struct {
+	AccountID  int64     `json:"-"`
+	Account    string    `json:"account"`
+	Day        time.Time `json:"day"`
+	Balance    float64   `json:"balance"`
+	AnnualRate float64   `json:"annual_rate"`
+	// Amount не округляется до копеек: округляется только сумма за месяц при капитализации.
+	Amount      float64 `json:"amount"`
+	Capitalized bool    `json:"capitalized"`
+}
+
+// Capitalization — итог капитализации процентов за месяц.
+type Capitalization struct {
+	Month    time.Time `json:"month"`
+	Accounts int       `json:"accounts"`
+	// Total — выплаты клиентам за вычетом списанных процентов по овердрафту.
+	Total float64 `json:"total"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Способы погашения кредита
+const (
+	LoanAnnuity = "annuity" // аннуитетный: равные ежемесячные платежи
+	LoanLinear  = "linear"  // дифференцированный: равные доли основного долга и проценты на остаток
+)
+
+// Статусы кредита
+const (
+	LoanActive = "active"
+	LoanClosed = "closed" // долг погашен полностью
+)
+
+// Статусы платежа по графику
+const (
+	InstallmentPending = "pending"
+	InstallmentPaid    = "paid"
+	InstallmentOverdue = "overdue" // не оплачен в срок, начислены пени
+)
+
+// LoanTerms — условия выдачи кредита.
+type LoanTerms struct {
+	Principal float64 `json:"principal"`
+	// AnnualRate — годовая ставка в процентах.
+	AnnualRate float64 `json:"annual_rate"`
+	TermMonths int     `json:"term_months"`
+	Method     string  `json:"method"`
+	// LateFee — пени за каждый просроченный платёж.
+	LateFee float64 `json:"late_fee"`
+}
+
+// Loan — кредит, выданный на счёт Account и погашаемый с него же.
+type Loan struct {
+	ID        int64  `json:"id"`
+	UserID    int64  `json:"user_id"`
+	AccountID int64  `json:"-"`
+	Account   string `json:"account"`
+	LoanTerms
+	// StartDate — день выдачи: платёж N приходится на тот же день через N месяцев.
+	StartDate time.Time `json:"start_date"`
+	// Outstanding — остаток основного долга.
+	Outstanding float64 `json:"outstanding"`
+	// Arrears — просроченная задолженность: неоплаченная часть просроченных платежей вместе с пенями.
+	Arrears   float64   `json:"arrears"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	// Schedule — график платежей; заполняется только для одного кредита.
+	Schedule []LoanInstallment `json:"schedule,omitempty"`
+}
+
+// LoanInstallment — платёж по графику.
+type LoanInstallment struct {
+	Number    int       `json:"number"`
+	DueDate   time.Time `json:"due_date"`
+	Principal float64   `json:"principal"`
+	Interest  float64   `json:"interest"`
+	LateFee   float64   `json:"late_fee"`
+	// Оплачено: основной долг и отдельно проценты с пенями.
+	PaidPrincipal float64 `json:"paid_principal"`
+	PaidInterest  float64 `json:"paid_interest"`
+	Status        string  `json:"status"`
+}
+
+// Due возвращает неоплаченную часть платежа.
+func (i *LoanInstallment) Due() float64 {
+	return math.Round((i.Principal+i.Interest+i.LateFee-i.PaidPrincipal-i.PaidInterest)*100) / 100
+}
+
+// LoanRepayment — итог досрочного погашения.
+type LoanRepayment struct {
+	LoanID int64   `json:"loan_id"`
+	Amount float64 `json:"amount"`
+	// Scheduled — часть суммы, погасившая наступившие платежи, остальное ушло в основной долг.
+	Scheduled   float64 `json:"scheduled"`
+	Principal   float64 `json:"principal"`
+	Outstanding float64 `json:"outstanding"`
+	Status      string  `json:"status"`
+}
+
+// LoanCollection — итог автоматического списания платежей за день.
+type LoanCollection struct {
+	Day   time.Time `json:"day"`
+	Loans int       `json:"loans"`
+	// Amount — списано со счетов заёмщиков.
+	Amount float64 `json:"amount"`
+	// Overdue — платежи, впервые ставшие просроченными.
+	Overdue int `json:"overdue"`
+	Closed  int `json:"closed"`
+}
+
+// RepayLoanRequest — сумма досрочного погашения.
+type RepayLoanRequest struct {
+	Amount float64 `json:"amount"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Payee — получатель из адресной книги пользователя: другой пользователь
+// (Username, перевод на его первый счёт) или конкретный счёт (Account).
+// До CoolingOffUntil крупные переводы новому получателю не выполняются.
+type Payee struct {
+	ID        int64  `json:"id"`
+	UserID    int64  `json:"-"`
+	Nickname  string `json:"nickname"`
+	ToUserID  int64  `json:"-"`
+	Username  string `json:"username,omitempty"`
+	AccountID int64  `json:"-"`
+	Account   string `json:"account,omitempty"`
+	// Reference — назначение перевода по умолчанию.
+	Reference       string    `json:"reference,omitempty"`
+	CoolingOffUntil time.Time `json:"cooling_off_until"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// PayeeRequest — получатель для добавления или изменения: указывается
+// username или номер счёта account.
+type PayeeRequest struct {
+	Nickname  string `json:"nickname"`
+	Username  string `json:"username"`
+	Account   string `json:"account"`
+	Reference string `json:"reference"`
+}
+
+// PayeeTransferRequest — перевод получателю из адресной книги. Пустой
+// reference заменяется назначением получателя.
+type PayeeTransferRequest struct {
+	FromAccount string  `json:"from_account"`
+	Amount      float64 `json:"amount"`
+	Reference   string  `json:"reference"`
+}
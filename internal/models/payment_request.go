@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Статусы запроса денег
+const (
+	PaymentRequestPending   = "pending"   // ждёт ответа плательщика
+	PaymentRequestAccepted  = "accepted"  // оплачен переводом
+	PaymentRequestDeclined  = "declined"  // отклонён плательщиком
+	PaymentRequestCancelled = "cancelled" // отменён запросившим
+	PaymentRequestExpired   = "expired"   // истёк срок ответа
+)
+
+// PaymentRequest — запрос денег: Requester просит Payer перевести Amount на
+// свой счёт ToAccount. Принятый запрос исполняется переводом TransactionID.
+type PaymentRequest struct {
+	ID          int64   `json:"id"`
+	RequesterID int64   `json:"-"`
+	PayerID     int64   `json:"-"`
+	Requester   string  `json:"requester"`
+	Payer       string  `json:"payer"`
+	ToAccountID int64   `json:"-"`
+	ToAccount   string  `json:"to_account"`
+	Amount      float64 `json:"amount"`
+	Note        string  `json:"note"`
+	Status      string  `json:"status"`
+	// TransactionID — перевод, которым оплачен запрос, 0 — запрос не оплачен.
+	TransactionID int64      `json:"transaction_id,omitempty"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+}
+
+type CreatePaymentRequestRequest struct {
+	Payer     string  `json:"payer"`
+	ToAccount string  `json:"to_account"`
+	Amount    float64 `json:"amount"`
+	Note      string  `json:"note"`
+}
+
+type AcceptPaymentRequestRequest struct {
+	FromAccount string `json:"from_account"`
+}
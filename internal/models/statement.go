@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Statement — выписка по счёту за период [From, To): остатки на начало и
+// конец периода и движения по счёту, начиная с ранних.
+type Statement struct {
+	AccountID      int64            `json:"-"`
+	Account        string           `json:"account"`
+	Owner          string           `json:"owner"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	OpeningBalance float64          `json:"opening_balance"`
+	ClosingBalance float64          `json:"closing_balance"`
+	Entries        []StatementEntry `json:"entries"`
+}
+
+// StatementEntry — движение выписки. Reference и EndToEndID заполнены у
+// переводов из пакетов.
+type StatementEntry struct {
+	Transaction
+	Reference  string `json:"reference,omitempty"`
+	EndToEndID string `json:"end_to_end_id,omitempty"`
+}
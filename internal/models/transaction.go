@@ -2,21 +2,64 @@ package models
 
 import "time"
 
+// Виды движений по счетам
+const (
+	KindTransfer   = "transfer"   // перевод между счетами
+	KindTopUp      = "topup"      // пополнение счёта клиентом, без счёта-отправителя
+	KindAdjustment = "adjustment" // ручная корректировка баланса администратором
+	KindInterest   = "interest"   // капитализация процентов: выплата со счёта банка или списание процентов по овердрафту
+	KindFee        = "fee"        // комиссия на счёт банка
+	// Кредиты: выдача со счёта банка и погашение на него
+	KindLoanDisbursement = "loan_disbursement"
+	KindLoanRepayment    = "loan_repayment"
+	// Обратные движения по исходному переводу: возврат получателем и сторно администратором
+	KindRefund   = "refund"
+	KindReversal = "reversal"
+)
+
+// TransactionKinds — все допустимые виды движений.
+var TransactionKinds = []string{KindTransfer, KindTopUp, KindAdjustment, KindInterest, KindFee, KindLoanDisbursement, KindLoanRepayment, KindRefund, KindReversal}
+
 type Transaction struct {
-	ID            int64     `json:"id"`
-	FromAccountID int64     `json:"from_account_id"`
-	ToAccountID   int64     `json:"to_account_id"`
-	Amount        float64   `json:"amount"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID            int64  `json:"id"`
+	Kind          string `json:"kind"`
+	FromAccountID int64  `json:"-"`
+	ToAccountID   int64  `json:"-"`
+	// FromAccount и ToAccount — номера счетов; пусты у пополнений и корректировок без второй стороны.
+	FromAccount string    `json:"from_account,omitempty"`
+	ToAccount   string    `json:"to_account,omitempty"`
+	Amount      float64   `json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+	// OriginalTransactionID — исходное движение возврата или сторно.
+	OriginalTransactionID int64 `json:"original_transaction_id,omitempty"`
+	// ReasonCode — код причины сторно (ReversalReasons).
+	ReasonCode string `json:"reason_code,omitempty"`
+}
+
+// Коды причин сторно
+const (
+	ReversalDuplicate = "duplicate" // повторное списание
+	ReversalFraud     = "fraud"     // мошенническая операция
+	ReversalError     = "error"     // ошибка банка
+	ReversalDispute   = "dispute"   // решение по спору клиента
+)
+
+// ReversalReasons — все допустимые коды причин сторно.
+var ReversalReasons = []string{ReversalDuplicate, ReversalFraud, ReversalError, ReversalDispute}
+
+// RefundRequest — сумма возврата, 0 — весь ещё не возвращённый остаток перевода.
+type RefundRequest struct {
+	Amount float64 `json:"amount"`
 }
 
 type TransferRequest struct {
-	FromAccountID int64   `json:"from_account_id"`
-	ToAccountID   int64   `json:"to_account_id"`
-	Amount        float64 `json:"amount"`
+	FromAccount string  `json:"from_account"`
+	ToAccount   string  `json:"to_account"`
+	Amount      float64 `json:"amount"`
 }
 
 type TransferByUsernamesRequest struct {
+	// FromUsername необязателен: отправитель — пользователь токена.
 	FromUsername string  `json:"from_username"`
 	ToUsername   string  `json:"to_username"`
 	Amount       float64 `json:"amount"`
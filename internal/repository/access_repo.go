@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"banking-api/internal/models"
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// SQLAccessRepository — реализация AccessRepository поверх PostgreSQL или SQLite.
+type SQLAccessRepository struct {
+	DB      *sql.DB
+	Dialect Dialect
+}
+
+func NewSQLAccessRepository(db *sql.DB, dialect Dialect) *SQLAccessRepository {
+	return &SQLAccessRepository{DB: db, Dialect: dialect}
+}
+
+// hasAccess возвращает условие SQL: у пользователя param есть активный доступ
+// не ниже level к счёту из столбца accountColumn.
+func hasAccess(accountColumn, param, level string) string {
+	return `EXISTS (SELECT 1 FROM account_access acl WHERE acl.account_id = ` + accountColumn +
+		` AND acl.user_id = ` + param + ` AND acl.status = 'active' AND acl.level IN ('` +
+		strings.Join(models.AccessAtLeast(level), `', '`) + `'))`
+}
+
+// accessSelect выбирает доступы с именами пользователей в порядке, который
+// ожидает scanAccess.
+const accessSelect = `
+	SELECT g.account_id, COALESCE(a.number, ''), g.user_id, u.username, g.level, g.status, a.user_id = g.user_id,
+		COALESCE(g.invited_by, 0), COALESCE(i.username, ''), g.created_at, g.accepted_at
+	FROM account_access g
+	JOIN users u ON u.id = g.user_id
+	JOIN accounts a ON a.id = g.account_id
+	LEFT JOIN users i ON i.id = g.invited_by`
+
+func scanAccess(row interface{ Scan(...any) error }, a *models.AccountAccess) error {
+	var accepted sql.NullTime
+	err := row.Scan(&a.AccountID, &a.Account, &a.UserID, &a.Username, &a.Level, &a.Status, &a.Owner,
+		&a.InvitedByID, &a.InvitedBy, &a.CreatedAt, &accepted)
+	if err != nil {
+		return err
+	}
+	a.AcceptedAt = nil
+	if accepted.Valid {
+		a.AcceptedAt = &accepted.Time
+	}
+	return nil
+}
+
+func (r *SQLAccessRepository) InviteAccess(ctx context.Context, access *models.AccountAccess) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRowContext(ctx,
+		`SELECT 1 FROM accounts WHERE id = $1 AND `+hasAccess("accounts.id", "$2", models.AccessManage)+r.Dialect.forUpdate(),
+		access.AccountID, access.InvitedByID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO account_access (account_id, user_id, level, status, invited_by)
+		VALUES ($1, $2, $3, $4, $5)`,
+		access.AccountID, access.UserID, access.Level, models.AccessPending, access.InvitedByID)
+	if err != nil {
+		return r.Dialect.mapError(err)
+	}
+	err = scanAccess(tx.QueryRowContext(ctx, accessSelect+`
+		WHERE g.account_id = $1 AND g.user_id = $2`, access.AccountID, access.UserID), access)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLAccessRepository) ListAccess(ctx context.Context, accountID, userID int64) ([]models.AccountAccess, error) {
+	var exists int
+	err := r.DB.QueryRowContext(ctx,
+		`SELECT 1 FROM accounts WHERE id = $1 AND `+hasAccess("accounts.id", "$2", models.AccessView), accountID, userID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	return r.list(ctx, accessSelect+`
+		WHERE g.account_id = $1
+		ORDER BY CASE WHEN a.user_id = g.user_id THEN 0 ELSE 1 END, g.user_id`, accountID)
+}
+
+func (r *SQLAccessRepository) ListInvitations(ctx context.Context, userID int64) ([]models.AccountAccess, error) {
+	return r.list(ctx, accessSelect+`
+		WHERE g.user_id = $1 AND g.status = $2
+		ORDER BY g.account_id`, userID, models.AccessPending)
+}
+
+func (r *SQLAccessRepository) list(ctx context.Context, query string, args ...any) ([]models.AccountAccess, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	access := []models.AccountAccess{}
+	for rows.Next() {
+		var a models.AccountAccess
+		if err := scanAccess(rows, &a); err != nil {
+			return nil, err
+		}
+		access = append(access, a)
+	}
+	return access, rows.Err()
+}
+
+func (r *SQLAccessRepository) AcceptAccess(ctx context.Context, accountID, userID int64, now time.Time) (*models.AccountAccess, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE account_access SET status = $1, accepted_at = $2
+		WHERE account_id = $3 AND user_id = $4 AND status = $5`,
+		models.AccessActive, now.UTC(), accountID, userID, models.AccessPending)
+	if err != nil {
+		return nil, err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return nil, sql.ErrNoRows
+	}
+	var access models.AccountAccess
+	err = scanAccess(tx.QueryRowContext(ctx, accessSelect+`
+		WHERE g.account_id = $1 AND g.user_id = $2`, accountID, userID), &access)
+	if err != nil {
+		return nil, err
+	}
+	return &access, tx.Commit()
+}
+
+func (r *SQLAccessRepository) RevokeAccess(ctx context.Context, accountID, userID, targetID int64) (*models.AccountAccess, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Отозвать доступ может пользователь с доступом manage, отказаться — сам пользователь
+	var access models.AccountAccess
+	err = scanAccess(tx.QueryRowContext(ctx, accessSelect+`
+		WHERE g.account_id = $1 AND g.user_id = $2 AND (g.user_id = $3 OR `+hasAccess("g.account_id", "$3", models.AccessManage)+`)`,
+		accountID, targetID, userID), &access)
+	if err != nil {
+		return nil, err
+	}
+	if access.Owner {
+		return nil, ErrOwnerAccess
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM account_access WHERE account_id = $1 AND user_id = $2`, accountID, targetID); err != nil {
+		return nil, err
+	}
+	return &access, tx.Commit()
+}
@@ -1,93 +1,420 @@
 package repository
 
 import (
+	"banking-api/internal/accountno"
 	"banking-api/internal/models"
+	"context"
 	"database/sql"
 	"errors"
+	"math"
+	"time"
 )
 
-type AccountRepository struct {
-	DB *sql.DB
+// SQLAccountRepository — реализация AccountRepository поверх PostgreSQL или SQLite.
+type SQLAccountRepository struct {
+	DB      *sql.DB
+	Dialect Dialect
 }
 
-func NewAccountRepository(db *sql.DB) *AccountRepository {
-	return &AccountRepository{DB: db}
+func NewSQLAccountRepository(db *sql.DB, dialect Dialect) *SQLAccountRepository {
+	return &SQLAccountRepository{DB: db, Dialect: dialect}
 }
 
-func (r *AccountRepository) CreateAccount(userID int64) (*models.Account, error) {
-	query := `INSERT INTO accounts (user_id, balance) VALUES ($1, 0) RETURNING id, created_at`
-	var account models.Account
-	err := r.DB.QueryRow(query, userID).Scan(&account.ID, &account.CreatedAt)
+// accountColumns — столбцы accounts в порядке, который ожидает scanAccount.
+const accountColumns = `id, COALESCE(number, ''), COALESCE(user_id, 0), type, balance, held, credit_limit, monthly_transfer_limit,
+	overdraft_limit, overdraft_rate, overdraft_fee, low_balance_threshold, approval_threshold, frozen, created_at`
+
+func scanAccount(row interface{ Scan(...any) error }, a *models.Account) error {
+	return row.Scan(&a.ID, &a.Number, &a.UserID, &a.Type, &a.Balance, &a.Held, &a.CreditLimit, &a.MonthlyTransferLimit,
+		&a.OverdraftLimit, &a.OverdraftRate, &a.OverdraftFee, &a.LowBalanceThreshold, &a.ApprovalThreshold, &a.Frozen, &a.CreatedAt)
+}
+
+// accountNumber возвращает SQL-выражение с номером счёта из столбца
+// accountColumn; для NULL — пустую строку.
+func accountNumber(accountColumn string) string {
+	return `COALESCE((SELECT n.number FROM accounts n WHERE n.id = ` + accountColumn + `), '')`
+}
+
+// transactionSelect выбирает движения с номерами счетов в порядке, который
+// ожидает scanTransaction.
+var transactionSelect = `
+	SELECT t.id, t.kind, COALESCE(t.from_account_id, 0), COALESCE(t.to_account_id, 0),
+		` + accountNumber("t.from_account_id") + `, ` + accountNumber("t.to_account_id") + `,
+		t.amount, t.created_at, COALESCE(t.original_transaction_id, 0), t.reason_code
+	FROM transactions t`
+
+func scanTransaction(row interface{ Scan(...any) error }, t *models.Transaction) error {
+	return row.Scan(&t.ID, &t.Kind, &t.FromAccountID, &t.ToAccountID, &t.FromAccount, &t.ToAccount,
+		&t.Amount, &t.CreatedAt, &t.OriginalTransactionID, &t.ReasonCode)
+}
+
+// CreateAccount создаёт счёт с номером account.Number, а без номера —
+// с новым номером по схеме accountno.Default.
+func (r *SQLAccountRepository) CreateAccount(ctx context.Context, account *models.Account) error {
+	if account.Number == "" {
+		account.Number = accountno.New()
+	}
+	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO accounts (number, user_id, type, balance, credit_limit, monthly_transfer_limit)
+		VALUES ($1, $2, $3, 0, $4, $5)
+		RETURNING id, created_at`
+	if account.Type == "" {
+		account.Type = models.AccountChecking
+	}
+	err = tx.QueryRowContext(ctx, query, account.Number, account.UserID, account.Type, account.CreditLimit, account.MonthlyTransferLimit).
+		Scan(&account.ID, &account.CreatedAt)
+	if err != nil {
+		return r.Dialect.mapError(err)
+	}
+	// Владелец получает полный доступ к счёту
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO account_access (account_id, user_id, level, status, created_at, accepted_at)
+		VALUES ($1, $2, $3, $4, $5, $5)`,
+		account.ID, account.UserID, models.AccessManage, models.AccessActive, account.CreatedAt)
+	if err != nil {
+		return err
 	}
-	account.UserID = userID
 	account.Balance = 0
-	return &account, nil
+	return tx.Commit()
 }
 
-func (r *AccountRepository) TopUpAccount(accountID, userID int64, amount float64) error {
-	query := `UPDATE accounts SET balance = balance + $1 WHERE id = $2 AND user_id = $3`
-	res, err := r.DB.Exec(query, amount, accountID, userID)
+func (r *SQLAccountRepository) TopUpAccount(ctx context.Context, accountID, userID int64, amount float64) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE accounts SET balance = ROUND(balance + $1, 2)
+		WHERE id = $2 AND ` + hasAccess("accounts.id", "$3", models.AccessTransact) + ` AND NOT frozen`
+	res, err := tx.ExecContext(ctx, query, amount, accountID, userID)
 	if err != nil {
 		return err
 	}
 	rows, _ := res.RowsAffected()
 	if rows == 0 {
-		return sql.ErrNoRows
+		// Счёта нет, к нему нет доступа или он заморожен
+		var frozen bool
+		err := tx.QueryRowContext(ctx,
+			`SELECT frozen FROM accounts WHERE id = $1 AND `+hasAccess("accounts.id", "$2", models.AccessTransact), accountID, userID).Scan(&frozen)
+		if err != nil {
+			return err
+		}
+		return ErrAccountFrozen
 	}
-	return nil
+
+	// Пополнение записывается как движение без счёта-отправителя
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO transactions (kind, to_account_id, amount)
+		VALUES ($1, $2, $3)`,
+		models.KindTopUp, accountID, amount)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *AccountRepository) TransferFunds(fromID, toID, userID int64, amount float64) error {
-	tx, err := r.DB.Begin()
+func (r *SQLAccountRepository) TransferFunds(ctx context.Context, fromID, toID, userID int64, amount float64, idempotencyKey string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	// Проверка владения и баланса
-	var balance float64
-	err = tx.QueryRow(`SELECT balance FROM accounts WHERE id = $1 AND user_id = $2`, fromID, userID).Scan(&balance)
-	if err != nil {
+	if _, err := transfer(ctx, tx, r.Dialect, fromID, toID, userID, amount, idempotencyKey); err != nil {
 		return err
 	}
-	if balance < amount {
-		return errors.New("недостаточно средств")
+	return tx.Commit()
+}
+
+// transfer выполняет перевод по правилам TransferFunds внутри транзакции tx
+// и возвращает ID записанного движения.
+func transfer(ctx context.Context, tx *sql.Tx, dialect Dialect, fromID, toID, userID int64, amount float64, idempotencyKey string) (int64, error) {
+	return moveFunds(ctx, tx, dialect, fromID, toID, userID, amount, idempotencyKey, false, false)
+}
+
+// approvedTransfer выполняет одобренный перевод: как transfer, но без
+// проверки порога одобрения.
+func approvedTransfer(ctx context.Context, tx *sql.Tx, dialect Dialect, fromID, toID, userID int64, amount float64, idempotencyKey string) (int64, error) {
+	return moveFunds(ctx, tx, dialect, fromID, toID, userID, amount, idempotencyKey, true, false)
+}
+
+// heldTransfer списывает блокировку: как transfer, но получатель со
+// сберегательного счёта проверен при создании блокировки.
+func heldTransfer(ctx context.Context, tx *sql.Tx, dialect Dialect, fromID, toID, userID int64, amount float64) (int64, error) {
+	return moveFunds(ctx, tx, dialect, fromID, toID, userID, amount, "", false, true)
+}
+
+func moveFunds(ctx context.Context, tx *sql.Tx, dialect Dialect, fromID, toID, userID int64, amount float64, idempotencyKey string, approved, held bool) (int64, error) {
+	// Проверка доступа и баланса
+	var from models.Account
+	err := scanAccount(tx.QueryRowContext(ctx, `SELECT `+accountColumns+` FROM accounts
+		WHERE id = $1 AND `+hasAccess("accounts.id", "$2", models.AccessTransact)+dialect.forUpdate(), fromID, userID), &from)
+	if err != nil {
+		return 0, err
+	}
+
+	// Повтор по ключу идемпотентности. Строка счёта уже заблокирована,
+	// поэтому параллельные повторы с тем же ключом ждут здесь.
+	var key sql.NullString
+	if idempotencyKey != "" {
+		key = sql.NullString{String: idempotencyKey, Valid: true}
+		var prevTo int64
+		var prevAmount float64
+		err = tx.QueryRowContext(ctx, `
+			SELECT COALESCE(to_account_id, 0), amount FROM transactions
+			WHERE from_account_id = $1 AND idempotency_key = $2`,
+			fromID, idempotencyKey).Scan(&prevTo, &prevAmount)
+		switch {
+		case err == nil:
+			return 0, replayResult(prevTo, prevAmount, toID, amount)
+		case !errors.Is(err, sql.ErrNoRows):
+			return 0, err
+		}
+	}
+	if !approved && from.NeedsApproval(amount) {
+		return 0, ErrApprovalRequired
+	}
+
+	var toFrozen bool
+	// Счета банка недоступны для переводов
+	err = tx.QueryRowContext(ctx, `SELECT frozen FROM accounts WHERE id = $1 AND type <> 'internal'`, toID).Scan(&toFrozen)
+	if err != nil {
+		return 0, err
+	}
+	if from.Frozen || toFrozen {
+		return 0, ErrAccountFrozen
+	}
+	if from.Type == models.AccountSavings && !held {
+		if err := checkSavingsDestination(ctx, tx, toID, userID); err != nil {
+			return 0, err
+		}
+	}
+	if from.MonthlyTransferLimit > 0 {
+		var count int
+		err = tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM transactions
+			WHERE from_account_id = $1 AND kind = 'transfer' AND created_at >= $2`,
+			fromID, MonthStart(time.Now())).Scan(&count)
+		if err != nil {
+			return 0, err
+		}
+		if count >= from.MonthlyTransferLimit {
+			return 0, ErrTransferLimitExceeded
+		}
+	}
+	fee := from.OverdraftFeeFor(amount)
+	if from.Available() < amount+fee {
+		return 0, ErrInsufficientFunds
 	}
 
 	// Списание
-	_, err = tx.Exec(`UPDATE accounts SET balance = balance - $1 WHERE id = $2`, amount, fromID)
+	_, err = tx.ExecContext(ctx, `UPDATE accounts SET balance = ROUND(balance - $1, 2) WHERE id = $2`, amount+fee, fromID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// Зачисление
-	_, err = tx.Exec(`UPDATE accounts SET balance = balance + $1 WHERE id = $2`, amount, toID)
+	res, err := tx.ExecContext(ctx, `UPDATE accounts SET balance = ROUND(balance + $1, 2) WHERE id = $2`, amount, toID)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return 0, sql.ErrNoRows
 	}
 
 	// Запись в транзакции
-	_, err = tx.Exec(`
-		INSERT INTO transactions (from_account_id, to_account_id, amount) 
-		VALUES ($1, $2, $3)`,
-		fromID, toID, amount)
+	var transactionID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO transactions (from_account_id, to_account_id, amount, idempotency_key)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`,
+		fromID, toID, amount, key).Scan(&transactionID)
+	if err != nil {
+		return 0, dialect.mapError(err)
+	}
+
+	// Комиссия за уход в овердрафт
+	if fee > 0 {
+		feesID, err := systemAccount(ctx, tx, dialect, models.SystemFees)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE accounts SET balance = ROUND(balance + $1, 2) WHERE id = $2`, fee, feesID); err != nil {
+			return 0, err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO transactions (kind, from_account_id, to_account_id, amount)
+			VALUES ($1, $2, $3, $4)`,
+			models.KindFee, fromID, feesID, fee)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return transactionID, nil
+}
+
+func (r *SQLAccountRepository) GetAccount(ctx context.Context, accountID, userID int64) (*models.Account, error) {
+	var account models.Account
+	err := scanAccount(r.DB.QueryRowContext(ctx,
+		`SELECT `+accountColumns+` FROM accounts WHERE id = $1 AND `+hasAccess("accounts.id", "$2", models.AccessView), accountID, userID), &account)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *SQLAccountRepository) SetLowBalanceThreshold(ctx context.Context, accountID, userID int64, threshold float64) error {
+	res, err := r.DB.ExecContext(ctx, `UPDATE accounts SET low_balance_threshold = $1
+		WHERE id = $2 AND `+hasAccess("accounts.id", "$3", models.AccessManage), threshold, accountID, userID)
 	if err != nil {
 		return err
 	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
 
-	return tx.Commit()
+func (r *SQLAccountRepository) GetTransactions(ctx context.Context, accountID, userID int64, limit, offset int) ([]models.Transaction, error) {
+	var exists int
+	err := r.DB.QueryRowContext(ctx,
+		`SELECT 1 FROM accounts WHERE id = $1 AND `+hasAccess("accounts.id", "$2", models.AccessView), accountID, userID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	return accountTransactions(ctx, r.DB, accountID, limit, offset)
+}
+
+// accountTransactions возвращает движения по счёту, начиная с последних.
+func accountTransactions(ctx context.Context, q querier, accountID int64, limit, offset int) ([]models.Transaction, error) {
+	rows, err := q.QueryContext(ctx, transactionSelect+`
+		WHERE t.from_account_id = $1 OR t.to_account_id = $1
+		ORDER BY t.id DESC
+		LIMIT $2 OFFSET $3`,
+		accountID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []models.Transaction{}
+	for rows.Next() {
+		var t models.Transaction
+		if err := scanTransaction(rows, &t); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, rows.Err()
 }
 
-func (r *AccountRepository) GetFirstAccountByUserID(userID int64) (int64, error) {
+func (r *SQLAccountRepository) GetStatement(ctx context.Context, accountID, userID int64, from, to time.Time) (*models.Statement, error) {
+	from, to = from.UTC(), to.UTC()
+	statement := models.Statement{AccountID: accountID, From: from, To: to, Entries: []models.StatementEntry{}}
+	// Движения после начала и после конца периода вычитаются из текущего
+	// баланса одним запросом, чтобы остатки были согласованы между собой
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT COALESCE(a.number, ''), COALESCE(u.username, ''),
+			a.balance - COALESCE((
+				SELECT SUM(CASE WHEN t.to_account_id = a.id THEN t.amount ELSE -t.amount END)
+				FROM transactions t
+				WHERE (t.from_account_id = a.id OR t.to_account_id = a.id) AND t.created_at >= $1
+			), 0),
+			a.balance - COALESCE((
+				SELECT SUM(CASE WHEN t.to_account_id = a.id THEN t.amount ELSE -t.amount END)
+				FROM transactions t
+				WHERE (t.from_account_id = a.id OR t.to_account_id = a.id) AND t.created_at >= $2
+			), 0)
+		FROM accounts a
+		LEFT JOIN users u ON u.id = a.user_id
+		WHERE a.id = $3 AND `+hasAccess("a.id", "$4", models.AccessView),
+		from, to, accountID, userID).Scan(&statement.Account, &statement.Owner, &statement.OpeningBalance, &statement.ClosingBalance)
+	if err != nil {
+		return nil, err
+	}
+	statement.OpeningBalance = round2(statement.OpeningBalance)
+	statement.ClosingBalance = round2(statement.ClosingBalance)
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT t.id, t.kind, COALESCE(t.from_account_id, 0), COALESCE(t.to_account_id, 0),
+			`+accountNumber("t.from_account_id")+`, `+accountNumber("t.to_account_id")+`,
+			t.amount, t.created_at, COALESCE(t.original_transaction_id, 0), t.reason_code,
+			COALESCE(i.reference, ''), COALESCE(i.end_to_end_id, '')
+		FROM transactions t
+		LEFT JOIN batch_items i ON i.transaction_id = t.id
+		WHERE (t.from_account_id = $1 OR t.to_account_id = $1) AND t.created_at >= $2 AND t.created_at < $3
+		ORDER BY t.id`,
+		accountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.StatementEntry
+		err := rows.Scan(&e.ID, &e.Kind, &e.FromAccountID, &e.ToAccountID, &e.FromAccount, &e.ToAccount,
+			&e.Amount, &e.CreatedAt, &e.OriginalTransactionID, &e.ReasonCode, &e.Reference, &e.EndToEndID)
+		if err != nil {
+			return nil, err
+		}
+		statement.Entries = append(statement.Entries, e)
+	}
+	return &statement, rows.Err()
+}
+
+func (r *SQLAccountRepository) GetFirstAccountByUserID(ctx context.Context, userID int64) (int64, error) {
 	var accountID int64
-	err := r.DB.QueryRow(`SELECT id FROM accounts WHERE user_id = $1 ORDER BY id LIMIT 1`, userID).Scan(&accountID)
+	err := r.DB.QueryRowContext(ctx, `SELECT id FROM accounts WHERE user_id = $1 ORDER BY id LIMIT 1`, userID).Scan(&accountID)
 	return accountID, err
 }
 
-func (r *AccountRepository) GetUserIDByAccountID(accountID int64) (int64, error) {
-	var userID int64
-	err := r.DB.QueryRow(`SELECT user_id FROM accounts WHERE id = $1`, accountID).Scan(&userID)
-	return userID, err
+func (r *SQLAccountRepository) GetAccountIDByNumber(ctx context.Context, number string) (int64, error) {
+	var accountID int64
+	err := r.DB.QueryRowContext(ctx, `SELECT id FROM accounts WHERE number = $1`, number).Scan(&accountID)
+	return accountID, err
+}
+
+func (r *SQLAccountRepository) GetUserIDByAccountID(ctx context.Context, accountID int64) (int64, error) {
+	var userID sql.NullInt64
+	err := r.DB.QueryRowContext(ctx, `SELECT user_id FROM accounts WHERE id = $1`, accountID).Scan(&userID)
+	return userID.Int64, err
+}
+
+// checkSavingsDestination проверяет получателя перевода со сберегательного
+// счёта: у userID должен быть доступ transact к счёту toID — своему или
+// совместному. Иначе ErrSavingsExternalTransfer.
+func checkSavingsDestination(ctx context.Context, tx *sql.Tx, toID, userID int64) error {
+	var holder bool
+	err := tx.QueryRowContext(ctx, `SELECT `+hasAccess("$1", "$2", models.AccessTransact), toID, userID).Scan(&holder)
+	if err != nil {
+		return err
+	}
+	if !holder {
+		return ErrSavingsExternalTransfer
+	}
+	return nil
+}
+
+// replayResult сравнивает повтор перевода с уже выполненным по тому же ключу.
+func replayResult(prevTo int64, prevAmount float64, toID int64, amount float64) error {
+	if prevTo == toID && math.Round(prevAmount*100) == math.Round(amount*100) {
+		return ErrAlreadyApplied
+	}
+	return ErrIdempotencyConflict
+}
+
+// MonthStart возвращает начало календарного месяца t в UTC — с него считается
+// месячный лимит переводов.
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
 }
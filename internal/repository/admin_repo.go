@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"banking-api/internal/models"
+	"context"
+	"database/sql"
+	"math"
+	"strings"
+)
+
+// SQLAdminRepository — реализация AdminRepository поверх PostgreSQL или SQLite.
+type SQLAdminRepository struct {
+	DB      *sql.DB
+	Dialect Dialect
+}
+
+func NewSQLAdminRepository(db *sql.DB, dialect Dialect) *SQLAdminRepository {
+	return &SQLAdminRepository{DB: db, Dialect: dialect}
+}
+
+func (r *SQLAdminRepository) GetAccountByID(ctx context.Context, accountID int64) (*models.Account, error) {
+	var account models.Account
+	err := scanAccount(r.DB.QueryRowContext(ctx, `SELECT `+accountColumns+` FROM accounts WHERE id = $1`, accountID), &account)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *SQLAdminRepository) ListAccountsByUserID(ctx context.Context, userID int64) ([]models.Account, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+accountColumns+` FROM accounts WHERE user_id = $1 ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []models.Account{}
+	for rows.Next() {
+		var a models.Account
+		if err := scanAccount(rows, &a); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *SQLAdminRepository) ListTransactions(ctx context.Context, accountID int64, limit, offset int) ([]models.Transaction, error) {
+	var exists int
+	if err := r.DB.QueryRowContext(ctx, `SELECT 1 FROM accounts WHERE id = $1`, accountID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	return accountTransactions(ctx, r.DB, accountID, limit, offset)
+}
+
+func (r *SQLAdminRepository) SetAccountFrozen(ctx context.Context, accountID int64, frozen bool, entry models.AuditEntry) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE accounts SET frozen = $1 WHERE id = $2`, frozen, accountID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLAdminRepository) AdjustBalance(ctx context.Context, accountID int64, amount float64, entry models.AuditEntry) (*models.Transaction, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var balance, limit float64
+	err = tx.QueryRowContext(ctx, `SELECT balance, credit_limit + overdraft_limit - held FROM accounts WHERE id = $1`+r.Dialect.forUpdate(), accountID).
+		Scan(&balance, &limit)
+	if err != nil {
+		return nil, err
+	}
+	if balance+limit+amount < 0 {
+		return nil, ErrInsufficientFunds
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE accounts SET balance = ROUND(balance + $1, 2) WHERE id = $2`, amount, accountID); err != nil {
+		return nil, err
+	}
+
+	// Зачисление записывается как движение без счёта-отправителя, списание — без получателя
+	var from, to sql.NullInt64
+	if amount > 0 {
+		to = sql.NullInt64{Int64: accountID, Valid: true}
+	} else {
+		from = sql.NullInt64{Int64: accountID, Valid: true}
+	}
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO transactions (kind, from_account_id, to_account_id, amount)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`,
+		models.KindAdjustment, from, to, math.Abs(amount)).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	var t models.Transaction
+	if err := scanTransaction(tx.QueryRowContext(ctx, transactionSelect+` WHERE t.id = $1`, id), &t); err != nil {
+		return nil, err
+	}
+
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *SQLAdminRepository) ReverseTransaction(ctx context.Context, transactionID int64, reasonCode string, entry models.AuditEntry) (*models.Transaction, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	original, remaining, err := lockOriginal(ctx, tx, `
+		SELECT id, kind, COALESCE(from_account_id, 0), COALESCE(to_account_id, 0), amount FROM transactions
+		WHERE id = $1`+r.Dialect.forUpdate(), transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if original.Kind != models.KindTransfer && original.Kind != models.KindFee {
+		return nil, ErrNotRefundable
+	}
+	amount, err := refundAmount(0, remaining)
+	if err != nil {
+		return nil, err
+	}
+	t, err := moveBack(ctx, tx, original, models.KindReversal, amount, reasonCode)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.AccountID = original.FromAccountID
+	entry.Amount = amount
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *SQLAdminRepository) SetOverdraft(ctx context.Context, accountID int64, overdraft models.Overdraft, entry models.AuditEntry) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var accountType string
+	var balance float64
+	err = tx.QueryRowContext(ctx, `SELECT type, balance FROM accounts WHERE id = $1`+r.Dialect.forUpdate(), accountID).
+		Scan(&accountType, &balance)
+	if err != nil {
+		return err
+	}
+	if accountType != models.AccountChecking {
+		return ErrOverdraftNotAllowed
+	}
+	if balance+overdraft.Limit < 0 {
+		return ErrOverdraftInUse
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE accounts SET overdraft_limit = $1, overdraft_rate = $2, overdraft_fee = $3
+		WHERE id = $4`,
+		overdraft.Limit, overdraft.Rate, overdraft.Fee, accountID)
+	if err != nil {
+		return err
+	}
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLAdminRepository) SetApprovalPolicy(ctx context.Context, accountID int64, threshold float64, approverIDs []int64, entry models.AuditEntry) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE accounts SET approval_threshold = $1 WHERE id = $2 AND type <> 'internal'`, round2(threshold), accountID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM account_approvers WHERE account_id = $1`, accountID); err != nil {
+		return err
+	}
+	for _, userID := range approverIDs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO account_approvers (account_id, user_id) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING`, accountID, userID)
+		if err != nil {
+			return err
+		}
+	}
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertAudit(ctx context.Context, tx *sql.Tx, entry models.AuditEntry) error {
+	accountID := sql.NullInt64{Int64: entry.AccountID, Valid: entry.AccountID != 0}
+	amount := sql.NullFloat64{Float64: entry.Amount, Valid: entry.Amount != 0}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO audit_log (actor, action, account_id, amount, reason)
+		VALUES ($1, $2, $3, $4, $5)`,
+		entry.Actor, entry.Action, accountID, amount, entry.Reason)
+	return err
+}
+
+func (r *SQLAdminRepository) ListAuditLog(ctx context.Context, accountID int64, limit int) ([]models.AuditEntry, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT l.id, l.actor, l.action, COALESCE(l.account_id, 0), `+accountNumber("l.account_id")+`,
+			COALESCE(l.amount, 0), l.reason, l.created_at
+		FROM audit_log l
+		WHERE $1 = 0 OR l.account_id = $1
+		ORDER BY l.id DESC
+		LIMIT $2`,
+		accountID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(&e.ID, &e.Actor, &e.Action, &e.AccountID, &e.Account, &e.Amount, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Reconcile выполняет все проверки в одной читающей транзакции, чтобы переводы,
+// завершившиеся во время сверки, не давали ложных расхождений.
+func (r *SQLAdminRepository) Reconcile(ctx context.Context) (*models.Reconciliation, error) {
+	tx, err := r.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	report := &models.Reconciliation{NegativeBalances: []string{}, InvalidTransactions: []int64{}, BalanceMismatches: []models.BalanceMismatch{}}
+
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(balance), 0) FROM accounts`).
+		Scan(&report.Accounts, &report.TotalBalance)
+	if err != nil {
+		return nil, err
+	}
+	report.TotalBalance = math.Round(report.TotalBalance*100) / 100
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM transactions`).Scan(&report.Transactions); err != nil {
+		return nil, err
+	}
+
+	if report.NegativeBalances, err = column[string](ctx, tx, `
+		SELECT number FROM accounts
+		WHERE balance < -(credit_limit + overdraft_limit) AND type <> 'internal'
+		ORDER BY number`); err != nil {
+		return nil, err
+	}
+	report.InvalidTransactions, err = column[int64](ctx, tx, `
+		SELECT id FROM transactions
+		WHERE amount <= 0 OR kind NOT IN ('`+strings.Join(models.TransactionKinds, "', '")+`')
+		ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+
+	// Движения без второго счёта: пополнения, корректировки и движения удалённых счетов
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(CASE WHEN from_account_id IS NULL THEN amount ELSE -amount END), 0)
+		FROM transactions
+		WHERE (from_account_id IS NULL) <> (to_account_id IS NULL)`).Scan(&report.ExternalNet)
+	if err != nil {
+		return nil, err
+	}
+	report.ExternalNet = math.Round(report.ExternalNet*100) / 100
+	report.Imbalance = math.Round((report.TotalBalance-report.ExternalNet)*100) / 100
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT a.id, a.number, a.balance, COALESCE(i.amount, 0) - COALESCE(o.amount, 0)
+		FROM accounts a
+		LEFT JOIN (SELECT to_account_id AS id, SUM(amount) AS amount FROM transactions GROUP BY to_account_id) i ON i.id = a.id
+		LEFT JOIN (SELECT from_account_id AS id, SUM(amount) AS amount FROM transactions GROUP BY from_account_id) o ON o.id = a.id
+		WHERE ROUND(a.balance - (COALESCE(i.amount, 0) - COALESCE(o.amount, 0)), 2) <> 0
+		ORDER BY a.number`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m models.BalanceMismatch
+		if err := rows.Scan(&m.AccountID, &m.Account, &m.Balance, &m.Expected); err != nil {
+			return nil, err
+		}
+		m.Expected = math.Round(m.Expected*100) / 100
+		m.Difference = math.Round((m.Balance-m.Expected)*100) / 100
+		report.BalanceMismatches = append(report.BalanceMismatches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return report, tx.Commit()
+}
+
+// column выполняет запрос, возвращающий один столбец.
+func column[T any](ctx context.Context, q querier, query string) ([]T, error) {
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []T{}
+	for rows.Next() {
+		var v T
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"banking-api/internal/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// SQLApprovalRepository — реализация ApprovalRepository поверх PostgreSQL или SQLite.
+type SQLApprovalRepository struct {
+	DB      *sql.DB
+	Dialect Dialect
+}
+
+func NewSQLApprovalRepository(db *sql.DB, dialect Dialect) *SQLApprovalRepository {
+	return &SQLApprovalRepository{DB: db, Dialect: dialect}
+}
+
+// approvalSelect выбирает переводы на одобрении с именами автора и
+// одобряющего в порядке, который ожидает scanApproval.
+const approvalSelect = `
+	SELECT p.id, p.from_account_id, p.to_account_id, COALESCE(fa.number, ''), COALESCE(ta.number, ''),
+		p.amount, p.maker_id, COALESCE(p.checker_id, 0), m.username, COALESCE(c.username, ''), p.status, p.reason, COALESCE(p.idempotency_key, ''),
+		COALESCE(p.transaction_id, 0), p.expires_at, p.created_at, p.resolved_at
+	FROM transfer_approvals p
+	JOIN accounts fa ON fa.id = p.from_account_id
+	JOIN accounts ta ON ta.id = p.to_account_id
+	JOIN users m ON m.id = p.maker_id
+	LEFT JOIN users c ON c.id = p.checker_id`
+
+// approvalVisible — условие видимости перевода пользователю $2: автору и
+// одобряющим счёта списания.
+const approvalVisible = `(p.maker_id = $2 OR EXISTS (
+	SELECT 1 FROM account_approvers v WHERE v.account_id = p.from_account_id AND v.user_id = $2))`
+
+func scanApproval(row interface{ Scan(...any) error }, a *models.TransferApproval) error {
+	var resolved sql.NullTime
+	err := row.Scan(&a.ID, &a.FromAccountID, &a.ToAccountID, &a.FromAccount, &a.ToAccount, &a.Amount, &a.MakerID, &a.CheckerID,
+		&a.Maker, &a.Checker, &a.Status, &a.Reason, &a.IdempotencyKey,
+		&a.TransactionID, &a.ExpiresAt, &a.CreatedAt, &resolved)
+	if err != nil {
+		return err
+	}
+	a.ResolvedAt = nil
+	if resolved.Valid {
+		a.ResolvedAt = &resolved.Time
+	}
+	return nil
+}
+
+func (r *SQLApprovalRepository) CreateApproval(ctx context.Context, approval *models.TransferApproval) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Счёт списания — с доступом transact, счёт зачисления — любой, кроме счетов банка
+	var count int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM accounts
+		WHERE (id = $1 AND `+hasAccess("accounts.id", "$2", models.AccessTransact)+`) OR (id = $3 AND type <> 'internal')`,
+		approval.FromAccountID, approval.MakerID, approval.ToAccountID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count != 2 {
+		return sql.ErrNoRows
+	}
+	key := sql.NullString{String: approval.IdempotencyKey, Valid: approval.IdempotencyKey != ""}
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO transfer_approvals (from_account_id, to_account_id, amount, maker_id, idempotency_key, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		approval.FromAccountID, approval.ToAccountID, round2(approval.Amount), approval.MakerID, key, approval.ExpiresAt.UTC()).Scan(&id)
+	if err != nil {
+		return r.Dialect.mapError(err)
+	}
+	if err := scanApproval(tx.QueryRowContext(ctx, approvalSelect+` WHERE p.id = $1`, id), approval); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLApprovalRepository) GetApprovalByKey(ctx context.Context, fromAccountID int64, idempotencyKey string) (*models.TransferApproval, error) {
+	var approval models.TransferApproval
+	err := scanApproval(r.DB.QueryRowContext(ctx, approvalSelect+`
+		WHERE p.from_account_id = $1 AND p.idempotency_key = $2`, fromAccountID, idempotencyKey), &approval)
+	if err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+func (r *SQLApprovalRepository) GetApproval(ctx context.Context, approvalID, userID int64) (*models.TransferApproval, error) {
+	var approval models.TransferApproval
+	err := scanApproval(r.DB.QueryRowContext(ctx, approvalSelect+`
+		WHERE p.id = $1 AND `+approvalVisible, approvalID, userID), &approval)
+	if err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+func (r *SQLApprovalRepository) ListApprovals(ctx context.Context, userID int64, status string) ([]models.TransferApproval, error) {
+	rows, err := r.DB.QueryContext(ctx, approvalSelect+`
+		WHERE ($1 = '' OR p.status = $1) AND `+approvalVisible+`
+		ORDER BY p.id DESC`, status, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	approvals := []models.TransferApproval{}
+	for rows.Next() {
+		var approval models.TransferApproval
+		if err := scanApproval(rows, &approval); err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, approval)
+	}
+	return approvals, rows.Err()
+}
+
+func (r *SQLApprovalRepository) Approvers(ctx context.Context, accountID int64) ([]int64, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT user_id FROM account_approvers WHERE account_id = $1 ORDER BY user_id`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// lockPending блокирует перевод approvalID, который может рассмотреть
+// checkerID, и проверяет, что он ещё ждёт решения.
+func (r *SQLApprovalRepository) lockPending(ctx context.Context, tx *sql.Tx, approvalID, checkerID int64, now time.Time) (*models.TransferApproval, error) {
+	var a models.TransferApproval
+	err := tx.QueryRowContext(ctx, `
+		SELECT p.id, p.from_account_id, p.to_account_id, p.amount, p.maker_id, p.status,
+			COALESCE(p.idempotency_key, ''), p.expires_at
+		FROM transfer_approvals p
+		WHERE p.id = $1 AND p.maker_id <> $2 AND EXISTS (
+			SELECT 1 FROM account_approvers v WHERE v.account_id = p.from_account_id AND v.user_id = $2)`+r.Dialect.forUpdate(),
+		approvalID, checkerID).
+		Scan(&a.ID, &a.FromAccountID, &a.ToAccountID, &a.Amount, &a.MakerID, &a.Status, &a.IdempotencyKey, &a.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if a.Status != models.ApprovalPending || !a.ExpiresAt.After(now) {
+		return nil, ErrApprovalClosed
+	}
+	return &a, nil
+}
+
+func (r *SQLApprovalRepository) ApproveTransfer(ctx context.Context, approvalID, checkerID int64, now time.Time) (*models.TransferApproval, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	approval, err := r.lockPending(ctx, tx, approvalID, checkerID, now)
+	if err != nil {
+		return nil, err
+	}
+	transactionID, err := approvedTransfer(ctx, tx, r.Dialect, approval.FromAccountID, approval.ToAccountID,
+		approval.MakerID, approval.Amount, approval.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE transfer_approvals SET status = $1, checker_id = $2, transaction_id = $3, resolved_at = $4 WHERE id = $5`,
+		models.ApprovalApproved, checkerID, transactionID, now.UTC(), approvalID)
+	if err != nil {
+		return nil, err
+	}
+	if err := scanApproval(tx.QueryRowContext(ctx, approvalSelect+` WHERE p.id = $1`, approvalID), approval); err != nil {
+		return nil, err
+	}
+	return approval, tx.Commit()
+}
+
+func (r *SQLApprovalRepository) RejectTransfer(ctx context.Context, approvalID, checkerID int64, reason string, now time.Time) (*models.TransferApproval, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	approval, err := r.lockPending(ctx, tx, approvalID, checkerID, now)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE transfer_approvals SET status = $1, checker_id = $2, reason = $3, resolved_at = $4 WHERE id = $5`,
+		models.ApprovalRejected, checkerID, reason, now.UTC(), approvalID)
+	if err != nil {
+		return nil, err
+	}
+	if err := scanApproval(tx.QueryRowContext(ctx, approvalSelect+` WHERE p.id = $1`, approvalID), approval); err != nil {
+		return nil, err
+	}
+	return approval, tx.Commit()
+}
+
+func (r *SQLApprovalRepository) ExpireApprovals(ctx context.Context, now time.Time) ([]models.TransferApproval, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now = now.UTC()
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM transfer_approvals
+		WHERE status = $1 AND expires_at <= $2
+		ORDER BY id`+r.Dialect.forUpdate(), models.ApprovalPending, now)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	expired := []models.TransferApproval{}
+	for _, id := range ids {
+		_, err := tx.ExecContext(ctx, `UPDATE transfer_approvals SET status = $1, resolved_at = $2 WHERE id = $3`,
+			models.ApprovalExpired, now, id)
+		if err != nil {
+			return nil, err
+		}
+		var approval models.TransferApproval
+		if err := scanApproval(tx.QueryRowContext(ctx, approvalSelect+` WHERE p.id = $1`, id), &approval); err != nil {
+			return nil, err
+		}
+		expired = append(expired, approval)
+	}
+	return expired, tx.Commit()
+}
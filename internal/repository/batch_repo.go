@@ -0,0 +1,360 @@
+package repository
+
+import (
+	"banking-api/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// SQLBatchRepository — реализация BatchRepository поверх PostgreSQL или SQLite.
+type SQLBatchRepository struct {
+	DB      *sql.DB
+	Dialect Dialect
+}
+
+func NewSQLBatchRepository(db *sql.DB, dialect Dialect) *SQLBatchRepository {
+	return &SQLBatchRepository{DB: db, Dialect: dialect}
+}
+
+// TransferRejection сообщает, что перевод отклонён по правилам TransferFunds,
+// а не из-за сбоя хранилища, и возвращает причину для отчёта по строке пакета.
+func TransferRejection(err error) (string, bool) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "счёт не найден", true
+	case errors.Is(err, ErrInsufficientFunds), errors.Is(err, ErrAccountFrozen),
+		errors.Is(err, ErrTransferLimitExceeded), errors.Is(err, ErrSavingsExternalTransfer),
+		errors.Is(err, ErrApprovalRequired):
+		return err.Error(), true
+	}
+	return "", false
+}
+
+// batchColumns — столбцы batches с номером счёта в порядке, который ожидает scanBatch.
+var batchColumns = `b.id, b.user_id, b.from_account_id, ` + accountNumber("b.from_account_id") + `, b.all_or_nothing,
+	b.message_id, b.payment_info_id, b.status, b.created_at, b.completed_at`
+
+func scanBatch(row interface{ Scan(...any) error }, b *models.Batch, summary ...any) error {
+	var completed sql.NullTime
+	dest := append([]any{&b.ID, &b.UserID, &b.FromAccountID, &b.FromAccount, &b.AllOrNothing, &b.MessageID, &b.PaymentInfoID, &b.Status, &b.CreatedAt, &completed}, summary...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	b.CompletedAt = nil
+	if completed.Valid {
+		b.CompletedAt = &completed.Time
+	}
+	return nil
+}
+
+func (r *SQLBatchRepository) CreateBatch(ctx context.Context, batch *models.Batch) error {
+	return r.CreateBatches(ctx, []*models.Batch{batch})
+}
+
+func (r *SQLBatchRepository) CreateBatches(ctx context.Context, batches []*models.Batch) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, batch := range batches {
+		if err := r.insertBatch(ctx, tx, batch); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// insertBatch сохраняет пакет со строками в транзакции tx.
+func (r *SQLBatchRepository) insertBatch(ctx context.Context, tx *sql.Tx, batch *models.Batch) error {
+	err := tx.QueryRowContext(ctx,
+		`SELECT COALESCE(number, '') FROM accounts WHERE id = $1 AND `+hasAccess("accounts.id", "$2", models.AccessTransact),
+		batch.FromAccountID, batch.UserID).Scan(&batch.FromAccount)
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO batches (user_id, from_account_id, all_or_nothing, message_id, payment_info_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, created_at`,
+		batch.UserID, batch.FromAccountID, batch.AllOrNothing, batch.MessageID, batch.PaymentInfoID).Scan(&batch.ID, &batch.Status, &batch.CreatedAt)
+	if err != nil {
+		return r.Dialect.mapError(err)
+	}
+	for i := range batch.Items {
+		item := &batch.Items[i]
+		item.Amount = round2(item.Amount)
+		item.Status = models.BatchItemPending
+		err := tx.QueryRowContext(ctx, `SELECT COALESCE(number, '') FROM accounts WHERE id = $1`, item.ToAccountID).Scan(&item.ToAccount)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO batch_items (batch_id, line, to_username, to_account_id, amount, reference, end_to_end_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			batch.ID, item.Line, item.ToUsername, item.ToAccountID, item.Amount, item.Reference, item.EndToEndID)
+		if err != nil {
+			return err
+		}
+	}
+	batch.CompletedAt = nil
+	batch.Tally()
+	return nil
+}
+
+func (r *SQLBatchRepository) GetBatch(ctx context.Context, batchID, userID int64) (*models.Batch, error) {
+	var batch models.Batch
+	err := scanBatch(r.DB.QueryRowContext(ctx,
+		`SELECT `+batchColumns+` FROM batches b WHERE b.id = $1 AND b.user_id = $2`, batchID, userID), &batch)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Items, err = r.items(ctx, r.DB, batchID, ""); err != nil {
+		return nil, err
+	}
+	batch.Tally()
+	return &batch, nil
+}
+
+// items возвращает строки пакета по порядку, непустой status оставляет строки в этом статусе.
+func (r *SQLBatchRepository) items(ctx context.Context, q querier, batchID int64, status string) ([]models.BatchItem, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT i.line, i.to_username, i.to_account_id, `+accountNumber("i.to_account_id")+`, i.amount, i.reference,
+			i.end_to_end_id, i.status, i.error, COALESCE(i.transaction_id, 0)
+		FROM batch_items i
+		WHERE i.batch_id = $1 AND ($2 = '' OR i.status = $2)
+		ORDER BY i.line`, batchID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.BatchItem{}
+	for rows.Next() {
+		var item models.BatchItem
+		err := rows.Scan(&item.Line, &item.ToUsername, &item.ToAccountID, &item.ToAccount, &item.Amount, &item.Reference,
+			&item.EndToEndID, &item.Status, &item.Error, &item.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (r *SQLBatchRepository) ListBatches(ctx context.Context, userID int64) ([]models.Batch, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT `+batchColumns+`, COUNT(i.line), COALESCE(SUM(i.amount), 0),
+			COALESCE(SUM(CASE WHEN i.status = 'succeeded' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN i.status IN ('failed', 'skipped') THEN 1 ELSE 0 END), 0)
+		FROM batches b
+		LEFT JOIN batch_items i ON i.batch_id = b.id
+		WHERE b.user_id = $1
+		GROUP BY b.id
+		ORDER BY b.id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batches := []models.Batch{}
+	for rows.Next() {
+		var b models.Batch
+		if err := scanBatch(rows, &b, &b.Total, &b.Amount, &b.Succeeded, &b.Failed); err != nil {
+			return nil, err
+		}
+		b.Amount = round2(b.Amount)
+		batches = append(batches, b)
+	}
+	return batches, rows.Err()
+}
+
+func (r *SQLBatchRepository) PendingBatches(ctx context.Context) ([]int64, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT id FROM batches WHERE status = $1 ORDER BY id`, models.BatchPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *SQLBatchRepository) ExecuteBatch(ctx context.Context, batchID int64, now time.Time) (*models.Batch, error) {
+	var batch models.Batch
+	err := scanBatch(r.DB.QueryRowContext(ctx, `SELECT `+batchColumns+` FROM batches b WHERE b.id = $1`, batchID), &batch)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status != models.BatchPending {
+		return nil, ErrBatchClosed
+	}
+	now = now.UTC()
+	if batch.AllOrNothing {
+		err = r.executeAll(ctx, &batch, now)
+	} else {
+		err = r.executeEach(ctx, &batch, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetBatch(ctx, batchID, batch.UserID)
+}
+
+// lockPending блокирует пакет и проверяет, что он ещё не выполнен.
+func (r *SQLBatchRepository) lockPending(ctx context.Context, tx *sql.Tx, batchID int64) error {
+	var status string
+	err := tx.QueryRowContext(ctx, `SELECT status FROM batches WHERE id = $1`+r.Dialect.forUpdate(), batchID).Scan(&status)
+	if err != nil {
+		return err
+	}
+	if status != models.BatchPending {
+		return ErrBatchClosed
+	}
+	return nil
+}
+
+// executeAll выполняет пакет all_or_nothing одной транзакцией.
+func (r *SQLBatchRepository) executeAll(ctx context.Context, batch *models.Batch, now time.Time) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockPending(ctx, tx, batch.ID); err != nil {
+		return err
+	}
+	items, err := r.items(ctx, tx, batch.ID, models.BatchItemPending)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		transactionID, err := transfer(ctx, tx, r.Dialect, batch.FromAccountID, item.ToAccountID, batch.UserID, item.Amount, "")
+		if reason, rejected := TransferRejection(err); rejected {
+			// Отменяем уже проведённые строки и записываем отказ отдельной транзакцией
+			tx.Rollback()
+			return r.failAll(ctx, batch.ID, item.Line, reason, now)
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE batch_items SET status = $1, transaction_id = $2 WHERE batch_id = $3 AND line = $4`,
+			models.BatchItemSucceeded, transactionID, batch.ID, item.Line)
+		if err != nil {
+			return err
+		}
+	}
+	if err := r.finish(ctx, tx, batch.ID, models.BatchCompleted, now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// failAll отмечает строку line отказом с причиной reason, остальные строки —
+// пропущенными, а пакет — невыполненным.
+func (r *SQLBatchRepository) failAll(ctx context.Context, batchID int64, line int, reason string, now time.Time) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockPending(ctx, tx, batchID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE batch_items SET status = CASE WHEN line = $1 THEN $2 ELSE $3 END,
+			error = CASE WHEN line = $1 THEN $4 ELSE '' END
+		WHERE batch_id = $5`,
+		line, models.BatchItemFailed, models.BatchItemSkipped, reason, batchID)
+	if err != nil {
+		return err
+	}
+	if err := r.finish(ctx, tx, batchID, models.BatchFailed, now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// executeEach выполняет строки обычного пакета, каждую своей транзакцией.
+func (r *SQLBatchRepository) executeEach(ctx context.Context, batch *models.Batch, now time.Time) error {
+	items, err := r.items(ctx, r.DB, batch.ID, models.BatchItemPending)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := r.executeItem(ctx, batch, item); err != nil {
+			return err
+		}
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := r.lockPending(ctx, tx, batch.ID); err != nil {
+		return err
+	}
+	if err := r.finish(ctx, tx, batch.ID, models.BatchCompleted, now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// executeItem переводит одну строку пакета, если её ещё никто не выполнил.
+func (r *SQLBatchRepository) executeItem(ctx context.Context, batch *models.Batch, item models.BatchItem) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Пакет заблокирован, поэтому параллельный обработчик ждёт здесь и затем
+	// видит строку уже выполненной
+	if err := r.lockPending(ctx, tx, batch.ID); err != nil {
+		return err
+	}
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM batch_items WHERE batch_id = $1 AND line = $2`, batch.ID, item.Line).Scan(&status)
+	if err != nil {
+		return err
+	}
+	if status != models.BatchItemPending {
+		return nil
+	}
+
+	transactionID, err := transfer(ctx, tx, r.Dialect, batch.FromAccountID, item.ToAccountID, batch.UserID, item.Amount, "")
+	if reason, rejected := TransferRejection(err); rejected {
+		tx.Rollback()
+		_, err := r.DB.ExecContext(ctx, `UPDATE batch_items SET status = $1, error = $2 WHERE batch_id = $3 AND line = $4 AND status = $5`,
+			models.BatchItemFailed, reason, batch.ID, item.Line, models.BatchItemPending)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE batch_items SET status = $1, transaction_id = $2 WHERE batch_id = $3 AND line = $4`,
+		models.BatchItemSucceeded, transactionID, batch.ID, item.Line)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// finish закрывает пакет со статусом status.
+func (r *SQLBatchRepository) finish(ctx context.Context, tx *sql.Tx, batchID int64, status string, now time.Time) error {
+	_, err := tx.ExecContext(ctx, `UPDATE batches SET status = $1, completed_at = $2 WHERE id = $3`, status, now, batchID)
+	return err
+}
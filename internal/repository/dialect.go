@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Dialect — диалект SQL, на котором работает SQL-реализация репозиториев.
+// Запросы пишутся так, чтобы выполняться и в PostgreSQL, и в SQLite;
+// различия (блокировки строк, коды ошибок) сосредоточены здесь.
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// DialectFor возвращает диалект для имени драйвера database/sql.
+func DialectFor(driver string) (Dialect, error) {
+	switch Dialect(driver) {
+	case Postgres, SQLite:
+		return Dialect(driver), nil
+	}
+	return "", fmt.Errorf("неподдерживаемый драйвер БД %q", driver)
+}
+
+// forUpdate возвращает суффикс блокировки строки для SELECT внутри транзакции.
+// SQLite блокирует всю БД на запись при BEGIN IMMEDIATE (_txlock=immediate), поэтому там суффикс не нужен.
+func (d Dialect) forUpdate() string {
+	if d == Postgres {
+		return " FOR UPDATE"
+	}
+	return ""
+}
+
+// mapError приводит нарушение уникальности к ErrDuplicate.
+func (d Dialect) mapError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrDuplicate
+	}
+	var liteErr *sqlite.Error
+	if errors.As(err, &liteErr) && (liteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || liteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY) {
+		return ErrDuplicate
+	}
+	return err
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"banking-api/internal/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// SQLHoldRepository — реализация HoldRepository поверх PostgreSQL или SQLite.
+type SQLHoldRepository struct {
+	DB      *sql.DB
+	Dialect Dialect
+}
+
+func NewSQLHoldRepository(db *sql.DB, dialect Dialect) *SQLHoldRepository {
+	return &SQLHoldRepository{DB: db, Dialect: dialect}
+}
+
+// holdColumns — столбцы holds с номерами счетов в порядке, который ожидает scanHold.
+var holdColumns = `h.id, h.account_id, h.to_account_id, ` + accountNumber("h.account_id") + `, ` + accountNumber("h.to_account_id") + `, h.amount, h.captured_amount, h.status,
+	COALESCE(h.transaction_id, 0), h.expires_at, h.created_at, h.resolved_at`
+
+// holdVisible — условие видимости блокировки пользователю param: с доступом
+// view к счёту блокировки или счёту-получателю.
+func holdVisible(param string) string {
+	return `(` + hasAccess("h.account_id", param, models.AccessView) + ` OR ` + hasAccess("h.to_account_id", param, models.AccessView) + `)`
+}
+
+func scanHold(row interface{ Scan(...any) error }, h *models.Hold) error {
+	var resolved sql.NullTime
+	err := row.Scan(&h.ID, &h.AccountID, &h.ToAccountID, &h.Account, &h.ToAccount, &h.Amount, &h.CapturedAmount, &h.Status,
+		&h.TransactionID, &h.ExpiresAt, &h.CreatedAt, &resolved)
+	if err != nil {
+		return err
+	}
+	h.ResolvedAt = nil
+	if resolved.Valid {
+		h.ResolvedAt = &resolved.Time
+	}
+	return nil
+}
+
+func (r *SQLHoldRepository) CreateHold(ctx context.Context, hold *models.Hold, userID int64) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var from models.Account
+	err = scanAccount(tx.QueryRowContext(ctx,
+		`SELECT `+accountColumns+` FROM accounts WHERE id = $1 AND `+hasAccess("accounts.id", "$2", models.AccessTransact)+r.Dialect.forUpdate(),
+		hold.AccountID, userID), &from)
+	if err != nil {
+		return err
+	}
+	var toFrozen bool
+	err = tx.QueryRowContext(ctx, `SELECT frozen FROM accounts WHERE id = $1 AND type <> 'internal'`, hold.ToAccountID).Scan(&toFrozen)
+	if err != nil {
+		return err
+	}
+	if from.Frozen || toFrozen {
+		return ErrAccountFrozen
+	}
+	if from.Type == models.AccountSavings {
+		if err := checkSavingsDestination(ctx, tx, hold.ToAccountID, userID); err != nil {
+			return err
+		}
+	}
+	amount := round2(hold.Amount)
+	// Списание блокировки не проверяет порог одобрения, поэтому он проверяется здесь
+	if from.NeedsApproval(amount) {
+		return ErrApprovalRequired
+	}
+	if from.Available() < amount {
+		return ErrInsufficientFunds
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE accounts SET held = ROUND(held + $1, 2) WHERE id = $2`, amount, hold.AccountID); err != nil {
+		return err
+	}
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO holds (account_id, to_account_id, amount, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`,
+		hold.AccountID, hold.ToAccountID, amount, hold.ExpiresAt.UTC()).Scan(&id)
+	if err != nil {
+		return err
+	}
+	if err := scanHold(tx.QueryRowContext(ctx, `SELECT `+holdColumns+` FROM holds h WHERE h.id = $1`, id), hold); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLHoldRepository) GetHold(ctx context.Context, holdID, userID int64) (*models.Hold, error) {
+	var hold models.Hold
+	err := scanHold(r.DB.QueryRowContext(ctx, `
+		SELECT `+holdColumns+` FROM holds h
+		WHERE h.id = $1 AND `+holdVisible("$2"), holdID, userID), &hold)
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *SQLHoldRepository) ListHolds(ctx context.Context, userID int64, status string) ([]models.Hold, error) {
+	return r.list(ctx, r.DB, `
+		SELECT `+holdColumns+` FROM holds h
+		WHERE `+holdVisible("$1")+` AND ($2 = '' OR h.status = $2)
+		ORDER BY h.id DESC`, userID, status)
+}
+
+func (r *SQLHoldRepository) list(ctx context.Context, q querier, query string, args ...any) ([]models.Hold, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := []models.Hold{}
+	for rows.Next() {
+		var hold models.Hold
+		if err := scanHold(rows, &hold); err != nil {
+			return nil, err
+		}
+		holds = append(holds, hold)
+	}
+	return holds, rows.Err()
+}
+
+// lockActive блокирует блокировку holdID в пользу счёта с доступом transact у
+// userID, проверяет, что её ещё можно закрыть, и освобождает заблокированную
+// сумму. Возвращает блокировку и владельца счёта, на котором она стояла.
+func (r *SQLHoldRepository) lockActive(ctx context.Context, tx *sql.Tx, holdID, userID int64, now time.Time) (*models.Hold, int64, error) {
+	var hold models.Hold
+	var payerID int64
+	err := tx.QueryRowContext(ctx, `
+		SELECT h.id, h.account_id, h.to_account_id, h.amount, h.status, h.expires_at, a.user_id FROM holds h
+		JOIN accounts a ON a.id = h.account_id
+		WHERE h.id = $1 AND `+hasAccess("h.to_account_id", "$2", models.AccessTransact)+r.Dialect.forUpdate(), holdID, userID).
+		Scan(&hold.ID, &hold.AccountID, &hold.ToAccountID, &hold.Amount, &hold.Status, &hold.ExpiresAt, &payerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if hold.Status != models.HoldActive || !hold.ExpiresAt.After(now) {
+		return nil, 0, ErrHoldClosed
+	}
+	if err := releaseHeld(ctx, tx, hold.AccountID, hold.Amount); err != nil {
+		return nil, 0, err
+	}
+	return &hold, payerID, nil
+}
+
+// releaseHeld уменьшает заблокированную сумму счёта на amount.
+func releaseHeld(ctx context.Context, tx *sql.Tx, accountID int64, amount float64) error {
+	_, err := tx.ExecContext(ctx, `UPDATE accounts SET held = ROUND(held - $1, 2) WHERE id = $2`, amount, accountID)
+	return err
+}
+
+func (r *SQLHoldRepository) CaptureHold(ctx context.Context, holdID, userID int64, amount float64, now time.Time) (*models.Hold, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hold, payerID, err := r.lockActive(ctx, tx, holdID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	amount = round2(amount)
+	if amount > hold.Amount {
+		return nil, ErrCaptureExceedsHold
+	}
+	transactionID, err := heldTransfer(ctx, tx, r.Dialect, hold.AccountID, hold.ToAccountID, payerID, amount)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE holds SET status = $1, captured_amount = $2, transaction_id = $3, resolved_at = $4 WHERE id = $5`,
+		models.HoldCaptured, amount, transactionID, now.UTC(), holdID)
+	if err != nil {
+		return nil, err
+	}
+	if err := scanHold(tx.QueryRowContext(ctx, `SELECT `+holdColumns+` FROM holds h WHERE h.id = $1`, holdID), hold); err != nil {
+		return nil, err
+	}
+	return hold, tx.Commit()
+}
+
+func (r *SQLHoldRepository) ReleaseHold(ctx context.Context, holdID, userID int64, now time.Time) (*models.Hold, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hold, _, err := r.lockActive(ctx, tx, holdID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE holds SET status = $1, resolved_at = $2 WHERE id = $3`,
+		models.HoldReleased, now.UTC(), holdID)
+	if err != nil {
+		return nil, err
+	}
+	if err := scanHold(tx.QueryRowContext(ctx, `SELECT `+holdColumns+` FROM holds h WHERE h.id = $1`, holdID), hold); err != nil {
+		return nil, err
+	}
+	return hold, tx.Commit()
+}
+
+func (r *SQLHoldRepository) ExpireHolds(ctx context.Context, now time.Time) ([]models.Hold, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now = now.UTC()
+	expired, err := r.list(ctx, tx, `
+		SELECT `+holdColumns+` FROM holds h
+		WHERE h.status = $1 AND h.expires_at <= $2
+		ORDER BY h.id`+r.Dialect.forUpdate(), models.HoldActive, now)
+	if err != nil {
+		return nil, err
+	}
+	for i := range expired {
+		if err := releaseHeld(ctx, tx, expired[i].AccountID, expired[i].Amount); err != nil {
+			return nil, err
+		}
+		_, err := tx.ExecContext(ctx, `UPDATE holds SET status = $1, resolved_at = $2 WHERE id = $3`,
+			models.HoldExpired, now, expired[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		expired[i].Status = models.HoldExpired
+		expired[i].ResolvedAt = &now
+	}
+	return expired, tx.Commit()
+}
@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"banking-api/internal/accountno"
+	"banking-api/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+)
+
+// DayLayout — формат дней в таблицах процентов.
+const DayLayout = "2006-01-02"
+
+// DaysInYear — база начисления: годовая ставка делится на 365 дней независимо от года.
+const DaysInYear = 365
+
+// SQLInterestRepository — реализация InterestRepository поверх PostgreSQL или SQLite.
+type SQLInterestRepository struct {
+	DB      *sql.DB
+	Dialect Dialect
+}
+
+func NewSQLInterestRepository(db *sql.DB, dialect Dialect) *SQLInterestRepository {
+	return &SQLInterestRepository{DB: db, Dialect: dialect}
+}
+
+func (r *SQLInterestRepository) SetRate(ctx context.Context, rate models.InterestRate, entry models.AuditEntry) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO interest_rates (account_type, effective_from, annual_rate)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (account_type, effective_from) DO UPDATE SET annual_rate = excluded.annual_rate`,
+		rate.AccountType, rate.EffectiveFrom.Format(DayLayout), rate.AnnualRate)
+	if err != nil {
+		return err
+	}
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLInterestRepository) ListRates(ctx context.Context) ([]models.InterestRate, error) {
+	return listRates(ctx, r.DB)
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func listRates(ctx context.Context, q querier) ([]models.InterestRate, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT account_type, effective_from, annual_rate FROM interest_rates
+		ORDER BY account_type, effective_from`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []models.InterestRate{}
+	for rows.Next() {
+		var rate models.InterestRate
+		var from string
+		if err := rows.Scan(&rate.AccountType, &from, &rate.AnnualRate); err != nil {
+			return nil, err
+		}
+		if rate.EffectiveFrom, err = time.Parse(DayLayout, from); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+// RatesOn возвращает ставки по типам счетов, действующие в день day.
+func RatesOn(rates []models.InterestRate, day time.Time) map[string]float64 {
+	current := make(map[string]models.InterestRate)
+	for _, rate := range rates {
+		if rate.EffectiveFrom.After(day) {
+			continue
+		}
+		if prev, ok := current[rate.AccountType]; !ok || rate.EffectiveFrom.After(prev.EffectiveFrom) {
+			current[rate.AccountType] = rate
+		}
+	}
+	result := make(map[string]float64, len(current))
+	for accountType, rate := range current {
+		result[accountType] = rate.AnnualRate
+	}
+	return result
+}
+
+// AccrualRate выбирает ставку для остатка balance: на положительный остаток
+// начисляется ставка типа счёта, на отрицательный — ставка овердрафта.
+func AccrualRate(balance, typeRate, overdraftRate float64) float64 {
+	switch {
+	case balance > 0:
+		return typeRate
+	case balance < 0:
+		return overdraftRate
+	}
+	return 0
+}
+
+// DailyInterest — проценты за один день на остаток balance по годовой ставке rate (%).
+// Для отрицательного остатка проценты отрицательные: их платит клиент.
+func DailyInterest(balance, rate float64) float64 {
+	return math.Round(balance*rate/100/DaysInYear*1e6) / 1e6
+}
+
+func (r *SQLInterestRepository) AccrueInterest(ctx context.Context, day time.Time) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rates, err := listRates(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	dayRates := RatesOn(rates, day)
+	end := day.AddDate(0, 0, 1)
+
+	// Остаток на конец дня — текущий баланс за вычетом движений после конца дня.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT a.id, a.type, a.overdraft_rate, a.balance - COALESCE((
+			SELECT SUM(CASE WHEN t.to_account_id = a.id THEN t.amount ELSE -t.amount END)
+			FROM transactions t
+			WHERE (t.from_account_id = a.id OR t.to_account_id = a.id) AND t.created_at >= $1
+		), 0)
+		FROM accounts a
+		WHERE a.created_at < $1 AND a.type <> 'internal'
+		ORDER BY a.id`, end)
+	if err != nil {
+		return 0, err
+	}
+	var accruals []models.InterestAccrual
+	for rows.Next() {
+		var a models.InterestAccrual
+		var accountType string
+		var overdraftRate float64
+		if err := rows.Scan(&a.AccountID, &accountType, &overdraftRate, &a.Balance); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		a.Balance = math.Round(a.Balance*100) / 100
+		rate := AccrualRate(a.Balance, dayRates[accountType], overdraftRate)
+		if rate <= 0 {
+			continue
+		}
+		a.AnnualRate = rate
+		a.Amount = DailyInterest(a.Balance, rate)
+		accruals = append(accruals, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, a := range accruals {
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO interest_accruals (account_id, day, balance, annual_rate, amount)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (account_id, day) DO NOTHING`,
+			a.AccountID, day.Format(DayLayout), a.Balance, a.AnnualRate, a.Amount)
+		if err != nil {
+			return 0, err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			created++
+		}
+	}
+	return created, tx.Commit()
+}
+
+func (r *SQLInterestRepository) CapitalizeInterest(ctx context.Context, month time.Time) (*models.Capitalization, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Блокировка счёта банка сериализует параллельные капитализации
+	interestID, err := systemAccount(ctx, tx, r.Dialect, models.SystemInterest)
+	if err != nil {
+		return nil, err
+	}
+	var balance float64
+	if err := tx.QueryRowContext(ctx, `SELECT balance FROM accounts WHERE id = $1`+r.Dialect.forUpdate(), interestID).Scan(&balance); err != nil {
+		return nil, err
+	}
+
+	from, to := month.Format(DayLayout), month.AddDate(0, 1, 0).Format(DayLayout)
+	rows, err := tx.QueryContext(ctx, `
+		SELECT account_id, SUM(amount) FROM interest_accruals
+		WHERE day >= $1 AND day < $2 AND NOT capitalized
+		GROUP BY account_id
+		ORDER BY account_id`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	type payout struct {
+		accountID int64
+		amount    float64
+	}
+	var payouts []payout
+	for rows.Next() {
+		var p payout
+		if err := rows.Scan(&p.accountID, &p.amount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		p.amount = math.Round(p.amount*100) / 100
+		payouts = append(payouts, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &models.Capitalization{Month: month}
+	for _, p := range payouts {
+		var transactionID sql.NullInt64
+		if p.amount != 0 {
+			if _, err := tx.ExecContext(ctx, `UPDATE accounts SET balance = ROUND(balance - $1, 2) WHERE id = $2`, p.amount, interestID); err != nil {
+				return nil, err
+			}
+			if _, err := tx.ExecContext(ctx, `UPDATE accounts SET balance = ROUND(balance + $1, 2) WHERE id = $2`, p.amount, p.accountID); err != nil {
+				return nil, err
+			}
+			// Проценты по овердрафту списываются со счёта клиента на счёт банка
+			from, to := interestID, p.accountID
+			if p.amount < 0 {
+				from, to = to, from
+			}
+			err = tx.QueryRowContext(ctx, `
+				INSERT INTO transactions (kind, from_account_id, to_account_id, amount)
+				VALUES ($1, $2, $3, $4)
+				RETURNING id`,
+				models.KindInterest, from, to, math.Abs(p.amount)).Scan(&transactionID)
+			if err != nil {
+				return nil, err
+			}
+			report.Accounts++
+			report.Total += p.amount
+		}
+		// Начисления, округлившиеся до нуля, тоже закрываются
+		_, err = tx.ExecContext(ctx, `
+			UPDATE interest_accruals SET capitalized = TRUE, transaction_id = $1
+			WHERE account_id = $2 AND day >= $3 AND day < $4 AND NOT capitalized`,
+			transactionID, p.accountID, from, to)
+		if err != nil {
+			return nil, err
+		}
+	}
+	report.Total = math.Round(report.Total*100) / 100
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// systemAccount возвращает счёт банка name, создавая его при первом обращении.
+func systemAccount(ctx context.Context, tx *sql.Tx, dialect Dialect, name string) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT account_id FROM system_accounts WHERE name = $1`, name).Scan(&id)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return id, err
+	}
+	err = tx.QueryRowContext(ctx, `INSERT INTO accounts (number, user_id, type) VALUES ($1, NULL, $2) RETURNING id`,
+		accountno.New(), models.AccountInternal).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO system_accounts (name, account_id) VALUES ($1, $2)`, name, id); err != nil {
+		return 0, dialect.mapError(err)
+	}
+	return id, nil
+}
+
+func (r *SQLInterestRepository) ListAccruals(ctx context.Context, accountID int64, from, to time.Time) ([]models.InterestAccrual, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT i.account_id, `+accountNumber("i.account_id")+`, i.day, i.balance, i.annual_rate, i.amount, i.capitalized
+		FROM interest_accruals i
+		WHERE i.account_id = $1 AND i.day >= $2 AND i.day < $3
+		ORDER BY i.day`,
+		accountID, from.Format(DayLayout), to.Format(DayLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accruals := []models.InterestAccrual{}
+	for rows.Next() {
+		var a models.InterestAccrual
+		var day string
+		if err := rows.Scan(&a.AccountID, &a.Account, &day, &a.Balance, &a.AnnualRate, &a.Amount, &a.Capitalized); err != nil {
+			return nil, err
+		}
+		if a.Day, err = time.Parse(DayLayout, day); err != nil {
+			return nil, err
+		}
+		accruals = append(accruals, a)
+	}
+	return accruals, rows.Err()
+}
+
+func (r *SQLInterestRepository) LastAccrualDay(ctx context.Context) (time.Time, error) {
+	var day string
+	if err := r.DB.QueryRowContext(ctx, `SELECT COALESCE(MAX(day), '') FROM interest_accruals`).Scan(&day); err != nil || day == "" {
+		return time.Time{}, err
+	}
+	return time.Parse(DayLayout, day)
+}
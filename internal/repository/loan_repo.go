@@ -0,0 +1,460 @@
+package repository
+
+import (
+	"banking-api/internal/models"
+	"context"
+	"database/sql"
+	"math"
+	"time"
+)
+
+// SQLLoanRepository — реализация LoanRepository поверх PostgreSQL или SQLite.
+type SQLLoanRepository struct {
+	DB      *sql.DB
+	Dialect Dialect
+}
+
+func NewSQLLoanRepository(db *sql.DB, dialect Dialect) *SQLLoanRepository {
+	return &SQLLoanRepository{DB: db, Dialect: dialect}
+}
+
+// AddMonths возвращает день через months месяцев после day. Если в том месяце
+// нет такого числа, берётся последний день месяца: 31 января + 1 = 28 (29) февраля.
+func AddMonths(day time.Time, months int) time.Time {
+	first := time.Date(day.Year(), day.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day.Day(), last)-1)
+}
+
+// LoanSchedule строит график платежей с номера from по последний (TermMonths)
+// на остаток основного долга principal. Проценты за месяц — AnnualRate/12 от
+// остатка. Аннуитетный платёж одинаков во всех месяцах, в дифференцированном
+// основной долг делится поровну; последний платёж гасит остаток долга целиком.
+func LoanSchedule(terms models.LoanTerms, start time.Time, principal float64, from int) []models.LoanInstallment {
+	n := terms.TermMonths - from + 1
+	if n <= 0 || principal <= 0 {
+		return nil
+	}
+	rate := terms.AnnualRate / 100 / 12
+	payment := principal / float64(n)
+	if terms.Method == models.LoanAnnuity && rate > 0 {
+		payment = principal * rate / (1 - math.Pow(1+rate, -float64(n)))
+	}
+	payment = round2(payment)
+
+	schedule := make([]models.LoanInstallment, 0, n)
+	rest := principal
+	for number := from; number <= terms.TermMonths; number++ {
+		interest := round2(rest * rate)
+		part := payment
+		if terms.Method == models.LoanAnnuity {
+			part = round2(payment - interest)
+		}
+		if number == terms.TermMonths || part > rest {
+			part = rest
+		}
+		schedule = append(schedule, models.LoanInstallment{
+			Number:    number,
+			DueDate:   AddMonths(start, number),
+			Principal: part,
+			Interest:  interest,
+			Status:    models.InstallmentPending,
+		})
+		rest = round2(rest - part)
+	}
+	return schedule
+}
+
+// payInstallments распределяет amount по неоплаченным платежам со сроком не
+// позже day в порядке номеров: в каждом сначала пени и проценты, затем основной
+// долг. Возвращает израсходованную сумму.
+func payInstallments(schedule []models.LoanInstallment, day time.Time, amount float64) float64 {
+	spent := 0.0
+	for i := range schedule {
+		inst := &schedule[i]
+		if amount <= 0 || inst.DueDate.After(day) {
+			break
+		}
+		if inst.Status == models.InstallmentPaid {
+			continue
+		}
+		interest := min(amount, round2(inst.Interest+inst.LateFee-inst.PaidInterest))
+		inst.PaidInterest = round2(inst.PaidInterest + interest)
+		principal := min(round2(amount-interest), round2(inst.Principal-inst.PaidPrincipal))
+		inst.PaidPrincipal = round2(inst.PaidPrincipal + principal)
+		amount = round2(amount - interest - principal)
+		spent = round2(spent + interest + principal)
+		if inst.Due() <= 0 {
+			inst.Status = models.InstallmentPaid
+		}
+	}
+	return spent
+}
+
+// SettleLoan пересчитывает по графику остаток долга, просрочку и статус кредита.
+func SettleLoan(loan *models.Loan, schedule []models.LoanInstallment) {
+	outstanding, arrears := 0.0, 0.0
+	closed := true
+	for _, inst := range schedule {
+		outstanding += inst.Principal - inst.PaidPrincipal
+		if inst.Status == models.InstallmentOverdue {
+			arrears += inst.Due()
+		}
+		if inst.Status != models.InstallmentPaid {
+			closed = false
+		}
+	}
+	loan.Outstanding = round2(outstanding)
+	loan.Arrears = round2(arrears)
+	loan.Status = models.LoanActive
+	if closed {
+		loan.Status = models.LoanClosed
+	}
+}
+
+// CollectLoan списывает до available на наступившие к дню day платежи. Платёж,
+// оставшийся неоплаченным, становится просроченным, и по нему один раз
+// начисляются пени LateFee. Возвращает списанную сумму и число новых просрочек.
+func CollectLoan(loan *models.Loan, schedule []models.LoanInstallment, day time.Time, available float64) (paid float64, overdue int) {
+	paid = payInstallments(schedule, day, max(available, 0))
+	for i := range schedule {
+		inst := &schedule[i]
+		if inst.DueDate.After(day) {
+			break
+		}
+		if inst.Status == models.InstallmentPending {
+			inst.Status = models.InstallmentOverdue
+			inst.LateFee = loan.LateFee
+			overdue++
+		}
+	}
+	SettleLoan(loan, schedule)
+	return paid, overdue
+}
+
+// RepayLoanEarly вносит amount в счёт кредита: сначала гасятся наступившие к дню
+// day платежи, остаток уменьшает основной долг, и график будущих платежей
+// пересчитывается на прежний срок — уменьшается размер платежа. Проценты
+// текущего месяца считаются уже на новый остаток. Возвращает новый график.
+func RepayLoanEarly(loan *models.Loan, schedule []models.LoanInstallment, day time.Time, amount float64) ([]models.LoanInstallment, *models.LoanRepayment, error) {
+	if loan.Status == models.LoanClosed {
+		return nil, nil, ErrLoanClosed
+	}
+	debt := 0.0
+	for _, inst := range schedule {
+		if inst.DueDate.After(day) {
+			debt += inst.Principal - inst.PaidPrincipal
+		} else {
+			debt += inst.Due()
+		}
+	}
+	if round2(amount) > round2(debt) {
+		return nil, nil, ErrLoanOverpayment
+	}
+
+	repayment := &models.LoanRepayment{LoanID: loan.ID, Amount: amount}
+	repayment.Scheduled = payInstallments(schedule, day, amount)
+	repayment.Principal = round2(amount - repayment.Scheduled)
+	if repayment.Principal > 0 {
+		// Все наступившие платежи оплачены: будущие строятся заново на остаток
+		keep := 0
+		for keep < len(schedule) && !schedule[keep].DueDate.After(day) {
+			keep++
+		}
+		rest := 0.0
+		for _, inst := range schedule[keep:] {
+			rest += inst.Principal
+		}
+		schedule = append(schedule[:keep:keep],
+			LoanSchedule(loan.LoanTerms, loan.StartDate, round2(rest-repayment.Principal), keep+1)...)
+	}
+	SettleLoan(loan, schedule)
+	repayment.Outstanding = loan.Outstanding
+	repayment.Status = loan.Status
+	return schedule, repayment, nil
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// loanColumns — столбцы loans с номером счёта в порядке, который ожидает scanLoan.
+var loanColumns = `id, user_id, account_id, ` + accountNumber("loans.account_id") + `, principal, annual_rate, term_months, method, late_fee,
+	start_date, outstanding, arrears, status, created_at`
+
+func scanLoan(row interface{ Scan(...any) error }, l *models.Loan) error {
+	var start string
+	err := row.Scan(&l.ID, &l.UserID, &l.AccountID, &l.Account, &l.Principal, &l.AnnualRate, &l.TermMonths, &l.Method, &l.LateFee,
+		&start, &l.Outstanding, &l.Arrears, &l.Status, &l.CreatedAt)
+	if err != nil {
+		return err
+	}
+	l.StartDate, err = time.Parse(DayLayout, start)
+	return err
+}
+
+func (r *SQLLoanRepository) CreateLoan(ctx context.Context, loan *models.Loan, entry models.AuditEntry) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var frozen bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT frozen, COALESCE(number, '') FROM accounts WHERE id = $1 AND `+hasAccess("accounts.id", "$2", models.AccessTransact)+r.Dialect.forUpdate(),
+		loan.AccountID, loan.UserID).Scan(&frozen, &loan.Account)
+	if err != nil {
+		return err
+	}
+	if frozen {
+		return ErrAccountFrozen
+	}
+	loansID, err := systemAccount(ctx, tx, r.Dialect, models.SystemLoans)
+	if err != nil {
+		return err
+	}
+
+	schedule := LoanSchedule(loan.LoanTerms, loan.StartDate, loan.Principal, 1)
+	SettleLoan(loan, schedule)
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO loans (user_id, account_id, principal, annual_rate, term_months, method, late_fee, start_date, outstanding, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`,
+		loan.UserID, loan.AccountID, loan.Principal, loan.AnnualRate, loan.TermMonths, loan.Method, loan.LateFee,
+		loan.StartDate.Format(DayLayout), loan.Outstanding, loan.Status).Scan(&loan.ID, &loan.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := saveSchedule(ctx, tx, loan, schedule); err != nil {
+		return err
+	}
+	if err := moveLoanFunds(ctx, tx, models.KindLoanDisbursement, loansID, loan.AccountID, loan.Principal); err != nil {
+		return err
+	}
+	if err := insertAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// moveLoanFunds переводит amount между счётом банка и счётом заёмщика и записывает движение вида kind.
+func moveLoanFunds(ctx context.Context, tx *sql.Tx, kind string, fromID, toID int64, amount float64) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE accounts SET balance = ROUND(balance - $1, 2) WHERE id = $2`, amount, fromID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE accounts SET balance = ROUND(balance + $1, 2) WHERE id = $2`, amount, toID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO transactions (kind, from_account_id, to_account_id, amount)
+		VALUES ($1, $2, $3, $4)`,
+		kind, fromID, toID, amount)
+	return err
+}
+
+// saveSchedule заменяет график кредита и сохраняет пересчитанные по нему остаток долга, просрочку и статус.
+func saveSchedule(ctx context.Context, tx *sql.Tx, loan *models.Loan, schedule []models.LoanInstallment) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM loan_installments WHERE loan_id = $1`, loan.ID); err != nil {
+		return err
+	}
+	for _, inst := range schedule {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO loan_installments (loan_id, number, due_date, principal, interest, late_fee, paid_principal, paid_interest, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			loan.ID, inst.Number, inst.DueDate.Format(DayLayout), inst.Principal, inst.Interest, inst.LateFee,
+			inst.PaidPrincipal, inst.PaidInterest, inst.Status)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := tx.ExecContext(ctx, `UPDATE loans SET outstanding = $1, arrears = $2, status = $3 WHERE id = $4`,
+		loan.Outstanding, loan.Arrears, loan.Status, loan.ID)
+	return err
+}
+
+func listSchedule(ctx context.Context, q querier, loanID int64) ([]models.LoanInstallment, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT number, due_date, principal, interest, late_fee, paid_principal, paid_interest, status
+		FROM loan_installments
+		WHERE loan_id = $1
+		ORDER BY number`, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedule := []models.LoanInstallment{}
+	for rows.Next() {
+		var inst models.LoanInstallment
+		var due string
+		if err := rows.Scan(&inst.Number, &due, &inst.Principal, &inst.Interest, &inst.LateFee,
+			&inst.PaidPrincipal, &inst.PaidInterest, &inst.Status); err != nil {
+			return nil, err
+		}
+		if inst.DueDate, err = time.Parse(DayLayout, due); err != nil {
+			return nil, err
+		}
+		schedule = append(schedule, inst)
+	}
+	return schedule, rows.Err()
+}
+
+func (r *SQLLoanRepository) GetLoan(ctx context.Context, loanID, userID int64) (*models.Loan, error) {
+	var loan models.Loan
+	err := scanLoan(r.DB.QueryRowContext(ctx, `SELECT `+loanColumns+` FROM loans WHERE id = $1 AND user_id = $2`, loanID, userID), &loan)
+	if err != nil {
+		return nil, err
+	}
+	if loan.Schedule, err = listSchedule(ctx, r.DB, loanID); err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+func (r *SQLLoanRepository) ListLoans(ctx context.Context, userID int64) ([]models.Loan, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+loanColumns+` FROM loans WHERE user_id = $1 ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loans := []models.Loan{}
+	for rows.Next() {
+		var loan models.Loan
+		if err := scanLoan(rows, &loan); err != nil {
+			return nil, err
+		}
+		loans = append(loans, loan)
+	}
+	return loans, rows.Err()
+}
+
+func (r *SQLLoanRepository) RepayLoan(ctx context.Context, loanID, userID int64, amount float64, day time.Time) (*models.LoanRepayment, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var loan models.Loan
+	err = scanLoan(tx.QueryRowContext(ctx,
+		`SELECT `+loanColumns+` FROM loans WHERE id = $1 AND user_id = $2`+r.Dialect.forUpdate(), loanID, userID), &loan)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := listSchedule(ctx, tx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	schedule, repayment, err := RepayLoanEarly(&loan, schedule, day, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	var balance float64
+	var frozen bool
+	err = tx.QueryRowContext(ctx, `SELECT balance - held, frozen FROM accounts WHERE id = $1`+r.Dialect.forUpdate(), loan.AccountID).
+		Scan(&balance, &frozen)
+	if err != nil {
+		return nil, err
+	}
+	if frozen {
+		return nil, ErrAccountFrozen
+	}
+	if balance < amount {
+		return nil, ErrInsufficientFunds
+	}
+	loansID, err := systemAccount(ctx, tx, r.Dialect, models.SystemLoans)
+	if err != nil {
+		return nil, err
+	}
+	if err := moveLoanFunds(ctx, tx, models.KindLoanRepayment, loan.AccountID, loansID, amount); err != nil {
+		return nil, err
+	}
+	if err := saveSchedule(ctx, tx, &loan, schedule); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return repayment, nil
+}
+
+func (r *SQLLoanRepository) CollectLoanPayments(ctx context.Context, day time.Time) (*models.LoanCollection, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+loanColumns+` FROM loans
+		WHERE status = 'active' AND id IN (
+			SELECT loan_id FROM loan_installments WHERE due_date <= $1 AND status <> 'paid'
+		)
+		ORDER BY id`+r.Dialect.forUpdate(), day.Format(DayLayout))
+	if err != nil {
+		return nil, err
+	}
+	var loans []models.Loan
+	for rows.Next() {
+		var loan models.Loan
+		if err := scanLoan(rows, &loan); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		loans = append(loans, loan)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &models.LoanCollection{Day: day}
+	if len(loans) == 0 {
+		return report, nil
+	}
+	loansID, err := systemAccount(ctx, tx, r.Dialect, models.SystemLoans)
+	if err != nil {
+		return nil, err
+	}
+	for _, loan := range loans {
+		schedule, err := listSchedule(ctx, tx, loan.ID)
+		if err != nil {
+			return nil, err
+		}
+		var balance float64
+		var frozen bool
+		err = tx.QueryRowContext(ctx, `SELECT balance - held, frozen FROM accounts WHERE id = $1`+r.Dialect.forUpdate(), loan.AccountID).
+			Scan(&balance, &frozen)
+		if err != nil {
+			return nil, err
+		}
+		// С замороженного счёта ничего не списывается, платежи уходят в просрочку
+		if frozen {
+			balance = 0
+		}
+
+		paid, overdue := CollectLoan(&loan, schedule, day, balance)
+		if paid > 0 {
+			if err := moveLoanFunds(ctx, tx, models.KindLoanRepayment, loan.AccountID, loansID, paid); err != nil {
+				return nil, err
+			}
+			report.Loans++
+			report.Amount += paid
+		}
+		if err := saveSchedule(ctx, tx, &loan, schedule); err != nil {
+			return nil, err
+		}
+		report.Overdue += overdue
+		if loan.Status == models.LoanClosed {
+			report.Closed++
+		}
+	}
+	report.Amount = round2(report.Amount)
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,57 @@
+package repository_test
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAddMonths(t *testing.T) {
+	jan31 := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		months int
+		want   time.Time
+	}{
+		{1, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{2, time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)},
+		{13, time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := repository.AddMonths(jan31, tt.months); !got.Equal(tt.want) {
+			t.Errorf("AddMonths(%d) = %s, ожидалось %s", tt.months, got, tt.want)
+		}
+	}
+}
+
+func TestLoanSchedule(t *testing.T) {
+	start := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	linear := repository.LoanSchedule(models.LoanTerms{Principal: 1200, AnnualRate: 12, TermMonths: 12, Method: models.LoanLinear}, start, 1200, 1)
+	if len(linear) != 12 || linear[0].Principal != 100 || linear[0].Interest != 12 || linear[11].Principal != 100 || linear[11].Interest != 1 {
+		t.Errorf("дифференцированный график: %+v", linear)
+	}
+
+	annuity := repository.LoanSchedule(models.LoanTerms{Principal: 1000, AnnualRate: 18, TermMonths: 24, Method: models.LoanAnnuity}, start, 1000, 1)
+	if len(annuity) != 24 || !annuity[23].DueDate.Equal(time.Date(2027, time.March, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("аннуитетный график: %+v", annuity)
+	}
+	var principal float64
+	for _, inst := range annuity {
+		principal += inst.Principal
+		// Платёж одинаков, последний гасит накопившуюся ошибку округления
+		if payment := inst.Principal + inst.Interest; inst.Number < 24 && math.Round(payment*100) != 4992 || math.Abs(payment-49.92) > 0.5 {
+			t.Errorf("платёж %d = %.2f, ожидалось 49.92", inst.Number, payment)
+		}
+	}
+	if math.Round(principal*100) != 100000 {
+		t.Errorf("сумма основного долга = %.2f", principal)
+	}
+
+	// Беспроцентный кредит с пересчётом с середины срока
+	rest := repository.LoanSchedule(models.LoanTerms{TermMonths: 6, Method: models.LoanAnnuity}, start, 100, 4)
+	if len(rest) != 3 || rest[0].Number != 4 || rest[0].Principal != 33.33 || rest[2].Principal != 33.34 {
+		t.Errorf("пересчёт графика: %+v", rest)
+	}
+}
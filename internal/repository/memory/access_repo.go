@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+)
+
+type AccessRepository struct {
+	Store *Store
+}
+
+func NewAccessRepository(store *Store) *AccessRepository {
+	return &AccessRepository{Store: store}
+}
+
+// hasAccess сообщает, что у userID есть активный доступ к счёту не ниже level.
+func hasAccess(st *state, accountID, userID int64, level string) bool {
+	a, ok := st.access[accessKey{accountID, userID}]
+	return ok && a.Status == models.AccessActive && models.AccessAllows(a.Level, level)
+}
+
+// accessNames подставляет номер счёта, имена пользователя и пригласившего и признак владельца.
+func accessNames(st *state, a models.AccountAccess) models.AccountAccess {
+	a.Account = number(st, a.AccountID)
+	a.Username = st.users[a.UserID].Username
+	a.InvitedBy = st.users[a.InvitedByID].Username
+	a.Owner = st.accounts[a.AccountID].UserID == a.UserID
+	return a
+}
+
+func (r *AccessRepository) InviteAccess(ctx context.Context, access *models.AccountAccess) error {
+	return r.Store.update(ctx, func(st *state) error {
+		if !hasAccess(st, access.AccountID, access.InvitedByID, models.AccessManage) {
+			return sql.ErrNoRows
+		}
+		if _, ok := st.users[access.UserID]; !ok {
+			return sql.ErrNoRows
+		}
+		key := accessKey{access.AccountID, access.UserID}
+		if _, ok := st.access[key]; ok {
+			return repository.ErrDuplicate
+		}
+		access.Status = models.AccessPending
+		access.CreatedAt = r.Store.Now()
+		access.AcceptedAt = nil
+		st.access[key] = *access
+		*access = accessNames(st, *access)
+		return nil
+	})
+}
+
+func (r *AccessRepository) ListAccess(ctx context.Context, accountID, userID int64) ([]models.AccountAccess, error) {
+	access := []models.AccountAccess{}
+	err := r.Store.view(ctx, func(st *state) error {
+		if !hasAccess(st, accountID, userID, models.AccessView) {
+			return sql.ErrNoRows
+		}
+		for key, a := range st.access {
+			if key.accountID == accountID {
+				access = append(access, accessNames(st, a))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Владелец первым, остальные по ID пользователя
+	slices.SortFunc(access, func(a, b models.AccountAccess) int {
+		if a.Owner != b.Owner {
+			if a.Owner {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.UserID, b.UserID)
+	})
+	return access, nil
+}
+
+func (r *AccessRepository) ListInvitations(ctx context.Context, userID int64) ([]models.AccountAccess, error) {
+	invitations := []models.AccountAccess{}
+	err := r.Store.view(ctx, func(st *state) error {
+		for key, a := range st.access {
+			if key.userID == userID && a.Status == models.AccessPending {
+				invitations = append(invitations, accessNames(st, a))
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(invitations, func(a, b models.AccountAccess) int { return cmp.Compare(a.AccountID, b.AccountID) })
+	return invitations, err
+}
+
+func (r *AccessRepository) AcceptAccess(ctx context.Context, accountID, userID int64, now time.Time) (*models.AccountAccess, error) {
+	var access models.AccountAccess
+	err := r.Store.update(ctx, func(st *state) error {
+		key := accessKey{accountID, userID}
+		a, ok := st.access[key]
+		if !ok || a.Status != models.AccessPending {
+			return sql.ErrNoRows
+		}
+		a.Status = models.AccessActive
+		a.AcceptedAt = &now
+		st.access[key] = a
+		access = accessNames(st, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &access, nil
+}
+
+func (r *AccessRepository) RevokeAccess(ctx context.Context, accountID, userID, targetID int64) (*models.AccountAccess, error) {
+	var access models.AccountAccess
+	err := r.Store.update(ctx, func(st *state) error {
+		key := accessKey{accountID, targetID}
+		a, ok := st.access[key]
+		// Отозвать доступ может пользователь с доступом manage, отказаться — сам пользователь
+		if !ok || (userID != targetID && !hasAccess(st, accountID, userID, models.AccessManage)) {
+			return sql.ErrNoRows
+		}
+		access = accessNames(st, a)
+		if access.Owner {
+			return repository.ErrOwnerAccess
+		}
+		delete(st.access, key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &access, nil
+}
@@ -0,0 +1,370 @@
+package memory
+
+import (
+	"banking-api/internal/accountno"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type AccountRepository struct {
+	Store *Store
+}
+
+func NewAccountRepository(store *Store) *AccountRepository {
+	return &AccountRepository{Store: store}
+}
+
+func (r *AccountRepository) CreateAccount(ctx context.Context, account *models.Account) error {
+	return r.Store.update(ctx, func(st *state) error {
+		if _, ok := st.users[account.UserID]; !ok {
+			return sql.ErrNoRows
+		}
+		if account.Number == "" {
+			account.Number = newNumber(st)
+		}
+		for _, acc := range st.accounts {
+			if acc.Number == account.Number {
+				return repository.ErrDuplicate
+			}
+		}
+		st.lastAccountID++
+		account.ID = st.lastAccountID
+		if account.Type == "" {
+			account.Type = models.AccountChecking
+		}
+		account.Balance = 0
+		account.Held = 0
+		account.ApprovalThreshold = 0
+		account.CreditLimit = round2(account.CreditLimit)
+		account.CreatedAt = r.Store.Now()
+		st.accounts[account.ID] = *account
+		// Владелец получает полный доступ к счёту
+		st.access[accessKey{account.ID, account.UserID}] = models.AccountAccess{
+			AccountID:  account.ID,
+			UserID:     account.UserID,
+			Level:      models.AccessManage,
+			Status:     models.AccessActive,
+			CreatedAt:  account.CreatedAt,
+			AcceptedAt: &account.CreatedAt,
+		}
+		return nil
+	})
+}
+
+func (r *AccountRepository) TopUpAccount(ctx context.Context, accountID, userID int64, amount float64) error {
+	return r.Store.update(ctx, func(st *state) error {
+		acc, ok := st.accounts[accountID]
+		if !ok || !hasAccess(st, accountID, userID, models.AccessTransact) {
+			return sql.ErrNoRows
+		}
+		if acc.Frozen {
+			return repository.ErrAccountFrozen
+		}
+		acc.Balance = round2(acc.Balance + amount)
+		st.accounts[accountID] = acc
+		appendTransaction(st, models.Transaction{
+			Kind:        models.KindTopUp,
+			ToAccountID: accountID,
+			Amount:      round2(amount),
+			CreatedAt:   r.Store.Now(),
+		})
+		return nil
+	})
+}
+
+func (r *AccountRepository) TransferFunds(ctx context.Context, fromID, toID, userID int64, amount float64, idempotencyKey string) error {
+	return r.Store.update(ctx, func(st *state) error {
+		_, err := transfer(st, r.Store.Now(), fromID, toID, userID, amount, idempotencyKey)
+		return err
+	})
+}
+
+// transfer выполняет перевод по правилам TransferFunds и возвращает ID записанного движения.
+func transfer(st *state, now time.Time, fromID, toID, userID int64, amount float64, idempotencyKey string) (int64, error) {
+	return moveFunds(st, now, fromID, toID, userID, amount, idempotencyKey, false, false)
+}
+
+// approvedTransfer выполняет одобренный перевод: как transfer, но без
+// проверки порога одобрения.
+func approvedTransfer(st *state, now time.Time, fromID, toID, userID int64, amount float64, idempotencyKey string) (int64, error) {
+	return moveFunds(st, now, fromID, toID, userID, amount, idempotencyKey, true, false)
+}
+
+// heldTransfer списывает блокировку: как transfer, но получатель со
+// сберегательного счёта проверен при создании блокировки.
+func heldTransfer(st *state, now time.Time, fromID, toID, userID int64, amount float64) (int64, error) {
+	return moveFunds(st, now, fromID, toID, userID, amount, "", false, true)
+}
+
+func moveFunds(st *state, now time.Time, fromID, toID, userID int64, amount float64, idempotencyKey string, approved, held bool) (int64, error) {
+	// Проверка доступа и баланса
+	from, ok := st.accounts[fromID]
+	if !ok || !hasAccess(st, fromID, userID, models.AccessTransact) {
+		return 0, sql.ErrNoRows
+	}
+	key := transferKey{fromID: fromID, key: idempotencyKey}
+	if idempotencyKey != "" {
+		if i, ok := st.idempotency[key]; ok {
+			prev := st.transactions[i]
+			if prev.ToAccountID == toID && prev.Amount == round2(amount) {
+				return 0, repository.ErrAlreadyApplied
+			}
+			return 0, repository.ErrIdempotencyConflict
+		}
+	}
+	if !approved && from.NeedsApproval(amount) {
+		return 0, repository.ErrApprovalRequired
+	}
+	to, ok := st.accounts[toID]
+	if !ok || to.Type == models.AccountInternal {
+		return 0, sql.ErrNoRows
+	}
+	if from.Frozen || to.Frozen {
+		return 0, repository.ErrAccountFrozen
+	}
+	// Со сберегательного — только на счета, которыми userID распоряжается сам
+	if from.Type == models.AccountSavings && !held && !hasAccess(st, toID, userID, models.AccessTransact) {
+		return 0, repository.ErrSavingsExternalTransfer
+	}
+	if from.MonthlyTransferLimit > 0 {
+		count := 0
+		monthStart := repository.MonthStart(now)
+		for _, t := range st.transactions {
+			if t.FromAccountID == fromID && t.Kind == models.KindTransfer && !t.CreatedAt.Before(monthStart) {
+				count++
+			}
+		}
+		if count >= from.MonthlyTransferLimit {
+			return 0, repository.ErrTransferLimitExceeded
+		}
+	}
+	fee := from.OverdraftFeeFor(amount)
+	if from.Available() < amount+fee {
+		return 0, repository.ErrInsufficientFunds
+	}
+
+	// Списание
+	from.Balance = round2(from.Balance - amount - fee)
+	st.accounts[fromID] = from
+
+	// Зачисление
+	to = st.accounts[toID]
+	to.Balance = round2(to.Balance + amount)
+	st.accounts[toID] = to
+
+	// Запись в транзакции
+	transactionID := appendTransaction(st, models.Transaction{
+		Kind:          models.KindTransfer,
+		FromAccountID: fromID,
+		ToAccountID:   toID,
+		Amount:        round2(amount),
+		CreatedAt:     now,
+	}).ID
+	if idempotencyKey != "" {
+		st.idempotency[key] = len(st.transactions) - 1
+	}
+
+	// Комиссия за уход в овердрафт
+	if fee > 0 {
+		feesID := systemAccount(st, models.SystemFees, now)
+		fees := st.accounts[feesID]
+		fees.Balance = round2(fees.Balance + fee)
+		st.accounts[feesID] = fees
+
+		appendTransaction(st, models.Transaction{
+			Kind:          models.KindFee,
+			FromAccountID: fromID,
+			ToAccountID:   feesID,
+			Amount:        fee,
+			CreatedAt:     now,
+		})
+	}
+	return transactionID, nil
+}
+
+func (r *AccountRepository) GetAccount(ctx context.Context, accountID, userID int64) (*models.Account, error) {
+	var account models.Account
+	err := r.Store.view(ctx, func(st *state) error {
+		acc, ok := st.accounts[accountID]
+		if !ok || !hasAccess(st, accountID, userID, models.AccessView) {
+			return sql.ErrNoRows
+		}
+		account = acc
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *AccountRepository) SetLowBalanceThreshold(ctx context.Context, accountID, userID int64, threshold float64) error {
+	return r.Store.update(ctx, func(st *state) error {
+		acc, ok := st.accounts[accountID]
+		if !ok || !hasAccess(st, accountID, userID, models.AccessManage) {
+			return sql.ErrNoRows
+		}
+		acc.LowBalanceThreshold = round2(threshold)
+		st.accounts[accountID] = acc
+		return nil
+	})
+}
+
+func (r *AccountRepository) GetTransactions(ctx context.Context, accountID, userID int64, limit, offset int) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.Store.view(ctx, func(st *state) error {
+		if !hasAccess(st, accountID, userID, models.AccessView) {
+			return sql.ErrNoRows
+		}
+		transactions = accountTransactions(st, accountID, limit, offset)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// accountTransactions возвращает движения по счёту, начиная с последних.
+func accountTransactions(st *state, accountID int64, limit, offset int) []models.Transaction {
+	transactions := []models.Transaction{}
+	skipped := 0
+	for i := len(st.transactions) - 1; i >= 0 && len(transactions) < limit; i-- {
+		t := st.transactions[i]
+		if t.FromAccountID != accountID && t.ToAccountID != accountID {
+			continue
+		}
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions
+}
+
+func (r *AccountRepository) GetStatement(ctx context.Context, accountID, userID int64, from, to time.Time) (*models.Statement, error) {
+	from, to = from.UTC(), to.UTC()
+	statement := models.Statement{AccountID: accountID, From: from, To: to, Entries: []models.StatementEntry{}}
+	err := r.Store.view(ctx, func(st *state) error {
+		acc, ok := st.accounts[accountID]
+		if !ok || !hasAccess(st, accountID, userID, models.AccessView) {
+			return sql.ErrNoRows
+		}
+		statement.Account = acc.Number
+		statement.Owner = st.users[acc.UserID].Username
+
+		// Назначение и EndToEndId переводов из пакетов
+		items := map[int64]models.BatchItem{}
+		for _, batchItems := range st.batchItems {
+			for _, item := range batchItems {
+				if item.TransactionID != 0 {
+					items[item.TransactionID] = item
+				}
+			}
+		}
+		opening, closing := acc.Balance, acc.Balance
+		for _, t := range st.transactions {
+			var delta float64
+			switch accountID {
+			case t.ToAccountID:
+				delta = t.Amount
+			case t.FromAccountID:
+				delta = -t.Amount
+			default:
+				continue
+			}
+			if !t.CreatedAt.Before(from) {
+				opening -= delta
+			}
+			if !t.CreatedAt.Before(to) {
+				closing -= delta
+				continue
+			}
+			if !t.CreatedAt.Before(from) {
+				item := items[t.ID]
+				statement.Entries = append(statement.Entries, models.StatementEntry{Transaction: t, Reference: item.Reference, EndToEndID: item.EndToEndID})
+			}
+		}
+		statement.OpeningBalance, statement.ClosingBalance = round2(opening), round2(closing)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+func (r *AccountRepository) GetFirstAccountByUserID(ctx context.Context, userID int64) (int64, error) {
+	var accountID int64
+	err := r.Store.view(ctx, func(st *state) error {
+		for id, acc := range st.accounts {
+			if acc.UserID == userID && (accountID == 0 || id < accountID) {
+				accountID = id
+			}
+		}
+		if accountID == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+	return accountID, err
+}
+
+func (r *AccountRepository) GetAccountIDByNumber(ctx context.Context, number string) (int64, error) {
+	var accountID int64
+	err := r.Store.view(ctx, func(st *state) error {
+		for id, acc := range st.accounts {
+			if acc.Number == number {
+				accountID = id
+				return nil
+			}
+		}
+		return sql.ErrNoRows
+	})
+	return accountID, err
+}
+
+func (r *AccountRepository) GetUserIDByAccountID(ctx context.Context, accountID int64) (int64, error) {
+	var userID int64
+	err := r.Store.view(ctx, func(st *state) error {
+		acc, ok := st.accounts[accountID]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		userID = acc.UserID
+		return nil
+	})
+	return userID, err
+}
+
+// appendTransaction записывает движение t с новым ID и номерами счетов.
+func appendTransaction(st *state, t models.Transaction) models.Transaction {
+	st.lastTransactionID++
+	t.ID = st.lastTransactionID
+	t.FromAccount, t.ToAccount = number(st, t.FromAccountID), number(st, t.ToAccountID)
+	st.transactions = append(st.transactions, t)
+	return t
+}
+
+// number возвращает номер счёта accountID, пустой для несуществующего счёта.
+func number(st *state, accountID int64) string {
+	return st.accounts[accountID].Number
+}
+
+// newNumber возвращает номер по схеме accountno.Default, не занятый другими счетами.
+func newNumber(st *state) string {
+	for {
+		number := accountno.New()
+		taken := false
+		for _, acc := range st.accounts {
+			taken = taken || acc.Number == number
+		}
+		if !taken {
+			return number
+		}
+	}
+}
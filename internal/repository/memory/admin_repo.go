@@ -0,0 +1,244 @@
+package memory
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"math"
+	"slices"
+	"strings"
+)
+
+type AdminRepository struct {
+	Store *Store
+}
+
+func NewAdminRepository(store *Store) *AdminRepository {
+	return &AdminRepository{Store: store}
+}
+
+func (r *AdminRepository) GetAccountByID(ctx context.Context, accountID int64) (*models.Account, error) {
+	var account models.Account
+	err := r.Store.view(ctx, func(st *state) error {
+		acc, ok := st.accounts[accountID]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		account = acc
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *AdminRepository) ListAccountsByUserID(ctx context.Context, userID int64) ([]models.Account, error) {
+	accounts := []models.Account{}
+	err := r.Store.view(ctx, func(st *state) error {
+		for _, acc := range st.accounts {
+			if acc.UserID == userID {
+				accounts = append(accounts, acc)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(accounts, func(a, b models.Account) int { return int(a.ID - b.ID) })
+	return accounts, err
+}
+
+func (r *AdminRepository) ListTransactions(ctx context.Context, accountID int64, limit, offset int) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.Store.view(ctx, func(st *state) error {
+		if _, ok := st.accounts[accountID]; !ok {
+			return sql.ErrNoRows
+		}
+		transactions = accountTransactions(st, accountID, limit, offset)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+func (r *AdminRepository) SetAccountFrozen(ctx context.Context, accountID int64, frozen bool, entry models.AuditEntry) error {
+	return r.Store.update(ctx, func(st *state) error {
+		acc, ok := st.accounts[accountID]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		acc.Frozen = frozen
+		st.accounts[accountID] = acc
+		r.appendAudit(st, entry)
+		return nil
+	})
+}
+
+func (r *AdminRepository) AdjustBalance(ctx context.Context, accountID int64, amount float64, entry models.AuditEntry) (*models.Transaction, error) {
+	var t models.Transaction
+	err := r.Store.update(ctx, func(st *state) error {
+		acc, ok := st.accounts[accountID]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		if acc.Available()+amount < 0 {
+			return repository.ErrInsufficientFunds
+		}
+		acc.Balance = round2(acc.Balance + amount)
+		st.accounts[accountID] = acc
+
+		t = models.Transaction{
+			Kind:      models.KindAdjustment,
+			Amount:    round2(math.Abs(amount)),
+			CreatedAt: r.Store.Now(),
+		}
+		if amount > 0 {
+			t.ToAccountID = accountID
+		} else {
+			t.FromAccountID = accountID
+		}
+		t = appendTransaction(st, t)
+		r.appendAudit(st, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *AdminRepository) ReverseTransaction(ctx context.Context, transactionID int64, reasonCode string, entry models.AuditEntry) (*models.Transaction, error) {
+	var t models.Transaction
+	err := r.Store.update(ctx, func(st *state) error {
+		original, remaining, ok := findOriginal(st, transactionID)
+		if !ok {
+			return sql.ErrNoRows
+		}
+		if original.Kind != models.KindTransfer && original.Kind != models.KindFee {
+			return repository.ErrNotRefundable
+		}
+		amount, err := refundAmount(0, remaining)
+		if err != nil {
+			return err
+		}
+		t = moveBack(st, r.Store.Now(), original, models.KindReversal, amount, reasonCode)
+
+		entry.AccountID = original.FromAccountID
+		entry.Amount = amount
+		r.appendAudit(st, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *AdminRepository) SetOverdraft(ctx context.Context, accountID int64, overdraft models.Overdraft, entry models.AuditEntry) error {
+	return r.Store.update(ctx, func(st *state) error {
+		acc, ok := st.accounts[accountID]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		if acc.Type != models.AccountChecking {
+			return repository.ErrOverdraftNotAllowed
+		}
+		if acc.Balance+overdraft.Limit < 0 {
+			return repository.ErrOverdraftInUse
+		}
+		acc.OverdraftLimit = round2(overdraft.Limit)
+		acc.OverdraftRate = overdraft.Rate
+		acc.OverdraftFee = round2(overdraft.Fee)
+		st.accounts[accountID] = acc
+		r.appendAudit(st, entry)
+		return nil
+	})
+}
+
+func (r *AdminRepository) SetApprovalPolicy(ctx context.Context, accountID int64, threshold float64, approverIDs []int64, entry models.AuditEntry) error {
+	return r.Store.update(ctx, func(st *state) error {
+		acc, ok := st.accounts[accountID]
+		if !ok || acc.Type == models.AccountInternal {
+			return sql.ErrNoRows
+		}
+		acc.ApprovalThreshold = round2(threshold)
+		st.accounts[accountID] = acc
+		approvers := []int64{}
+		for _, userID := range approverIDs {
+			if !slices.Contains(approvers, userID) {
+				approvers = append(approvers, userID)
+			}
+		}
+		st.approvers[accountID] = approvers
+		r.appendAudit(st, entry)
+		return nil
+	})
+}
+
+func (r *AdminRepository) appendAudit(st *state, entry models.AuditEntry) {
+	st.lastAuditID++
+	entry.ID = st.lastAuditID
+	entry.Account = number(st, entry.AccountID)
+	entry.Amount = round2(entry.Amount)
+	entry.CreatedAt = r.Store.Now()
+	st.audit = append(st.audit, entry)
+}
+
+func (r *AdminRepository) ListAuditLog(ctx context.Context, accountID int64, limit int) ([]models.AuditEntry, error) {
+	entries := []models.AuditEntry{}
+	err := r.Store.view(ctx, func(st *state) error {
+		for i := len(st.audit) - 1; i >= 0 && len(entries) < limit; i-- {
+			if accountID == 0 || st.audit[i].AccountID == accountID {
+				entries = append(entries, st.audit[i])
+			}
+		}
+		return nil
+	})
+	return entries, err
+}
+
+func (r *AdminRepository) Reconcile(ctx context.Context) (*models.Reconciliation, error) {
+	report := &models.Reconciliation{NegativeBalances: []string{}, InvalidTransactions: []int64{}, BalanceMismatches: []models.BalanceMismatch{}}
+	err := r.Store.view(ctx, func(st *state) error {
+		report.Accounts = len(st.accounts)
+		report.Transactions = len(st.transactions)
+		expected := map[int64]float64{}
+		for _, t := range st.transactions {
+			if t.Amount <= 0 || !slices.Contains(models.TransactionKinds, t.Kind) {
+				report.InvalidTransactions = append(report.InvalidTransactions, t.ID)
+			}
+			expected[t.ToAccountID] += t.Amount
+			expected[t.FromAccountID] -= t.Amount
+			// Движения без второго счёта: пополнения, корректировки и движения удалённых счетов
+			switch {
+			case t.FromAccountID == 0 && t.ToAccountID != 0:
+				report.ExternalNet += t.Amount
+			case t.ToAccountID == 0 && t.FromAccountID != 0:
+				report.ExternalNet -= t.Amount
+			}
+		}
+		for id, acc := range st.accounts {
+			report.TotalBalance += acc.Balance
+			if acc.Balance+acc.CreditLimit+acc.OverdraftLimit < 0 && acc.Type != models.AccountInternal {
+				report.NegativeBalances = append(report.NegativeBalances, acc.Number)
+			}
+			if want := round2(expected[id]); round2(acc.Balance-want) != 0 {
+				report.BalanceMismatches = append(report.BalanceMismatches, models.BalanceMismatch{
+					AccountID: id, Account: acc.Number, Balance: acc.Balance, Expected: want, Difference: round2(acc.Balance - want),
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.TotalBalance = round2(report.TotalBalance)
+	report.ExternalNet = round2(report.ExternalNet)
+	report.Imbalance = round2(report.TotalBalance - report.ExternalNet)
+	slices.Sort(report.NegativeBalances)
+	slices.SortFunc(report.BalanceMismatches, func(a, b models.BalanceMismatch) int { return strings.Compare(a.Account, b.Account) })
+	return report, nil
+}
@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+)
+
+type ApprovalRepository struct {
+	Store *Store
+}
+
+func NewApprovalRepository(store *Store) *ApprovalRepository {
+	return &ApprovalRepository{Store: store}
+}
+
+func (r *ApprovalRepository) CreateApproval(ctx context.Context, approval *models.TransferApproval) error {
+	return r.Store.update(ctx, func(st *state) error {
+		if !hasAccess(st, approval.FromAccountID, approval.MakerID, models.AccessTransact) {
+			return sql.ErrNoRows
+		}
+		if to, ok := st.accounts[approval.ToAccountID]; !ok || to.Type == models.AccountInternal {
+			return sql.ErrNoRows
+		}
+		if approval.IdempotencyKey != "" {
+			for _, a := range st.approvals {
+				if a.FromAccountID == approval.FromAccountID && a.IdempotencyKey == approval.IdempotencyKey {
+					return repository.ErrDuplicate
+				}
+			}
+		}
+		st.lastApprovalID++
+		approval.ID = st.lastApprovalID
+		approval.Amount = round2(approval.Amount)
+		approval.CheckerID = 0
+		approval.Status = models.ApprovalPending
+		approval.Reason = ""
+		approval.TransactionID = 0
+		approval.ResolvedAt = nil
+		approval.CreatedAt = r.Store.Now()
+		st.approvals[approval.ID] = *approval
+		*approval = approvalNames(st, *approval)
+		return nil
+	})
+}
+
+// approvalNames подставляет номера счетов и имена автора и одобряющего перевода.
+func approvalNames(st *state, a models.TransferApproval) models.TransferApproval {
+	a.FromAccount, a.ToAccount = number(st, a.FromAccountID), number(st, a.ToAccountID)
+	a.Maker = st.users[a.MakerID].Username
+	a.Checker = st.users[a.CheckerID].Username
+	return a
+}
+
+// seesApproval сообщает, что userID — автор перевода или одобряющий счёта списания.
+func seesApproval(st *state, a models.TransferApproval, userID int64) bool {
+	return a.MakerID == userID || slices.Contains(st.approvers[a.FromAccountID], userID)
+}
+
+func (r *ApprovalRepository) GetApprovalByKey(ctx context.Context, fromAccountID int64, idempotencyKey string) (*models.TransferApproval, error) {
+	var approval models.TransferApproval
+	err := r.Store.view(ctx, func(st *state) error {
+		for _, a := range st.approvals {
+			if a.FromAccountID == fromAccountID && a.IdempotencyKey == idempotencyKey {
+				approval = approvalNames(st, a)
+				return nil
+			}
+		}
+		return sql.ErrNoRows
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+func (r *ApprovalRepository) GetApproval(ctx context.Context, approvalID, userID int64) (*models.TransferApproval, error) {
+	var approval models.TransferApproval
+	err := r.Store.view(ctx, func(st *state) error {
+		a, ok := st.approvals[approvalID]
+		if !ok || !seesApproval(st, a, userID) {
+			return sql.ErrNoRows
+		}
+		approval = approvalNames(st, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+func (r *ApprovalRepository) ListApprovals(ctx context.Context, userID int64, status string) ([]models.TransferApproval, error) {
+	approvals := []models.TransferApproval{}
+	err := r.Store.view(ctx, func(st *state) error {
+		for _, a := range st.approvals {
+			if seesApproval(st, a, userID) && (status == "" || a.Status == status) {
+				approvals = append(approvals, approvalNames(st, a))
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(approvals, func(a, b models.TransferApproval) int { return int(b.ID - a.ID) })
+	return approvals, err
+}
+
+func (r *ApprovalRepository) Approvers(ctx context.Context, accountID int64) ([]int64, error) {
+	var ids []int64
+	err := r.Store.view(ctx, func(st *state) error {
+		ids = slices.Clone(st.approvers[accountID])
+		return nil
+	})
+	slices.Sort(ids)
+	return ids, err
+}
+
+// pendingApproval возвращает перевод approvalID, который может рассмотреть
+// checkerID, если он ещё ждёт решения.
+func pendingApproval(st *state, approvalID, checkerID int64, now time.Time) (models.TransferApproval, error) {
+	a, ok := st.approvals[approvalID]
+	if !ok || a.MakerID == checkerID || !slices.Contains(st.approvers[a.FromAccountID], checkerID) {
+		return a, sql.ErrNoRows
+	}
+	if a.Status != models.ApprovalPending || !a.ExpiresAt.After(now) {
+		return a, repository.ErrApprovalClosed
+	}
+	return a, nil
+}
+
+func (r *ApprovalRepository) ApproveTransfer(ctx context.Context, approvalID, checkerID int64, now time.Time) (*models.TransferApproval, error) {
+	var approval models.TransferApproval
+	err := r.Store.update(ctx, func(st *state) error {
+		a, err := pendingApproval(st, approvalID, checkerID, now)
+		if err != nil {
+			return err
+		}
+		a.TransactionID, err = approvedTransfer(st, r.Store.Now(), a.FromAccountID, a.ToAccountID, a.MakerID, a.Amount, a.IdempotencyKey)
+		if err != nil {
+			return err
+		}
+		a.Status = models.ApprovalApproved
+		a.CheckerID = checkerID
+		a.ResolvedAt = &now
+		st.approvals[approvalID] = a
+		approval = approvalNames(st, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+func (r *ApprovalRepository) RejectTransfer(ctx context.Context, approvalID, checkerID int64, reason string, now time.Time) (*models.TransferApproval, error) {
+	var approval models.TransferApproval
+	err := r.Store.update(ctx, func(st *state) error {
+		a, err := pendingApproval(st, approvalID, checkerID, now)
+		if err != nil {
+			return err
+		}
+		a.Status = models.ApprovalRejected
+		a.CheckerID = checkerID
+		a.Reason = reason
+		a.ResolvedAt = &now
+		st.approvals[approvalID] = a
+		approval = approvalNames(st, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+func (r *ApprovalRepository) ExpireApprovals(ctx context.Context, now time.Time) ([]models.TransferApproval, error) {
+	expired := []models.TransferApproval{}
+	err := r.Store.update(ctx, func(st *state) error {
+		for id, a := range st.approvals {
+			if a.Status != models.ApprovalPending || a.ExpiresAt.After(now) {
+				continue
+			}
+			a.Status = models.ApprovalExpired
+			a.ResolvedAt = &now
+			st.approvals[id] = a
+			expired = append(expired, approvalNames(st, a))
+		}
+		return nil
+	})
+	slices.SortFunc(expired, func(a, b models.TransferApproval) int { return int(a.ID - b.ID) })
+	return expired, err
+}
@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+)
+
+type BatchRepository struct {
+	Store *Store
+}
+
+func NewBatchRepository(store *Store) *BatchRepository {
+	return &BatchRepository{Store: store}
+}
+
+func (r *BatchRepository) CreateBatch(ctx context.Context, batch *models.Batch) error {
+	return r.CreateBatches(ctx, []*models.Batch{batch})
+}
+
+func (r *BatchRepository) CreateBatches(ctx context.Context, batches []*models.Batch) error {
+	return r.Store.update(ctx, func(st *state) error {
+		for _, batch := range batches {
+			if err := r.insertBatch(st, batch); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// insertBatch сохраняет пакет со строками в состоянии st.
+func (r *BatchRepository) insertBatch(st *state, batch *models.Batch) error {
+	if !hasAccess(st, batch.FromAccountID, batch.UserID, models.AccessTransact) {
+		return sql.ErrNoRows
+	}
+	if batch.MessageID != "" {
+		for _, b := range st.batches {
+			if b.UserID == batch.UserID && b.MessageID == batch.MessageID && b.PaymentInfoID == batch.PaymentInfoID {
+				return repository.ErrDuplicate
+			}
+		}
+	}
+	st.lastBatchID++
+	batch.ID = st.lastBatchID
+	batch.FromAccount = number(st, batch.FromAccountID)
+	batch.Status = models.BatchPending
+	batch.CreatedAt = r.Store.Now()
+	batch.CompletedAt = nil
+	items := make([]models.BatchItem, len(batch.Items))
+	for i, item := range batch.Items {
+		if _, ok := st.accounts[item.ToAccountID]; !ok {
+			return sql.ErrNoRows
+		}
+		item.ToAccount = number(st, item.ToAccountID)
+		item.Amount = round2(item.Amount)
+		item.Status = models.BatchItemPending
+		item.Error = ""
+		item.TransactionID = 0
+		items[i] = item
+	}
+	batch.Items = items
+	batch.Tally()
+
+	stored := *batch
+	stored.Items = nil
+	st.batches[batch.ID] = stored
+	st.batchItems[batch.ID] = slices.Clone(items)
+	return nil
+}
+
+// loadBatch собирает пакет со строками и сводкой.
+func loadBatch(st *state, batchID int64) (models.Batch, bool) {
+	b, ok := st.batches[batchID]
+	if !ok {
+		return b, false
+	}
+	b.Items = slices.Clone(st.batchItems[batchID])
+	b.Tally()
+	return b, true
+}
+
+func (r *BatchRepository) GetBatch(ctx context.Context, batchID, userID int64) (*models.Batch, error) {
+	var batch models.Batch
+	err := r.Store.view(ctx, func(st *state) error {
+		b, ok := loadBatch(st, batchID)
+		if !ok || b.UserID != userID {
+			return sql.ErrNoRows
+		}
+		batch = b
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *BatchRepository) ListBatches(ctx context.Context, userID int64) ([]models.Batch, error) {
+	batches := []models.Batch{}
+	err := r.Store.view(ctx, func(st *state) error {
+		for id, b := range st.batches {
+			if b.UserID != userID {
+				continue
+			}
+			b, _ = loadBatch(st, id)
+			b.Items = nil
+			batches = append(batches, b)
+		}
+		return nil
+	})
+	slices.SortFunc(batches, func(a, b models.Batch) int { return int(b.ID - a.ID) })
+	return batches, err
+}
+
+func (r *BatchRepository) PendingBatches(ctx context.Context) ([]int64, error) {
+	ids := []int64{}
+	err := r.Store.view(ctx, func(st *state) error {
+		for id, b := range st.batches {
+			if b.Status == models.BatchPending {
+				ids = append(ids, id)
+			}
+		}
+		return nil
+	})
+	slices.Sort(ids)
+	return ids, err
+}
+
+func (r *BatchRepository) ExecuteBatch(ctx context.Context, batchID int64, now time.Time) (*models.Batch, error) {
+	var batch models.Batch
+	err := r.Store.update(ctx, func(st *state) error {
+		b, ok := st.batches[batchID]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		if b.Status != models.BatchPending {
+			return repository.ErrBatchClosed
+		}
+		// Строки изменяются только заменой среза целиком
+		items := slices.Clone(st.batchItems[batchID])
+		if b.AllOrNothing {
+			b.Status = executeAll(st, r.Store.Now(), b, items)
+		} else {
+			executeEach(st, r.Store.Now(), b, items)
+			b.Status = models.BatchCompleted
+		}
+		b.CompletedAt = &now
+		st.batches[batchID] = b
+		st.batchItems[batchID] = items
+		batch, _ = loadBatch(st, batchID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// executeAll выполняет пакет all_or_nothing и возвращает его статус. При
+// отказе в строке проведённые переводы откатываются к снимку состояния.
+func executeAll(st *state, now time.Time, b models.Batch, items []models.BatchItem) string {
+	snapshot := st.clone()
+	for i := range items {
+		transactionID, err := transfer(st, now, b.FromAccountID, items[i].ToAccountID, b.UserID, items[i].Amount, "")
+		if err == nil {
+			items[i].Status = models.BatchItemSucceeded
+			items[i].TransactionID = transactionID
+			continue
+		}
+		*st = *snapshot
+		reason, _ := repository.TransferRejection(err)
+		for j := range items {
+			items[j].Status = models.BatchItemSkipped
+			items[j].TransactionID = 0
+		}
+		items[i].Status = models.BatchItemFailed
+		items[i].Error = reason
+		return models.BatchFailed
+	}
+	return models.BatchCompleted
+}
+
+// executeEach выполняет строки обычного пакета независимо друг от друга.
+func executeEach(st *state, now time.Time, b models.Batch, items []models.BatchItem) {
+	for i := range items {
+		if items[i].Status != models.BatchItemPending {
+			continue
+		}
+		transactionID, err := transfer(st, now, b.FromAccountID, items[i].ToAccountID, b.UserID, items[i].Amount, "")
+		if err != nil {
+			reason, _ := repository.TransferRejection(err)
+			items[i].Status = models.BatchItemFailed
+			items[i].Error = reason
+			continue
+		}
+		items[i].Status = models.BatchItemSucceeded
+		items[i].TransactionID = transactionID
+	}
+}
@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+)
+
+type HoldRepository struct {
+	Store *Store
+}
+
+func NewHoldRepository(store *Store) *HoldRepository {
+	return &HoldRepository{Store: store}
+}
+
+func (r *HoldRepository) CreateHold(ctx context.Context, hold *models.Hold, userID int64) error {
+	return r.Store.update(ctx, func(st *state) error {
+		from, ok := st.accounts[hold.AccountID]
+		if !ok || !hasAccess(st, hold.AccountID, userID, models.AccessTransact) {
+			return sql.ErrNoRows
+		}
+		to, ok := st.accounts[hold.ToAccountID]
+		if !ok || to.Type == models.AccountInternal {
+			return sql.ErrNoRows
+		}
+		if from.Frozen || to.Frozen {
+			return repository.ErrAccountFrozen
+		}
+		if from.Type == models.AccountSavings && !hasAccess(st, hold.ToAccountID, userID, models.AccessTransact) {
+			return repository.ErrSavingsExternalTransfer
+		}
+		amount := round2(hold.Amount)
+		// Списание блокировки не проверяет порог одобрения, поэтому он проверяется здесь
+		if from.NeedsApproval(amount) {
+			return repository.ErrApprovalRequired
+		}
+		if from.Available() < amount {
+			return repository.ErrInsufficientFunds
+		}
+		from.Held = round2(from.Held + amount)
+		st.accounts[hold.AccountID] = from
+
+		st.lastHoldID++
+		hold.ID = st.lastHoldID
+		hold.Account, hold.ToAccount = from.Number, to.Number
+		hold.Amount = amount
+		hold.CapturedAmount = 0
+		hold.Status = models.HoldActive
+		hold.TransactionID = 0
+		hold.ResolvedAt = nil
+		hold.CreatedAt = r.Store.Now()
+		st.holds[hold.ID] = *hold
+		return nil
+	})
+}
+
+// visible сообщает, есть ли у userID доступ view к счёту блокировки или счёту-получателю.
+func visible(st *state, hold models.Hold, userID int64) bool {
+	return hasAccess(st, hold.AccountID, userID, models.AccessView) || hasAccess(st, hold.ToAccountID, userID, models.AccessView)
+}
+
+func (r *HoldRepository) GetHold(ctx context.Context, holdID, userID int64) (*models.Hold, error) {
+	var hold models.Hold
+	err := r.Store.view(ctx, func(st *state) error {
+		h, ok := st.holds[holdID]
+		if !ok || !visible(st, h, userID) {
+			return sql.ErrNoRows
+		}
+		hold = h
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *HoldRepository) ListHolds(ctx context.Context, userID int64, status string) ([]models.Hold, error) {
+	holds := []models.Hold{}
+	err := r.Store.view(ctx, func(st *state) error {
+		for _, h := range st.holds {
+			if visible(st, h, userID) && (status == "" || h.Status == status) {
+				holds = append(holds, h)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(holds, func(a, b models.Hold) int { return int(b.ID - a.ID) })
+	return holds, err
+}
+
+// active возвращает блокировку holdID в пользу счёта с доступом transact у
+// userID, если её ещё можно закрыть, и освобождает заблокированную сумму.
+func active(st *state, holdID, userID int64, now time.Time) (models.Hold, error) {
+	h, ok := st.holds[holdID]
+	if !ok || !hasAccess(st, h.ToAccountID, userID, models.AccessTransact) {
+		return h, sql.ErrNoRows
+	}
+	if h.Status != models.HoldActive || !h.ExpiresAt.After(now) {
+		return h, repository.ErrHoldClosed
+	}
+	releaseHeld(st, h)
+	return h, nil
+}
+
+// releaseHeld уменьшает заблокированную сумму счёта блокировки.
+func releaseHeld(st *state, h models.Hold) {
+	acc := st.accounts[h.AccountID]
+	acc.Held = round2(acc.Held - h.Amount)
+	st.accounts[h.AccountID] = acc
+}
+
+func (r *HoldRepository) CaptureHold(ctx context.Context, holdID, userID int64, amount float64, now time.Time) (*models.Hold, error) {
+	var hold models.Hold
+	err := r.Store.update(ctx, func(st *state) error {
+		h, err := active(st, holdID, userID, now)
+		if err != nil {
+			return err
+		}
+		amount = round2(amount)
+		if amount > h.Amount {
+			return repository.ErrCaptureExceedsHold
+		}
+		payerID := st.accounts[h.AccountID].UserID
+		h.TransactionID, err = heldTransfer(st, r.Store.Now(), h.AccountID, h.ToAccountID, payerID, amount)
+		if err != nil {
+			return err
+		}
+		h.Status = models.HoldCaptured
+		h.CapturedAmount = amount
+		h.ResolvedAt = &now
+		st.holds[holdID] = h
+		hold = h
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *HoldRepository) ReleaseHold(ctx context.Context, holdID, userID int64, now time.Time) (*models.Hold, error) {
+	var hold models.Hold
+	err := r.Store.update(ctx, func(st *state) error {
+		h, err := active(st, holdID, userID, now)
+		if err != nil {
+			return err
+		}
+		h.Status = models.HoldReleased
+		h.ResolvedAt = &now
+		st.holds[holdID] = h
+		hold = h
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *HoldRepository) ExpireHolds(ctx context.Context, now time.Time) ([]models.Hold, error) {
+	expired := []models.Hold{}
+	err := r.Store.update(ctx, func(st *state) error {
+		for id, h := range st.holds {
+			if h.Status != models.HoldActive || h.ExpiresAt.After(now) {
+				continue
+			}
+			releaseHeld(st, h)
+			h.Status = models.HoldExpired
+			h.ResolvedAt = &now
+			st.holds[id] = h
+			expired = append(expired, h)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(expired, func(a, b models.Hold) int { return int(a.ID - b.ID) })
+	return expired, nil
+}
@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"math"
+	"slices"
+	"time"
+)
+
+type InterestRepository struct {
+	Store *Store
+}
+
+func NewInterestRepository(store *Store) *InterestRepository {
+	return &InterestRepository{Store: store}
+}
+
+func (r *InterestRepository) SetRate(ctx context.Context, rate models.InterestRate, entry models.AuditEntry) error {
+	return r.Store.update(ctx, func(st *state) error {
+		st.rates[rateKey{accountType: rate.AccountType, from: rate.EffectiveFrom}] = rate
+		(&AdminRepository{Store: r.Store}).appendAudit(st, entry)
+		return nil
+	})
+}
+
+func (r *InterestRepository) ListRates(ctx context.Context) ([]models.InterestRate, error) {
+	rates := []models.InterestRate{}
+	err := r.Store.view(ctx, func(st *state) error {
+		for _, rate := range st.rates {
+			rates = append(rates, rate)
+		}
+		return nil
+	})
+	slices.SortFunc(rates, func(a, b models.InterestRate) int {
+		if a.AccountType != b.AccountType {
+			if a.AccountType < b.AccountType {
+				return -1
+			}
+			return 1
+		}
+		return a.EffectiveFrom.Compare(b.EffectiveFrom)
+	})
+	return rates, err
+}
+
+func (r *InterestRepository) AccrueInterest(ctx context.Context, day time.Time) (int, error) {
+	created := 0
+	err := r.Store.update(ctx, func(st *state) error {
+		rates := make([]models.InterestRate, 0, len(st.rates))
+		for _, rate := range st.rates {
+			rates = append(rates, rate)
+		}
+		dayRates := repository.RatesOn(rates, day)
+		end := day.AddDate(0, 0, 1)
+
+		for id, acc := range st.accounts {
+			if acc.Type == models.AccountInternal || !acc.CreatedAt.Before(end) {
+				continue
+			}
+			key := accrualKey{accountID: id, day: day}
+			if _, ok := st.accruals[key]; ok {
+				continue
+			}
+			// Остаток на конец дня: текущий баланс без движений после конца дня
+			balance := acc.Balance
+			for _, t := range st.transactions {
+				if t.CreatedAt.Before(end) {
+					continue
+				}
+				if t.ToAccountID == id {
+					balance -= t.Amount
+				} else if t.FromAccountID == id {
+					balance += t.Amount
+				}
+			}
+			balance = round2(balance)
+			rate := repository.AccrualRate(balance, dayRates[acc.Type], acc.OverdraftRate)
+			if rate <= 0 {
+				continue
+			}
+			st.accruals[key] = models.InterestAccrual{
+				AccountID:  id,
+				Account:    acc.Number,
+				Day:        day,
+				Balance:    balance,
+				AnnualRate: rate,
+				Amount:     repository.DailyInterest(balance, rate),
+			}
+			created++
+		}
+		return nil
+	})
+	return created, err
+}
+
+func (r *InterestRepository) CapitalizeInterest(ctx context.Context, month time.Time) (*models.Capitalization, error) {
+	report := &models.Capitalization{Month: month}
+	err := r.Store.update(ctx, func(st *state) error {
+		interestID := systemAccount(st, models.SystemInterest, r.Store.Now())
+		next := month.AddDate(0, 1, 0)
+
+		sums := make(map[int64]float64)
+		for key, a := range st.accruals {
+			if a.Capitalized || key.day.Before(month) || !key.day.Before(next) {
+				continue
+			}
+			sums[key.accountID] += a.Amount
+			a.Capitalized = true
+			st.accruals[key] = a
+		}
+
+		ids := make([]int64, 0, len(sums))
+		for id := range sums {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+		for _, id := range ids {
+			amount := round2(sums[id])
+			if amount == 0 {
+				continue
+			}
+			bank := st.accounts[interestID]
+			bank.Balance = round2(bank.Balance - amount)
+			st.accounts[interestID] = bank
+			acc := st.accounts[id]
+			acc.Balance = round2(acc.Balance + amount)
+			st.accounts[id] = acc
+
+			// Проценты по овердрафту списываются со счёта клиента на счёт банка
+			from, to := interestID, id
+			if amount < 0 {
+				from, to = to, from
+			}
+			appendTransaction(st, models.Transaction{
+				Kind:          models.KindInterest,
+				FromAccountID: from,
+				ToAccountID:   to,
+				Amount:        math.Abs(amount),
+				CreatedAt:     r.Store.Now(),
+			})
+			report.Accounts++
+			report.Total += amount
+		}
+		report.Total = round2(report.Total)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// systemAccount возвращает счёт банка name, создавая его при первом обращении.
+func systemAccount(st *state, name string, now time.Time) int64 {
+	if id, ok := st.systemAccounts[name]; ok {
+		return id
+	}
+	st.lastAccountID++
+	st.accounts[st.lastAccountID] = models.Account{
+		ID:        st.lastAccountID,
+		Number:    newNumber(st),
+		Type:      models.AccountInternal,
+		CreatedAt: now,
+	}
+	st.systemAccounts[name] = st.lastAccountID
+	return st.lastAccountID
+}
+
+func (r *InterestRepository) ListAccruals(ctx context.Context, accountID int64, from, to time.Time) ([]models.InterestAccrual, error) {
+	accruals := []models.InterestAccrual{}
+	err := r.Store.view(ctx, func(st *state) error {
+		for key, a := range st.accruals {
+			if key.accountID == accountID && !key.day.Before(from) && key.day.Before(to) {
+				accruals = append(accruals, a)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(accruals, func(a, b models.InterestAccrual) int { return a.Day.Compare(b.Day) })
+	return accruals, err
+}
+
+func (r *InterestRepository) LastAccrualDay(ctx context.Context) (time.Time, error) {
+	var last time.Time
+	err := r.Store.view(ctx, func(st *state) error {
+		for key := range st.accruals {
+			if key.day.After(last) {
+				last = key.day
+			}
+		}
+		return nil
+	})
+	return last, err
+}
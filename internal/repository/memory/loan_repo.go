@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+)
+
+type LoanRepository struct {
+	Store *Store
+}
+
+func NewLoanRepository(store *Store) *LoanRepository {
+	return &LoanRepository{Store: store}
+}
+
+func (r *LoanRepository) CreateLoan(ctx context.Context, loan *models.Loan, entry models.AuditEntry) error {
+	return r.Store.update(ctx, func(st *state) error {
+		acc, ok := st.accounts[loan.AccountID]
+		if !ok || !hasAccess(st, loan.AccountID, loan.UserID, models.AccessTransact) || acc.Type == models.AccountInternal {
+			return sql.ErrNoRows
+		}
+		if acc.Frozen {
+			return repository.ErrAccountFrozen
+		}
+		loansID := systemAccount(st, models.SystemLoans, r.Store.Now())
+
+		st.lastLoanID++
+		loan.ID = st.lastLoanID
+		loan.Account = acc.Number
+		loan.CreatedAt = r.Store.Now()
+		schedule := repository.LoanSchedule(loan.LoanTerms, loan.StartDate, loan.Principal, 1)
+		repository.SettleLoan(loan, schedule)
+		saveSchedule(st, loan, schedule)
+		r.moveFunds(st, models.KindLoanDisbursement, loansID, loan.AccountID, loan.Principal)
+		(&AdminRepository{Store: r.Store}).appendAudit(st, entry)
+		return nil
+	})
+}
+
+// moveFunds переводит amount между счётом банка и счётом заёмщика и записывает движение вида kind.
+func (r *LoanRepository) moveFunds(st *state, kind string, fromID, toID int64, amount float64) {
+	from := st.accounts[fromID]
+	from.Balance = round2(from.Balance - amount)
+	st.accounts[fromID] = from
+	to := st.accounts[toID]
+	to.Balance = round2(to.Balance + amount)
+	st.accounts[toID] = to
+
+	appendTransaction(st, models.Transaction{
+		Kind:          kind,
+		FromAccountID: fromID,
+		ToAccountID:   toID,
+		Amount:        amount,
+		CreatedAt:     r.Store.Now(),
+	})
+}
+
+// saveSchedule заменяет график кредита и сохраняет пересчитанный по нему кредит.
+func saveSchedule(st *state, loan *models.Loan, schedule []models.LoanInstallment) {
+	stored := *loan
+	stored.Schedule = nil
+	st.loans[loan.ID] = stored
+	st.schedules[loan.ID] = schedule
+	*loan = stored
+}
+
+func (r *LoanRepository) GetLoan(ctx context.Context, loanID, userID int64) (*models.Loan, error) {
+	var loan models.Loan
+	err := r.Store.view(ctx, func(st *state) error {
+		l, ok := st.loans[loanID]
+		if !ok || l.UserID != userID {
+			return sql.ErrNoRows
+		}
+		loan = l
+		loan.Schedule = slices.Clone(st.schedules[loanID])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+func (r *LoanRepository) ListLoans(ctx context.Context, userID int64) ([]models.Loan, error) {
+	loans := []models.Loan{}
+	err := r.Store.view(ctx, func(st *state) error {
+		for _, loan := range st.loans {
+			if loan.UserID == userID {
+				loans = append(loans, loan)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(loans, func(a, b models.Loan) int { return int(a.ID - b.ID) })
+	return loans, err
+}
+
+func (r *LoanRepository) RepayLoan(ctx context.Context, loanID, userID int64, amount float64, day time.Time) (*models.LoanRepayment, error) {
+	var repayment *models.LoanRepayment
+	err := r.Store.update(ctx, func(st *state) error {
+		loan, ok := st.loans[loanID]
+		if !ok || loan.UserID != userID {
+			return sql.ErrNoRows
+		}
+		schedule, result, err := repository.RepayLoanEarly(&loan, slices.Clone(st.schedules[loanID]), day, amount)
+		if err != nil {
+			return err
+		}
+		acc := st.accounts[loan.AccountID]
+		if acc.Frozen {
+			return repository.ErrAccountFrozen
+		}
+		if acc.Balance-acc.Held < amount {
+			return repository.ErrInsufficientFunds
+		}
+		loansID := systemAccount(st, models.SystemLoans, r.Store.Now())
+		r.moveFunds(st, models.KindLoanRepayment, loan.AccountID, loansID, amount)
+		saveSchedule(st, &loan, schedule)
+		repayment = result
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repayment, nil
+}
+
+func (r *LoanRepository) CollectLoanPayments(ctx context.Context, day time.Time) (*models.LoanCollection, error) {
+	report := &models.LoanCollection{Day: day}
+	err := r.Store.update(ctx, func(st *state) error {
+		ids := make([]int64, 0, len(st.loans))
+		for id, loan := range st.loans {
+			if loan.Status != models.LoanActive {
+				continue
+			}
+			if i := slices.IndexFunc(st.schedules[id], func(inst models.LoanInstallment) bool {
+				return !inst.DueDate.After(day) && inst.Status != models.InstallmentPaid
+			}); i >= 0 {
+				ids = append(ids, id)
+			}
+		}
+		slices.Sort(ids)
+
+		for _, id := range ids {
+			loan := st.loans[id]
+			schedule := slices.Clone(st.schedules[id])
+			acc := st.accounts[loan.AccountID]
+			balance := acc.Balance - acc.Held
+			// С замороженного счёта ничего не списывается, платежи уходят в просрочку
+			if acc.Frozen {
+				balance = 0
+			}
+
+			paid, overdue := repository.CollectLoan(&loan, schedule, day, balance)
+			if paid > 0 {
+				loansID := systemAccount(st, models.SystemLoans, r.Store.Now())
+				r.moveFunds(st, models.KindLoanRepayment, loan.AccountID, loansID, paid)
+				report.Loans++
+				report.Amount += paid
+			}
+			saveSchedule(st, &loan, schedule)
+			report.Overdue += overdue
+			if loan.Status == models.LoanClosed {
+				report.Closed++
+			}
+		}
+		report.Amount = round2(report.Amount)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
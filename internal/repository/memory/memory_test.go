@@ -0,0 +1,90 @@
+package memory_test
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/repository/memory"
+	"banking-api/internal/repository/repotest"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newRepos(t *testing.T) repotest.Repos {
+	store := memory.NewStore()
+	return repotest.Repos{
+		Users:     memory.NewUserRepository(store),
+		Accounts:  memory.NewAccountRepository(store),
+		Admin:     memory.NewAdminRepository(store),
+		Interest:  memory.NewInterestRepository(store),
+		Loans:     memory.NewLoanRepository(store),
+		Requests:  memory.NewPaymentRequestRepository(store),
+		Holds:     memory.NewHoldRepository(store),
+		Batches:   memory.NewBatchRepository(store),
+		Approvals: memory.NewApprovalRepository(store),
+		Access:    memory.NewAccessRepository(store),
+		Payees:    memory.NewPayeeRepository(store),
+	}
+}
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, newRepos)
+}
+
+func TestCancelledContext(t *testing.T) {
+	r := newRepos(t)
+	ctx := context.Background()
+
+	user := &models.User{Email: "alice@example.com", Username: "alice"}
+	if err := r.Users.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	acc := &models.Account{UserID: user.ID}
+	if err := r.Accounts.CreateAccount(ctx, acc); err != nil {
+		t.Fatal(err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := r.Accounts.TopUpAccount(cancelled, acc.ID, user.ID, 10); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ожидалась context.Canceled, получено %v", err)
+	}
+	if got, _ := r.Accounts.GetAccount(ctx, acc.ID, user.ID); got.Balance != 0 {
+		t.Errorf("баланс = %v, ожидалось 0", got.Balance)
+	}
+}
+
+func TestMonthlyTransferLimitResets(t *testing.T) {
+	store := memory.NewStore()
+	now := time.Date(2025, time.March, 31, 23, 0, 0, 0, time.UTC)
+	store.Now = func() time.Time { return now }
+	r := repotest.Repos{Users: memory.NewUserRepository(store), Accounts: memory.NewAccountRepository(store)}
+	ctx := context.Background()
+
+	user := &models.User{Email: "alice@example.com", Username: "alice"}
+	if err := r.Users.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	savings := &models.Account{UserID: user.ID, Type: models.AccountSavings, MonthlyTransferLimit: 1}
+	checking := &models.Account{UserID: user.ID}
+	for _, acc := range []*models.Account{savings, checking} {
+		if err := r.Accounts.CreateAccount(ctx, acc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Accounts.TopUpAccount(ctx, savings.ID, user.ID, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Accounts.TransferFunds(ctx, savings.ID, checking.ID, user.ID, 10, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, savings.ID, checking.ID, user.ID, 10, ""); !errors.Is(err, repository.ErrTransferLimitExceeded) {
+		t.Fatalf("второй перевод в марте: %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+	if err := r.Accounts.TransferFunds(ctx, savings.ID, checking.ID, user.ID, 10, ""); err != nil {
+		t.Errorf("первый перевод в апреле: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+	"time"
+)
+
+type PayeeRepository struct {
+	Store *Store
+}
+
+func NewPayeeRepository(store *Store) *PayeeRepository {
+	return &PayeeRepository{Store: store}
+}
+
+// payeeTarget проверяет, что получатель существует, и подставляет имя
+// пользователя-получателя или номер счёта.
+func payeeTarget(st *state, payee *models.Payee) error {
+	if payee.AccountID != 0 {
+		acc, ok := st.accounts[payee.AccountID]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		payee.ToUserID, payee.Username, payee.Account = 0, "", acc.Number
+		return nil
+	}
+	user, ok := st.users[payee.ToUserID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	payee.Username, payee.Account = user.Username, ""
+	return nil
+}
+
+// nicknameTaken сообщает, что у пользователя есть другой получатель с этим nickname.
+func nicknameTaken(st *state, payee *models.Payee) bool {
+	for _, p := range st.payees {
+		if p.UserID == payee.UserID && p.Nickname == payee.Nickname && p.ID != payee.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *PayeeRepository) CreatePayee(ctx context.Context, payee *models.Payee) error {
+	return r.Store.update(ctx, func(st *state) error {
+		if err := payeeTarget(st, payee); err != nil {
+			return err
+		}
+		payee.ID = 0
+		if nicknameTaken(st, payee) {
+			return repository.ErrDuplicate
+		}
+		st.lastPayeeID++
+		payee.ID = st.lastPayeeID
+		payee.CreatedAt = r.Store.Now()
+		st.payees[payee.ID] = *payee
+		return nil
+	})
+}
+
+func (r *PayeeRepository) GetPayee(ctx context.Context, payeeID, userID int64) (*models.Payee, error) {
+	var payee models.Payee
+	err := r.Store.view(ctx, func(st *state) error {
+		p, ok := st.payees[payeeID]
+		if !ok || p.UserID != userID {
+			return sql.ErrNoRows
+		}
+		payee = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &payee, nil
+}
+
+func (r *PayeeRepository) ListPayees(ctx context.Context, userID int64) ([]models.Payee, error) {
+	payees := []models.Payee{}
+	err := r.Store.view(ctx, func(st *state) error {
+		for _, p := range st.payees {
+			if p.UserID == userID {
+				payees = append(payees, p)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(payees, func(a, b models.Payee) int { return strings.Compare(a.Nickname, b.Nickname) })
+	return payees, err
+}
+
+func (r *PayeeRepository) UpdatePayee(ctx context.Context, payee *models.Payee) error {
+	return r.Store.update(ctx, func(st *state) error {
+		p, ok := st.payees[payee.ID]
+		if !ok || p.UserID != payee.UserID {
+			return sql.ErrNoRows
+		}
+		if err := payeeTarget(st, payee); err != nil {
+			return err
+		}
+		if nicknameTaken(st, payee) {
+			return repository.ErrDuplicate
+		}
+		payee.CreatedAt = p.CreatedAt
+		st.payees[payee.ID] = *payee
+		return nil
+	})
+}
+
+func (r *PayeeRepository) DeletePayee(ctx context.Context, payeeID, userID int64) error {
+	return r.Store.update(ctx, func(st *state) error {
+		p, ok := st.payees[payeeID]
+		if !ok || p.UserID != userID {
+			return sql.ErrNoRows
+		}
+		delete(st.payees, payeeID)
+		return nil
+	})
+}
+
+func (r *PayeeRepository) SentToAccount(ctx context.Context, userID, toAccountID int64, since time.Time) (float64, error) {
+	var sent float64
+	err := r.Store.view(ctx, func(st *state) error {
+		for _, t := range st.transactions {
+			if t.ToAccountID == toAccountID && t.Kind == models.KindTransfer && !t.CreatedAt.Before(since) &&
+				hasAccess(st, t.FromAccountID, userID, models.AccessTransact) {
+				sent += t.Amount
+			}
+		}
+		return nil
+	})
+	return sent, err
+}
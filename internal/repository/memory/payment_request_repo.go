@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+)
+
+type PaymentRequestRepository struct {
+	Store *Store
+}
+
+func NewPaymentRequestRepository(store *Store) *PaymentRequestRepository {
+	return &PaymentRequestRepository{Store: store}
+}
+
+// withNames заполняет имена сторон запроса и номер счёта получателя.
+func withNames(st *state, req models.PaymentRequest) models.PaymentRequest {
+	req.ToAccount = number(st, req.ToAccountID)
+	req.Requester = st.users[req.RequesterID].Username
+	req.Payer = st.users[req.PayerID].Username
+	return req
+}
+
+func (r *PaymentRequestRepository) CreatePaymentRequest(ctx context.Context, req *models.PaymentRequest) error {
+	return r.Store.update(ctx, func(st *state) error {
+		if !hasAccess(st, req.ToAccountID, req.RequesterID, models.AccessTransact) {
+			return sql.ErrNoRows
+		}
+		if _, ok := st.users[req.PayerID]; !ok {
+			return sql.ErrNoRows
+		}
+		st.lastPaymentRequestID++
+		req.ID = st.lastPaymentRequestID
+		req.Amount = round2(req.Amount)
+		req.Status = models.PaymentRequestPending
+		req.TransactionID = 0
+		req.ResolvedAt = nil
+		req.CreatedAt = r.Store.Now()
+		*req = withNames(st, *req)
+		st.paymentRequests[req.ID] = *req
+		return nil
+	})
+}
+
+func (r *PaymentRequestRepository) GetPaymentRequest(ctx context.Context, requestID, userID int64) (*models.PaymentRequest, error) {
+	var req models.PaymentRequest
+	err := r.Store.view(ctx, func(st *state) error {
+		p, ok := st.paymentRequests[requestID]
+		if !ok || (p.RequesterID != userID && p.PayerID != userID) {
+			return sql.ErrNoRows
+		}
+		req = withNames(st, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *PaymentRequestRepository) ListPaymentRequests(ctx context.Context, userID int64, incoming bool, status string) ([]models.PaymentRequest, error) {
+	requests := []models.PaymentRequest{}
+	err := r.Store.view(ctx, func(st *state) error {
+		for _, p := range st.paymentRequests {
+			party := p.RequesterID
+			if incoming {
+				party = p.PayerID
+			}
+			if party == userID && (status == "" || p.Status == status) {
+				requests = append(requests, withNames(st, p))
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(requests, func(a, b models.PaymentRequest) int { return int(b.ID - a.ID) })
+	return requests, err
+}
+
+// pending возвращает запрос requestID, если userID — нужная сторона (плательщик
+// при asPayer) и запрос ещё можно закрыть.
+func pending(st *state, requestID, userID int64, asPayer bool, now time.Time) (models.PaymentRequest, error) {
+	p, ok := st.paymentRequests[requestID]
+	if !ok || (asPayer && p.PayerID != userID) || (!asPayer && p.RequesterID != userID) {
+		return p, sql.ErrNoRows
+	}
+	if p.Status != models.PaymentRequestPending || !p.ExpiresAt.After(now) {
+		return p, repository.ErrPaymentRequestClosed
+	}
+	return p, nil
+}
+
+func (r *PaymentRequestRepository) AcceptPaymentRequest(ctx context.Context, requestID, payerID, fromAccountID int64, now time.Time) (*models.PaymentRequest, error) {
+	var req models.PaymentRequest
+	err := r.Store.update(ctx, func(st *state) error {
+		p, err := pending(st, requestID, payerID, true, now)
+		if err != nil {
+			return err
+		}
+		p.TransactionID, err = transfer(st, r.Store.Now(), fromAccountID, p.ToAccountID, payerID, p.Amount, "")
+		if err != nil {
+			return err
+		}
+		p.Status = models.PaymentRequestAccepted
+		p.ResolvedAt = &now
+		st.paymentRequests[requestID] = p
+		req = withNames(st, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *PaymentRequestRepository) ResolvePaymentRequest(ctx context.Context, requestID, userID int64, status string, now time.Time) (*models.PaymentRequest, error) {
+	var req models.PaymentRequest
+	err := r.Store.update(ctx, func(st *state) error {
+		p, err := pending(st, requestID, userID, status != models.PaymentRequestCancelled, now)
+		if err != nil {
+			return err
+		}
+		p.Status = status
+		p.ResolvedAt = &now
+		st.paymentRequests[requestID] = p
+		req = withNames(st, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *PaymentRequestRepository) ExpirePaymentRequests(ctx context.Context, now time.Time) ([]models.PaymentRequest, error) {
+	expired := []models.PaymentRequest{}
+	err := r.Store.update(ctx, func(st *state) error {
+		for id, p := range st.paymentRequests {
+			if p.Status != models.PaymentRequestPending || p.ExpiresAt.After(now) {
+				continue
+			}
+			p.Status = models.PaymentRequestExpired
+			p.ResolvedAt = &now
+			st.paymentRequests[id] = p
+			expired = append(expired, withNames(st, p))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(expired, func(a, b models.PaymentRequest) int { return int(a.ID - b.ID) })
+	return expired, nil
+}
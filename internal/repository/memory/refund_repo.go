@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"time"
+)
+
+func (r *AccountRepository) RefundTransfer(ctx context.Context, transactionID, userID int64, amount float64) (*models.Transaction, error) {
+	var t models.Transaction
+	err := r.Store.update(ctx, func(st *state) error {
+		original, remaining, ok := findOriginal(st, transactionID)
+		// Вернуть перевод может только пользователь с доступом transact к счёту, на который он пришёл
+		if !ok || !hasAccess(st, original.ToAccountID, userID, models.AccessTransact) {
+			return sql.ErrNoRows
+		}
+		if original.Kind != models.KindTransfer {
+			return repository.ErrNotRefundable
+		}
+		amount, err := refundAmount(amount, remaining)
+		if err != nil {
+			return err
+		}
+		payer, sender := st.accounts[original.ToAccountID], st.accounts[original.FromAccountID]
+		if payer.Frozen || sender.Frozen {
+			return repository.ErrAccountFrozen
+		}
+		if payer.Available() < amount {
+			return repository.ErrInsufficientFunds
+		}
+		t = moveBack(st, r.Store.Now(), original, models.KindRefund, amount, "")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// findOriginal возвращает движение transactionID и ещё не возвращённый остаток.
+func findOriginal(st *state, transactionID int64) (models.Transaction, float64, bool) {
+	var original models.Transaction
+	var found bool
+	var returned float64
+	for _, t := range st.transactions {
+		switch {
+		case t.ID == transactionID:
+			original, found = t, true
+		case t.OriginalTransactionID == transactionID:
+			returned += t.Amount
+		}
+	}
+	return original, round2(original.Amount - returned), found
+}
+
+// refundAmount проверяет сумму возврата по остатку перевода, 0 — весь остаток.
+func refundAmount(amount, remaining float64) (float64, error) {
+	if remaining <= 0 {
+		return 0, repository.ErrTransferRefunded
+	}
+	if amount == 0 {
+		return remaining, nil
+	}
+	amount = round2(amount)
+	if amount > remaining {
+		return 0, repository.ErrRefundExceedsTransfer
+	}
+	return amount, nil
+}
+
+// moveBack переводит amount обратно со счёта получателя original на счёт
+// отправителя и записывает движение kind со ссылкой на original.
+func moveBack(st *state, now time.Time, original models.Transaction, kind string, amount float64, reasonCode string) models.Transaction {
+	payer := st.accounts[original.ToAccountID]
+	payer.Balance = round2(payer.Balance - amount)
+	st.accounts[payer.ID] = payer
+	sender := st.accounts[original.FromAccountID]
+	sender.Balance = round2(sender.Balance + amount)
+	st.accounts[sender.ID] = sender
+
+	return appendTransaction(st, models.Transaction{
+		Kind:                  kind,
+		FromAccountID:         original.ToAccountID,
+		ToAccountID:           original.FromAccountID,
+		Amount:                amount,
+		CreatedAt:             now,
+		OriginalTransactionID: original.ID,
+		ReasonCode:            reasonCode,
+	})
+}
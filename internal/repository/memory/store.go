@@ -0,0 +1,182 @@
+// Package memory — реализация репозиториев в памяти процесса для тестов и
+// локальной разработки. Семантика совпадает с PostgreSQL-реализацией:
+// отсутствующие и чужие записи дают sql.ErrNoRows, нехватка средств —
+// repository.ErrInsufficientFunds, а изменения применяются транзакционно.
+package memory
+
+import (
+	"banking-api/internal/models"
+	"context"
+	"maps"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// state — снимок всех данных хранилища.
+type state struct {
+	users        map[int64]models.User
+	accounts     map[int64]models.Account
+	transactions []models.Transaction
+	// idempotency — индекс перевода в transactions по счёту списания и ключу идемпотентности
+	idempotency map[transferKey]int
+	audit       []models.AuditEntry
+
+	// Проценты: ставки по типу счёта и дате начала, начисления по счёту и дню
+	rates          map[rateKey]models.InterestRate
+	accruals       map[accrualKey]models.InterestAccrual
+	systemAccounts map[string]int64
+
+	// Кредиты и их графики платежей по ID кредита
+	loans     map[int64]models.Loan
+	schedules map[int64][]models.LoanInstallment
+
+	paymentRequests map[int64]models.PaymentRequest
+	holds           map[int64]models.Hold
+
+	// Пакеты переводов без строк и строки по ID пакета
+	batches    map[int64]models.Batch
+	batchItems map[int64][]models.BatchItem
+
+	// Одобряющие по ID счёта и переводы на одобрении
+	approvers map[int64][]int64
+	approvals map[int64]models.TransferApproval
+
+	// Доступ пользователей к счетам по счёту и пользователю
+	access map[accessKey]models.AccountAccess
+
+	payees map[int64]models.Payee
+
+	lastUserID           int64
+	lastAccountID        int64
+	lastTransactionID    int64
+	lastAuditID          int64
+	lastLoanID           int64
+	lastPaymentRequestID int64
+	lastHoldID           int64
+	lastBatchID          int64
+	lastApprovalID       int64
+	lastPayeeID          int64
+}
+
+type transferKey struct {
+	fromID int64
+	key    string
+}
+
+type accessKey struct {
+	accountID int64
+	userID    int64
+}
+
+type rateKey struct {
+	accountType string
+	from        time.Time
+}
+
+type accrualKey struct {
+	accountID int64
+	day       time.Time
+}
+
+func (st *state) clone() *state {
+	c := *st
+	c.users = maps.Clone(st.users)
+	c.accounts = maps.Clone(st.accounts)
+	c.transactions = slices.Clone(st.transactions)
+	c.idempotency = maps.Clone(st.idempotency)
+	c.audit = slices.Clone(st.audit)
+	c.rates = maps.Clone(st.rates)
+	c.accruals = maps.Clone(st.accruals)
+	c.systemAccounts = maps.Clone(st.systemAccounts)
+	c.loans = maps.Clone(st.loans)
+	// Графики изменяются только заменой целиком, поэтому срезы можно не копировать
+	c.schedules = maps.Clone(st.schedules)
+	c.paymentRequests = maps.Clone(st.paymentRequests)
+	c.holds = maps.Clone(st.holds)
+	// Строки пакетов, как и графики, изменяются только заменой целиком
+	c.batches = maps.Clone(st.batches)
+	c.batchItems = maps.Clone(st.batchItems)
+	// Списки одобряющих тоже заменяются целиком
+	c.approvers = maps.Clone(st.approvers)
+	c.approvals = maps.Clone(st.approvals)
+	c.access = maps.Clone(st.access)
+	c.payees = maps.Clone(st.payees)
+	return &c
+}
+
+// Store хранит данные, общие для всех репозиториев в памяти.
+type Store struct {
+	mu sync.RWMutex
+	st *state
+
+	// Now возвращает текущее время; можно подменить в тестах.
+	Now func() time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		st: &state{
+			users:       make(map[int64]models.User),
+			accounts:    make(map[int64]models.Account),
+			idempotency: make(map[transferKey]int),
+
+			rates:          make(map[rateKey]models.InterestRate),
+			accruals:       make(map[accrualKey]models.InterestAccrual),
+			systemAccounts: make(map[string]int64),
+
+			loans:     make(map[int64]models.Loan),
+			schedules: make(map[int64][]models.LoanInstallment),
+
+			paymentRequests: make(map[int64]models.PaymentRequest),
+			holds:           make(map[int64]models.Hold),
+
+			batches:    make(map[int64]models.Batch),
+			batchItems: make(map[int64][]models.BatchItem),
+
+			approvers: make(map[int64][]int64),
+			approvals: make(map[int64]models.TransferApproval),
+
+			access: make(map[accessKey]models.AccountAccess),
+
+			payees: make(map[int64]models.Payee),
+		},
+		Now: time.Now,
+	}
+}
+
+// view выполняет fn над текущим состоянием только для чтения.
+func (s *Store) view(ctx context.Context, fn func(st *state) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fn(s.st)
+}
+
+// update выполняет fn над копией состояния и публикует её, только если fn
+// и контекст завершились без ошибки — аналог COMMIT/ROLLBACK.
+func (s *Store) update(ctx context.Context, fn func(st *state) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := s.st.clone()
+	if err := fn(next); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.st = next
+	return nil
+}
+
+// round2 повторяет округление NUMERIC(12, 2).
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+)
+
+type UserRepository struct {
+	Store *Store
+}
+
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{Store: store}
+}
+
+func (r *UserRepository) IsEmailOrUsernameTaken(ctx context.Context, email, username string) (bool, error) {
+	var taken bool
+	err := r.Store.view(ctx, func(st *state) error {
+		for _, u := range st.users {
+			if u.Email == email || u.Username == username {
+				taken = true
+				break
+			}
+		}
+		return nil
+	})
+	return taken, err
+}
+
+func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	return r.Store.update(ctx, func(st *state) error {
+		for _, u := range st.users {
+			if u.Email == user.Email || u.Username == user.Username {
+				return repository.ErrDuplicate
+			}
+		}
+		st.lastUserID++
+		user.ID = st.lastUserID
+		user.CreatedAt = r.Store.Now()
+		st.users[user.ID] = *user
+		return nil
+	})
+}
+
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	var found *models.User
+	err := r.Store.view(ctx, func(st *state) error {
+		for _, u := range st.users {
+			if u.Email == email {
+				found = &u
+				return nil
+			}
+		}
+		return sql.ErrNoRows
+	})
+	return found, err
+}
+
+func (r *UserRepository) GetUserIDByUsername(ctx context.Context, username string) (int64, error) {
+	var userID int64
+	err := r.Store.view(ctx, func(st *state) error {
+		for _, u := range st.users {
+			if u.Username == username {
+				userID = u.ID
+				return nil
+			}
+		}
+		return sql.ErrNoRows
+	})
+	return userID, err
+}
+
+// GetUserByID, как и SQL-реализация, не возвращает хеш пароля.
+func (r *UserRepository) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	var found *models.User
+	err := r.Store.view(ctx, func(st *state) error {
+		u, ok := st.users[userID]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		found = &models.User{ID: u.ID, Email: u.Email, Username: u.Username, CreatedAt: u.CreatedAt}
+		return nil
+	})
+	return found, err
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"banking-api/internal/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// SQLPayeeRepository — реализация PayeeRepository поверх PostgreSQL или SQLite.
+type SQLPayeeRepository struct {
+	DB      *sql.DB
+	Dialect Dialect
+}
+
+func NewSQLPayeeRepository(db *sql.DB, dialect Dialect) *SQLPayeeRepository {
+	return &SQLPayeeRepository{DB: db, Dialect: dialect}
+}
+
+// payeeSelect выбирает получателей с именем пользователя и номером счёта получателя в порядке,
+// который ожидает scanPayee.
+const payeeSelect = `
+	SELECT p.id, p.user_id, p.nickname, COALESCE(p.to_user_id, 0), COALESCE(u.username, ''),
+		COALESCE(p.to_account_id, 0), COALESCE(a.number, ''), p.reference, p.cooling_off_until, p.created_at
+	FROM payees p
+	LEFT JOIN users u ON u.id = p.to_user_id
+	LEFT JOIN accounts a ON a.id = p.to_account_id`
+
+func scanPayee(row interface{ Scan(...any) error }, p *models.Payee) error {
+	return row.Scan(&p.ID, &p.UserID, &p.Nickname, &p.ToUserID, &p.Username,
+		&p.AccountID, &p.Account, &p.Reference, &p.CoolingOffUntil, &p.CreatedAt)
+}
+
+// payeeTarget возвращает получателя для INSERT и UPDATE: ровно одно из
+// значений не NULL.
+func payeeTarget(p *models.Payee) (toUserID, toAccountID sql.NullInt64) {
+	if p.AccountID != 0 {
+		return toUserID, sql.NullInt64{Int64: p.AccountID, Valid: true}
+	}
+	return sql.NullInt64{Int64: p.ToUserID, Valid: true}, toAccountID
+}
+
+// checkPayeeTarget проверяет, что получатель существует.
+func checkPayeeTarget(ctx context.Context, tx *sql.Tx, p *models.Payee) error {
+	query, id := `SELECT 1 FROM users WHERE id = $1`, p.ToUserID
+	if p.AccountID != 0 {
+		query, id = `SELECT 1 FROM accounts WHERE id = $1`, p.AccountID
+	}
+	var exists int
+	return tx.QueryRowContext(ctx, query, id).Scan(&exists)
+}
+
+func (r *SQLPayeeRepository) CreatePayee(ctx context.Context, payee *models.Payee) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkPayeeTarget(ctx, tx, payee); err != nil {
+		return err
+	}
+	toUserID, toAccountID := payeeTarget(payee)
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO payees (user_id, nickname, to_user_id, to_account_id, reference, cooling_off_until)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		payee.UserID, payee.Nickname, toUserID, toAccountID, payee.Reference, payee.CoolingOffUntil.UTC()).Scan(&id)
+	if err != nil {
+		return r.Dialect.mapError(err)
+	}
+	if err := scanPayee(tx.QueryRowContext(ctx, payeeSelect+` WHERE p.id = $1`, id), payee); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLPayeeRepository) GetPayee(ctx context.Context, payeeID, userID int64) (*models.Payee, error) {
+	var payee models.Payee
+	err := scanPayee(r.DB.QueryRowContext(ctx, payeeSelect+` WHERE p.id = $1 AND p.user_id = $2`, payeeID, userID), &payee)
+	if err != nil {
+		return nil, err
+	}
+	return &payee, nil
+}
+
+func (r *SQLPayeeRepository) ListPayees(ctx context.Context, userID int64) ([]models.Payee, error) {
+	rows, err := r.DB.QueryContext(ctx, payeeSelect+` WHERE p.user_id = $1 ORDER BY p.nickname`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payees := []models.Payee{}
+	for rows.Next() {
+		var p models.Payee
+		if err := scanPayee(rows, &p); err != nil {
+			return nil, err
+		}
+		payees = append(payees, p)
+	}
+	return payees, rows.Err()
+}
+
+func (r *SQLPayeeRepository) UpdatePayee(ctx context.Context, payee *models.Payee) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkPayeeTarget(ctx, tx, payee); err != nil {
+		return err
+	}
+	toUserID, toAccountID := payeeTarget(payee)
+	res, err := tx.ExecContext(ctx, `
+		UPDATE payees SET nickname = $1, to_user_id = $2, to_account_id = $3, reference = $4, cooling_off_until = $5
+		WHERE id = $6 AND user_id = $7`,
+		payee.Nickname, toUserID, toAccountID, payee.Reference, payee.CoolingOffUntil.UTC(), payee.ID, payee.UserID)
+	if err != nil {
+		return r.Dialect.mapError(err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	if err := scanPayee(tx.QueryRowContext(ctx, payeeSelect+` WHERE p.id = $1`, payee.ID), payee); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLPayeeRepository) DeletePayee(ctx context.Context, payeeID, userID int64) error {
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM payees WHERE id = $1 AND user_id = $2`, payeeID, userID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *SQLPayeeRepository) SentToAccount(ctx context.Context, userID, toAccountID int64, since time.Time) (float64, error) {
+	var sent float64
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0) FROM transactions
+		WHERE to_account_id = $1 AND kind = 'transfer' AND created_at >= $2 AND `+hasAccess("transactions.from_account_id", "$3", models.AccessTransact),
+		toAccountID, since.UTC(), userID).Scan(&sent)
+	return sent, err
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"banking-api/internal/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// SQLPaymentRequestRepository — реализация PaymentRequestRepository поверх PostgreSQL или SQLite.
+type SQLPaymentRequestRepository struct {
+	DB      *sql.DB
+	Dialect Dialect
+}
+
+func NewSQLPaymentRequestRepository(db *sql.DB, dialect Dialect) *SQLPaymentRequestRepository {
+	return &SQLPaymentRequestRepository{DB: db, Dialect: dialect}
+}
+
+// paymentRequestSelect выбирает запросы с именами сторон в порядке, который ожидает scanPaymentRequest.
+const paymentRequestSelect = `
+	SELECT p.id, p.requester_id, p.payer_id, r.username, u.username, p.to_account_id, COALESCE(a.number, ''), p.amount, p.note,
+		p.status, COALESCE(p.transaction_id, 0), p.expires_at, p.created_at, p.resolved_at
+	FROM payment_requests p
+	JOIN users r ON r.id = p.requester_id
+	JOIN users u ON u.id = p.payer_id
+	JOIN accounts a ON a.id = p.to_account_id`
+
+func scanPaymentRequest(row interface{ Scan(...any) error }, p *models.PaymentRequest) error {
+	var resolved sql.NullTime
+	err := row.Scan(&p.ID, &p.RequesterID, &p.PayerID, &p.Requester, &p.Payer, &p.ToAccountID, &p.ToAccount, &p.Amount, &p.Note,
+		&p.Status, &p.TransactionID, &p.ExpiresAt, &p.CreatedAt, &resolved)
+	if err != nil {
+		return err
+	}
+	p.ResolvedAt = nil
+	if resolved.Valid {
+		p.ResolvedAt = &resolved.Time
+	}
+	return nil
+}
+
+func (r *SQLPaymentRequestRepository) CreatePaymentRequest(ctx context.Context, req *models.PaymentRequest) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT TRUE FROM accounts WHERE id = $1 AND `+hasAccess("accounts.id", "$2", models.AccessTransact), req.ToAccountID, req.RequesterID).
+		Scan(&exists)
+	if err != nil {
+		return err
+	}
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO payment_requests (requester_id, payer_id, to_account_id, amount, note, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		req.RequesterID, req.PayerID, req.ToAccountID, round2(req.Amount), req.Note, req.ExpiresAt.UTC()).Scan(&id)
+	if err != nil {
+		return err
+	}
+	if err := scanPaymentRequest(tx.QueryRowContext(ctx, paymentRequestSelect+` WHERE p.id = $1`, id), req); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLPaymentRequestRepository) GetPaymentRequest(ctx context.Context, requestID, userID int64) (*models.PaymentRequest, error) {
+	var req models.PaymentRequest
+	err := scanPaymentRequest(r.DB.QueryRowContext(ctx,
+		paymentRequestSelect+` WHERE p.id = $1 AND (p.requester_id = $2 OR p.payer_id = $2)`, requestID, userID), &req)
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *SQLPaymentRequestRepository) ListPaymentRequests(ctx context.Context, userID int64, incoming bool, status string) ([]models.PaymentRequest, error) {
+	query := paymentRequestSelect + ` WHERE p.requester_id = $1 AND ($2 = '' OR p.status = $2) ORDER BY p.id DESC`
+	if incoming {
+		query = paymentRequestSelect + ` WHERE p.payer_id = $1 AND ($2 = '' OR p.status = $2) ORDER BY p.id DESC`
+	}
+	return r.list(ctx, r.DB, query, userID, status)
+}
+
+func (r *SQLPaymentRequestRepository) list(ctx context.Context, q querier, query string, args ...any) ([]models.PaymentRequest, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []models.PaymentRequest{}
+	for rows.Next() {
+		var req models.PaymentRequest
+		if err := scanPaymentRequest(rows, &req); err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	return requests, rows.Err()
+}
+
+// lockPending блокирует запрос requestID стороны userID (column — requester_id
+// или payer_id) и проверяет, что его ещё можно закрыть.
+func (r *SQLPaymentRequestRepository) lockPending(ctx context.Context, tx *sql.Tx, requestID, userID int64, column string, now time.Time) (*models.PaymentRequest, error) {
+	var req models.PaymentRequest
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, to_account_id, amount, status, expires_at FROM payment_requests
+		WHERE id = $1 AND `+column+` = $2`+r.Dialect.forUpdate(), requestID, userID).
+		Scan(&req.ID, &req.ToAccountID, &req.Amount, &req.Status, &req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != models.PaymentRequestPending || !req.ExpiresAt.After(now) {
+		return nil, ErrPaymentRequestClosed
+	}
+	return &req, nil
+}
+
+func (r *SQLPaymentRequestRepository) AcceptPaymentRequest(ctx context.Context, requestID, payerID, fromAccountID int64, now time.Time) (*models.PaymentRequest, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	req, err := r.lockPending(ctx, tx, requestID, payerID, "payer_id", now)
+	if err != nil {
+		return nil, err
+	}
+	transactionID, err := transfer(ctx, tx, r.Dialect, fromAccountID, req.ToAccountID, payerID, req.Amount, "")
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE payment_requests SET status = $1, transaction_id = $2, resolved_at = $3 WHERE id = $4`,
+		models.PaymentRequestAccepted, transactionID, now.UTC(), requestID)
+	if err != nil {
+		return nil, err
+	}
+	if err := scanPaymentRequest(tx.QueryRowContext(ctx, paymentRequestSelect+` WHERE p.id = $1`, requestID), req); err != nil {
+		return nil, err
+	}
+	return req, tx.Commit()
+}
+
+func (r *SQLPaymentRequestRepository) ResolvePaymentRequest(ctx context.Context, requestID, userID int64, status string, now time.Time) (*models.PaymentRequest, error) {
+	column := "payer_id"
+	if status == models.PaymentRequestCancelled {
+		column = "requester_id"
+	}
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	req, err := r.lockPending(ctx, tx, requestID, userID, column, now)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE payment_requests SET status = $1, resolved_at = $2 WHERE id = $3`,
+		status, now.UTC(), requestID)
+	if err != nil {
+		return nil, err
+	}
+	if err := scanPaymentRequest(tx.QueryRowContext(ctx, paymentRequestSelect+` WHERE p.id = $1`, requestID), req); err != nil {
+		return nil, err
+	}
+	return req, tx.Commit()
+}
+
+func (r *SQLPaymentRequestRepository) ExpirePaymentRequests(ctx context.Context, now time.Time) ([]models.PaymentRequest, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now = now.UTC()
+	expired, err := r.list(ctx, tx, paymentRequestSelect+` WHERE p.status = $1 AND p.expires_at <= $2 ORDER BY p.id`,
+		models.PaymentRequestPending, now)
+	if err != nil {
+		return nil, err
+	}
+	for i := range expired {
+		_, err := tx.ExecContext(ctx, `UPDATE payment_requests SET status = $1, resolved_at = $2 WHERE id = $3`,
+			models.PaymentRequestExpired, now, expired[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		expired[i].Status = models.PaymentRequestExpired
+		expired[i].ResolvedAt = &now
+	}
+	return expired, tx.Commit()
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"banking-api/internal/models"
+	"context"
+	"database/sql"
+)
+
+func (r *SQLAccountRepository) RefundTransfer(ctx context.Context, transactionID, userID int64, amount float64) (*models.Transaction, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Вернуть перевод может только пользователь с доступом transact к счёту, на который он пришёл
+	original, remaining, err := lockOriginal(ctx, tx, `
+		SELECT t.id, t.kind, COALESCE(t.from_account_id, 0), COALESCE(t.to_account_id, 0), t.amount FROM transactions t
+		WHERE t.id = $1 AND `+hasAccess("t.to_account_id", "$2", models.AccessTransact)+r.Dialect.forUpdate(), transactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if original.Kind != models.KindTransfer {
+		return nil, ErrNotRefundable
+	}
+	amount, err = refundAmount(amount, remaining)
+	if err != nil {
+		return nil, err
+	}
+
+	var payer models.Account
+	err = scanAccount(tx.QueryRowContext(ctx,
+		`SELECT `+accountColumns+` FROM accounts WHERE id = $1`+r.Dialect.forUpdate(), original.ToAccountID), &payer)
+	if err != nil {
+		return nil, err
+	}
+	var senderFrozen bool
+	if err := tx.QueryRowContext(ctx, `SELECT frozen FROM accounts WHERE id = $1`, original.FromAccountID).Scan(&senderFrozen); err != nil {
+		return nil, err
+	}
+	if payer.Frozen || senderFrozen {
+		return nil, ErrAccountFrozen
+	}
+	if payer.Available() < amount {
+		return nil, ErrInsufficientFunds
+	}
+
+	t, err := moveBack(ctx, tx, original, models.KindRefund, amount, "")
+	if err != nil {
+		return nil, err
+	}
+	return t, tx.Commit()
+}
+
+// lockOriginal блокирует исходное движение, выбранное query, и возвращает его
+// вместе с ещё не возвращённым остатком.
+func lockOriginal(ctx context.Context, tx *sql.Tx, query string, args ...any) (*models.Transaction, float64, error) {
+	var original models.Transaction
+	err := tx.QueryRowContext(ctx, query, args...).
+		Scan(&original.ID, &original.Kind, &original.FromAccountID, &original.ToAccountID, &original.Amount)
+	if err != nil {
+		return nil, 0, err
+	}
+	var returned float64
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(amount), 0) FROM transactions WHERE original_transaction_id = $1`, original.ID).
+		Scan(&returned)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &original, round2(original.Amount - returned), nil
+}
+
+// refundAmount проверяет сумму возврата по остатку перевода, 0 — весь остаток.
+func refundAmount(amount, remaining float64) (float64, error) {
+	if remaining <= 0 {
+		return 0, ErrTransferRefunded
+	}
+	if amount == 0 {
+		return remaining, nil
+	}
+	amount = round2(amount)
+	if amount > remaining {
+		return 0, ErrRefundExceedsTransfer
+	}
+	return amount, nil
+}
+
+// moveBack переводит amount обратно со счёта получателя original на счёт
+// отправителя и записывает движение kind со ссылкой на original.
+func moveBack(ctx context.Context, tx *sql.Tx, original *models.Transaction, kind string, amount float64, reasonCode string) (*models.Transaction, error) {
+	if _, err := tx.ExecContext(ctx, `UPDATE accounts SET balance = ROUND(balance - $1, 2) WHERE id = $2`, amount, original.ToAccountID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE accounts SET balance = ROUND(balance + $1, 2) WHERE id = $2`, amount, original.FromAccountID); err != nil {
+		return nil, err
+	}
+	var id int64
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO transactions (kind, from_account_id, to_account_id, amount, original_transaction_id, reason_code)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		kind, original.ToAccountID, original.FromAccountID, amount, original.ID, reasonCode).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	var t models.Transaction
+	if err := scanTransaction(tx.QueryRowContext(ctx, transactionSelect+` WHERE t.id = $1`, id), &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
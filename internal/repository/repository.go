@@ -0,0 +1,338 @@
+package repository
+
+import (
+	"banking-api/internal/models"
+	"context"
+	"errors"
+	"time"
+)
+
+// Ошибки, общие для всех реализаций хранилища. Отсутствие записи
+// (в том числе чужой счёт или счёт без нужного уровня доступа, см.
+// AccessRepository) обозначается sql.ErrNoRows.
+var (
+	ErrInsufficientFunds = errors.New("недостаточно средств")
+	ErrDuplicate         = errors.New("запись уже существует")
+
+	// ErrAlreadyApplied — перевод с этим ключом идемпотентности и теми же
+	// параметрами уже выполнен; повтор ничего не меняет.
+	ErrAlreadyApplied = errors.New("перевод с этим ключом идемпотентности уже выполнен")
+	// ErrIdempotencyConflict — ключ идемпотентности уже использован для другого перевода.
+	ErrIdempotencyConflict = errors.New("ключ идемпотентности уже использован для другого перевода")
+	// ErrAccountFrozen — счёт заморожен администратором.
+	ErrAccountFrozen = errors.New("счёт заморожен")
+	// ErrTransferLimitExceeded — исчерпан месячный лимит исходящих переводов счёта.
+	ErrTransferLimitExceeded = errors.New("исчерпан месячный лимит переводов по счёту")
+	// ErrSavingsExternalTransfer — перевод со сберегательного счёта на чужой счёт.
+	ErrSavingsExternalTransfer = errors.New("со сберегательного счёта можно переводить только на свои и совместные счета")
+	// ErrOverdraftNotAllowed — овердрафт подключается только к расчётным счетам.
+	ErrOverdraftNotAllowed = errors.New("овердрафт доступен только для расчётных счетов")
+	// ErrOverdraftInUse — текущий минус по счёту больше нового лимита овердрафта.
+	ErrOverdraftInUse = errors.New("текущий минус по счёту больше нового лимита овердрафта")
+	// ErrLoanClosed — кредит уже погашен.
+	ErrLoanClosed = errors.New("кредит уже погашен")
+	// ErrLoanOverpayment — сумма погашения больше всей задолженности по кредиту.
+	ErrLoanOverpayment = errors.New("сумма больше задолженности по кредиту")
+	// ErrPaymentRequestClosed — запрос денег уже оплачен, отклонён, отменён или истёк.
+	ErrPaymentRequestClosed = errors.New("запрос денег уже закрыт")
+	// ErrHoldClosed — блокировка уже списана, снята или истекла.
+	ErrHoldClosed = errors.New("блокировка уже закрыта")
+	// ErrCaptureExceedsHold — сумма списания больше заблокированной.
+	ErrCaptureExceedsHold = errors.New("сумма списания больше заблокированной")
+	// ErrNotRefundable — движение этого вида нельзя вернуть или сторнировать.
+	ErrNotRefundable = errors.New("это движение нельзя вернуть")
+	// ErrTransferRefunded — перевод уже возвращён или сторнирован полностью.
+	ErrTransferRefunded = errors.New("перевод уже возвращён полностью")
+	// ErrRefundExceedsTransfer — сумма возврата больше ещё не возвращённой части перевода.
+	ErrRefundExceedsTransfer = errors.New("сумма возврата больше остатка перевода")
+	// ErrBatchClosed — пакет переводов уже выполнен.
+	ErrBatchClosed = errors.New("пакет уже выполнен")
+	// ErrApprovalRequired — сумма больше порога одобрения счёта: перевод
+	// выполняется только через одобрение вторым пользователем.
+	ErrApprovalRequired = errors.New("перевод больше порога одобрения счёта требует одобрения")
+	// ErrApprovalClosed — перевод уже одобрен, отклонён или истёк.
+	ErrApprovalClosed = errors.New("перевод уже рассмотрен или истёк")
+	// ErrOwnerAccess — доступ владельца счёта нельзя отозвать.
+	ErrOwnerAccess = errors.New("доступ владельца счёта нельзя отозвать")
+)
+
+type UserRepository interface {
+	IsEmailOrUsernameTaken(ctx context.Context, email, username string) (bool, error)
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUserIDByUsername(ctx context.Context, username string) (int64, error)
+	GetUserByID(ctx context.Context, userID int64) (*models.User, error)
+}
+
+type AccountRepository interface {
+	// CreateAccount создаёт счёт с нулевым балансом по UserID, Type и лимитам
+	// из account, даёт владельцу доступ manage и заполняет ID и CreatedAt.
+	CreateAccount(ctx context.Context, account *models.Account) error
+	// TopUpAccount пополняет счёт accountID, к которому у userID доступ transact,
+	// и записывает движение вида KindTopUp. Замороженный счёт (как и в
+	// TransferFunds) даёт ErrAccountFrozen.
+	TopUpAccount(ctx context.Context, accountID, userID int64, amount float64) error
+	// TransferFunds атомарно переводит amount со счёта fromID (с доступом transact у userID) на счёт toID.
+	// Непустой idempotencyKey уникален в пределах счёта fromID: повтор с теми же
+	// параметрами возвращает ErrAlreadyApplied, с другими — ErrIdempotencyConflict.
+	// Правила типа счёта: списание до -(CreditLimit + OverdraftLimit) за вычетом
+	// заблокированной суммы Held, не больше
+	// MonthlyTransferLimit переводов в месяц (ErrTransferLimitExceeded), со
+	// сберегательного — только на счета, к которым у userID есть доступ transact
+	// (ErrSavingsExternalTransfer).
+	// Если перевод уводит баланс в минус по овердрафту, в той же транзакции
+	// списывается комиссия OverdraftFee (движение KindFee на счёт банка), и
+	// перевод вместе с комиссией должен уложиться в лимит. Сумма больше
+	// ApprovalThreshold счёта — ErrApprovalRequired.
+	TransferFunds(ctx context.Context, fromID, toID, userID int64, amount float64, idempotencyKey string) error
+	// GetAccount возвращает счёт accountID, если у userID есть доступ view.
+	GetAccount(ctx context.Context, accountID, userID int64) (*models.Account, error)
+	// SetLowBalanceThreshold задаёт порог уведомления о низком остатке счёта
+	// (нужен доступ manage).
+	SetLowBalanceThreshold(ctx context.Context, accountID, userID int64, threshold float64) error
+	// GetTransactions возвращает переводы по счёту accountID (входящие и исходящие), начиная с последних.
+	// Без доступа view у userID возвращает sql.ErrNoRows.
+	GetTransactions(ctx context.Context, accountID, userID int64, limit, offset int) ([]models.Transaction, error)
+	// GetStatement возвращает выписку по счёту accountID (доступ view у userID)
+	// за [from, to). Остатки считаются как в AccrueInterest: текущий баланс за
+	// вычетом движений после начала и конца периода.
+	GetStatement(ctx context.Context, accountID, userID int64, from, to time.Time) (*models.Statement, error)
+	// RefundTransfer возвращает отправителю amount (0 — весь остаток) перевода
+	// transactionID новым движением KindRefund со ссылкой на исходный. Вернуть
+	// может только пользователь с доступом transact к счёту-получателю (иначе
+	// sql.ErrNoRows) и только перевод KindTransfer (иначе ErrNotRefundable).
+	// Возвраты и сторно в сумме не больше перевода: ErrRefundExceedsTransfer, а
+	// если остатка нет — ErrTransferRefunded. Замороженные счета — ErrAccountFrozen, сумма больше
+	// Available получателя — ErrInsufficientFunds. Комиссий и лимитов нет.
+	RefundTransfer(ctx context.Context, transactionID, userID int64, amount float64) (*models.Transaction, error)
+	GetFirstAccountByUserID(ctx context.Context, userID int64) (int64, error)
+	// GetUserIDByAccountID возвращает владельца счёта; у служебных счетов
+	// владельца нет — 0.
+	GetUserIDByAccountID(ctx context.Context, accountID int64) (int64, error)
+	// GetAccountIDByNumber возвращает ID счёта по номеру или sql.ErrNoRows.
+	GetAccountIDByNumber(ctx context.Context, number string) (int64, error)
+}
+
+// InterestRepository — ставки, ежедневное начисление и капитализация процентов.
+// Дни передаются как полночь UTC.
+type InterestRepository interface {
+	// SetRate добавляет или заменяет ставку типа счёта с даты EffectiveFrom.
+	SetRate(ctx context.Context, rate models.InterestRate, entry models.AuditEntry) error
+	ListRates(ctx context.Context) ([]models.InterestRate, error)
+	// AccrueInterest начисляет проценты за день day на остаток на конец дня всем
+	// счетам, для типа которых на этот день действует ставка. Уже начисленные за
+	// этот день счета пропускаются. Возвращает число новых начислений.
+	AccrueInterest(ctx context.Context, day time.Time) (int, error)
+	// CapitalizeInterest переводит начисленные за месяц month и ещё не выплаченные
+	// проценты со счёта банка SystemInterest на счета клиентов (вид KindInterest).
+	CapitalizeInterest(ctx context.Context, month time.Time) (*models.Capitalization, error)
+	// ListAccruals возвращает начисления по счёту за [from, to), начиная с ранних.
+	ListAccruals(ctx context.Context, accountID int64, from, to time.Time) ([]models.InterestAccrual, error)
+	// LastAccrualDay возвращает последний день, за который есть начисления,
+	// или нулевое время, если начислений ещё не было.
+	LastAccrualDay(ctx context.Context) (time.Time, error)
+}
+
+// LoanRepository — кредиты и их графики платежей. Деньги выдаются со счёта
+// банка SystemLoans и возвращаются на него. Дни передаются как полночь UTC.
+type LoanRepository interface {
+	// CreateLoan выдаёт кредит: строит график LoanSchedule с loan.StartDate,
+	// зачисляет Principal на счёт AccountID (с доступом transact у UserID, иначе
+	// sql.ErrNoRows) и заполняет ID, Outstanding, Status и CreatedAt.
+	CreateLoan(ctx context.Context, loan *models.Loan, entry models.AuditEntry) error
+	// GetLoan возвращает кредит userID вместе с графиком.
+	GetLoan(ctx context.Context, loanID, userID int64) (*models.Loan, error)
+	// ListLoans возвращает кредиты пользователя без графиков, начиная с ранних.
+	ListLoans(ctx context.Context, userID int64) ([]models.Loan, error)
+	// RepayLoan досрочно вносит amount со счёта кредита (только из положительного
+	// остатка, иначе ErrInsufficientFunds): см. RepayLoanEarly.
+	RepayLoan(ctx context.Context, loanID, userID int64, amount float64, day time.Time) (*models.LoanRepayment, error)
+	// CollectLoanPayments списывает наступившие к дню day платежи по всем
+	// кредитам (см. CollectLoan). Повторный запуск за тот же день только
+	// повторяет попытку списать просрочку.
+	CollectLoanPayments(ctx context.Context, day time.Time) (*models.LoanCollection, error)
+}
+
+// PaymentRequestRepository — запросы денег между пользователями. Запрос виден
+// только запросившему и плательщику, для остальных — sql.ErrNoRows. Закрыть
+// можно только запрос в статусе pending, срок которого к now не истёк, иначе
+// ErrPaymentRequestClosed.
+type PaymentRequestRepository interface {
+	// CreatePaymentRequest сохраняет запрос в статусе pending и заполняет ID,
+	// Status, Requester, Payer и CreatedAt. К счёту ToAccountID у RequesterID
+	// должен быть доступ transact, иначе sql.ErrNoRows.
+	CreatePaymentRequest(ctx context.Context, req *models.PaymentRequest) error
+	GetPaymentRequest(ctx context.Context, requestID, userID int64) (*models.PaymentRequest, error)
+	// ListPaymentRequests возвращает входящие (userID — плательщик) или исходящие
+	// запросы, начиная с последних. Непустой status оставляет запросы в этом статусе.
+	ListPaymentRequests(ctx context.Context, userID int64, incoming bool, status string) ([]models.PaymentRequest, error)
+	// AcceptPaymentRequest оплачивает запрос плательщиком payerID: переводит сумму
+	// со счёта fromAccountID по правилам TransferFunds и в той же транзакции
+	// отмечает запрос принятым.
+	AcceptPaymentRequest(ctx context.Context, requestID, payerID, fromAccountID int64, now time.Time) (*models.PaymentRequest, error)
+	// ResolvePaymentRequest закрывает запрос без перевода: в статус declined — от
+	// имени плательщика, cancelled — от имени запросившего. Другой стороне
+	// запрос не найден.
+	ResolvePaymentRequest(ctx context.Context, requestID, userID int64, status string, now time.Time) (*models.PaymentRequest, error)
+	// ExpirePaymentRequests переводит в expired запросы в статусе pending, срок
+	// которых истёк к now, и возвращает их.
+	ExpirePaymentRequests(ctx context.Context, now time.Time) ([]models.PaymentRequest, error)
+}
+
+// HoldRepository — блокировки средств (двухфазные платежи). Блокировка видна
+// пользователям с доступом view к счёту или счёту-получателю, для остальных —
+// sql.ErrNoRows. Списать или снять можно только активную блокировку, срок
+// которой к now не истёк, иначе ErrHoldClosed; это делает пользователь с
+// доступом transact к счёту-получателю.
+type HoldRepository interface {
+	// CreateHold резервирует hold.Amount на счёте AccountID (доступ transact у userID)
+	// в пользу ToAccountID: увеличивает Held счёта и заполняет ID, Status и
+	// CreatedAt. Правила как у TransferFunds: счёт-получатель не может быть
+	// счётом банка, замороженные счета — ErrAccountFrozen, со сберегательного —
+	// только на счета с доступом transact у userID, сумма не больше Available,
+	// иначе ErrInsufficientFunds.
+	CreateHold(ctx context.Context, hold *models.Hold, userID int64) error
+	GetHold(ctx context.Context, holdID, userID int64) (*models.Hold, error)
+	// ListHolds возвращает блокировки на доступных userID счетах и в их пользу,
+	// начиная с последних. Непустой status оставляет блокировки в этом статусе.
+	ListHolds(ctx context.Context, userID int64, status string) ([]models.Hold, error)
+	// CaptureHold снимает блокировку и в той же транзакции переводит amount (не
+	// больше заблокированной суммы, иначе ErrCaptureExceedsHold) получателю по
+	// правилам TransferFunds. Остаток блокировки освобождается.
+	CaptureHold(ctx context.Context, holdID, userID int64, amount float64, now time.Time) (*models.Hold, error)
+	// ReleaseHold снимает блокировку без списания.
+	ReleaseHold(ctx context.Context, holdID, userID int64, now time.Time) (*models.Hold, error)
+	// ExpireHolds снимает активные блокировки, срок которых истёк к now, и возвращает их.
+	ExpireHolds(ctx context.Context, now time.Time) ([]models.Hold, error)
+}
+
+// BatchRepository — пакетные переводы. Пакет виден только автору, для
+// остальных — sql.ErrNoRows.
+type BatchRepository interface {
+	// CreateBatch сохраняет пакет со строками в статусе pending и заполняет ID,
+	// Status и CreatedAt. К счёту FromAccountID у UserID должен быть доступ
+	// transact, иначе sql.ErrNoRows. Пакет с непустым MessageID, у которого
+	// уже есть пакет того же пользователя с теми же MessageID и PaymentInfoID, —
+	// ErrDuplicate.
+	CreateBatch(ctx context.Context, batch *models.Batch) error
+	// CreateBatches сохраняет пакеты одного сообщения, как CreateBatch, в одной
+	// транзакции: при ошибке не сохраняется ни один пакет.
+	CreateBatches(ctx context.Context, batches []*models.Batch) error
+	// GetBatch возвращает пакет со строками.
+	GetBatch(ctx context.Context, batchID, userID int64) (*models.Batch, error)
+	// ListBatches возвращает пакеты пользователя со сводкой, но без строк,
+	// начиная с последних.
+	ListBatches(ctx context.Context, userID int64) ([]models.Batch, error)
+	// PendingBatches возвращает ID пакетов в статусе pending, начиная с ранних.
+	PendingBatches(ctx context.Context) ([]int64, error)
+	// ExecuteBatch переводит невыполненные строки пакета по правилам
+	// TransferFunds от имени автора и возвращает пакет с результатами.
+	// Отказ по правилам перевода записывается в строку, а не возвращается.
+	// Обычный пакет выполняется построчно, каждая строка — своей транзакцией,
+	// поэтому прерванное выполнение можно продолжить. Пакет all_or_nothing
+	// выполняется одной транзакцией: при отказе в строке ни один перевод не
+	// проводится, строка помечается failed, остальные — skipped, пакет — failed.
+	// Уже выполненный пакет — ErrBatchClosed.
+	ExecuteBatch(ctx context.Context, batchID int64, now time.Time) (*models.Batch, error)
+}
+
+// ApprovalRepository — переводы выше порога одобрения счёта (maker-checker).
+// Перевод видят его автор и одобряющие счёта списания; одобрить или
+// отклонить его может только одобряющий, не являющийся автором (иначе sql.ErrNoRows).
+type ApprovalRepository interface {
+	// CreateApproval сохраняет перевод пользователя с доступом transact к
+	// счёту списания (MakerID) со статусом pending. Ключ идемпотентности, уже
+	// использованный для другого перевода на одобрении со счёта, — ErrDuplicate.
+	CreateApproval(ctx context.Context, approval *models.TransferApproval) error
+	// GetApprovalByKey возвращает перевод на одобрении по ключу идемпотентности.
+	GetApprovalByKey(ctx context.Context, fromAccountID int64, idempotencyKey string) (*models.TransferApproval, error)
+	GetApproval(ctx context.Context, approvalID, userID int64) (*models.TransferApproval, error)
+	// ListApprovals возвращает переводы, которые видит userID, начиная с
+	// последних; непустой status оставляет переводы в этом статусе.
+	ListApprovals(ctx context.Context, userID int64, status string) ([]models.TransferApproval, error)
+	// Approvers возвращает пользователей, одобряющих переводы со счёта.
+	Approvers(ctx context.Context, accountID int64) ([]int64, error)
+	// ApproveTransfer одобряет перевод и в той же транзакции выполняет его по
+	// правилам TransferFunds без проверки порога. Если перевод не прошёл,
+	// он остаётся на одобрении. Рассмотренный или истёкший — ErrApprovalClosed.
+	ApproveTransfer(ctx context.Context, approvalID, checkerID int64, now time.Time) (*models.TransferApproval, error)
+	// RejectTransfer отклоняет перевод с причиной reason.
+	RejectTransfer(ctx context.Context, approvalID, checkerID int64, reason string, now time.Time) (*models.TransferApproval, error)
+	// ExpireApprovals переводит не рассмотренные к now переводы в expired и возвращает их.
+	ExpireApprovals(ctx context.Context, now time.Time) ([]models.TransferApproval, error)
+}
+
+// AdminRepository — операции поддержки без проверки владельца счёта. Каждое
+// изменение записывается в журнал аудита в той же транзакции.
+type AdminRepository interface {
+	// GetAccountByID возвращает счёт независимо от владельца.
+	GetAccountByID(ctx context.Context, accountID int64) (*models.Account, error)
+	ListAccountsByUserID(ctx context.Context, userID int64) ([]models.Account, error)
+	// ListTransactions возвращает движения по счёту, как GetTransactions, но
+	// без проверки доступа — в том числе по служебным счетам. Нет счёта — sql.ErrNoRows.
+	ListTransactions(ctx context.Context, accountID int64, limit, offset int) ([]models.Transaction, error)
+	// SetAccountFrozen замораживает или размораживает счёт.
+	SetAccountFrozen(ctx context.Context, accountID int64, frozen bool, entry models.AuditEntry) error
+	// AdjustBalance зачисляет (amount > 0) или списывает (amount < 0) средства
+	// и записывает движение вида KindAdjustment. Списание сверх баланса даёт ErrInsufficientFunds.
+	AdjustBalance(ctx context.Context, accountID int64, amount float64, entry models.AuditEntry) (*models.Transaction, error)
+	// ReverseTransaction сторнирует ещё не возвращённый остаток перевода или
+	// комиссии transactionID движением KindReversal с кодом reasonCode (остальные
+	// виды — ErrNotRefundable, нет остатка — ErrTransferRefunded). Заморозка и
+	// лимиты не проверяются: ушедший в минус счёт покажет Reconcile. В entry
+	// подставляются счёт, которому вернулись деньги, и сумма сторно.
+	ReverseTransaction(ctx context.Context, transactionID int64, reasonCode string, entry models.AuditEntry) (*models.Transaction, error)
+	// SetOverdraft задаёт условия овердрафта расчётного счёта (иначе ErrOverdraftNotAllowed).
+	// Лимит нельзя опустить ниже текущего минуса (ErrOverdraftInUse).
+	SetOverdraft(ctx context.Context, accountID int64, overdraft models.Overdraft, entry models.AuditEntry) error
+	// SetApprovalPolicy задаёт порог одобрения счёта и одобряющих переводы
+	// пользователей (заменяя прежних); нулевой порог отключает одобрение.
+	SetApprovalPolicy(ctx context.Context, accountID int64, threshold float64, approverIDs []int64, entry models.AuditEntry) error
+	// ListAuditLog возвращает записи журнала по счёту (все при accountID == 0), начиная с последних.
+	ListAuditLog(ctx context.Context, accountID int64, limit int) ([]models.AuditEntry, error)
+	// Reconcile проверяет инварианты хранимых данных: балансы счетов сверяются
+	// с суммой движений, а сумма всех балансов — с движениями без второго счёта.
+	Reconcile(ctx context.Context) (*models.Reconciliation, error)
+}
+
+// AccessRepository — совместные счета: доступ пользователей к счетам с уровнями
+// models.AccessLevels. Владелец получает доступ manage при создании счёта,
+// остальные — по приглашению пользователя с доступом manage, которое
+// действует после принятия.
+type AccessRepository interface {
+	// InviteAccess сохраняет приглашение access.UserID к счёту AccountID с
+	// уровнем Level от имени InvitedByID (нужен доступ manage, иначе
+	// sql.ErrNoRows) и заполняет остальные поля. Пользователь, у которого уже
+	// есть доступ или приглашение, — ErrDuplicate.
+	InviteAccess(ctx context.Context, access *models.AccountAccess) error
+	// ListAccess возвращает доступы и приглашения к счёту, если у userID есть
+	// доступ view, начиная с владельца.
+	ListAccess(ctx context.Context, accountID, userID int64) ([]models.AccountAccess, error)
+	// ListInvitations возвращает приглашения userID, ждущие принятия.
+	ListInvitations(ctx context.Context, userID int64) ([]models.AccountAccess, error)
+	// AcceptAccess принимает приглашение userID к счёту; без приглашения — sql.ErrNoRows.
+	AcceptAccess(ctx context.Context, accountID, userID int64, now time.Time) (*models.AccountAccess, error)
+	// RevokeAccess удаляет доступ или приглашение targetID к счёту и возвращает
+	// его. Это может пользователь userID с доступом manage или сам targetID
+	// (отказ от приглашения или выход из счёта). Доступ владельца — ErrOwnerAccess.
+	RevokeAccess(ctx context.Context, accountID, userID, targetID int64) (*models.AccountAccess, error)
+}
+
+// PayeeRepository — адресная книга: получатели видны только своему
+// пользователю, для остальных — sql.ErrNoRows. Nickname уникален в книге
+// пользователя, повтор — ErrDuplicate.
+type PayeeRepository interface {
+	// CreatePayee сохраняет получателя и заполняет ID, Username и CreatedAt.
+	// Получатель ToUserID или ToAccountID должен существовать, иначе sql.ErrNoRows.
+	CreatePayee(ctx context.Context, payee *models.Payee) error
+	GetPayee(ctx context.Context, payeeID, userID int64) (*models.Payee, error)
+	// ListPayees возвращает получателей userID по nickname.
+	ListPayees(ctx context.Context, userID int64) ([]models.Payee, error)
+	// UpdatePayee заменяет nickname, получателя, назначение и CoolingOffUntil
+	// получателя payee.ID пользователя payee.UserID и заполняет остальные поля.
+	UpdatePayee(ctx context.Context, payee *models.Payee) error
+	DeletePayee(ctx context.Context, payeeID, userID int64) error
+	// SentToAccount возвращает сумму переводов на счёт toAccountID с момента
+	// since со всех счетов, к которым у userID есть доступ transact.
+	SentToAccount(ctx context.Context, userID, toAccountID int64, since time.Time) (float64, error)
+}
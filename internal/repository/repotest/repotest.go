@@ -0,0 +1,1851 @@
+// Package repotest — общий набор тестов, который должна проходить каждая
+// реализация репозиториев (PostgreSQL, SQLite, в памяти).
+package repotest
+
+import (
+	"banking-api/internal/accountno"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Repos — набор репозиториев одной реализации поверх общего хранилища.
+type Repos struct {
+	Users     repository.UserRepository
+	Accounts  repository.AccountRepository
+	Admin     repository.AdminRepository
+	Interest  repository.InterestRepository
+	Loans     repository.LoanRepository
+	Requests  repository.PaymentRequestRepository
+	Holds     repository.HoldRepository
+	Batches   repository.BatchRepository
+	Approvals repository.ApprovalRepository
+	Access    repository.AccessRepository
+	Payees    repository.PayeeRepository
+}
+
+// Run прогоняет набор тестов. newRepos должен возвращать репозитории поверх пустого хранилища.
+func Run(t *testing.T, newRepos func(t *testing.T) Repos) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r Repos)
+	}{
+		{"Users", testUsers},
+		{"Accounts", testAccounts},
+		{"TransferFunds", testTransferFunds},
+		{"TransferFundsErrors", testTransferFundsErrors},
+		{"ConcurrentTransfers", testConcurrentTransfers},
+		{"IdempotentTransfers", testIdempotentTransfers},
+		{"Transactions", testTransactions},
+		{"FrozenAccounts", testFrozenAccounts},
+		{"Adjustments", testAdjustments},
+		{"Reconcile", testReconcile},
+		{"ConcurrentReconcile", testConcurrentReconcile},
+		{"CreditAccounts", testCreditAccounts},
+		{"SavingsAccounts", testSavingsAccounts},
+		{"Interest", testInterest},
+		{"Overdraft", testOverdraft},
+		{"OverdraftInterest", testOverdraftInterest},
+		{"LowBalanceThreshold", testLowBalanceThreshold},
+		{"Loans", testLoans},
+		{"PaymentRequests", testPaymentRequests},
+		{"Holds", testHolds},
+		{"Refunds", testRefunds},
+		{"Batches", testBatches},
+		{"AllOrNothingBatches", testAllOrNothingBatches},
+		{"BatchMessages", testBatchMessages},
+		{"Statements", testStatements},
+		{"Approvals", testApprovals},
+		{"JointAccounts", testJointAccounts},
+		{"Payees", testPayees},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepos(t))
+		})
+	}
+}
+
+func createUser(t *testing.T, r Repos, name string) *models.User {
+	t.Helper()
+	user := &models.User{Email: name + "@example.com", Username: name, PasswordHash: "hash-" + name}
+	if err := r.Users.CreateUser(context.Background(), user); err != nil {
+		t.Fatalf("CreateUser(%s): %v", name, err)
+	}
+	return user
+}
+
+func createAccount(t *testing.T, r Repos, userID int64, balance float64) int64 {
+	t.Helper()
+	return createTypedAccount(t, r, models.Account{UserID: userID}, balance)
+}
+
+// createTypedAccount создаёт счёт с типом и лимитами из acc.
+func createTypedAccount(t *testing.T, r Repos, acc models.Account, balance float64) int64 {
+	t.Helper()
+	ctx := context.Background()
+	if err := r.Accounts.CreateAccount(ctx, &acc); err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	if balance > 0 {
+		if err := r.Accounts.TopUpAccount(ctx, acc.ID, acc.UserID, balance); err != nil {
+			t.Fatalf("TopUpAccount: %v", err)
+		}
+	}
+	return acc.ID
+}
+
+func assertBalance(t *testing.T, r Repos, accountID, userID int64, want float64) {
+	t.Helper()
+	acc, err := r.Accounts.GetAccount(context.Background(), accountID, userID)
+	if err != nil {
+		t.Fatalf("GetAccount(%d): %v", accountID, err)
+	}
+	if acc.Balance != want {
+		t.Errorf("баланс счёта %d = %v, ожидалось %v", accountID, acc.Balance, want)
+	}
+}
+
+func testUsers(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	if alice.ID == 0 || alice.CreatedAt.IsZero() {
+		t.Fatalf("CreateUser не заполнил ID и CreatedAt: %+v", alice)
+	}
+
+	err := r.Users.CreateUser(ctx, &models.User{Email: "alice@example.com", Username: "other", PasswordHash: "x"})
+	if !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("повторный email: ожидалась ErrDuplicate, получено %v", err)
+	}
+
+	for _, c := range []struct{ email, username string }{
+		{"alice@example.com", "nobody"},
+		{"nobody@example.com", "alice"},
+	} {
+		taken, err := r.Users.IsEmailOrUsernameTaken(ctx, c.email, c.username)
+		if err != nil || !taken {
+			t.Errorf("IsEmailOrUsernameTaken(%s, %s) = %v, %v; ожидалось true", c.email, c.username, taken, err)
+		}
+	}
+	if taken, err := r.Users.IsEmailOrUsernameTaken(ctx, "nobody@example.com", "nobody"); err != nil || taken {
+		t.Errorf("IsEmailOrUsernameTaken для свободных значений = %v, %v", taken, err)
+	}
+
+	byEmail, err := r.Users.GetUserByEmail(ctx, "alice@example.com")
+	if err != nil {
+		t.Fatalf("GetUserByEmail: %v", err)
+	}
+	if byEmail.ID != alice.ID || byEmail.PasswordHash != "hash-alice" {
+		t.Errorf("GetUserByEmail = %+v", byEmail)
+	}
+	if id, err := r.Users.GetUserIDByUsername(ctx, "alice"); err != nil || id != alice.ID {
+		t.Errorf("GetUserIDByUsername = %d, %v", id, err)
+	}
+	if u, err := r.Users.GetUserByID(ctx, alice.ID); err != nil || u.Email != "alice@example.com" || u.Username != "alice" || u.CreatedAt.IsZero() {
+		t.Errorf("GetUserByID = %+v, %v", u, err)
+	}
+
+	if _, err := r.Users.GetUserByEmail(ctx, "nobody@example.com"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUserByEmail для неизвестного email: %v", err)
+	}
+	if _, err := r.Users.GetUserIDByUsername(ctx, "nobody"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUserIDByUsername для неизвестного username: %v", err)
+	}
+	if _, err := r.Users.GetUserByID(ctx, alice.ID+100); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUserByID для неизвестного ID: %v", err)
+	}
+}
+
+func testAccounts(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+
+	if _, err := r.Accounts.GetFirstAccountByUserID(ctx, alice.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetFirstAccountByUserID без счетов: %v", err)
+	}
+
+	first := &models.Account{UserID: alice.ID}
+	if err := r.Accounts.CreateAccount(ctx, first); err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	if first.ID == 0 || first.Type != models.AccountChecking || first.Balance != 0 || first.CreatedAt.IsZero() {
+		t.Errorf("CreateAccount = %+v", first)
+	}
+	if got, err := r.Accounts.GetAccount(ctx, first.ID, alice.ID); err != nil || got.Type != models.AccountChecking || got.CreditLimit != 0 ||
+		got.Number != first.Number {
+		t.Errorf("GetAccount = %+v, %v", got, err)
+	}
+	createAccount(t, r, alice.ID, 0)
+
+	// Номер выдаётся при создании и уникален
+	if err := accountno.Validate(first.Number); err != nil {
+		t.Errorf("номер счёта %q: %v", first.Number, err)
+	}
+	if id, err := r.Accounts.GetAccountIDByNumber(ctx, first.Number); err != nil || id != first.ID {
+		t.Errorf("GetAccountIDByNumber = %d, %v; ожидался %d", id, err, first.ID)
+	}
+	if _, err := r.Accounts.GetAccountIDByNumber(ctx, accountno.New()); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetAccountIDByNumber для неизвестного номера: %v", err)
+	}
+	if err := r.Accounts.CreateAccount(ctx, &models.Account{UserID: bob.ID, Number: first.Number}); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("повторный номер счёта: ожидалась ErrDuplicate, получено %v", err)
+	}
+
+	if id, err := r.Accounts.GetFirstAccountByUserID(ctx, alice.ID); err != nil || id != first.ID {
+		t.Errorf("GetFirstAccountByUserID = %d, %v; ожидался %d", id, err, first.ID)
+	}
+	if id, err := r.Accounts.GetUserIDByAccountID(ctx, first.ID); err != nil || id != alice.ID {
+		t.Errorf("GetUserIDByAccountID = %d, %v", id, err)
+	}
+
+	// Суммы хранятся с точностью до копеек
+	for _, amount := range []float64{0.1, 0.2} {
+		if err := r.Accounts.TopUpAccount(ctx, first.ID, alice.ID, amount); err != nil {
+			t.Fatalf("TopUpAccount: %v", err)
+		}
+	}
+	assertBalance(t, r, first.ID, alice.ID, 0.3)
+
+	if err := r.Accounts.TopUpAccount(ctx, first.ID, bob.ID, 10); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("пополнение чужого счёта: %v", err)
+	}
+	if _, err := r.Accounts.GetAccount(ctx, first.ID, bob.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("чтение чужого счёта: %v", err)
+	}
+}
+
+func testTransferFunds(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	from := createAccount(t, r, alice.ID, 100)
+	to := createAccount(t, r, bob.ID, 0)
+
+	if err := r.Accounts.TransferFunds(ctx, from, to, alice.ID, 30.5, ""); err != nil {
+		t.Fatalf("TransferFunds: %v", err)
+	}
+	assertBalance(t, r, from, alice.ID, 69.5)
+	assertBalance(t, r, to, bob.ID, 30.5)
+
+	// Весь остаток можно перевести
+	if err := r.Accounts.TransferFunds(ctx, from, to, alice.ID, 69.5, ""); err != nil {
+		t.Fatalf("перевод всего остатка: %v", err)
+	}
+	assertBalance(t, r, from, alice.ID, 0)
+}
+
+func testTransferFundsErrors(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	from := createAccount(t, r, alice.ID, 100)
+	to := createAccount(t, r, bob.ID, 0)
+
+	tests := []struct {
+		name     string
+		from, to int64
+		userID   int64
+		amount   float64
+		want     error
+	}{
+		{"чужой счёт", from, to, bob.ID, 10, sql.ErrNoRows},
+		{"несуществующий счёт", to + 100, to, alice.ID, 10, sql.ErrNoRows},
+		{"недостаточно средств", from, to, alice.ID, 100.01, repository.ErrInsufficientFunds},
+		{"несуществующий получатель", from, to + 100, alice.ID, 10, sql.ErrNoRows},
+	}
+	for _, tt := range tests {
+		err := r.Accounts.TransferFunds(ctx, tt.from, tt.to, tt.userID, tt.amount, "")
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: ожидалась %v, получено %v", tt.name, tt.want, err)
+		}
+		// Неудачный перевод ничего не меняет
+		assertBalance(t, r, from, alice.ID, 100)
+		assertBalance(t, r, to, bob.ID, 0)
+	}
+}
+
+func testConcurrentTransfers(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	from := createAccount(t, r, alice.ID, 100)
+	to := createAccount(t, r, bob.ID, 0)
+
+	const workers = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := r.Accounts.TransferFunds(ctx, from, to, alice.ID, 10, "")
+			if err != nil && !errors.Is(err, repository.ErrInsufficientFunds) {
+				t.Errorf("TransferFunds: %v", err)
+			}
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 10 {
+		t.Errorf("успешных переводов %d, ожидалось 10", succeeded)
+	}
+	assertBalance(t, r, from, alice.ID, 0)
+	assertBalance(t, r, to, bob.ID, 100)
+}
+
+func testIdempotentTransfers(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	from := createAccount(t, r, alice.ID, 100)
+	to := createAccount(t, r, bob.ID, 0)
+	other := createAccount(t, r, bob.ID, 0)
+
+	if err := r.Accounts.TransferFunds(ctx, from, to, alice.ID, 10, "key-1"); err != nil {
+		t.Fatalf("TransferFunds: %v", err)
+	}
+	// Повтор с тем же ключом не списывает повторно, даже если средств уже не хватило бы
+	if err := r.Accounts.TransferFunds(ctx, from, to, alice.ID, 10, "key-1"); !errors.Is(err, repository.ErrAlreadyApplied) {
+		t.Errorf("повтор: %v", err)
+	}
+	assertBalance(t, r, from, alice.ID, 90)
+	assertBalance(t, r, to, bob.ID, 10)
+
+	for _, tt := range []struct {
+		name   string
+		to     int64
+		amount float64
+	}{
+		{"другая сумма", to, 11},
+		{"другой получатель", other, 10},
+	} {
+		if err := r.Accounts.TransferFunds(ctx, from, tt.to, alice.ID, tt.amount, "key-1"); !errors.Is(err, repository.ErrIdempotencyConflict) {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+
+	// Ключ уникален в пределах счёта списания; пустой ключ не ограничивает повторы
+	if err := r.Accounts.TransferFunds(ctx, to, from, bob.ID, 1, "key-1"); err != nil {
+		t.Errorf("тот же ключ с другого счёта: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := r.Accounts.TransferFunds(ctx, from, to, alice.ID, 1, ""); err != nil {
+			t.Errorf("перевод без ключа: %v", err)
+		}
+	}
+	assertBalance(t, r, from, alice.ID, 89)
+
+	// Параллельные повторы с одним ключом дают ровно один перевод
+	const workers = 10
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := r.Accounts.TransferFunds(ctx, from, to, alice.ID, 5, "key-2")
+			if err != nil && !errors.Is(err, repository.ErrAlreadyApplied) {
+				t.Errorf("TransferFunds: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	assertBalance(t, r, from, alice.ID, 84)
+}
+
+func testTransactions(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	aliceAcc := createAccount(t, r, alice.ID, 100)
+	bobAcc := createAccount(t, r, bob.ID, 100)
+	otherAcc := createAccount(t, r, bob.ID, 0)
+
+	for _, tr := range []struct {
+		from, to, userID int64
+		amount           float64
+	}{
+		{aliceAcc, bobAcc, alice.ID, 1},
+		{bobAcc, aliceAcc, bob.ID, 2},
+		{bobAcc, otherAcc, bob.ID, 3},
+		{aliceAcc, otherAcc, alice.ID, 4},
+	} {
+		if err := r.Accounts.TransferFunds(ctx, tr.from, tr.to, tr.userID, tr.amount, ""); err != nil {
+			t.Fatalf("TransferFunds: %v", err)
+		}
+	}
+
+	got, err := r.Accounts.GetTransactions(ctx, aliceAcc, alice.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("GetTransactions: %v", err)
+	}
+	var amounts []float64
+	for _, tr := range got {
+		amounts = append(amounts, tr.Amount)
+	}
+	// Последнее движение — пополнение при создании счёта
+	if len(amounts) != 4 || amounts[0] != 4 || amounts[1] != 2 || amounts[2] != 1 || got[3].Kind != models.KindTopUp {
+		t.Errorf("история счёта = %v, ожидалось [4 2 1 100]", amounts)
+	}
+	if got[0].FromAccountID != aliceAcc || got[0].ToAccountID != otherAcc || got[0].Kind != models.KindTransfer || got[0].CreatedAt.IsZero() {
+		t.Errorf("транзакция = %+v", got[0])
+	}
+	alicesAccount, _ := r.Accounts.GetAccount(ctx, aliceAcc, alice.ID)
+	if got[0].FromAccount != alicesAccount.Number || got[0].ToAccount == "" {
+		t.Errorf("номера счетов транзакции = %q, %q; ожидался %q", got[0].FromAccount, got[0].ToAccount, alicesAccount.Number)
+	}
+
+	page, err := r.Accounts.GetTransactions(ctx, aliceAcc, alice.ID, 1, 1)
+	if err != nil || len(page) != 1 || page[0].Amount != 2 {
+		t.Errorf("вторая страница = %+v, %v", page, err)
+	}
+	empty, err := r.Accounts.GetTransactions(ctx, aliceAcc, alice.ID, 10, 10)
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("пустая страница = %#v, %v", empty, err)
+	}
+	if _, err := r.Accounts.GetTransactions(ctx, aliceAcc, bob.ID, 10, 0); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("история чужого счёта: %v", err)
+	}
+}
+
+func testFrozenAccounts(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	aliceAcc := createAccount(t, r, alice.ID, 100)
+	bobAcc := createAccount(t, r, bob.ID, 100)
+
+	entry := models.AuditEntry{Actor: "support", Action: models.AuditFreeze, AccountID: aliceAcc, Reason: "проверка"}
+	if err := r.Admin.SetAccountFrozen(ctx, aliceAcc, true, entry); err != nil {
+		t.Fatalf("SetAccountFrozen: %v", err)
+	}
+	if acc, err := r.Accounts.GetAccount(ctx, aliceAcc, alice.ID); err != nil || !acc.Frozen {
+		t.Errorf("GetAccount = %+v, %v; ожидался замороженный счёт", acc, err)
+	}
+	if err := r.Accounts.TopUpAccount(ctx, aliceAcc, alice.ID, 10); !errors.Is(err, repository.ErrAccountFrozen) {
+		t.Errorf("пополнение замороженного счёта: %v", err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, alice.ID, 10, ""); !errors.Is(err, repository.ErrAccountFrozen) {
+		t.Errorf("перевод с замороженного счёта: %v", err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, bobAcc, aliceAcc, bob.ID, 10, ""); !errors.Is(err, repository.ErrAccountFrozen) {
+		t.Errorf("перевод на замороженный счёт: %v", err)
+	}
+	assertBalance(t, r, aliceAcc, alice.ID, 100)
+	assertBalance(t, r, bobAcc, bob.ID, 100)
+
+	entry.Action = models.AuditUnfreeze
+	if err := r.Admin.SetAccountFrozen(ctx, aliceAcc, false, entry); err != nil {
+		t.Fatalf("SetAccountFrozen: %v", err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, alice.ID, 10, ""); err != nil {
+		t.Errorf("перевод после разморозки: %v", err)
+	}
+	if err := r.Admin.SetAccountFrozen(ctx, bobAcc+100, true, entry); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("заморозка несуществующего счёта: %v", err)
+	}
+
+	log, err := r.Admin.ListAuditLog(ctx, aliceAcc, 10)
+	if err != nil {
+		t.Fatalf("ListAuditLog: %v", err)
+	}
+	if len(log) != 2 || log[0].Action != models.AuditUnfreeze || log[1].Action != models.AuditFreeze {
+		t.Fatalf("журнал = %+v, ожидались unfreeze и freeze", log)
+	}
+	if log[1].Actor != "support" || log[1].Reason != "проверка" || log[1].AccountID != aliceAcc || log[1].CreatedAt.IsZero() {
+		t.Errorf("запись журнала = %+v", log[1])
+	}
+}
+
+func testAdjustments(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	aliceAcc := createAccount(t, r, alice.ID, 100)
+	bobAcc := createAccount(t, r, bob.ID, 0)
+
+	credit := models.AuditEntry{Actor: "support", Action: models.AuditAdjust, AccountID: aliceAcc, Amount: 25.5, Reason: "компенсация"}
+	tr, err := r.Admin.AdjustBalance(ctx, aliceAcc, 25.5, credit)
+	if err != nil {
+		t.Fatalf("AdjustBalance: %v", err)
+	}
+	if tr.ID == 0 || tr.Kind != models.KindAdjustment || tr.ToAccountID != aliceAcc || tr.FromAccountID != 0 || tr.Amount != 25.5 {
+		t.Errorf("зачисление = %+v", tr)
+	}
+	debit := credit
+	debit.Amount = -5.5
+	if _, err := r.Admin.AdjustBalance(ctx, aliceAcc, -5.5, debit); err != nil {
+		t.Fatalf("AdjustBalance: %v", err)
+	}
+	assertBalance(t, r, aliceAcc, alice.ID, 120)
+
+	if _, err := r.Admin.AdjustBalance(ctx, aliceAcc, -500, debit); !errors.Is(err, repository.ErrInsufficientFunds) {
+		t.Errorf("списание сверх баланса: %v", err)
+	}
+	if _, err := r.Admin.AdjustBalance(ctx, bobAcc+100, 1, credit); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("корректировка несуществующего счёта: %v", err)
+	}
+	assertBalance(t, r, aliceAcc, alice.ID, 120)
+
+	history, err := r.Accounts.GetTransactions(ctx, aliceAcc, alice.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("GetTransactions: %v", err)
+	}
+	if len(history) != 3 || history[0].Kind != models.KindAdjustment || history[0].FromAccountID != aliceAcc || history[0].ToAccountID != 0 || history[0].Amount != 5.5 {
+		t.Errorf("история = %+v", history)
+	}
+
+	log, err := r.Admin.ListAuditLog(ctx, 0, 1)
+	if err != nil || len(log) != 1 || log[0].Amount != -5.5 {
+		t.Errorf("последняя запись журнала = %+v, %v", log, err)
+	}
+	if log, err := r.Admin.ListAuditLog(ctx, bobAcc, 10); err != nil || len(log) != 0 {
+		t.Errorf("журнал счёта без действий = %+v, %v", log, err)
+	}
+
+	accounts, err := r.Admin.ListAccountsByUserID(ctx, alice.ID)
+	if err != nil || len(accounts) != 1 || accounts[0].ID != aliceAcc || accounts[0].Balance != 120 {
+		t.Errorf("ListAccountsByUserID = %+v, %v", accounts, err)
+	}
+	if acc, err := r.Admin.GetAccountByID(ctx, bobAcc); err != nil || acc.UserID != bob.ID {
+		t.Errorf("GetAccountByID = %+v, %v", acc, err)
+	}
+}
+
+func testReconcile(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	aliceAcc := createAccount(t, r, alice.ID, 100)
+	bobAcc := createAccount(t, r, bob.ID, 50.25)
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, alice.ID, 10, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := r.Admin.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if !report.OK() || report.Accounts != 2 || report.Transactions != 3 || report.TotalBalance != 150.25 || report.ExternalNet != 150.25 {
+		t.Errorf("сверка = %+v", report)
+	}
+}
+
+// testConcurrentReconcile проверяет, что сверка видит согласованный снимок:
+// переводы и пополнения, идущие во время сверки, не дают расхождений.
+func testConcurrentReconcile(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	aliceAcc := createAccount(t, r, alice.ID, 1000)
+	bobAcc := createAccount(t, r, bob.ID, 1000)
+
+	const writes = 50
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < writes; i++ {
+			if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, alice.ID, 1, ""); err != nil {
+				t.Errorf("TransferFunds: %v", err)
+				return
+			}
+			if err := r.Accounts.TopUpAccount(ctx, bobAcc, bob.ID, 1); err != nil {
+				t.Errorf("TopUpAccount: %v", err)
+				return
+			}
+		}
+	}()
+	// Писатель должен завершиться до конца теста, даже если сверка упала
+	defer func() { <-done }()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		report, err := r.Admin.Reconcile(ctx)
+		if err != nil {
+			t.Errorf("Reconcile: %v", err)
+			return
+		}
+		if !report.OK() {
+			t.Errorf("сверка во время записи нашла нарушения: %+v", report)
+			return
+		}
+	}
+}
+
+func testCreditAccounts(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	credit := createTypedAccount(t, r, models.Account{UserID: alice.ID, Type: models.AccountCredit, CreditLimit: 500}, 0)
+	bobAcc := createAccount(t, r, bob.ID, 0)
+
+	acc, err := r.Accounts.GetAccount(ctx, credit, alice.ID)
+	if err != nil || acc.Type != models.AccountCredit || acc.CreditLimit != 500 {
+		t.Fatalf("GetAccount = %+v, %v", acc, err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, credit, bobAcc, alice.ID, 300, ""); err != nil {
+		t.Fatalf("перевод в пределах кредитного лимита: %v", err)
+	}
+	assertBalance(t, r, credit, alice.ID, -300)
+	if err := r.Accounts.TransferFunds(ctx, credit, bobAcc, alice.ID, 200.01, ""); !errors.Is(err, repository.ErrInsufficientFunds) {
+		t.Errorf("перевод сверх кредитного лимита: %v", err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, credit, bobAcc, alice.ID, 200, ""); err != nil {
+		t.Errorf("перевод до кредитного лимита: %v", err)
+	}
+	assertBalance(t, r, credit, alice.ID, -500)
+
+	if report, err := r.Admin.Reconcile(ctx); err != nil || !report.OK() {
+		t.Errorf("минус в пределах лимита не нарушение: %+v, %v", report, err)
+	}
+}
+
+func testSavingsAccounts(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	savings := createTypedAccount(t, r, models.Account{UserID: alice.ID, Type: models.AccountSavings, MonthlyTransferLimit: 2}, 100)
+	checking := createAccount(t, r, alice.ID, 0)
+	bobAcc := createAccount(t, r, bob.ID, 0)
+
+	if err := r.Accounts.TransferFunds(ctx, savings, bobAcc, alice.ID, 10, ""); !errors.Is(err, repository.ErrSavingsExternalTransfer) {
+		t.Errorf("перевод со сберегательного на чужой счёт: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := r.Accounts.TransferFunds(ctx, savings, checking, alice.ID, 10, ""); err != nil {
+			t.Fatalf("перевод %d на свой счёт: %v", i+1, err)
+		}
+	}
+	if err := r.Accounts.TransferFunds(ctx, savings, checking, alice.ID, 10, ""); !errors.Is(err, repository.ErrTransferLimitExceeded) {
+		t.Errorf("третий перевод за месяц: %v", err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, savings, checking, alice.ID, 500, ""); !errors.Is(err, repository.ErrTransferLimitExceeded) {
+		t.Errorf("лимит проверяется раньше баланса: %v", err)
+	}
+	assertBalance(t, r, savings, alice.ID, 80)
+
+	// Входящие переводы и пополнения не ограничены
+	if err := r.Accounts.TransferFunds(ctx, checking, savings, alice.ID, 5, ""); err != nil {
+		t.Errorf("перевод на сберегательный счёт: %v", err)
+	}
+	if err := r.Accounts.TopUpAccount(ctx, savings, alice.ID, 5); err != nil {
+		t.Errorf("пополнение сберегательного счёта: %v", err)
+	}
+}
+
+func testInterest(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	savings := createTypedAccount(t, r, models.Account{UserID: alice.ID, Type: models.AccountSavings}, 1000)
+	checking := createAccount(t, r, alice.ID, 10)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	yesterday := today.AddDate(0, 0, -1)
+	month := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	entry := models.AuditEntry{Actor: "ops", Action: models.AuditSetRate, Reason: "тариф"}
+	if err := r.Interest.SetRate(ctx, models.InterestRate{AccountType: models.AccountSavings, AnnualRate: 1, EffectiveFrom: yesterday}, entry); err != nil {
+		t.Fatalf("SetRate: %v", err)
+	}
+	// Повторная установка на ту же дату заменяет ставку
+	if err := r.Interest.SetRate(ctx, models.InterestRate{AccountType: models.AccountSavings, AnnualRate: 3.65, EffectiveFrom: yesterday}, entry); err != nil {
+		t.Fatalf("SetRate: %v", err)
+	}
+	if rates, err := r.Interest.ListRates(ctx); err != nil || len(rates) != 1 || rates[0].AnnualRate != 3.65 || !rates[0].EffectiveFrom.Equal(yesterday) {
+		t.Errorf("ListRates = %+v, %v", rates, err)
+	}
+
+	if last, err := r.Interest.LastAccrualDay(ctx); err != nil || !last.IsZero() {
+		t.Errorf("LastAccrualDay без начислений = %v, %v", last, err)
+	}
+	// Счёт открыт сегодня: за вчера начислять нечего
+	if n, err := r.Interest.AccrueInterest(ctx, yesterday); err != nil || n != 0 {
+		t.Errorf("AccrueInterest(вчера) = %d, %v", n, err)
+	}
+	if n, err := r.Interest.AccrueInterest(ctx, today); err != nil || n != 1 {
+		t.Fatalf("AccrueInterest(сегодня) = %d, %v", n, err)
+	}
+	if last, err := r.Interest.LastAccrualDay(ctx); err != nil || !last.Equal(today) {
+		t.Errorf("LastAccrualDay = %v, %v", last, err)
+	}
+	if n, err := r.Interest.AccrueInterest(ctx, today); err != nil || n != 0 {
+		t.Errorf("повторное AccrueInterest = %d, %v", n, err)
+	}
+	accruals, err := r.Interest.ListAccruals(ctx, savings, month, month.AddDate(0, 1, 0))
+	if err != nil || len(accruals) != 1 || accruals[0].Balance != 1000 || accruals[0].Amount != 0.1 || !accruals[0].Day.Equal(today) {
+		t.Fatalf("ListAccruals = %+v, %v", accruals, err)
+	}
+
+	report, err := r.Interest.CapitalizeInterest(ctx, month)
+	if err != nil || report.Accounts != 1 || report.Total != 0.1 {
+		t.Fatalf("CapitalizeInterest = %+v, %v", report, err)
+	}
+	if report, err := r.Interest.CapitalizeInterest(ctx, month); err != nil || report.Accounts != 0 {
+		t.Errorf("повторная CapitalizeInterest = %+v, %v", report, err)
+	}
+	assertBalance(t, r, savings, alice.ID, 1000.1)
+	assertBalance(t, r, checking, alice.ID, 10)
+
+	transactions, err := r.Accounts.GetTransactions(ctx, savings, alice.ID, 10, 0)
+	if err != nil || len(transactions) != 2 || transactions[0].Kind != models.KindInterest || transactions[0].Amount != 0.1 {
+		t.Fatalf("GetTransactions = %+v, %v", transactions, err)
+	}
+	if accruals, _ := r.Interest.ListAccruals(ctx, savings, month, month.AddDate(0, 1, 0)); len(accruals) != 1 || !accruals[0].Capitalized {
+		t.Errorf("начисление не отмечено капитализированным: %+v", accruals)
+	}
+
+	// Проценты переводятся со счёта банка, поэтому сумма балансов не меняется
+	reconciliation, err := r.Admin.Reconcile(ctx)
+	if err != nil || !reconciliation.OK() || reconciliation.TotalBalance != 1010 {
+		t.Errorf("сверка = %+v, %v", reconciliation, err)
+	}
+	interestID := transactions[0].FromAccountID
+	if err := r.Accounts.TransferFunds(ctx, checking, interestID, alice.ID, 1, ""); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("перевод на счёт банка: %v", err)
+	}
+}
+
+func testOverdraft(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	checking := createAccount(t, r, alice.ID, 100)
+	savings := createTypedAccount(t, r, models.Account{UserID: alice.ID, Type: models.AccountSavings}, 0)
+	bobAcc := createAccount(t, r, bob.ID, 0)
+	entry := models.AuditEntry{Actor: "ops", Action: models.AuditOverdraft, AccountID: checking, Reason: "заявка"}
+	overdraft := models.Overdraft{Limit: 500, Rate: 36.5, Fee: 10}
+
+	if err := r.Admin.SetOverdraft(ctx, savings, overdraft, entry); !errors.Is(err, repository.ErrOverdraftNotAllowed) {
+		t.Errorf("овердрафт сберегательного счёта: %v", err)
+	}
+	if err := r.Admin.SetOverdraft(ctx, checking, overdraft, entry); err != nil {
+		t.Fatalf("SetOverdraft: %v", err)
+	}
+	acc, err := r.Accounts.GetAccount(ctx, checking, alice.ID)
+	if err != nil || acc.OverdraftLimit != 500 || acc.OverdraftRate != 36.5 || acc.OverdraftFee != 10 {
+		t.Fatalf("GetAccount = %+v, %v", acc, err)
+	}
+
+	// Пока баланс не уходит в минус, комиссии нет
+	if err := r.Accounts.TransferFunds(ctx, checking, bobAcc, alice.ID, 50, ""); err != nil {
+		t.Fatal(err)
+	}
+	assertBalance(t, r, checking, alice.ID, 50)
+	// Уход в минус: перевод и комиссия
+	if err := r.Accounts.TransferFunds(ctx, checking, bobAcc, alice.ID, 100, ""); err != nil {
+		t.Fatalf("перевод в овердрафт: %v", err)
+	}
+	assertBalance(t, r, checking, alice.ID, -60)
+	assertBalance(t, r, bobAcc, bob.ID, 150)
+	transactions, err := r.Accounts.GetTransactions(ctx, checking, alice.ID, 10, 0)
+	if err != nil || len(transactions) != 4 || transactions[0].Kind != models.KindFee || transactions[0].Amount != 10 || transactions[0].FromAccountID != checking {
+		t.Fatalf("GetTransactions = %+v, %v", transactions, err)
+	}
+	// Служебный счёт комиссий без владельца виден администратору
+	fees := transactions[0].ToAccountID
+	if owner, err := r.Accounts.GetUserIDByAccountID(ctx, fees); err != nil || owner != 0 {
+		t.Errorf("владелец счёта комиссий = %d, %v", owner, err)
+	}
+	feeHistory, err := r.Admin.ListTransactions(ctx, fees, 10, 0)
+	if err != nil || len(feeHistory) != 1 || feeHistory[0].ID != transactions[0].ID {
+		t.Errorf("ListTransactions(комиссии) = %+v, %v", feeHistory, err)
+	}
+	if _, err := r.Admin.ListTransactions(ctx, 424242, 10, 0); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("ListTransactions несуществующего счёта: %v", err)
+	}
+
+	// Уже в минусе: комиссия не берётся повторно, лимит соблюдается
+	if err := r.Accounts.TransferFunds(ctx, checking, bobAcc, alice.ID, 440.01, ""); !errors.Is(err, repository.ErrInsufficientFunds) {
+		t.Errorf("перевод сверх овердрафта: %v", err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, checking, bobAcc, alice.ID, 440, ""); err != nil {
+		t.Errorf("перевод до лимита овердрафта: %v", err)
+	}
+	assertBalance(t, r, checking, alice.ID, -500)
+
+	if err := r.Admin.SetOverdraft(ctx, checking, models.Overdraft{Limit: 100}, entry); !errors.Is(err, repository.ErrOverdraftInUse) {
+		t.Errorf("лимит ниже текущего минуса: %v", err)
+	}
+	// Комиссия переходит на счёт банка: сумма балансов равна сумме пополнений
+	report, err := r.Admin.Reconcile(ctx)
+	if err != nil || !report.OK() || report.TotalBalance != 100 {
+		t.Errorf("сверка = %+v, %v", report, err)
+	}
+	if log, err := r.Admin.ListAuditLog(ctx, checking, 10); err != nil || len(log) != 1 || log[0].Action != models.AuditOverdraft {
+		t.Errorf("журнал = %+v, %v", log, err)
+	}
+}
+
+func testOverdraftInterest(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	checking := createAccount(t, r, alice.ID, 0)
+	bobAcc := createAccount(t, r, bob.ID, 0)
+	entry := models.AuditEntry{Actor: "ops", Action: models.AuditOverdraft, AccountID: checking, Reason: "заявка"}
+	if err := r.Admin.SetOverdraft(ctx, checking, models.Overdraft{Limit: 1000, Rate: 36.5}, entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, checking, bobAcc, alice.ID, 500, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	month := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	// Положительный остаток Боба без ставки не даёт начислений
+	if n, err := r.Interest.AccrueInterest(ctx, today); err != nil || n != 1 {
+		t.Fatalf("AccrueInterest = %d, %v", n, err)
+	}
+	accruals, err := r.Interest.ListAccruals(ctx, checking, month, month.AddDate(0, 1, 0))
+	if err != nil || len(accruals) != 1 || accruals[0].Balance != -500 || accruals[0].AnnualRate != 36.5 || accruals[0].Amount != -0.5 {
+		t.Fatalf("ListAccruals = %+v, %v", accruals, err)
+	}
+
+	report, err := r.Interest.CapitalizeInterest(ctx, month)
+	if err != nil || report.Accounts != 1 || report.Total != -0.5 {
+		t.Fatalf("CapitalizeInterest = %+v, %v", report, err)
+	}
+	assertBalance(t, r, checking, alice.ID, -500.5)
+	transactions, err := r.Accounts.GetTransactions(ctx, checking, alice.ID, 1, 0)
+	if err != nil || len(transactions) != 1 || transactions[0].Kind != models.KindInterest || transactions[0].FromAccountID != checking || transactions[0].Amount != 0.5 {
+		t.Errorf("GetTransactions = %+v, %v", transactions, err)
+	}
+	if reconciliation, err := r.Admin.Reconcile(ctx); err != nil || !reconciliation.OK() || reconciliation.TotalBalance != 0 {
+		t.Errorf("сверка = %+v, %v", reconciliation, err)
+	}
+}
+
+func testLowBalanceThreshold(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	acc := createAccount(t, r, alice.ID, 0)
+
+	if err := r.Accounts.SetLowBalanceThreshold(ctx, acc, alice.ID, 25.5); err != nil {
+		t.Fatalf("SetLowBalanceThreshold: %v", err)
+	}
+	if got, err := r.Accounts.GetAccount(ctx, acc, alice.ID); err != nil || got.LowBalanceThreshold != 25.5 {
+		t.Errorf("GetAccount = %+v, %v", got, err)
+	}
+	if err := r.Accounts.SetLowBalanceThreshold(ctx, acc, bob.ID, 10); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("порог для чужого счёта: %v", err)
+	}
+}
+
+func testLoans(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	checking := createAccount(t, r, alice.ID, 0)
+	bobAcc := createAccount(t, r, bob.ID, 0)
+	entry := models.AuditEntry{Actor: "ops", Action: models.AuditLoan, AccountID: checking, Reason: "заявка"}
+	start := time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC)
+	terms := models.LoanTerms{Principal: 1200, AnnualRate: 12, TermMonths: 3, Method: models.LoanAnnuity, LateFee: 5}
+
+	if err := r.Loans.CreateLoan(ctx, &models.Loan{UserID: alice.ID, AccountID: bobAcc, LoanTerms: terms, StartDate: start}, entry); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("кредит на чужой счёт: %v", err)
+	}
+	loan := &models.Loan{UserID: alice.ID, AccountID: checking, LoanTerms: terms, StartDate: start}
+	if err := r.Loans.CreateLoan(ctx, loan, entry); err != nil {
+		t.Fatalf("CreateLoan: %v", err)
+	}
+	if loan.ID == 0 || loan.Outstanding != 1200 || loan.Status != models.LoanActive {
+		t.Fatalf("CreateLoan = %+v", loan)
+	}
+	assertBalance(t, r, checking, alice.ID, 1200)
+	got, err := r.Loans.GetLoan(ctx, loan.ID, alice.ID)
+	if err != nil || len(got.Schedule) != 3 || got.Schedule[0].Principal != 396.03 || got.Schedule[0].Interest != 12 ||
+		!got.Schedule[0].DueDate.Equal(time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("GetLoan = %+v, %v", got, err)
+	}
+	if _, err := r.Loans.GetLoan(ctx, loan.ID, bob.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("чужой кредит: %v", err)
+	}
+
+	// Платёж 408.03, на счёте 200: списывается всё, остальное уходит в просрочку с пенями
+	if err := r.Accounts.TransferFunds(ctx, checking, bobAcc, alice.ID, 1000, ""); err != nil {
+		t.Fatal(err)
+	}
+	feb28 := got.Schedule[0].DueDate
+	if report, err := r.Loans.CollectLoanPayments(ctx, feb28.AddDate(0, 0, -1)); err != nil || report.Loans != 0 || report.Overdue != 0 {
+		t.Errorf("CollectLoanPayments до срока = %+v, %v", report, err)
+	}
+	report, err := r.Loans.CollectLoanPayments(ctx, feb28)
+	if err != nil || report.Loans != 1 || report.Amount != 200 || report.Overdue != 1 {
+		t.Fatalf("CollectLoanPayments = %+v, %v", report, err)
+	}
+	if report, err := r.Loans.CollectLoanPayments(ctx, feb28); err != nil || report.Loans != 0 || report.Overdue != 0 {
+		t.Errorf("повторный CollectLoanPayments = %+v, %v", report, err)
+	}
+	assertBalance(t, r, checking, alice.ID, 0)
+	got, _ = r.Loans.GetLoan(ctx, loan.ID, alice.ID)
+	if got.Outstanding != 1012 || got.Arrears != 213.03 || got.Schedule[0].Status != models.InstallmentOverdue || got.Schedule[0].LateFee != 5 {
+		t.Fatalf("кредит после просрочки = %+v", got)
+	}
+
+	// Досрочное погашение: сначала просрочка, остаток — в основной долг с пересчётом графика
+	if err := r.Accounts.TopUpAccount(ctx, checking, alice.ID, 300); err != nil {
+		t.Fatal(err)
+	}
+	march1 := feb28.AddDate(0, 0, 1)
+	if _, err := r.Loans.RepayLoan(ctx, loan.ID, alice.ID, 5000, march1); !errors.Is(err, repository.ErrLoanOverpayment) {
+		t.Errorf("погашение сверх долга: %v", err)
+	}
+	if _, err := r.Loans.RepayLoan(ctx, loan.ID, alice.ID, 500, march1); !errors.Is(err, repository.ErrInsufficientFunds) {
+		t.Errorf("погашение без средств: %v", err)
+	}
+	repayment, err := r.Loans.RepayLoan(ctx, loan.ID, alice.ID, 300, march1)
+	if err != nil || repayment.Scheduled != 213.03 || repayment.Principal != 86.97 || repayment.Outstanding != 717 {
+		t.Fatalf("RepayLoan = %+v, %v", repayment, err)
+	}
+	got, _ = r.Loans.GetLoan(ctx, loan.ID, alice.ID)
+	if got.Arrears != 0 || len(got.Schedule) != 3 || got.Schedule[0].Status != models.InstallmentPaid ||
+		got.Schedule[1].Principal+got.Schedule[2].Principal != 717 || got.Schedule[1].Interest != 7.17 {
+		t.Fatalf("кредит после досрочного погашения = %+v", got)
+	}
+
+	// Оба оставшихся платежа списываются разом, и кредит закрывается
+	if err := r.Accounts.TopUpAccount(ctx, checking, alice.ID, 1000); err != nil {
+		t.Fatal(err)
+	}
+	rest := got.Schedule[1].Due() + got.Schedule[2].Due()
+	report, err = r.Loans.CollectLoanPayments(ctx, got.Schedule[2].DueDate)
+	if err != nil || report.Amount != rest || report.Closed != 1 || report.Overdue != 0 {
+		t.Fatalf("CollectLoanPayments = %+v, %v", report, err)
+	}
+	loans, err := r.Loans.ListLoans(ctx, alice.ID)
+	if err != nil || len(loans) != 1 || loans[0].Status != models.LoanClosed || loans[0].Outstanding != 0 || loans[0].Schedule != nil {
+		t.Fatalf("ListLoans = %+v, %v", loans, err)
+	}
+	if _, err := r.Loans.RepayLoan(ctx, loan.ID, alice.ID, 1, march1); !errors.Is(err, repository.ErrLoanClosed) {
+		t.Errorf("погашение закрытого кредита: %v", err)
+	}
+
+	transactions, err := r.Accounts.GetTransactions(ctx, checking, alice.ID, 10, 0)
+	if err != nil || len(transactions) != 7 || transactions[6].Kind != models.KindLoanDisbursement || transactions[0].Kind != models.KindLoanRepayment {
+		t.Errorf("GetTransactions = %+v, %v", transactions, err)
+	}
+	// Кредит выдаётся со счёта банка, поэтому сумма балансов равна пополнениям
+	if reconciliation, err := r.Admin.Reconcile(ctx); err != nil || !reconciliation.OK() || reconciliation.TotalBalance != 1300 {
+		t.Errorf("сверка = %+v, %v", reconciliation, err)
+	}
+
+	if err := r.Admin.SetAccountFrozen(ctx, checking, true, entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Loans.CreateLoan(ctx, &models.Loan{UserID: alice.ID, AccountID: checking, LoanTerms: terms, StartDate: start}, entry); !errors.Is(err, repository.ErrAccountFrozen) {
+		t.Errorf("кредит на замороженный счёт: %v", err)
+	}
+}
+
+func testPaymentRequests(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	carol := createUser(t, r, "carol")
+	aliceAcc := createAccount(t, r, alice.ID, 0)
+	bobAcc := createAccount(t, r, bob.ID, 100)
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+	request := func(amount float64, ttl time.Duration) *models.PaymentRequest {
+		t.Helper()
+		req := &models.PaymentRequest{RequesterID: alice.ID, PayerID: bob.ID, ToAccountID: aliceAcc, Amount: amount, Note: "ужин", ExpiresAt: now.Add(ttl)}
+		if err := r.Requests.CreatePaymentRequest(ctx, req); err != nil {
+			t.Fatalf("CreatePaymentRequest: %v", err)
+		}
+		return req
+	}
+
+	if err := r.Requests.CreatePaymentRequest(ctx, &models.PaymentRequest{RequesterID: alice.ID, PayerID: bob.ID, ToAccountID: bobAcc, Amount: 1, ExpiresAt: now}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("запрос на чужой счёт: %v", err)
+	}
+	req := request(60, time.Hour)
+	if req.ID == 0 || req.Status != models.PaymentRequestPending || req.Requester != "alice" || req.Payer != "bob" || req.ResolvedAt != nil {
+		t.Fatalf("CreatePaymentRequest = %+v", req)
+	}
+	if _, err := r.Requests.GetPaymentRequest(ctx, req.ID, carol.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("чужой запрос: %v", err)
+	}
+	if got, err := r.Requests.GetPaymentRequest(ctx, req.ID, bob.ID); err != nil || got.Amount != 60 || got.Note != "ужин" || !got.ExpiresAt.Equal(req.ExpiresAt) {
+		t.Errorf("GetPaymentRequest = %+v, %v", got, err)
+	}
+
+	// Принять может только плательщик, и только со своего счёта и в пределах средств
+	if _, err := r.Requests.AcceptPaymentRequest(ctx, req.ID, alice.ID, aliceAcc, now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("принятие запросившим: %v", err)
+	}
+	second := request(500, time.Hour)
+	if _, err := r.Requests.AcceptPaymentRequest(ctx, second.ID, bob.ID, bobAcc, now); !errors.Is(err, repository.ErrInsufficientFunds) {
+		t.Errorf("принятие без средств: %v", err)
+	}
+	accepted, err := r.Requests.AcceptPaymentRequest(ctx, req.ID, bob.ID, bobAcc, now)
+	if err != nil || accepted.Status != models.PaymentRequestAccepted || accepted.TransactionID == 0 || accepted.ResolvedAt == nil {
+		t.Fatalf("AcceptPaymentRequest = %+v, %v", accepted, err)
+	}
+	assertBalance(t, r, aliceAcc, alice.ID, 60)
+	assertBalance(t, r, bobAcc, bob.ID, 40)
+	if _, err := r.Requests.AcceptPaymentRequest(ctx, req.ID, bob.ID, bobAcc, now); !errors.Is(err, repository.ErrPaymentRequestClosed) {
+		t.Errorf("повторное принятие: %v", err)
+	}
+	transactions, err := r.Accounts.GetTransactions(ctx, aliceAcc, alice.ID, 10, 0)
+	if err != nil || len(transactions) != 1 || transactions[0].ID != accepted.TransactionID || transactions[0].Amount != 60 {
+		t.Errorf("GetTransactions = %+v, %v", transactions, err)
+	}
+
+	// Отклоняет плательщик, отменяет запросивший
+	if _, err := r.Requests.ResolvePaymentRequest(ctx, second.ID, alice.ID, models.PaymentRequestDeclined, now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("отклонение запросившим: %v", err)
+	}
+	if _, err := r.Requests.ResolvePaymentRequest(ctx, second.ID, bob.ID, models.PaymentRequestCancelled, now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("отмена плательщиком: %v", err)
+	}
+	declined, err := r.Requests.ResolvePaymentRequest(ctx, second.ID, bob.ID, models.PaymentRequestDeclined, now)
+	if err != nil || declined.Status != models.PaymentRequestDeclined || declined.ResolvedAt == nil {
+		t.Fatalf("отклонение = %+v, %v", declined, err)
+	}
+	third := request(10, time.Hour)
+	if cancelled, err := r.Requests.ResolvePaymentRequest(ctx, third.ID, alice.ID, models.PaymentRequestCancelled, now); err != nil || cancelled.Status != models.PaymentRequestCancelled {
+		t.Errorf("отмена = %+v, %v", cancelled, err)
+	}
+
+	// Просроченный запрос нельзя принять ещё до того, как он помечен истёкшим
+	stale := request(10, time.Minute)
+	later := now.Add(2 * time.Minute)
+	if _, err := r.Requests.AcceptPaymentRequest(ctx, stale.ID, bob.ID, bobAcc, later); !errors.Is(err, repository.ErrPaymentRequestClosed) {
+		t.Errorf("принятие просроченного: %v", err)
+	}
+	if expired, err := r.Requests.ExpirePaymentRequests(ctx, now); err != nil || len(expired) != 0 {
+		t.Errorf("ExpirePaymentRequests до срока = %+v, %v", expired, err)
+	}
+	expired, err := r.Requests.ExpirePaymentRequests(ctx, later)
+	if err != nil || len(expired) != 1 || expired[0].ID != stale.ID || expired[0].Status != models.PaymentRequestExpired || expired[0].Payer != "bob" {
+		t.Fatalf("ExpirePaymentRequests = %+v, %v", expired, err)
+	}
+	if expired, err := r.Requests.ExpirePaymentRequests(ctx, later); err != nil || len(expired) != 0 {
+		t.Errorf("повторный ExpirePaymentRequests = %+v, %v", expired, err)
+	}
+
+	outgoing, err := r.Requests.ListPaymentRequests(ctx, alice.ID, false, "")
+	if err != nil || len(outgoing) != 4 || outgoing[0].ID != stale.ID || outgoing[3].ID != req.ID {
+		t.Errorf("исходящие = %+v, %v", outgoing, err)
+	}
+	if incoming, err := r.Requests.ListPaymentRequests(ctx, alice.ID, true, ""); err != nil || len(incoming) != 0 {
+		t.Errorf("входящие запросившего = %+v, %v", incoming, err)
+	}
+	incoming, err := r.Requests.ListPaymentRequests(ctx, bob.ID, true, models.PaymentRequestDeclined)
+	if err != nil || len(incoming) != 1 || incoming[0].ID != second.ID {
+		t.Errorf("отклонённые входящие = %+v, %v", incoming, err)
+	}
+}
+
+func testHolds(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	shop := createUser(t, r, "shop")
+	carol := createUser(t, r, "carol")
+	aliceAcc := createAccount(t, r, alice.ID, 100)
+	shopAcc := createAccount(t, r, shop.ID, 0)
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+	authorize := func(amount float64, ttl time.Duration) (*models.Hold, error) {
+		hold := &models.Hold{AccountID: aliceAcc, ToAccountID: shopAcc, Amount: amount, ExpiresAt: now.Add(ttl)}
+		return hold, r.Holds.CreateHold(ctx, hold, alice.ID)
+	}
+	assertHeld := func(want float64) {
+		t.Helper()
+		acc, err := r.Accounts.GetAccount(ctx, aliceAcc, alice.ID)
+		if err != nil || acc.Held != want {
+			t.Errorf("счёт = %+v, %v, ожидалось held %v", acc, err, want)
+		}
+	}
+
+	if err := r.Holds.CreateHold(ctx, &models.Hold{AccountID: aliceAcc, ToAccountID: shopAcc, Amount: 10, ExpiresAt: now}, shop.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("блокировка на чужом счёте: %v", err)
+	}
+	if _, err := authorize(101, time.Hour); !errors.Is(err, repository.ErrInsufficientFunds) {
+		t.Errorf("блокировка сверх остатка: %v", err)
+	}
+	hold, err := authorize(70, time.Hour)
+	if err != nil || hold.ID == 0 || hold.Status != models.HoldActive || hold.Amount != 70 || hold.ResolvedAt != nil {
+		t.Fatalf("CreateHold = %+v, %v", hold, err)
+	}
+	// Деньги не двигаются, но доступный остаток уменьшается
+	assertBalance(t, r, aliceAcc, alice.ID, 100)
+	assertHeld(70)
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, shopAcc, alice.ID, 40, ""); !errors.Is(err, repository.ErrInsufficientFunds) {
+		t.Errorf("перевод заблокированных средств: %v", err)
+	}
+	if _, err := authorize(40, time.Hour); !errors.Is(err, repository.ErrInsufficientFunds) {
+		t.Errorf("вторая блокировка сверх доступного: %v", err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, shopAcc, alice.ID, 30, ""); err != nil {
+		t.Fatalf("перевод доступного остатка: %v", err)
+	}
+
+	if _, err := r.Holds.GetHold(ctx, hold.ID, carol.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("чужая блокировка: %v", err)
+	}
+	if got, err := r.Holds.GetHold(ctx, hold.ID, shop.ID); err != nil || got.Amount != 70 || !got.ExpiresAt.Equal(hold.ExpiresAt) {
+		t.Errorf("GetHold получателем = %+v, %v", got, err)
+	}
+
+	// Списывает только получатель и не больше заблокированного; остаток освобождается
+	if _, err := r.Holds.CaptureHold(ctx, hold.ID, alice.ID, 70, now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("списание плательщиком: %v", err)
+	}
+	if _, err := r.Holds.CaptureHold(ctx, hold.ID, shop.ID, 70.01, now); !errors.Is(err, repository.ErrCaptureExceedsHold) {
+		t.Errorf("списание сверх блокировки: %v", err)
+	}
+	assertHeld(70)
+	captured, err := r.Holds.CaptureHold(ctx, hold.ID, shop.ID, 50, now)
+	if err != nil || captured.Status != models.HoldCaptured || captured.CapturedAmount != 50 || captured.TransactionID == 0 || captured.ResolvedAt == nil {
+		t.Fatalf("CaptureHold = %+v, %v", captured, err)
+	}
+	assertBalance(t, r, aliceAcc, alice.ID, 20)
+	assertBalance(t, r, shopAcc, shop.ID, 80)
+	assertHeld(0)
+	if _, err := r.Holds.CaptureHold(ctx, hold.ID, shop.ID, 10, now); !errors.Is(err, repository.ErrHoldClosed) {
+		t.Errorf("повторное списание: %v", err)
+	}
+	if _, err := r.Holds.ReleaseHold(ctx, hold.ID, shop.ID, now); !errors.Is(err, repository.ErrHoldClosed) {
+		t.Errorf("снятие списанной: %v", err)
+	}
+
+	released, err := authorize(15, time.Hour)
+	if err != nil {
+		t.Fatalf("CreateHold: %v", err)
+	}
+	if _, err := r.Holds.ReleaseHold(ctx, released.ID, alice.ID, now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("снятие плательщиком: %v", err)
+	}
+	if got, err := r.Holds.ReleaseHold(ctx, released.ID, shop.ID, now); err != nil || got.Status != models.HoldReleased || got.CapturedAmount != 0 {
+		t.Fatalf("ReleaseHold = %+v, %v", got, err)
+	}
+	assertHeld(0)
+	assertBalance(t, r, aliceAcc, alice.ID, 20)
+
+	// Просроченную блокировку нельзя списать ещё до того, как она помечена истёкшей
+	stale, err := authorize(20, time.Minute)
+	if err != nil {
+		t.Fatalf("CreateHold: %v", err)
+	}
+	later := now.Add(2 * time.Minute)
+	if _, err := r.Holds.CaptureHold(ctx, stale.ID, shop.ID, 20, later); !errors.Is(err, repository.ErrHoldClosed) {
+		t.Errorf("списание просроченной: %v", err)
+	}
+	if expired, err := r.Holds.ExpireHolds(ctx, now); err != nil || len(expired) != 0 {
+		t.Errorf("ExpireHolds до срока = %+v, %v", expired, err)
+	}
+	expired, err := r.Holds.ExpireHolds(ctx, later)
+	if err != nil || len(expired) != 1 || expired[0].ID != stale.ID || expired[0].Status != models.HoldExpired {
+		t.Fatalf("ExpireHolds = %+v, %v", expired, err)
+	}
+	assertHeld(0)
+	if expired, err := r.Holds.ExpireHolds(ctx, later); err != nil || len(expired) != 0 {
+		t.Errorf("повторный ExpireHolds = %+v, %v", expired, err)
+	}
+
+	holds, err := r.Holds.ListHolds(ctx, shop.ID, "")
+	if err != nil || len(holds) != 3 || holds[0].ID != stale.ID || holds[2].ID != hold.ID {
+		t.Errorf("блокировки получателя = %+v, %v", holds, err)
+	}
+	if holds, err := r.Holds.ListHolds(ctx, alice.ID, models.HoldReleased); err != nil || len(holds) != 1 || holds[0].ID != released.ID {
+		t.Errorf("снятые блокировки плательщика = %+v, %v", holds, err)
+	}
+	if holds, err := r.Holds.ListHolds(ctx, carol.ID, ""); err != nil || len(holds) != 0 {
+		t.Errorf("блокировки постороннего = %+v, %v", holds, err)
+	}
+}
+
+func testRefunds(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	aliceAcc := createAccount(t, r, alice.ID, 100)
+	bobAcc := createAccount(t, r, bob.ID, 0)
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, alice.ID, 60, ""); err != nil {
+		t.Fatal(err)
+	}
+	history, err := r.Accounts.GetTransactions(ctx, bobAcc, bob.ID, 1, 0)
+	if err != nil || len(history) != 1 {
+		t.Fatalf("GetTransactions = %+v, %v", history, err)
+	}
+	original := history[0].ID
+
+	// Возвращает только получатель и не больше перевода
+	if _, err := r.Accounts.RefundTransfer(ctx, original, alice.ID, 10); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("возврат отправителем: %v", err)
+	}
+	if _, err := r.Accounts.RefundTransfer(ctx, original, bob.ID, 60.01); !errors.Is(err, repository.ErrRefundExceedsTransfer) {
+		t.Errorf("возврат сверх перевода: %v", err)
+	}
+	refund, err := r.Accounts.RefundTransfer(ctx, original, bob.ID, 25)
+	if err != nil || refund.ID == 0 || refund.Kind != models.KindRefund || refund.OriginalTransactionID != original ||
+		refund.FromAccountID != bobAcc || refund.ToAccountID != aliceAcc || refund.Amount != 25 {
+		t.Fatalf("RefundTransfer = %+v, %v", refund, err)
+	}
+	assertBalance(t, r, aliceAcc, alice.ID, 65)
+	assertBalance(t, r, bobAcc, bob.ID, 35)
+	if _, err := r.Accounts.RefundTransfer(ctx, original, bob.ID, 40); !errors.Is(err, repository.ErrRefundExceedsTransfer) {
+		t.Errorf("второй возврат сверх остатка: %v", err)
+	}
+	if _, err := r.Accounts.RefundTransfer(ctx, refund.ID, alice.ID, 0); !errors.Is(err, repository.ErrNotRefundable) {
+		t.Errorf("возврат возврата: %v", err)
+	}
+
+	// Сторно забирает весь остаток, после него возвращать нечего
+	entry := models.AuditEntry{Actor: "support", Action: models.AuditReversal, Reason: "ошибочный перевод"}
+	reversal, err := r.Admin.ReverseTransaction(ctx, original, models.ReversalError, entry)
+	if err != nil || reversal.Kind != models.KindReversal || reversal.Amount != 35 || reversal.ReasonCode != models.ReversalError ||
+		reversal.OriginalTransactionID != original {
+		t.Fatalf("ReverseTransaction = %+v, %v", reversal, err)
+	}
+	assertBalance(t, r, aliceAcc, alice.ID, 100)
+	assertBalance(t, r, bobAcc, bob.ID, 0)
+	if _, err := r.Accounts.RefundTransfer(ctx, original, bob.ID, 0); !errors.Is(err, repository.ErrTransferRefunded) {
+		t.Errorf("возврат сторнированного: %v", err)
+	}
+	if _, err := r.Admin.ReverseTransaction(ctx, original, models.ReversalError, entry); !errors.Is(err, repository.ErrTransferRefunded) {
+		t.Errorf("повторное сторно: %v", err)
+	}
+	if _, err := r.Admin.ReverseTransaction(ctx, reversal.ID+100, models.ReversalError, entry); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("сторно несуществующего: %v", err)
+	}
+	if log, err := r.Admin.ListAuditLog(ctx, aliceAcc, 10); err != nil || len(log) != 1 || log[0].Amount != 35 {
+		t.Errorf("журнал сторно = %+v, %v", log, err)
+	}
+
+	history, err = r.Accounts.GetTransactions(ctx, aliceAcc, alice.ID, 10, 0)
+	if err != nil || len(history) != 4 || history[0].ID != reversal.ID || history[0].ReasonCode != models.ReversalError ||
+		history[1].OriginalTransactionID != original || history[2].OriginalTransactionID != 0 || history[3].Kind != models.KindTopUp {
+		t.Errorf("история = %+v, %v", history, err)
+	}
+
+	// Получатель возвращает только из доступного остатка
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, alice.ID, 30, ""); err != nil {
+		t.Fatal(err)
+	}
+	history, _ = r.Accounts.GetTransactions(ctx, bobAcc, bob.ID, 1, 0)
+	if err := r.Accounts.TransferFunds(ctx, bobAcc, aliceAcc, bob.ID, 20, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Accounts.RefundTransfer(ctx, history[0].ID, bob.ID, 0); !errors.Is(err, repository.ErrInsufficientFunds) {
+		t.Errorf("возврат сверх остатка получателя: %v", err)
+	}
+
+	report, err := r.Admin.Reconcile(ctx)
+	if err != nil || !report.OK() {
+		t.Errorf("сверка = %+v, %v", report, err)
+	}
+}
+
+func createBatch(t *testing.T, r Repos, userID, fromID int64, allOrNothing bool, items ...models.BatchItem) *models.Batch {
+	t.Helper()
+	for i := range items {
+		items[i].Line = i + 1
+	}
+	batch := &models.Batch{UserID: userID, FromAccountID: fromID, AllOrNothing: allOrNothing, Items: items}
+	if err := r.Batches.CreateBatch(context.Background(), batch); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+	return batch
+}
+
+func testBatches(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	carol := createUser(t, r, "carol")
+	aliceAcc := createAccount(t, r, alice.ID, 100)
+	bobAcc := createAccount(t, r, bob.ID, 0)
+	carolAcc := createAccount(t, r, carol.ID, 0)
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	if err := r.Batches.CreateBatch(ctx, &models.Batch{UserID: bob.ID, FromAccountID: aliceAcc}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("пакет с чужого счёта: %v", err)
+	}
+	batch := createBatch(t, r, alice.ID, aliceAcc, false,
+		models.BatchItem{ToUsername: "bob", ToAccountID: bobAcc, Amount: 30, Reference: "зарплата"},
+		models.BatchItem{ToAccountID: carolAcc, Amount: 80},
+		models.BatchItem{ToAccountID: carolAcc, Amount: 20.5},
+	)
+	if batch.ID == 0 || batch.Status != models.BatchPending || batch.Total != 3 || batch.Amount != 130.5 {
+		t.Fatalf("CreateBatch = %+v", batch)
+	}
+	if ids, err := r.Batches.PendingBatches(ctx); err != nil || len(ids) != 1 || ids[0] != batch.ID {
+		t.Errorf("PendingBatches = %v, %v", ids, err)
+	}
+	if _, err := r.Batches.GetBatch(ctx, batch.ID, bob.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("чужой пакет: %v", err)
+	}
+
+	// Строка, на которую не хватило средств, не мешает остальным
+	done, err := r.Batches.ExecuteBatch(ctx, batch.ID, now)
+	if err != nil || done.Status != models.BatchCompleted || done.Succeeded != 2 || done.Failed != 1 || done.CompletedAt == nil || len(done.Items) != 3 {
+		t.Fatalf("ExecuteBatch = %+v, %v", done, err)
+	}
+	first, second := done.Items[0], done.Items[1]
+	if first.Status != models.BatchItemSucceeded || first.TransactionID == 0 || first.ToUsername != "bob" || first.Reference != "зарплата" {
+		t.Errorf("первая строка = %+v", first)
+	}
+	if second.Status != models.BatchItemFailed || second.Error == "" || second.TransactionID != 0 {
+		t.Errorf("строка сверх остатка = %+v", second)
+	}
+	assertBalance(t, r, aliceAcc, alice.ID, 49.5)
+	assertBalance(t, r, bobAcc, bob.ID, 30)
+	assertBalance(t, r, carolAcc, carol.ID, 20.5)
+
+	if _, err := r.Batches.ExecuteBatch(ctx, batch.ID, now); !errors.Is(err, repository.ErrBatchClosed) {
+		t.Errorf("повторное выполнение: %v", err)
+	}
+	if ids, err := r.Batches.PendingBatches(ctx); err != nil || len(ids) != 0 {
+		t.Errorf("PendingBatches после выполнения = %v, %v", ids, err)
+	}
+	if got, err := r.Batches.GetBatch(ctx, batch.ID, alice.ID); err != nil || got.Succeeded != 2 || got.Items[2].Status != models.BatchItemSucceeded {
+		t.Errorf("GetBatch = %+v, %v", got, err)
+	}
+
+	another := createBatch(t, r, alice.ID, aliceAcc, false, models.BatchItem{ToAccountID: bobAcc, Amount: 1})
+	batches, err := r.Batches.ListBatches(ctx, alice.ID)
+	if err != nil || len(batches) != 2 || batches[0].ID != another.ID || batches[1].Succeeded != 2 || batches[1].Failed != 1 ||
+		batches[1].Amount != 130.5 || batches[1].Items != nil {
+		t.Errorf("ListBatches = %+v, %v", batches, err)
+	}
+	if batches, err := r.Batches.ListBatches(ctx, bob.ID); err != nil || len(batches) != 0 {
+		t.Errorf("пакеты постороннего = %+v, %v", batches, err)
+	}
+}
+
+func testAllOrNothingBatches(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	aliceAcc := createAccount(t, r, alice.ID, 100)
+	bobAcc := createAccount(t, r, bob.ID, 0)
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	// Отказ во второй строке отменяет и первую
+	failed := createBatch(t, r, alice.ID, aliceAcc, true,
+		models.BatchItem{ToAccountID: bobAcc, Amount: 60},
+		models.BatchItem{ToAccountID: bobAcc, Amount: 60},
+		models.BatchItem{ToAccountID: bobAcc, Amount: 10},
+	)
+	done, err := r.Batches.ExecuteBatch(ctx, failed.ID, now)
+	if err != nil || done.Status != models.BatchFailed || done.Succeeded != 0 || done.Failed != 3 {
+		t.Fatalf("ExecuteBatch = %+v, %v", done, err)
+	}
+	if item := done.Items[1]; item.Status != models.BatchItemFailed || item.Error == "" {
+		t.Errorf("строка с отказом = %+v", item)
+	}
+	if item := done.Items[0]; item.Status != models.BatchItemSkipped || item.TransactionID != 0 {
+		t.Errorf("отменённая строка = %+v", item)
+	}
+	assertBalance(t, r, aliceAcc, alice.ID, 100)
+	assertBalance(t, r, bobAcc, bob.ID, 0)
+	if history, err := r.Accounts.GetTransactions(ctx, aliceAcc, alice.ID, 10, 0); err != nil || len(history) != 1 || history[0].Kind != models.KindTopUp {
+		t.Errorf("история после отмены = %+v, %v", history, err)
+	}
+
+	ok := createBatch(t, r, alice.ID, aliceAcc, true,
+		models.BatchItem{ToAccountID: bobAcc, Amount: 60},
+		models.BatchItem{ToAccountID: bobAcc, Amount: 40},
+	)
+	done, err = r.Batches.ExecuteBatch(ctx, ok.ID, now)
+	if err != nil || done.Status != models.BatchCompleted || done.Succeeded != 2 {
+		t.Fatalf("ExecuteBatch = %+v, %v", done, err)
+	}
+	assertBalance(t, r, aliceAcc, alice.ID, 0)
+	assertBalance(t, r, bobAcc, bob.ID, 100)
+}
+
+func testBatchMessages(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	aliceAcc := createAccount(t, r, alice.ID, 100)
+	bobAcc := createAccount(t, r, bob.ID, 0)
+
+	batch := &models.Batch{UserID: alice.ID, FromAccountID: aliceAcc, MessageID: "MSG-1", PaymentInfoID: "PMT-1",
+		Items: []models.BatchItem{{Line: 1, ToAccountID: bobAcc, Amount: 10, EndToEndID: "E2E-1"}}}
+	if err := r.Batches.CreateBatch(ctx, batch); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+	got, err := r.Batches.GetBatch(ctx, batch.ID, alice.ID)
+	if err != nil || got.MessageID != "MSG-1" || got.PaymentInfoID != "PMT-1" || got.Items[0].EndToEndID != "E2E-1" {
+		t.Fatalf("GetBatch = %+v, %v", got, err)
+	}
+
+	// Тот же платёж того же сообщения загружается один раз
+	repeat := &models.Batch{UserID: alice.ID, FromAccountID: aliceAcc, MessageID: "MSG-1", PaymentInfoID: "PMT-1",
+		Items: []models.BatchItem{{Line: 1, ToAccountID: bobAcc, Amount: 10}}}
+	if err := r.Batches.CreateBatch(ctx, repeat); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("повторный платёж: %v", err)
+	}
+	// Пакеты не из pain.001 и другие платежи того же сообщения не конфликтуют
+	createBatch(t, r, alice.ID, aliceAcc, false, models.BatchItem{ToAccountID: bobAcc, Amount: 1})
+	createBatch(t, r, alice.ID, aliceAcc, false, models.BatchItem{ToAccountID: bobAcc, Amount: 1})
+	other := &models.Batch{UserID: alice.ID, FromAccountID: aliceAcc, MessageID: "MSG-1", PaymentInfoID: "PMT-2",
+		Items: []models.BatchItem{{Line: 1, ToAccountID: bobAcc, Amount: 10}}}
+	if err := r.Batches.CreateBatch(ctx, other); err != nil {
+		t.Errorf("другой платёж сообщения: %v", err)
+	}
+
+	// Платежи сообщения сохраняются вместе: повтор одного отменяет все
+	before, _ := r.Batches.ListBatches(ctx, alice.ID)
+	err = r.Batches.CreateBatches(ctx, []*models.Batch{
+		{UserID: alice.ID, FromAccountID: aliceAcc, MessageID: "MSG-2", PaymentInfoID: "PMT-1", Items: []models.BatchItem{{Line: 1, ToAccountID: bobAcc, Amount: 5}}},
+		{UserID: alice.ID, FromAccountID: aliceAcc, MessageID: "MSG-1", PaymentInfoID: "PMT-2", Items: []models.BatchItem{{Line: 1, ToAccountID: bobAcc, Amount: 5}}},
+	})
+	if !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("сообщение с повтором: %v", err)
+	}
+	if after, _ := r.Batches.ListBatches(ctx, alice.ID); len(after) != len(before) {
+		t.Errorf("пакетов после отказа %d, ожидалось %d", len(after), len(before))
+	}
+}
+
+func testStatements(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	aliceAcc := createAccount(t, r, alice.ID, 100)
+	bobAcc := createAccount(t, r, bob.ID, 0)
+	now := time.Now().UTC()
+
+	batch := createBatch(t, r, alice.ID, aliceAcc, false,
+		models.BatchItem{ToAccountID: bobAcc, Amount: 30, Reference: "зарплата", EndToEndID: "E2E-1"})
+	if _, err := r.Batches.ExecuteBatch(ctx, batch.ID, now); err != nil {
+		t.Fatalf("ExecuteBatch: %v", err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, alice.ID, 20, ""); err != nil {
+		t.Fatalf("TransferFunds: %v", err)
+	}
+
+	statement, err := r.Accounts.GetStatement(ctx, aliceAcc, alice.ID, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetStatement: %v", err)
+	}
+	// Пополнение при создании счёта тоже попадает в период
+	if statement.Owner != "alice" || statement.Account == "" || statement.OpeningBalance != 0 || statement.ClosingBalance != 50 || len(statement.Entries) != 3 {
+		t.Fatalf("выписка = %+v", statement)
+	}
+	topup, first, second := statement.Entries[0], statement.Entries[1], statement.Entries[2]
+	if topup.Kind != models.KindTopUp || topup.Amount != 100 || topup.FromAccount != "" {
+		t.Errorf("пополнение = %+v", topup)
+	}
+	if first.Amount != 30 || first.Reference != "зарплата" || first.EndToEndID != "E2E-1" || first.FromAccount != statement.Account {
+		t.Errorf("перевод из пакета = %+v", first)
+	}
+	if second.Amount != 20 || second.Reference != "" || second.EndToEndID != "" {
+		t.Errorf("обычный перевод = %+v", second)
+	}
+
+	// Периоды до и после движений: остаток не меняется, движений нет
+	before, err := r.Accounts.GetStatement(ctx, aliceAcc, alice.ID, now.Add(-2*time.Hour), now.Add(-time.Hour))
+	if err != nil || before.OpeningBalance != 0 || before.ClosingBalance != 0 || len(before.Entries) != 0 {
+		t.Errorf("выписка до движений = %+v, %v", before, err)
+	}
+	after, err := r.Accounts.GetStatement(ctx, aliceAcc, alice.ID, now.Add(time.Hour), now.Add(2*time.Hour))
+	if err != nil || after.OpeningBalance != 50 || after.ClosingBalance != 50 || len(after.Entries) != 0 {
+		t.Errorf("выписка после движений = %+v, %v", after, err)
+	}
+	if _, err := r.Accounts.GetStatement(ctx, aliceAcc, bob.ID, now.Add(-time.Hour), now.Add(time.Hour)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("чужая выписка: %v", err)
+	}
+}
+
+func testApprovals(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	carol := createUser(t, r, "carol")
+	aliceAcc := createAccount(t, r, alice.ID, 1000)
+	bobAcc := createAccount(t, r, bob.ID, 0)
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+	entry := models.AuditEntry{Actor: "ops", Action: models.AuditApprovals, AccountID: aliceAcc, Amount: 100, Reason: "политика"}
+
+	if err := r.Admin.SetApprovalPolicy(ctx, aliceAcc+100, 100, []int64{bob.ID}, entry); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("политика несуществующего счёта: %v", err)
+	}
+	if err := r.Admin.SetApprovalPolicy(ctx, aliceAcc, 100, []int64{bob.ID, bob.ID}, entry); err != nil {
+		t.Fatalf("SetApprovalPolicy: %v", err)
+	}
+	if acc, err := r.Accounts.GetAccount(ctx, aliceAcc, alice.ID); err != nil || acc.ApprovalThreshold != 100 {
+		t.Fatalf("GetAccount = %+v, %v", acc, err)
+	}
+	if ids, err := r.Approvals.Approvers(ctx, aliceAcc); err != nil || len(ids) != 1 || ids[0] != bob.ID {
+		t.Errorf("Approvers = %v, %v", ids, err)
+	}
+
+	// Сумма до порога включительно проходит, больше — требует одобрения
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, alice.ID, 100, ""); err != nil {
+		t.Errorf("перевод на сумму порога: %v", err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, alice.ID, 100.01, ""); !errors.Is(err, repository.ErrApprovalRequired) {
+		t.Errorf("перевод сверх порога: %v", err)
+	}
+	hold := &models.Hold{AccountID: aliceAcc, ToAccountID: bobAcc, Amount: 200, ExpiresAt: now.Add(time.Hour)}
+	if err := r.Holds.CreateHold(ctx, hold, alice.ID); !errors.Is(err, repository.ErrApprovalRequired) {
+		t.Errorf("блокировка сверх порога: %v", err)
+	}
+
+	create := func(amount float64, key string) (*models.TransferApproval, error) {
+		approval := &models.TransferApproval{FromAccountID: aliceAcc, ToAccountID: bobAcc, Amount: amount,
+			MakerID: alice.ID, IdempotencyKey: key, ExpiresAt: now.Add(time.Hour)}
+		return approval, r.Approvals.CreateApproval(ctx, approval)
+	}
+	if err := r.Approvals.CreateApproval(ctx, &models.TransferApproval{FromAccountID: aliceAcc, ToAccountID: bobAcc, Amount: 300,
+		MakerID: bob.ID, ExpiresAt: now.Add(time.Hour)}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("перевод с чужого счёта: %v", err)
+	}
+	approval, err := create(300, "key-1")
+	if err != nil || approval.ID == 0 || approval.Status != models.ApprovalPending || approval.Maker != "alice" ||
+		approval.Checker != "" || approval.CreatedAt.IsZero() || approval.ResolvedAt != nil {
+		t.Fatalf("CreateApproval = %+v, %v", approval, err)
+	}
+	if _, err := create(300, "key-1"); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("повторный ключ: %v", err)
+	}
+	if got, err := r.Approvals.GetApprovalByKey(ctx, aliceAcc, "key-1"); err != nil || got.ID != approval.ID || got.IdempotencyKey != "key-1" {
+		t.Errorf("GetApprovalByKey = %+v, %v", got, err)
+	}
+
+	// Перевод видят автор и одобряющие, но не посторонние
+	for _, userID := range []int64{alice.ID, bob.ID} {
+		if got, err := r.Approvals.GetApproval(ctx, approval.ID, userID); err != nil || got.Amount != 300 {
+			t.Errorf("GetApproval(%d) = %+v, %v", userID, got, err)
+		}
+	}
+	if _, err := r.Approvals.GetApproval(ctx, approval.ID, carol.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("перевод глазами постороннего: %v", err)
+	}
+	if list, err := r.Approvals.ListApprovals(ctx, carol.ID, ""); err != nil || len(list) != 0 {
+		t.Errorf("список постороннего = %+v, %v", list, err)
+	}
+
+	// Автор и посторонний не могут одобрить перевод
+	for _, userID := range []int64{alice.ID, carol.ID} {
+		if _, err := r.Approvals.ApproveTransfer(ctx, approval.ID, userID, now); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("одобрение пользователем %d: %v", userID, err)
+		}
+	}
+	approved, err := r.Approvals.ApproveTransfer(ctx, approval.ID, bob.ID, now)
+	if err != nil || approved.Status != models.ApprovalApproved || approved.Checker != "bob" || approved.TransactionID == 0 ||
+		approved.ResolvedAt == nil {
+		t.Fatalf("ApproveTransfer = %+v, %v", approved, err)
+	}
+	assertBalance(t, r, aliceAcc, alice.ID, 600)
+	assertBalance(t, r, bobAcc, bob.ID, 400)
+	if _, err := r.Approvals.ApproveTransfer(ctx, approval.ID, bob.ID, now); !errors.Is(err, repository.ErrApprovalClosed) {
+		t.Errorf("повторное одобрение: %v", err)
+	}
+	// Повтор перевода с ключом одобренного перевода ничего не меняет
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, alice.ID, 300, "key-1"); !errors.Is(err, repository.ErrAlreadyApplied) {
+		t.Errorf("повтор ключа одобренного перевода: %v", err)
+	}
+
+	rejected, err := create(250, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rejected, err = r.Approvals.RejectTransfer(ctx, rejected.ID, bob.ID, "не согласовано", now)
+	if err != nil || rejected.Status != models.ApprovalRejected || rejected.Reason != "не согласовано" || rejected.TransactionID != 0 {
+		t.Errorf("RejectTransfer = %+v, %v", rejected, err)
+	}
+	assertBalance(t, r, aliceAcc, alice.ID, 600)
+
+	// Неодобренный вовремя перевод истекает и больше не рассматривается
+	stale, err := create(200, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Approvals.ApproveTransfer(ctx, stale.ID, bob.ID, now.Add(time.Hour)); !errors.Is(err, repository.ErrApprovalClosed) {
+		t.Errorf("одобрение истёкшего перевода: %v", err)
+	}
+	if expired, err := r.Approvals.ExpireApprovals(ctx, now.Add(time.Minute)); err != nil || len(expired) != 0 {
+		t.Errorf("ExpireApprovals до срока = %+v, %v", expired, err)
+	}
+	expired, err := r.Approvals.ExpireApprovals(ctx, now.Add(time.Hour))
+	if err != nil || len(expired) != 1 || expired[0].ID != stale.ID || expired[0].Status != models.ApprovalExpired || expired[0].Maker != "alice" {
+		t.Errorf("ExpireApprovals = %+v, %v", expired, err)
+	}
+
+	list, err := r.Approvals.ListApprovals(ctx, bob.ID, "")
+	if err != nil || len(list) != 3 || list[0].ID != stale.ID || list[2].ID != approval.ID {
+		t.Errorf("ListApprovals = %+v, %v", list, err)
+	}
+	if list, err := r.Approvals.ListApprovals(ctx, alice.ID, models.ApprovalRejected); err != nil || len(list) != 1 || list[0].ID != rejected.ID {
+		t.Errorf("ListApprovals(rejected) = %+v, %v", list, err)
+	}
+
+	// Нулевой порог отключает одобрение
+	if err := r.Admin.SetApprovalPolicy(ctx, aliceAcc, 0, nil, entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, alice.ID, 500, ""); err != nil {
+		t.Errorf("перевод без порога: %v", err)
+	}
+	if ids, err := r.Approvals.Approvers(ctx, aliceAcc); err != nil || len(ids) != 0 {
+		t.Errorf("Approvers после отключения = %v, %v", ids, err)
+	}
+	if log, err := r.Admin.ListAuditLog(ctx, aliceAcc, 10); err != nil || len(log) != 2 || log[0].Action != models.AuditApprovals {
+		t.Errorf("журнал = %+v, %v", log, err)
+	}
+}
+
+func testJointAccounts(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	carol := createUser(t, r, "carol")
+	aliceAcc := createAccount(t, r, alice.ID, 1000)
+	savings := createTypedAccount(t, r, models.Account{UserID: alice.ID, Type: models.AccountSavings}, 100)
+	bobAcc := createAccount(t, r, bob.ID, 0)
+	now := time.Date(2025, time.April, 1, 12, 0, 0, 0, time.UTC)
+
+	// Владелец получает доступ manage при создании счёта
+	list, err := r.Access.ListAccess(ctx, aliceAcc, alice.ID)
+	if err != nil || len(list) != 1 || !list[0].Owner || list[0].Level != models.AccessManage ||
+		list[0].Status != models.AccessActive || list[0].Username != "alice" || list[0].AcceptedAt == nil {
+		t.Fatalf("ListAccess = %+v, %v", list, err)
+	}
+	if _, err := r.Access.ListAccess(ctx, aliceAcc, bob.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("ListAccess без доступа: %v", err)
+	}
+
+	invite := func(accountID, userID, by int64, level string) (*models.AccountAccess, error) {
+		access := &models.AccountAccess{AccountID: accountID, UserID: userID, Level: level, InvitedByID: by}
+		return access, r.Access.InviteAccess(ctx, access)
+	}
+	if _, err := invite(aliceAcc, carol.ID, bob.ID, models.AccessView); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("приглашение к чужому счёту: %v", err)
+	}
+	access, err := invite(aliceAcc, bob.ID, alice.ID, models.AccessTransact)
+	if err != nil || access.Status != models.AccessPending || access.Owner || access.Username != "bob" ||
+		access.InvitedBy != "alice" || access.CreatedAt.IsZero() || access.AcceptedAt != nil {
+		t.Fatalf("InviteAccess = %+v, %v", access, err)
+	}
+	if _, err := invite(aliceAcc, bob.ID, alice.ID, models.AccessView); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("повторное приглашение: %v", err)
+	}
+	if _, err := invite(aliceAcc, alice.ID, alice.ID, models.AccessView); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("приглашение владельца: %v", err)
+	}
+
+	// Приглашение не даёт доступа до принятия
+	if _, err := r.Accounts.GetAccount(ctx, aliceAcc, bob.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetAccount до принятия: %v", err)
+	}
+	if invitations, err := r.Access.ListInvitations(ctx, bob.ID); err != nil || len(invitations) != 1 || invitations[0].AccountID != aliceAcc {
+		t.Errorf("ListInvitations = %+v, %v", invitations, err)
+	}
+	if _, err := r.Access.AcceptAccess(ctx, aliceAcc, carol.ID, now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("принятие без приглашения: %v", err)
+	}
+	accepted, err := r.Access.AcceptAccess(ctx, aliceAcc, bob.ID, now)
+	if err != nil || accepted.Status != models.AccessActive || accepted.AcceptedAt == nil || !accepted.AcceptedAt.Equal(now) {
+		t.Fatalf("AcceptAccess = %+v, %v", accepted, err)
+	}
+	if _, err := r.Access.AcceptAccess(ctx, aliceAcc, bob.ID, now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("повторное принятие: %v", err)
+	}
+	if invitations, err := r.Access.ListInvitations(ctx, bob.ID); err != nil || len(invitations) != 0 {
+		t.Errorf("ListInvitations после принятия = %+v, %v", invitations, err)
+	}
+
+	// Доступ transact: просмотр, пополнение, переводы и блокировки, но не настройки
+	assertBalance(t, r, aliceAcc, bob.ID, 1000)
+	if _, err := r.Accounts.GetTransactions(ctx, aliceAcc, bob.ID, 10, 0); err != nil {
+		t.Errorf("GetTransactions совладельца: %v", err)
+	}
+	if err := r.Accounts.TopUpAccount(ctx, aliceAcc, bob.ID, 50); err != nil {
+		t.Errorf("пополнение совладельцем: %v", err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, bob.ID, 150, ""); err != nil {
+		t.Errorf("перевод совладельцем: %v", err)
+	}
+	assertBalance(t, r, aliceAcc, alice.ID, 900)
+	hold := &models.Hold{AccountID: aliceAcc, ToAccountID: bobAcc, Amount: 100, ExpiresAt: now.Add(time.Hour)}
+	if err := r.Holds.CreateHold(ctx, hold, bob.ID); err != nil {
+		t.Errorf("блокировка совладельцем: %v", err)
+	}
+	if err := r.Accounts.SetLowBalanceThreshold(ctx, aliceAcc, bob.ID, 10); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("порог без доступа manage: %v", err)
+	}
+	if _, err := invite(aliceAcc, carol.ID, bob.ID, models.AccessView); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("приглашение без доступа manage: %v", err)
+	}
+
+	// Доступ view: только просмотр
+	if _, err := invite(aliceAcc, carol.ID, alice.ID, models.AccessView); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Access.AcceptAccess(ctx, aliceAcc, carol.ID, now); err != nil {
+		t.Fatal(err)
+	}
+	assertBalance(t, r, aliceAcc, carol.ID, 900)
+	if holds, err := r.Holds.ListHolds(ctx, carol.ID, ""); err != nil || len(holds) != 1 {
+		t.Errorf("ListHolds с доступом view = %+v, %v", holds, err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, carol.ID, 10, ""); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("перевод с доступом view: %v", err)
+	}
+	if err := r.Accounts.TopUpAccount(ctx, aliceAcc, carol.ID, 10); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("пополнение с доступом view: %v", err)
+	}
+
+	// Со сберегательного счёта — только на счета, к которым у переводящего
+	// есть доступ transact: свои и совместные
+	if _, err := invite(savings, bob.ID, alice.ID, models.AccessManage); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Access.AcceptAccess(ctx, savings, bob.ID, now); err != nil {
+		t.Fatal(err)
+	}
+	carolAcc := createAccount(t, r, carol.ID, 0)
+	if err := r.Accounts.TransferFunds(ctx, savings, bobAcc, bob.ID, 10, ""); err != nil {
+		t.Errorf("перевод совладельцем со сберегательного на свой счёт: %v", err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, savings, aliceAcc, bob.ID, 10, ""); err != nil {
+		t.Errorf("перевод совладельцем со сберегательного на совместный счёт: %v", err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, savings, bobAcc, alice.ID, 10, ""); !errors.Is(err, repository.ErrSavingsExternalTransfer) {
+		t.Errorf("перевод владельцем со сберегательного на счёт совладельца: %v", err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, savings, carolAcc, bob.ID, 10, ""); !errors.Is(err, repository.ErrSavingsExternalTransfer) {
+		t.Errorf("перевод совладельцем со сберегательного на чужой счёт: %v", err)
+	}
+	if err := r.Holds.CreateHold(ctx, &models.Hold{AccountID: savings, ToAccountID: bobAcc, Amount: 10, ExpiresAt: now.Add(time.Hour)}, alice.ID); !errors.Is(err, repository.ErrSavingsExternalTransfer) {
+		t.Errorf("блокировка владельцем в пользу счёта совладельца: %v", err)
+	}
+	// Блокировку совладельца в свою пользу можно списать, хотя переводит она от имени владельца
+	savingsHold := &models.Hold{AccountID: savings, ToAccountID: bobAcc, Amount: 10, ExpiresAt: now.Add(time.Hour)}
+	if err := r.Holds.CreateHold(ctx, savingsHold, bob.ID); err != nil {
+		t.Fatalf("блокировка совладельцем в свою пользу: %v", err)
+	}
+	if _, err := r.Holds.CaptureHold(ctx, savingsHold.ID, bob.ID, 10, now); err != nil {
+		t.Errorf("списание блокировки совладельца: %v", err)
+	}
+	assertBalance(t, r, savings, alice.ID, 70)
+	if err := r.Accounts.SetLowBalanceThreshold(ctx, savings, bob.ID, 10); err != nil {
+		t.Errorf("порог с доступом manage: %v", err)
+	}
+
+	list, err = r.Access.ListAccess(ctx, aliceAcc, carol.ID)
+	if err != nil || len(list) != 3 || list[0].Username != "alice" || list[1].Username != "bob" || list[2].Level != models.AccessView {
+		t.Errorf("ListAccess = %+v, %v", list, err)
+	}
+
+	// Отзыв: доступ владельца неотзываем, совладелец может выйти сам
+	if _, err := r.Access.RevokeAccess(ctx, aliceAcc, bob.ID, carol.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("отзыв без доступа manage: %v", err)
+	}
+	if _, err := r.Access.RevokeAccess(ctx, savings, bob.ID, alice.ID); !errors.Is(err, repository.ErrOwnerAccess) {
+		t.Errorf("отзыв доступа владельца: %v", err)
+	}
+	if revoked, err := r.Access.RevokeAccess(ctx, aliceAcc, alice.ID, bob.ID); err != nil || revoked.Username != "bob" {
+		t.Errorf("RevokeAccess = %+v, %v", revoked, err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, bob.ID, 10, ""); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("перевод после отзыва: %v", err)
+	}
+	if _, err := r.Access.RevokeAccess(ctx, aliceAcc, carol.ID, carol.ID); err != nil {
+		t.Errorf("выход из счёта: %v", err)
+	}
+	if _, err := r.Accounts.GetAccount(ctx, aliceAcc, carol.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetAccount после выхода: %v", err)
+	}
+}
+
+func testPayees(t *testing.T, r Repos) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bob")
+	bobAcc := createAccount(t, r, bob.ID, 0)
+	until := time.Date(2025, time.April, 2, 12, 0, 0, 0, time.UTC)
+
+	rent := &models.Payee{UserID: alice.ID, Nickname: "аренда", AccountID: bobAcc, Reference: "за апрель", CoolingOffUntil: until}
+	if err := r.Payees.CreatePayee(ctx, rent); err != nil || rent.ID == 0 || rent.Username != "" || rent.CreatedAt.IsZero() {
+		t.Fatalf("CreatePayee = %+v, %v", rent, err)
+	}
+	friend := &models.Payee{UserID: alice.ID, Nickname: "боб", ToUserID: bob.ID, CoolingOffUntil: until}
+	if err := r.Payees.CreatePayee(ctx, friend); err != nil || friend.Username != "bob" || friend.AccountID != 0 {
+		t.Fatalf("CreatePayee = %+v, %v", friend, err)
+	}
+	if err := r.Payees.CreatePayee(ctx, &models.Payee{UserID: alice.ID, Nickname: "боб", ToUserID: bob.ID, CoolingOffUntil: until}); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("повторный nickname: %v", err)
+	}
+	if err := r.Payees.CreatePayee(ctx, &models.Payee{UserID: alice.ID, Nickname: "нет", AccountID: bobAcc + 100, CoolingOffUntil: until}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("несуществующий счёт: %v", err)
+	}
+	// Nickname уникален только в книге своего пользователя
+	if err := r.Payees.CreatePayee(ctx, &models.Payee{UserID: bob.ID, Nickname: "боб", ToUserID: alice.ID, CoolingOffUntil: until}); err != nil {
+		t.Errorf("тот же nickname у другого пользователя: %v", err)
+	}
+
+	got, err := r.Payees.GetPayee(ctx, rent.ID, alice.ID)
+	if err != nil || got.Nickname != "аренда" || got.AccountID != bobAcc || got.Reference != "за апрель" || !got.CoolingOffUntil.Equal(until) {
+		t.Errorf("GetPayee = %+v, %v", got, err)
+	}
+	if _, err := r.Payees.GetPayee(ctx, rent.ID, bob.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetPayee чужого: %v", err)
+	}
+	if list, err := r.Payees.ListPayees(ctx, alice.ID); err != nil || len(list) != 2 || list[0].ID != rent.ID || list[1].ID != friend.ID {
+		t.Errorf("ListPayees = %+v, %v", list, err)
+	}
+
+	// Учитываются переводы со счетов alice на счёт bob и только с момента since
+	aliceAcc := createAccount(t, r, alice.ID, 100)
+	since := time.Now().Add(-time.Minute)
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, alice.ID, 30, ""); err != nil {
+		t.Fatalf("TransferFunds: %v", err)
+	}
+	if err := r.Accounts.TransferFunds(ctx, aliceAcc, bobAcc, alice.ID, 20, ""); err != nil {
+		t.Fatalf("TransferFunds: %v", err)
+	}
+	if sent, err := r.Payees.SentToAccount(ctx, alice.ID, bobAcc, since); err != nil || sent != 50 {
+		t.Errorf("SentToAccount = %v, %v", sent, err)
+	}
+	if sent, err := r.Payees.SentToAccount(ctx, alice.ID, bobAcc, time.Now().Add(time.Hour)); err != nil || sent != 0 {
+		t.Errorf("SentToAccount в будущем = %v, %v", sent, err)
+	}
+	if sent, err := r.Payees.SentToAccount(ctx, bob.ID, bobAcc, since); err != nil || sent != 0 {
+		t.Errorf("SentToAccount чужих переводов = %v, %v", sent, err)
+	}
+
+	// Смена получателя: со счёта на пользователя
+	later := until.Add(24 * time.Hour)
+	update := &models.Payee{ID: rent.ID, UserID: alice.ID, Nickname: "квартира", ToUserID: bob.ID, CoolingOffUntil: later}
+	if err := r.Payees.UpdatePayee(ctx, update); err != nil || update.Username != "bob" || update.AccountID != 0 ||
+		update.Reference != "" || !update.CoolingOffUntil.Equal(later) || !update.CreatedAt.Equal(rent.CreatedAt) {
+		t.Errorf("UpdatePayee = %+v, %v", update, err)
+	}
+	if err := r.Payees.UpdatePayee(ctx, &models.Payee{ID: rent.ID, UserID: alice.ID, Nickname: "боб", ToUserID: bob.ID, CoolingOffUntil: later}); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("UpdatePayee на занятый nickname: %v", err)
+	}
+	if err := r.Payees.UpdatePayee(ctx, &models.Payee{ID: rent.ID, UserID: bob.ID, Nickname: "x", ToUserID: alice.ID, CoolingOffUntil: later}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("UpdatePayee чужого: %v", err)
+	}
+
+	if err := r.Payees.DeletePayee(ctx, rent.ID, bob.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("DeletePayee чужого: %v", err)
+	}
+	if err := r.Payees.DeletePayee(ctx, rent.ID, alice.ID); err != nil {
+		t.Fatalf("DeletePayee: %v", err)
+	}
+	if _, err := r.Payees.GetPayee(ctx, rent.ID, alice.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetPayee удалённого: %v", err)
+	}
+}
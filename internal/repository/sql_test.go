@@ -0,0 +1,124 @@
+package repository_test
+
+import (
+	"banking-api/internal/config"
+	"banking-api/internal/migrate"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/repository/repotest"
+	"banking-api/migrations"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// openMigrated открывает БД по DB_URL и применяет миграции.
+func openMigrated(t *testing.T, dbURL string) (*sql.DB, repository.Dialect) {
+	t.Helper()
+	driver, dsn, err := config.ParseDBURL(dbURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if driver == "sqlite" {
+		db.SetMaxOpenConns(1)
+	}
+
+	fsys, err := migrations.For(driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrate.New(db, driver, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("миграции: %v", err)
+	}
+	dialect, err := repository.DialectFor(driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, dialect
+}
+
+func sqlRepos(db *sql.DB, dialect repository.Dialect) repotest.Repos {
+	return repotest.Repos{
+		Users:     repository.NewSQLUserRepository(db, dialect),
+		Accounts:  repository.NewSQLAccountRepository(db, dialect),
+		Admin:     repository.NewSQLAdminRepository(db, dialect),
+		Interest:  repository.NewSQLInterestRepository(db, dialect),
+		Loans:     repository.NewSQLLoanRepository(db, dialect),
+		Requests:  repository.NewSQLPaymentRequestRepository(db, dialect),
+		Holds:     repository.NewSQLHoldRepository(db, dialect),
+		Batches:   repository.NewSQLBatchRepository(db, dialect),
+		Approvals: repository.NewSQLApprovalRepository(db, dialect),
+		Access:    repository.NewSQLAccessRepository(db, dialect),
+		Payees:    repository.NewSQLPayeeRepository(db, dialect),
+	}
+}
+
+func TestSQLiteConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		db, dialect := openMigrated(t, "sqlite:"+filepath.Join(t.TempDir(), "banking.db"))
+		return sqlRepos(db, dialect)
+	})
+}
+
+// TestSQLiteReconcileMismatch проверяет, что сверка находит баланс,
+// изменённый в обход истории движений.
+func TestSQLiteReconcileMismatch(t *testing.T) {
+	ctx := context.Background()
+	db, dialect := openMigrated(t, "sqlite:"+filepath.Join(t.TempDir(), "banking.db"))
+	r := sqlRepos(db, dialect)
+	user := &models.User{Email: "alice@example.com", Username: "alice", PasswordHash: "x"}
+	if err := r.Users.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	acc := &models.Account{UserID: user.ID}
+	if err := r.Accounts.CreateAccount(ctx, acc); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Accounts.TopUpAccount(ctx, acc.ID, user.ID, 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`UPDATE accounts SET balance = balance + 5 WHERE id = $1`, acc.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := r.Admin.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	want := models.BalanceMismatch{AccountID: acc.ID, Account: acc.Number, Balance: 105, Expected: 100, Difference: 5}
+	if report.OK() || len(report.BalanceMismatches) != 1 || report.BalanceMismatches[0] != want || report.Imbalance != 5 {
+		t.Errorf("сверка = %+v", report)
+	}
+}
+
+// TestPostgresConformance запускается, только если задан TEST_POSTGRES_URL.
+// Все таблицы в этой БД очищаются перед каждым тестом. Только этот прогон
+// проверяет работу с колонками TIMESTAMP в сессии timezone=UTC (см.
+// config.ParseDBURL): SQLite пишет CURRENT_TIMESTAMP в UTC всегда.
+func TestPostgresConformance(t *testing.T) {
+	dbURL := os.Getenv("TEST_POSTGRES_URL")
+	if dbURL == "" {
+		t.Skip("TEST_POSTGRES_URL не задан")
+	}
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		db, dialect := openMigrated(t, dbURL)
+		if _, err := db.Exec(`TRUNCATE users, accounts, transactions, audit_log, interest_rates, interest_accruals, system_accounts, loans, loan_installments, payment_requests, holds, batches, batch_items, account_approvers, transfer_approvals, account_access, payees RESTART IDENTITY CASCADE`); err != nil {
+			t.Fatal(err)
+		}
+		return sqlRepos(db, dialect)
+	})
+}
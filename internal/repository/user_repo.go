@@ -2,34 +2,37 @@ package repository
 
 import (
 	"banking-api/internal/models"
+	"context"
 	"database/sql"
-	//"errors"
 )
 
-type UserRepository struct {
-	DB *sql.DB
+// SQLUserRepository — реализация UserRepository поверх PostgreSQL или SQLite.
+type SQLUserRepository struct {
+	DB      *sql.DB
+	Dialect Dialect
 }
 
-func NewUserRepository(db *sql.DB) *UserRepository {
-	return &UserRepository{DB: db}
+func NewSQLUserRepository(db *sql.DB, dialect Dialect) *SQLUserRepository {
+	return &SQLUserRepository{DB: db, Dialect: dialect}
 }
 
-func (r *UserRepository) IsEmailOrUsernameTaken(email, username string) (bool, error) {
+func (r *SQLUserRepository) IsEmailOrUsernameTaken(ctx context.Context, email, username string) (bool, error) {
 	query := `SELECT COUNT(*) FROM users WHERE email = $1 OR username = $2`
 	var count int
-	err := r.DB.QueryRow(query, email, username).Scan(&count)
+	err := r.DB.QueryRowContext(ctx, query, email, username).Scan(&count)
 	return count > 0, err
 }
 
-func (r *UserRepository) CreateUser(user *models.User) error {
+func (r *SQLUserRepository) CreateUser(ctx context.Context, user *models.User) error {
 	query := `INSERT INTO users (email, username, password_hash) VALUES ($1, $2, $3) RETURNING id, created_at`
-	return r.DB.QueryRow(query, user.Email, user.Username, user.PasswordHash).
+	err := r.DB.QueryRowContext(ctx, query, user.Email, user.Username, user.PasswordHash).
 		Scan(&user.ID, &user.CreatedAt)
+	return r.Dialect.mapError(err)
 }
 
-func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
+func (r *SQLUserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `SELECT id, email, username, password_hash, created_at FROM users WHERE email = $1`
-	row := r.DB.QueryRow(query, email)
+	row := r.DB.QueryRowContext(ctx, query, email)
 
 	var user models.User
 	err := row.Scan(&user.ID, &user.Email, &user.Username, &user.PasswordHash, &user.CreatedAt)
@@ -39,18 +42,18 @@ func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) GetUserIDByUsername(username string) (int64, error) {
+func (r *SQLUserRepository) GetUserIDByUsername(ctx context.Context, username string) (int64, error) {
 	var userID int64
-	err := r.DB.QueryRow(`SELECT id FROM users WHERE username = $1`, username).Scan(&userID)
+	err := r.DB.QueryRowContext(ctx, `SELECT id FROM users WHERE username = $1`, username).Scan(&userID)
 	return userID, err
 }
 
-func (r *UserRepository) GetUserByID(userID int64) (*models.User, error) {
-	query := `SELECT id, email, username FROM users WHERE id = $1`
-	row := r.DB.QueryRow(query, userID)
+func (r *SQLUserRepository) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	query := `SELECT id, email, username, created_at FROM users WHERE id = $1`
+	row := r.DB.QueryRowContext(ctx, query, userID)
 
 	var user models.User
-	err := row.Scan(&user.ID, &user.Email, &user.Username)
+	err := row.Scan(&user.ID, &user.Email, &user.Username, &user.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
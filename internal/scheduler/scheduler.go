@@ -0,0 +1,87 @@
+// Package scheduler запускает фоновые задания по расписанию внутри процесса API.
+package scheduler
+
+import (
+	"banking-api/internal/config"
+	"banking-api/internal/metrics"
+	"context"
+	"time"
+)
+
+// Job обрабатывает закрытый день day (полночь UTC). Задание должно быть
+// идемпотентным: один и тот же день может обрабатываться повторно.
+type Job func(ctx context.Context, day time.Time) error
+
+// retryDelay — через сколько Daily повторяет задание, завершившееся ошибкой.
+var retryDelay = 15 * time.Minute
+
+// Daily запускает job при старте и затем ежедневно в at после полуночи UTC,
+// каждый раз за предыдущий день. Задание, завершившееся ошибкой, повторяется
+// через retryDelay, но не позже очередного запуска. Блокируется до отмены ctx.
+func Daily(ctx context.Context, name string, at time.Duration, job Job) {
+	run := func(now time.Time) bool {
+		day := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+		if err := job(ctx, day); err != nil {
+			metrics.JobRuns.WithLabelValues(name, "error").Inc()
+			config.Log.Errorf("Задание %s за %s завершилось ошибкой: %v", name, day.Format("2006-01-02"), err)
+			return false
+		}
+		metrics.JobRuns.WithLabelValues(name, "success").Inc()
+		return true
+	}
+
+	ok := run(time.Now().UTC())
+	for {
+		now := time.Now().UTC()
+		next := nextRun(now, at)
+		if retry := now.Add(retryDelay); !ok && retry.Before(next) {
+			next = retry
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			ok = run(next)
+		}
+	}
+}
+
+// nextRun возвращает ближайший момент после now, отстоящий на at от полуночи UTC.
+func nextRun(now time.Time, at time.Duration) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Add(at)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// Every запускает job при старте и затем каждые interval, передавая время
+// запуска. Блокируется до отмены ctx. Задание с неположительным interval
+// не запускается.
+func Every(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context, now time.Time) error) {
+	if interval <= 0 {
+		config.Log.Errorf("Задание %s не запущено: некорректный период %s", name, interval)
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	now := time.Now().UTC()
+	for {
+		if err := job(ctx, now); err != nil {
+			metrics.JobRuns.WithLabelValues(name, "error").Inc()
+			config.Log.Errorf("Задание %s завершилось ошибкой: %v", name, err)
+		} else {
+			metrics.JobRuns.WithLabelValues(name, "success").Inc()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-ticker.C:
+			now = t.UTC()
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"banking-api/internal/config"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestNextRun(t *testing.T) {
+	at := 30 * time.Minute
+	tests := []struct {
+		now, want time.Time
+	}{
+		{time.Date(2025, 3, 31, 0, 10, 0, 0, time.UTC), time.Date(2025, 3, 31, 0, 30, 0, 0, time.UTC)},
+		{time.Date(2025, 3, 31, 0, 30, 0, 0, time.UTC), time.Date(2025, 4, 1, 0, 30, 0, 0, time.UTC)},
+		{time.Date(2025, 3, 31, 23, 59, 0, 0, time.UTC), time.Date(2025, 4, 1, 0, 30, 0, 0, time.UTC)},
+		// Локальное время переводится в UTC
+		{time.Date(2025, 4, 1, 2, 0, 0, 0, time.FixedZone("MSK", 3*3600)), time.Date(2025, 4, 1, 0, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := nextRun(tt.now, at); !got.Equal(tt.want) {
+			t.Errorf("nextRun(%v) = %v, ожидалось %v", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestEveryInvalidInterval(t *testing.T) {
+	config.InitLogger()
+	config.Log.SetOutput(io.Discard)
+
+	// С нулевым периодом NewTicker паникует: задание не запускается вовсе
+	for _, interval := range []time.Duration{0, -time.Second} {
+		Every(context.Background(), "test", interval, func(context.Context, time.Time) error {
+			t.Errorf("задание с периодом %s запущено", interval)
+			return nil
+		})
+	}
+}
+
+func TestDailyRetry(t *testing.T) {
+	config.InitLogger()
+	config.Log.SetOutput(io.Discard)
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = 10 * time.Millisecond
+
+	// Первый запуск падает и повторяется, не дожидаясь следующих суток
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var days []time.Time
+	Daily(ctx, "test", 0, func(_ context.Context, day time.Time) error {
+		days = append(days, day)
+		if len(days) == 1 {
+			return errors.New("база недоступна")
+		}
+		cancel()
+		return nil
+	})
+	if len(days) != 2 || !days[0].Equal(days[1]) {
+		t.Errorf("запуски за %v, ожидались два запуска за один день", days)
+	}
+}
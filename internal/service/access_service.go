@@ -0,0 +1,143 @@
+package service
+
+import (
+	"banking-api/internal/config"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// AccessService — совместные счета: пользователь с доступом manage приглашает
+// других пользователей по username с уровнем view, transact или manage.
+// Приглашённый получает уведомление и доступ после принятия приглашения;
+// отказаться от приглашения или выйти из счёта он может сам.
+type AccessService struct {
+	Repo         repository.AccessRepository
+	UserRepo     repository.UserRepository
+	EmailService Mailer
+}
+
+func NewAccessService(repo repository.AccessRepository, userRepo repository.UserRepository, email Mailer) *AccessService {
+	return &AccessService{Repo: repo, UserRepo: userRepo, EmailService: email}
+}
+
+// Invite приглашает пользователя username к счёту accountID с уровнем level
+// от имени userID.
+func (s *AccessService) Invite(ctx context.Context, userID, accountID int64, username, level string) (access *models.AccountAccess, err error) {
+	ctx, span := startSpan(ctx, "AccessService.Invite")
+	defer func() { endSpan(span, err) }()
+
+	if !models.ValidAccessLevel(level) {
+		return nil, fmt.Errorf("%w: уровень %q, ожидается %s", ErrInvalidAccess, level, strings.Join(models.AccessLevels, ", "))
+	}
+	targetID, err := s.UserRepo.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, username)
+	}
+	if targetID == userID {
+		return nil, fmt.Errorf("%w: нельзя пригласить самого себя", ErrInvalidAccess)
+	}
+
+	access = &models.AccountAccess{AccountID: accountID, UserID: targetID, Level: level, InvitedByID: userID}
+	err = s.Repo.InviteAccess(ctx, access)
+	switch {
+	case errors.Is(err, repository.ErrDuplicate):
+		return nil, fmt.Errorf("%w: %s", ErrAccessExists, username)
+	case err != nil:
+		return nil, accountError(err)
+	}
+	config.Log.Infof("Пользователь %s приглашён к счёту %d с доступом %s", username, accountID, level)
+
+	body := fmt.Sprintf("<h3>%s приглашает вас к счёту %s с доступом %s</h3><p>Примите или отклоните приглашение в приложении</p>",
+		html.EscapeString(access.InvitedBy), access.Account, level)
+	s.notify(ctx, targetID, "Приглашение к счёту", body)
+	return access, nil
+}
+
+// List возвращает доступы и приглашения к счёту, видимому userID.
+func (s *AccessService) List(ctx context.Context, userID, accountID int64) (access []models.AccountAccess, err error) {
+	ctx, span := startSpan(ctx, "AccessService.List")
+	defer func() { endSpan(span, err) }()
+
+	access, err = s.Repo.ListAccess(ctx, accountID, userID)
+	if err != nil {
+		return nil, accountError(err)
+	}
+	return access, nil
+}
+
+// Invitations возвращает приглашения userID, ждущие принятия.
+func (s *AccessService) Invitations(ctx context.Context, userID int64) (invitations []models.AccountAccess, err error) {
+	ctx, span := startSpan(ctx, "AccessService.Invitations")
+	defer func() { endSpan(span, err) }()
+
+	return s.Repo.ListInvitations(ctx, userID)
+}
+
+// Accept принимает приглашение userID к счёту и уведомляет пригласившего.
+func (s *AccessService) Accept(ctx context.Context, userID, accountID int64) (access *models.AccountAccess, err error) {
+	ctx, span := startSpan(ctx, "AccessService.Accept")
+	defer func() { endSpan(span, err) }()
+
+	access, err = s.Repo.AcceptAccess(ctx, accountID, userID, time.Now().UTC())
+	if err != nil {
+		return nil, accessError(err)
+	}
+	config.Log.Infof("Пользователь %s принял приглашение к счёту %d", access.Username, accountID)
+
+	body := fmt.Sprintf("<h3>%s принял приглашение к счёту %s с доступом %s</h3>",
+		html.EscapeString(access.Username), access.Account, access.Level)
+	s.notify(ctx, access.InvitedByID, "Приглашение к счёту принято", body)
+	return access, nil
+}
+
+// Revoke удаляет доступ или приглашение пользователя username к счёту от
+// имени userID: это отзыв (нужен доступ manage), а для самого username —
+// отказ от приглашения или выход из счёта. Доступ владельца не отзывается.
+func (s *AccessService) Revoke(ctx context.Context, userID, accountID int64, username string) (access *models.AccountAccess, err error) {
+	ctx, span := startSpan(ctx, "AccessService.Revoke")
+	defer func() { endSpan(span, err) }()
+
+	targetID, err := s.UserRepo.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, username)
+	}
+	access, err = s.Repo.RevokeAccess(ctx, accountID, userID, targetID)
+	if err != nil {
+		return nil, accessError(err)
+	}
+	config.Log.Infof("Доступ пользователя %s к счёту %d (%s, %s) удалён", username, accountID, access.Level, access.Status)
+
+	if targetID != userID {
+		body := fmt.Sprintf("<h3>Ваш доступ к счёту %s отозван</h3>", access.Account)
+		s.notify(ctx, targetID, "Доступ к счёту отозван", body)
+	}
+	return access, nil
+}
+
+// notify отправляет письмо пользователю userID; ошибки отправки только логируются.
+func (s *AccessService) notify(ctx context.Context, userID int64, subject, body string) {
+	if s.EmailService == nil {
+		return
+	}
+	user, err := s.UserRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return
+	}
+	if err := s.EmailService.SendEmail(ctx, user.Email, subject, body); err != nil {
+		config.Log.Warnf("Не удалось отправить уведомление %q пользователю %d: %v", subject, userID, err)
+	}
+}
+
+func accessError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAccessNotFound
+	}
+	return err
+}
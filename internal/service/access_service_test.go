@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/service"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAccessFlow(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	carol := e.register(t, "carol")
+	aliceAcc := e.account(t, alice.ID, 1000)
+	carolAcc := e.account(t, carol.ID, 0)
+
+	invalid := []struct {
+		name     string
+		userID   int64
+		username string
+		level    string
+		want     error
+	}{
+		{"неизвестный уровень", alice.ID, "bob", "owner", service.ErrInvalidAccess},
+		{"неизвестный пользователь", alice.ID, "dave", models.AccessView, service.ErrUserNotFound},
+		{"сам себя", alice.ID, "alice", models.AccessView, service.ErrInvalidAccess},
+		{"чужой счёт", carol.ID, "bob", models.AccessView, service.ErrAccountNotFound},
+	}
+	for _, tt := range invalid {
+		if _, err := e.access.Invite(ctx, tt.userID, aliceAcc, tt.username, tt.level); !errors.Is(err, tt.want) {
+			t.Errorf("%s: %v, ожидалось %v", tt.name, err, tt.want)
+		}
+	}
+
+	e.mailer.sent = nil
+	access, err := e.access.Invite(ctx, alice.ID, aliceAcc, "bob", models.AccessTransact)
+	if err != nil || access.Status != models.AccessPending || access.InvitedBy != "alice" {
+		t.Fatalf("Invite = %+v, %v", access, err)
+	}
+	if len(e.mailer.sent) != 1 || e.mailer.sent[0].To != "bob@example.com" || e.mailer.sent[0].Subject != "Приглашение к счёту" {
+		t.Errorf("уведомление приглашённому = %+v", e.mailer.sent)
+	}
+	if _, err := e.access.Invite(ctx, alice.ID, aliceAcc, "bob", models.AccessView); !errors.Is(err, service.ErrAccessExists) {
+		t.Errorf("повторное приглашение: %v", err)
+	}
+	if invitations, err := e.access.Invitations(ctx, bob.ID); err != nil || len(invitations) != 1 {
+		t.Errorf("Invitations = %+v, %v", invitations, err)
+	}
+	if err := e.accounts.TransferFunds(ctx, bob.ID, aliceAcc, carolAcc, 10, ""); err == nil {
+		t.Error("перевод до принятия приглашения выполнен")
+	}
+
+	if _, err := e.access.Accept(ctx, carol.ID, aliceAcc); !errors.Is(err, service.ErrAccessNotFound) {
+		t.Errorf("принятие без приглашения: %v", err)
+	}
+	e.mailer.sent = nil
+	if _, err := e.access.Accept(ctx, bob.ID, aliceAcc); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if len(e.mailer.sent) != 1 || e.mailer.sent[0].To != "alice@example.com" {
+		t.Errorf("уведомление пригласившему = %+v", e.mailer.sent)
+	}
+	if err := e.accounts.TransferFunds(ctx, bob.ID, aliceAcc, carolAcc, 10, ""); err != nil {
+		t.Errorf("перевод совладельцем: %v", err)
+	}
+	if balance, err := e.accounts.Balance(ctx, bob.ID, aliceAcc); err != nil || balance.Ledger != 990 {
+		t.Errorf("Balance совладельца = %+v, %v", balance, err)
+	}
+
+	list, err := e.access.List(ctx, bob.ID, aliceAcc)
+	if err != nil || len(list) != 2 || !list[0].Owner || list[1].Username != "bob" || list[1].Level != models.AccessTransact {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+	if _, err := e.access.List(ctx, carol.ID, aliceAcc); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("List без доступа: %v", err)
+	}
+
+	if _, err := e.access.Revoke(ctx, bob.ID, aliceAcc, "alice"); !errors.Is(err, service.ErrAccessNotFound) {
+		t.Errorf("отзыв без доступа manage: %v", err)
+	}
+	if _, err := e.access.Revoke(ctx, alice.ID, aliceAcc, "alice"); !errors.Is(err, repository.ErrOwnerAccess) {
+		t.Errorf("отзыв доступа владельца: %v", err)
+	}
+	e.mailer.sent = nil
+	if _, err := e.access.Revoke(ctx, alice.ID, aliceAcc, "bob"); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if len(e.mailer.sent) != 1 || e.mailer.sent[0].To != "bob@example.com" || e.mailer.sent[0].Subject != "Доступ к счёту отозван" {
+		t.Errorf("уведомление об отзыве = %+v", e.mailer.sent)
+	}
+	if _, err := e.accounts.Balance(ctx, bob.ID, aliceAcc); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("Balance после отзыва: %v", err)
+	}
+
+	// Отказ от приглашения — удаление своего доступа, без уведомления
+	if _, err := e.access.Invite(ctx, alice.ID, aliceAcc, "carol", models.AccessView); err != nil {
+		t.Fatal(err)
+	}
+	e.mailer.sent = nil
+	if _, err := e.access.Revoke(ctx, carol.ID, aliceAcc, "carol"); err != nil || len(e.mailer.sent) != 0 {
+		t.Errorf("отказ от приглашения: %v, письма %+v", err, e.mailer.sent)
+	}
+	if invitations, err := e.access.Invitations(ctx, carol.ID); err != nil || len(invitations) != 0 {
+		t.Errorf("Invitations после отказа = %+v, %v", invitations, err)
+	}
+}
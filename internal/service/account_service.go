@@ -1,42 +1,119 @@
 package service
 
 import (
+	"banking-api/internal/accountno"
 	"banking-api/internal/config"
+	"banking-api/internal/metrics"
 	"banking-api/internal/models"
 	"banking-api/internal/repository"
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
 )
 
+// Параметры новых счетов по умолчанию
+const (
+	DefaultCreditLimit              = 10000
+	DefaultSavingsTransfersPerMonth = 6
+)
+
+// numberAttempts — сколько случайных номеров пробует CreateAccount, пока не
+// найдёт свободный.
+const numberAttempts = 5
+
 type AccountService struct {
-	Repo         *repository.AccountRepository
-	UserRepo     *repository.UserRepository
-	EmailService *EmailService
+	Repo         repository.AccountRepository
+	UserRepo     repository.UserRepository
+	EmailService Mailer
+	// Approvals ставит на одобрение переводы больше порога счёта; без него
+	// такие переводы отклоняются с repository.ErrApprovalRequired.
+	Approvals *ApprovalService
+
+	// CreditLimit — кредитный лимит новых кредитных счетов.
+	CreditLimit float64
+	// SavingsTransfersPerMonth — лимит исходящих переводов в месяц для новых сберегательных счетов.
+	SavingsTransfersPerMonth int
 }
 
-func NewAccountService(repo *repository.AccountRepository, userRepo *repository.UserRepository, email *EmailService) *AccountService {
+func NewAccountService(repo repository.AccountRepository, userRepo repository.UserRepository, email Mailer) *AccountService {
 	return &AccountService{
 		Repo:         repo,
 		UserRepo:     userRepo,
 		EmailService: email,
+
+		CreditLimit:              DefaultCreditLimit,
+		SavingsTransfersPerMonth: DefaultSavingsTransfersPerMonth,
 	}
 }
 
-func (s *AccountService) CreateAccount(userID int64) (*models.Account, error) {
-	account, err := s.Repo.CreateAccount(userID)
+// CreateAccount открывает счёт типа accountType (по умолчанию расчётный).
+// Лимиты счёта фиксируются при открытии.
+func (s *AccountService) CreateAccount(ctx context.Context, userID int64, accountType string) (account *models.Account, err error) {
+	ctx, span := startSpan(ctx, "AccountService.CreateAccount")
+	defer func() { endSpan(span, err) }()
+
+	account = &models.Account{UserID: userID, Type: accountType}
+	switch accountType {
+	case "", models.AccountChecking:
+		account.Type = models.AccountChecking
+	case models.AccountSavings:
+		account.MonthlyTransferLimit = s.SavingsTransfersPerMonth
+	case models.AccountCredit:
+		account.CreditLimit = s.CreditLimit
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAccountType, accountType)
+	}
+
+	for attempt := 1; ; attempt++ {
+		account.Number = accountno.New()
+		err = s.Repo.CreateAccount(ctx, account)
+		if !errors.Is(err, repository.ErrDuplicate) || attempt == numberAttempts {
+			break
+		}
+	}
 	if err != nil {
 		config.Log.Errorf("Ошибка создания счёта: %v", err)
 		return nil, err
 	}
-	config.Log.Infof("Создан счёт ID=%d для пользователя ID=%d", account.ID, userID)
+	config.Log.Infof("Создан счёт %s (ID=%d) типа %s для пользователя ID=%d", account.Number, account.ID, account.Type, userID)
 	return account, nil
 }
 
-func (s *AccountService) TopUp(userID, accountID int64, amount float64) error {
+// AccountID возвращает внутренний ID счёта по номеру. Контрольные цифры
+// проверяются до обращения к хранилищу: номер с опечаткой сразу даёт
+// ErrInvalidAccountNumber, неизвестный номер — ErrAccountNotFound.
+func (s *AccountService) AccountID(ctx context.Context, number string) (accountID int64, err error) {
+	ctx, span := startSpan(ctx, "AccountService.AccountID")
+	defer func() { endSpan(span, err) }()
+
+	return accountIDByNumber(ctx, s.Repo, number)
+}
+
+func accountIDByNumber(ctx context.Context, repo repository.AccountRepository, number string) (int64, error) {
+	number = accountno.Normalize(number)
+	if err := accountno.Validate(number); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidAccountNumber, err)
+	}
+	accountID, err := repo.GetAccountIDByNumber(ctx, number)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: %s", ErrAccountNotFound, number)
+	}
+	return accountID, err
+}
+
+func (s *AccountService) TopUp(ctx context.Context, userID, accountID int64, amount float64) (err error) {
+	ctx, span := startSpan(ctx, "AccountService.TopUp")
+	defer func() { endSpan(span, err) }()
+
 	if amount <= 0 {
-		return errors.New("сумма должна быть больше 0")
+		return ErrInvalidAmount
 	}
-	err := s.Repo.TopUpAccount(accountID, userID, amount)
+	err = s.Repo.TopUpAccount(ctx, accountID, userID, amount)
 	if err != nil {
 		config.Log.Errorf("Ошибка пополнения счёта %d: %v", accountID, err)
 		return err
@@ -45,82 +122,286 @@ func (s *AccountService) TopUp(userID, accountID int64, amount float64) error {
 	return nil
 }
 
-func (s *AccountService) TransferFunds(userID, fromID, toID int64, amount float64) error {
+// TransferFunds переводит amount со счёта fromID пользователя userID на счёт toID.
+// Повтор с тем же непустым idempotencyKey считается успешным и не выполняет
+// перевод и уведомление повторно. Перевод больше порога одобрения счёта
+// ставится на одобрение и возвращается как *ApprovalPendingError.
+func (s *AccountService) TransferFunds(ctx context.Context, userID, fromID, toID int64, amount float64, idempotencyKey string) (err error) {
+	ctx, span := startSpan(ctx, "AccountService.TransferFunds")
+	defer func() { endSpan(span, err) }()
+
+	return s.transfer(ctx, userID, fromID, toID, amount, idempotencyKey, "")
+}
+
+// transfer выполняет перевод по правилам TransferFunds; непустое назначение
+// reference попадает в уведомление получателю.
+func (s *AccountService) transfer(ctx context.Context, userID, fromID, toID int64, amount float64, idempotencyKey, reference string) error {
 	if amount <= 0 {
-		return errors.New("сумма должна быть положительной")
+		metrics.ObserveTransfer(metrics.TransferInvalid, amount)
+		return ErrInvalidAmount
 	}
 	if fromID == toID {
-		return errors.New("нельзя переводить самому себе")
+		metrics.ObserveTransfer(metrics.TransferInvalid, amount)
+		return ErrSelfTransfer
+	}
+	// Баланс до перевода нужен для уведомления о низком остатке
+	var before *models.Account
+	if s.EmailService != nil {
+		before, _ = s.Repo.GetAccount(ctx, fromID, userID)
+	}
+	err := s.Repo.TransferFunds(ctx, fromID, toID, userID, amount, idempotencyKey)
+	if errors.Is(err, repository.ErrAlreadyApplied) {
+		metrics.ObserveTransfer(metrics.TransferReplayed, amount)
+		config.Log.Infof("Повтор перевода с ключом %q со счёта %d, пропущен", idempotencyKey, fromID)
+		return nil
+	}
+	if errors.Is(err, repository.ErrApprovalRequired) && s.Approvals != nil {
+		approval, err := s.Approvals.Request(ctx, userID, fromID, toID, amount, idempotencyKey)
+		if err != nil {
+			return err
+		}
+		return &ApprovalPendingError{Approval: approval}
 	}
-	err := s.Repo.TransferFunds(fromID, toID, userID, amount)
 	if err != nil {
+		if errors.Is(err, repository.ErrInsufficientFunds) {
+			metrics.ObserveTransfer(metrics.TransferInsufficientFunds, amount)
+		} else {
+			metrics.ObserveTransfer(metrics.TransferError, amount)
+		}
 		config.Log.Errorf("Ошибка перевода: from %d to %d amount %.2f: %v", fromID, toID, amount, err)
 		return err
 	}
+	metrics.ObserveTransfer(metrics.TransferSuccess, amount)
 	config.Log.Infof("Перевод %.2f со счёта %d на счёт %d (user ID=%d)", amount, fromID, toID, userID)
 
 	// Уведомление по email (опционально)
 	if s.EmailService != nil {
 		// Получаем email получателя
-		toUserID, err := s.Repo.GetUserIDByAccountID(toID)
+		toUserID, err := s.Repo.GetUserIDByAccountID(ctx, toID)
 		if err == nil {
-			receiver, err := s.UserRepo.GetUserByID(toUserID)
+			receiver, err := s.UserRepo.GetUserByID(ctx, toUserID)
 			if err == nil {
 				body := fmt.Sprintf("<h3>Вам поступил перевод на сумму %.2f RUB</h3>", amount)
-				_ = s.EmailService.SendEmail(receiver.Email, "Вы получили перевод", body)
+				if reference != "" {
+					body += fmt.Sprintf("<p>Назначение: %s</p>", html.EscapeString(reference))
+				}
+				_ = s.EmailService.SendEmail(ctx, receiver.Email, "Вы получили перевод", body)
 			}
 		}
+		if before != nil {
+			s.notifyLowBalance(ctx, userID, before)
+		}
 	}
 
 	return nil
 }
 
-func (s *AccountService) TransferToUsername(fromUserID, fromAccountID int64, toUsername string, amount float64) error {
-	if amount <= 0 {
-		return errors.New("сумма должна быть положительной")
+// notifyLowBalance уведомляет владельца, если после списания остаток счёта
+// опустился ниже порога LowBalanceThreshold или ушёл в минус по овердрафту.
+// Уведомление отправляется только при пересечении границы, а не при каждом списании.
+func (s *AccountService) notifyLowBalance(ctx context.Context, userID int64, before *models.Account) {
+	after, err := s.Repo.GetAccount(ctx, before.ID, userID)
+	if err != nil {
+		return
 	}
-	if toUsername == "" {
-		return errors.New("получатель не указан")
+	var lines []string
+	if threshold := after.LowBalanceThreshold; threshold > 0 && before.Balance >= threshold && after.Balance < threshold {
+		lines = append(lines, fmt.Sprintf("<p>Остаток на счёте %s опустился ниже %.2f RUB: %.2f RUB</p>", after.Number, threshold, after.Balance))
+	}
+	if after.OverdraftLimit > 0 && before.Balance >= 0 && after.Balance < 0 {
+		line := fmt.Sprintf("<p>Счёт %s ушёл в овердрафт: остаток %.2f RUB, доступно ещё %.2f RUB", after.Number, after.Balance, after.Available())
+		if after.OverdraftFee > 0 {
+			line += fmt.Sprintf(", списана комиссия %.2f RUB", after.OverdraftFee)
+		}
+		lines = append(lines, line+"</p>")
+	}
+	if len(lines) == 0 {
+		return
+	}
+	// Уведомление получает владелец счёта, даже если списал совладелец
+	owner, err := s.UserRepo.GetUserByID(ctx, after.UserID)
+	if err != nil {
+		return
+	}
+	body := "<h3>Низкий остаток на счёте</h3>" + strings.Join(lines, "")
+	if err := s.EmailService.SendEmail(ctx, owner.Email, "Низкий остаток на счёте", body); err != nil {
+		config.Log.Warnf("Не удалось отправить уведомление о низком остатке счёта %d: %v", after.ID, err)
+	}
+}
+
+// Refund возвращает отправителю amount (0 — весь остаток) перевода
+// transactionID, пришедшего на счёт пользователя userID, и уведомляет отправителя.
+func (s *AccountService) Refund(ctx context.Context, userID, transactionID int64, amount float64) (refund *models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "AccountService.Refund")
+	defer func() { endSpan(span, err) }()
+
+	if amount < 0 {
+		return nil, ErrInvalidAmount
+	}
+	refund, err = s.Repo.RefundTransfer(ctx, transactionID, userID, amount)
+	if err != nil {
+		config.Log.Errorf("Ошибка возврата перевода %d: %v", transactionID, err)
+		return nil, transactionError(err, transactionID)
+	}
+	config.Log.Infof("Возврат %.2f по переводу %d со счёта %d на счёт %d", refund.Amount, transactionID, refund.FromAccountID, refund.ToAccountID)
+
+	if s.EmailService != nil {
+		senderID, err := s.Repo.GetUserIDByAccountID(ctx, refund.ToAccountID)
+		if err == nil {
+			sender, err := s.UserRepo.GetUserByID(ctx, senderID)
+			if err == nil {
+				body := fmt.Sprintf("<h3>Вам вернули %.2f RUB по переводу %d</h3>", refund.Amount, transactionID)
+				_ = s.EmailService.SendEmail(ctx, sender.Email, "Возврат перевода", body)
+			}
+		}
+	}
+	return refund, nil
+}
+
+// transactionError заменяет sql.ErrNoRows на ErrTransactionNotFound.
+func transactionError(err error, transactionID int64) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %d", ErrTransactionNotFound, transactionID)
 	}
+	return err
+}
 
-	toUserID, err := s.UserRepo.GetUserIDByUsername(toUsername)
+// Balance возвращает учтённый, заблокированный и доступный остатки счёта.
+func (s *AccountService) Balance(ctx context.Context, userID, accountID int64) (balance *models.Balance, err error) {
+	ctx, span := startSpan(ctx, "AccountService.Balance")
+	defer func() { endSpan(span, err) }()
+
+	account, err := s.Repo.GetAccount(ctx, accountID, userID)
 	if err != nil {
-		return errors.New("пользователь не найден")
+		return nil, accountError(err)
 	}
+	return &models.Balance{
+		AccountID: account.ID,
+		Account:   account.Number,
+		Ledger:    account.Balance,
+		Held:      account.Held,
+		Available: math.Round(account.Available()*100) / 100,
+	}, nil
+}
+
+// SetLowBalanceAlert задаёт порог уведомления о низком остатке, 0 отключает уведомление.
+func (s *AccountService) SetLowBalanceAlert(ctx context.Context, userID, accountID int64, threshold float64) (err error) {
+	ctx, span := startSpan(ctx, "AccountService.SetLowBalanceAlert")
+	defer func() { endSpan(span, err) }()
 
-	toAccountID, err := s.Repo.GetFirstAccountByUserID(toUserID)
+	if threshold < 0 {
+		return fmt.Errorf("%w: порог не может быть отрицательным", ErrInvalidAmount)
+	}
+	if err := s.Repo.SetLowBalanceThreshold(ctx, accountID, userID, threshold); err != nil {
+		return accountError(err)
+	}
+	config.Log.Infof("Порог уведомления о низком остатке счёта %d: %.2f", accountID, threshold)
+	return nil
+}
+
+// Ограничения на размер страницы истории переводов
+const (
+	DefaultTransactionsLimit = 50
+	MaxTransactionsLimit     = 500
+)
+
+func (s *AccountService) GetTransactions(ctx context.Context, userID, accountID int64, limit, offset int) (transactions []models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "AccountService.GetTransactions")
+	defer func() { endSpan(span, err) }()
+
+	if limit == 0 {
+		limit = DefaultTransactionsLimit
+	}
+	if limit < 0 || limit > MaxTransactionsLimit {
+		return nil, fmt.Errorf("%w: limit должен быть от 1 до %d", ErrInvalidPagination, MaxTransactionsLimit)
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("%w: offset не может быть отрицательным", ErrInvalidPagination)
+	}
+
+	transactions, err = s.Repo.GetTransactions(ctx, accountID, userID, limit, offset)
 	if err != nil {
-		return errors.New("счёт получателя не найден")
+		config.Log.Errorf("Ошибка получения истории счёта %d: %v", accountID, err)
+		return nil, err
 	}
+	return transactions, nil
+}
 
-	return s.TransferFunds(fromUserID, fromAccountID, toAccountID, amount)
+// Statement возвращает выписку по счёту за день day (UTC): остатки на начало
+// и конец дня и движения за день. Выписка за текущий день не формируется.
+func (s *AccountService) Statement(ctx context.Context, userID, accountID int64, day time.Time) (statement *models.Statement, err error) {
+	ctx, span := startSpan(ctx, "AccountService.Statement")
+	defer func() { endSpan(span, err) }()
+
+	day = day.UTC().Truncate(24 * time.Hour)
+	end := day.AddDate(0, 0, 1)
+	if end.After(time.Now()) {
+		return nil, fmt.Errorf("%w: %s", ErrStatementNotReady, day.Format(time.DateOnly))
+	}
+	statement, err = s.Repo.GetStatement(ctx, accountID, userID, day, end)
+	if err != nil {
+		return nil, accountError(err)
+	}
+	return statement, nil
 }
 
-func (s *AccountService) TransferBetweenUsers(fromUsername, toUsername string, amount float64) error {
+func (s *AccountService) TransferToUsername(ctx context.Context, fromUserID, fromAccountID int64, toUsername string, amount float64) (err error) {
+	ctx, span := startSpan(ctx, "AccountService.TransferToUsername")
+	defer func() { endSpan(span, err) }()
+
 	if amount <= 0 {
-		return errors.New("сумма должна быть положительной")
+		return ErrInvalidAmount
+	}
+	if toUsername == "" {
+		return fmt.Errorf("%w: получатель не указан", ErrUserNotFound)
+	}
+
+	toUserID, err := s.UserRepo.GetUserIDByUsername(ctx, toUsername)
+	if err != nil {
+		return ErrUserNotFound
 	}
-	if fromUsername == toUsername {
-		return errors.New("нельзя переводить самому себе")
+
+	toAccountID, err := s.Repo.GetFirstAccountByUserID(ctx, toUserID)
+	if err != nil {
+		return fmt.Errorf("%w: у получателя нет счёта", ErrAccountNotFound)
 	}
 
-	fromUserID, err := s.UserRepo.GetUserIDByUsername(fromUsername)
+	return s.TransferFunds(ctx, fromUserID, fromAccountID, toAccountID, amount, "")
+}
+
+// TransferBetweenUsers переводит с первого счёта пользователя userID на
+// первый счёт пользователя toUsername. Отправитель — всегда пользователь
+// токена: fromUsername необязателен и, если указан, должен с ним совпадать.
+func (s *AccountService) TransferBetweenUsers(ctx context.Context, userID int64, fromUsername, toUsername string, amount float64, idempotencyKey string) (err error) {
+	ctx, span := startSpan(ctx, "AccountService.TransferBetweenUsers")
+	defer func() { endSpan(span, err) }()
+
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+	sender, err := s.UserRepo.GetUserByID(ctx, userID)
 	if err != nil {
-		return errors.New("отправитель не найден")
+		return fmt.Errorf("%w: отправитель", ErrUserNotFound)
+	}
+	if fromUsername != "" && fromUsername != sender.Username {
+		return fmt.Errorf("%w: %s", ErrSenderMismatch, fromUsername)
+	}
+	if toUsername == sender.Username {
+		return ErrSelfTransfer
 	}
-	toUserID, err := s.UserRepo.GetUserIDByUsername(toUsername)
+	toUserID, err := s.UserRepo.GetUserIDByUsername(ctx, toUsername)
 	if err != nil {
-		return errors.New("получатель не найден")
+		return fmt.Errorf("%w: получатель %s", ErrUserNotFound, toUsername)
 	}
 
-	fromAccountID, err := s.Repo.GetFirstAccountByUserID(fromUserID)
+	fromAccountID, err := s.Repo.GetFirstAccountByUserID(ctx, userID)
 	if err != nil {
-		return errors.New("счёт отправителя не найден")
+		return fmt.Errorf("%w: у отправителя нет счёта", ErrAccountNotFound)
 	}
-	toAccountID, err := s.Repo.GetFirstAccountByUserID(toUserID)
+	toAccountID, err := s.Repo.GetFirstAccountByUserID(ctx, toUserID)
 	if err != nil {
-		return errors.New("счёт получателя не найден")
+		return fmt.Errorf("%w: у получателя нет счёта", ErrAccountNotFound)
 	}
 
-	return s.TransferFunds(fromUserID, fromAccountID, toAccountID, amount)
+	return s.TransferFunds(ctx, userID, fromAccountID, toAccountID, amount, idempotencyKey)
 }
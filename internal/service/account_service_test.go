@@ -0,0 +1,276 @@
+package service_test
+
+import (
+	"banking-api/internal/accountno"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/service"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTopUpValidation(t *testing.T) {
+	e := newEnv()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	acc := e.account(t, alice.ID, 0)
+	ctx := context.Background()
+
+	if err := e.accounts.TopUp(ctx, alice.ID, acc, 0); err == nil {
+		t.Error("ожидалась ошибка для нулевой суммы")
+	}
+	if err := e.accounts.TopUp(ctx, bob.ID, acc, 10); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("пополнение чужого счёта: ожидалась sql.ErrNoRows, получено %v", err)
+	}
+}
+
+// lookupCounter считает поиски счёта по номеру.
+type lookupCounter struct {
+	repository.AccountRepository
+	lookups int
+}
+
+func (r *lookupCounter) GetAccountIDByNumber(ctx context.Context, number string) (int64, error) {
+	r.lookups++
+	return r.AccountRepository.GetAccountIDByNumber(ctx, number)
+}
+
+func TestAccountID(t *testing.T) {
+	e := newEnv()
+	alice := e.register(t, "alice")
+	acc, err := e.accounts.CreateAccount(context.Background(), alice.ID, "")
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	if err := accountno.Validate(acc.Number); err != nil {
+		t.Fatalf("номер нового счёта %q: %v", acc.Number, err)
+	}
+	repo := &lookupCounter{AccountRepository: e.accounts.Repo}
+	accounts := service.NewAccountService(repo, nil, nil)
+	ctx := context.Background()
+
+	// Номер принимается группами по четыре символа и в нижнем регистре
+	grouped := strings.ToLower(acc.Number[:4] + " " + acc.Number[4:8] + " " + acc.Number[8:])
+	if id, err := accounts.AccountID(ctx, grouped); err != nil || id != acc.ID {
+		t.Errorf("AccountID(%q) = %d, %v; ожидался %d", grouped, id, err, acc.ID)
+	}
+
+	// Опечатка отклоняется по контрольным цифрам без обращения к хранилищу
+	last := len(acc.Number) - 1
+	typo := acc.Number[:last] + string('0'+(acc.Number[last]-'0'+1)%10)
+	repo.lookups = 0
+	for _, number := range []string{typo, "", "12345", "счёт"} {
+		if _, err := accounts.AccountID(ctx, number); !errors.Is(err, service.ErrInvalidAccountNumber) {
+			t.Errorf("AccountID(%q): ожидалась ErrInvalidAccountNumber, получено %v", number, err)
+		}
+	}
+	if repo.lookups != 0 {
+		t.Errorf("невалидные номера искались в хранилище %d раз", repo.lookups)
+	}
+
+	if _, err := accounts.AccountID(ctx, accountno.New()); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("неизвестный номер: ожидалась ErrAccountNotFound, получено %v", err)
+	}
+}
+
+func TestTransferFundsNotifiesReceiver(t *testing.T) {
+	e := newEnv()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	from := e.account(t, alice.ID, 100)
+	to := e.account(t, bob.ID, 0)
+
+	if err := e.accounts.TransferFunds(context.Background(), alice.ID, from, to, 40, ""); err != nil {
+		t.Fatalf("TransferFunds: %v", err)
+	}
+	if len(e.mailer.sent) != 1 || e.mailer.sent[0].To != "bob@example.com" {
+		t.Fatalf("ожидалось одно письмо получателю, отправлено %+v", e.mailer.sent)
+	}
+}
+
+func TestTransferFundsIdempotent(t *testing.T) {
+	e := newEnv()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	from := e.account(t, alice.ID, 100)
+	to := e.account(t, bob.ID, 0)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := e.accounts.TransferFunds(ctx, alice.ID, from, to, 40, "order-1"); err != nil {
+			t.Fatalf("попытка %d: %v", i+1, err)
+		}
+	}
+	if len(e.mailer.sent) != 1 {
+		t.Errorf("повторы не должны отправлять письма, отправлено %d", len(e.mailer.sent))
+	}
+	if err := e.accounts.TransferFunds(ctx, alice.ID, from, to, 50, "order-1"); !errors.Is(err, repository.ErrIdempotencyConflict) {
+		t.Errorf("тот же ключ с другой суммой: ожидалась ErrIdempotencyConflict, получено %v", err)
+	}
+}
+
+func TestTransferFundsErrors(t *testing.T) {
+	e := newEnv()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	from := e.account(t, alice.ID, 100)
+	to := e.account(t, bob.ID, 0)
+	ctx := context.Background()
+
+	if err := e.accounts.TransferFunds(ctx, alice.ID, from, to, -1, ""); !errors.Is(err, service.ErrInvalidAmount) {
+		t.Errorf("отрицательная сумма: ожидалась ErrInvalidAmount, получено %v", err)
+	}
+	if err := e.accounts.TransferFunds(ctx, alice.ID, from, from, 1, ""); !errors.Is(err, service.ErrSelfTransfer) {
+		t.Errorf("перевод на тот же счёт: ожидалась ErrSelfTransfer, получено %v", err)
+	}
+	if err := e.accounts.TransferFunds(ctx, alice.ID, from, to, 500, ""); !errors.Is(err, repository.ErrInsufficientFunds) {
+		t.Errorf("ожидалась ErrInsufficientFunds, получено %v", err)
+	}
+	if err := e.accounts.TransferFunds(ctx, bob.ID, from, to, 1, ""); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("перевод с чужого счёта: ожидалась sql.ErrNoRows, получено %v", err)
+	}
+	if len(e.mailer.sent) != 0 {
+		t.Errorf("неудачные переводы не должны отправлять письма, отправлено %d", len(e.mailer.sent))
+	}
+}
+
+func TestTransferBetweenUsers(t *testing.T) {
+	e := newEnv()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	aliceAcc := e.account(t, alice.ID, 100)
+	e.account(t, bob.ID, 0)
+	ctx := context.Background()
+
+	if err := e.accounts.TransferBetweenUsers(ctx, alice.ID, "alice", "bob", 25, ""); err != nil {
+		t.Fatalf("TransferBetweenUsers: %v", err)
+	}
+	if err := e.accounts.TransferBetweenUsers(ctx, alice.ID, "", "bob", 5, ""); err != nil {
+		t.Fatalf("TransferBetweenUsers без from_username: %v", err)
+	}
+	if err := e.accounts.TransferBetweenUsers(ctx, alice.ID, "alice", "nobody", 25, ""); !errors.Is(err, service.ErrUserNotFound) {
+		t.Errorf("несуществующий получатель: ожидалась ErrUserNotFound, получено %v", err)
+	}
+	// Отправитель — всегда пользователь токена: чужое имя в запросе отклоняется
+	if err := e.accounts.TransferBetweenUsers(ctx, bob.ID, "alice", "bob", 25, ""); !errors.Is(err, service.ErrSenderMismatch) {
+		t.Errorf("перевод от чужого имени: ожидалась ErrSenderMismatch, получено %v", err)
+	}
+	if err := e.accounts.TransferBetweenUsers(ctx, bob.ID, "", "bob", 25, ""); !errors.Is(err, service.ErrSelfTransfer) {
+		t.Errorf("перевод самому себе: ожидалась ErrSelfTransfer, получено %v", err)
+	}
+	if balance, _ := e.accounts.Balance(ctx, alice.ID, aliceAcc); balance.Ledger != 70 {
+		t.Errorf("баланс отправителя = %+v", balance)
+	}
+	if err := e.accounts.TransferToUsername(ctx, bob.ID, 2, "alice", 25); err != nil {
+		t.Fatalf("TransferToUsername: %v", err)
+	}
+}
+
+func TestCreateAccountTypes(t *testing.T) {
+	e := newEnv()
+	e.accounts.CreditLimit = 300
+	e.accounts.SavingsTransfersPerMonth = 3
+	alice := e.register(t, "alice")
+	ctx := context.Background()
+
+	for _, c := range []struct {
+		accountType, want string
+		creditLimit       float64
+		transferLimit     int
+	}{
+		{"", models.AccountChecking, 0, 0},
+		{models.AccountSavings, models.AccountSavings, 0, 3},
+		{models.AccountCredit, models.AccountCredit, 300, 0},
+	} {
+		acc, err := e.accounts.CreateAccount(ctx, alice.ID, c.accountType)
+		if err != nil {
+			t.Fatalf("CreateAccount(%q): %v", c.accountType, err)
+		}
+		if acc.Type != c.want || acc.CreditLimit != c.creditLimit || acc.MonthlyTransferLimit != c.transferLimit {
+			t.Errorf("CreateAccount(%q) = %+v", c.accountType, acc)
+		}
+	}
+	if _, err := e.accounts.CreateAccount(ctx, alice.ID, "deposit"); !errors.Is(err, service.ErrInvalidAccountType) {
+		t.Errorf("неизвестный тип: ожидалась ErrInvalidAccountType, получено %v", err)
+	}
+}
+
+func TestLowBalanceNotifications(t *testing.T) {
+	e := newEnv()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	from := e.account(t, alice.ID, 100)
+	to := e.account(t, bob.ID, 0)
+	ctx := context.Background()
+
+	if err := e.accounts.SetLowBalanceAlert(ctx, alice.ID, from, -1); !errors.Is(err, service.ErrInvalidAmount) {
+		t.Errorf("отрицательный порог: %v", err)
+	}
+	if err := e.accounts.SetLowBalanceAlert(ctx, bob.ID, from, 50); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("порог для чужого счёта: %v", err)
+	}
+	if err := e.accounts.SetLowBalanceAlert(ctx, alice.ID, from, 50); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.admin.SetOverdraft(ctx, "ops", from, models.Overdraft{Limit: 100, Fee: 5}, "заявка"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 100 → 70 → 40 (ниже порога) → 10 → -15 (овердрафт и комиссия)
+	var alerts []string
+	for _, amount := range []float64{30, 30, 30, 20} {
+		if err := e.accounts.TransferFunds(ctx, alice.ID, from, to, amount, ""); err != nil {
+			t.Fatalf("перевод %.2f: %v", amount, err)
+		}
+		alerts = append(alerts, "")
+		for _, m := range e.mailer.sent {
+			if m.To == "alice@example.com" {
+				alerts[len(alerts)-1] = m.Body
+			}
+		}
+		e.mailer.sent = nil
+	}
+	if alerts[0] != "" || alerts[2] != "" {
+		t.Errorf("лишние уведомления: %q", alerts)
+	}
+	if !strings.Contains(alerts[1], "ниже 50.00") {
+		t.Errorf("нет уведомления о низком остатке: %q", alerts[1])
+	}
+	if !strings.Contains(alerts[3], "овердрафт") || !strings.Contains(alerts[3], "комиссия 5.00") || !strings.Contains(alerts[3], "-15.00") {
+		t.Errorf("нет уведомления об овердрафте: %q", alerts[3])
+	}
+}
+
+func TestRefundNotifiesSender(t *testing.T) {
+	e := newEnv()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	from := e.account(t, alice.ID, 100)
+	to := e.account(t, bob.ID, 0)
+	ctx := context.Background()
+	if err := e.accounts.TransferFunds(ctx, alice.ID, from, to, 40, ""); err != nil {
+		t.Fatal(err)
+	}
+	history, err := e.accounts.GetTransactions(ctx, bob.ID, to, 1, 0)
+	if err != nil || len(history) != 1 {
+		t.Fatalf("GetTransactions = %+v, %v", history, err)
+	}
+	e.mailer.sent = nil
+
+	if _, err := e.accounts.Refund(ctx, bob.ID, history[0].ID, -1); !errors.Is(err, service.ErrInvalidAmount) {
+		t.Errorf("отрицательная сумма: %v", err)
+	}
+	if _, err := e.accounts.Refund(ctx, alice.ID, history[0].ID, 0); !errors.Is(err, service.ErrTransactionNotFound) {
+		t.Errorf("возврат отправителем: %v", err)
+	}
+	refund, err := e.accounts.Refund(ctx, bob.ID, history[0].ID, 0)
+	if err != nil || refund.Kind != models.KindRefund || refund.Amount != 40 || refund.ToAccountID != from {
+		t.Fatalf("Refund = %+v, %v", refund, err)
+	}
+	if len(e.mailer.sent) != 1 || e.mailer.sent[0].To != "alice@example.com" {
+		t.Errorf("ожидалось письмо отправителю, отправлено %+v", e.mailer.sent)
+	}
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"banking-api/internal/config"
+	"banking-api/internal/metrics"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AdminService — операции поддержки для bankctl. Изменяющие методы принимают
+// actor (имя оператора) и обязательную причину, которые попадают в журнал аудита.
+type AdminService struct {
+	Repo        repository.AdminRepository
+	AccountRepo repository.AccountRepository
+	UserRepo    repository.UserRepository
+
+	mu             sync.Mutex
+	reconciliation *models.Reconciliation // результат последней сверки
+}
+
+func NewAdminService(repo repository.AdminRepository, accountRepo repository.AccountRepository, userRepo repository.UserRepository) *AdminService {
+	return &AdminService{
+		Repo:        repo,
+		AccountRepo: accountRepo,
+		UserRepo:    userRepo,
+	}
+}
+
+// DefaultAuditLimit — сколько записей журнала возвращается по умолчанию.
+const DefaultAuditLimit = 50
+
+// FindUser ищет пользователя по ID, email (содержит @) или username.
+func (s *AdminService) FindUser(ctx context.Context, query string) (user *models.User, err error) {
+	ctx, span := startSpan(ctx, "AdminService.FindUser")
+	defer func() { endSpan(span, err) }()
+
+	switch id, parseErr := strconv.ParseInt(query, 10, 64); {
+	case parseErr == nil:
+		user, err = s.UserRepo.GetUserByID(ctx, id)
+	case strings.Contains(query, "@"):
+		user, err = s.UserRepo.GetUserByEmail(ctx, query)
+	default:
+		id, err = s.UserRepo.GetUserIDByUsername(ctx, query)
+		if err == nil {
+			user, err = s.UserRepo.GetUserByID(ctx, id)
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Хеш пароля не нужен оператору
+	user.PasswordHash = ""
+	return user, nil
+}
+
+// AccountID возвращает ID любого счёта по номеру; проверки — как у AccountService.AccountID.
+func (s *AdminService) AccountID(ctx context.Context, number string) (accountID int64, err error) {
+	ctx, span := startSpan(ctx, "AdminService.AccountID")
+	defer func() { endSpan(span, err) }()
+
+	return accountIDByNumber(ctx, s.AccountRepo, number)
+}
+
+func (s *AdminService) ListAccounts(ctx context.Context, userID int64) (accounts []models.Account, err error) {
+	ctx, span := startSpan(ctx, "AdminService.ListAccounts")
+	defer func() { endSpan(span, err) }()
+
+	return s.Repo.ListAccountsByUserID(ctx, userID)
+}
+
+// ListTransactions возвращает историю счёта независимо от владельца.
+func (s *AdminService) ListTransactions(ctx context.Context, accountID int64, limit, offset int) (transactions []models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "AdminService.ListTransactions")
+	defer func() { endSpan(span, err) }()
+
+	if limit == 0 {
+		limit = DefaultTransactionsLimit
+	}
+	if limit < 0 || limit > MaxTransactionsLimit {
+		return nil, fmt.Errorf("%w: limit должен быть от 1 до %d", ErrInvalidPagination, MaxTransactionsLimit)
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("%w: offset не может быть отрицательным", ErrInvalidPagination)
+	}
+
+	transactions, err = s.Repo.ListTransactions(ctx, accountID, limit, offset)
+	if err != nil {
+		return nil, accountError(err)
+	}
+	return transactions, nil
+}
+
+// Freeze запрещает пополнения и переводы с участием счёта.
+func (s *AdminService) Freeze(ctx context.Context, actor string, accountID int64, reason string) (err error) {
+	ctx, span := startSpan(ctx, "AdminService.Freeze")
+	defer func() { endSpan(span, err) }()
+
+	return s.setFrozen(ctx, actor, accountID, true, reason)
+}
+
+func (s *AdminService) Unfreeze(ctx context.Context, actor string, accountID int64, reason string) (err error) {
+	ctx, span := startSpan(ctx, "AdminService.Unfreeze")
+	defer func() { endSpan(span, err) }()
+
+	return s.setFrozen(ctx, actor, accountID, false, reason)
+}
+
+func (s *AdminService) setFrozen(ctx context.Context, actor string, accountID int64, frozen bool, reason string) error {
+	if err := checkActor(actor, reason); err != nil {
+		return err
+	}
+	action := models.AuditUnfreeze
+	if frozen {
+		action = models.AuditFreeze
+	}
+	entry := models.AuditEntry{Actor: actor, Action: action, AccountID: accountID, Reason: reason}
+	if err := s.Repo.SetAccountFrozen(ctx, accountID, frozen, entry); err != nil {
+		config.Log.Errorf("Ошибка %s счёта %d: %v", action, accountID, err)
+		return accountError(err)
+	}
+	config.Log.Warnf("Оператор %s: %s счёта %d, причина: %s", actor, action, accountID, reason)
+	return nil
+}
+
+// Adjust зачисляет (amount > 0) или списывает (amount < 0) средства вручную.
+func (s *AdminService) Adjust(ctx context.Context, actor string, accountID int64, amount float64, reason string) (transaction *models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "AdminService.Adjust")
+	defer func() { endSpan(span, err) }()
+
+	if amount == 0 {
+		return nil, fmt.Errorf("%w: сумма корректировки не может быть нулевой", ErrInvalidAmount)
+	}
+	if err := checkActor(actor, reason); err != nil {
+		return nil, err
+	}
+	entry := models.AuditEntry{Actor: actor, Action: models.AuditAdjust, AccountID: accountID, Amount: amount, Reason: reason}
+	transaction, err = s.Repo.AdjustBalance(ctx, accountID, amount, entry)
+	if err != nil {
+		config.Log.Errorf("Ошибка корректировки счёта %d на %.2f: %v", accountID, amount, err)
+		return nil, accountError(err)
+	}
+	config.Log.Warnf("Оператор %s: корректировка счёта %d на %.2f, причина: %s", actor, accountID, amount, reason)
+	return transaction, nil
+}
+
+// Reverse сторнирует ещё не возвращённый остаток перевода или комиссии
+// transactionID с кодом причины reasonCode (models.ReversalReasons).
+func (s *AdminService) Reverse(ctx context.Context, actor string, transactionID int64, reasonCode, reason string) (reversal *models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "AdminService.Reverse")
+	defer func() { endSpan(span, err) }()
+
+	if !slices.Contains(models.ReversalReasons, reasonCode) {
+		return nil, fmt.Errorf("%w: %q, ожидается одно из: %s", ErrInvalidReasonCode, reasonCode, strings.Join(models.ReversalReasons, ", "))
+	}
+	if err := checkActor(actor, reason); err != nil {
+		return nil, err
+	}
+	entry := models.AuditEntry{Actor: actor, Action: models.AuditReversal, Reason: fmt.Sprintf("%s, перевод %d: %s", reasonCode, transactionID, reason)}
+	reversal, err = s.Repo.ReverseTransaction(ctx, transactionID, reasonCode, entry)
+	if err != nil {
+		config.Log.Errorf("Ошибка сторно перевода %d: %v", transactionID, err)
+		return nil, transactionError(err, transactionID)
+	}
+	config.Log.Warnf("Оператор %s: сторно %.2f по переводу %d (%s), причина: %s", actor, reversal.Amount, transactionID, reasonCode, reason)
+	return reversal, nil
+}
+
+// SetOverdraft подключает, меняет или (нулевым лимитом) отключает овердрафт расчётного счёта.
+func (s *AdminService) SetOverdraft(ctx context.Context, actor string, accountID int64, overdraft models.Overdraft, reason string) (err error) {
+	ctx, span := startSpan(ctx, "AdminService.SetOverdraft")
+	defer func() { endSpan(span, err) }()
+
+	if overdraft.Limit < 0 || overdraft.Fee < 0 {
+		return fmt.Errorf("%w: лимит и комиссия овердрафта не могут быть отрицательными", ErrInvalidAmount)
+	}
+	if overdraft.Rate < 0 || overdraft.Rate > 100 {
+		return fmt.Errorf("%w: %v", ErrInvalidRate, overdraft.Rate)
+	}
+	if err := checkActor(actor, reason); err != nil {
+		return err
+	}
+	entry := models.AuditEntry{
+		Actor:     actor,
+		Action:    models.AuditOverdraft,
+		AccountID: accountID,
+		Amount:    overdraft.Limit,
+		Reason:    fmt.Sprintf("ставка %.4g%%, комиссия %.2f: %s", overdraft.Rate, overdraft.Fee, reason),
+	}
+	if err := s.Repo.SetOverdraft(ctx, accountID, overdraft, entry); err != nil {
+		config.Log.Errorf("Ошибка настройки овердрафта счёта %d: %v", accountID, err)
+		return accountError(err)
+	}
+	config.Log.Warnf("Оператор %s: овердрафт счёта %d %+v, причина: %s", actor, accountID, overdraft, reason)
+	return nil
+}
+
+// SetApprovalPolicy задаёт порог одобрения счёта и одобряющих (по username):
+// переводы со счёта больше порога выполняются только после одобрения одним
+// из них. Нулевой порог отключает одобрение.
+func (s *AdminService) SetApprovalPolicy(ctx context.Context, actor string, accountID int64, threshold float64, approvers []string, reason string) (err error) {
+	ctx, span := startSpan(ctx, "AdminService.SetApprovalPolicy")
+	defer func() { endSpan(span, err) }()
+
+	if threshold < 0 {
+		return fmt.Errorf("%w: порог одобрения не может быть отрицательным", ErrInvalidAmount)
+	}
+	if threshold > 0 && len(approvers) == 0 {
+		return fmt.Errorf("%w: для порога одобрения нужен хотя бы один одобряющий", ErrInvalidApproval)
+	}
+	if threshold == 0 && len(approvers) > 0 {
+		return fmt.Errorf("%w: одобряющие без порога одобрения", ErrInvalidApproval)
+	}
+	if err := checkActor(actor, reason); err != nil {
+		return err
+	}
+	account, err := s.Repo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return accountError(err)
+	}
+	approverIDs := make([]int64, 0, len(approvers))
+	for _, username := range approvers {
+		userID, err := s.UserRepo.GetUserIDByUsername(ctx, username)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrUserNotFound, username)
+		}
+		// Владелец — автор переводов и не может одобрять их сам
+		if userID == account.UserID {
+			return fmt.Errorf("%w: владелец счёта не может одобрять свои переводы", ErrInvalidApproval)
+		}
+		approverIDs = append(approverIDs, userID)
+	}
+	entry := models.AuditEntry{
+		Actor:     actor,
+		Action:    models.AuditApprovals,
+		AccountID: accountID,
+		Amount:    threshold,
+		Reason:    fmt.Sprintf("одобряющие [%s]: %s", strings.Join(approvers, ", "), reason),
+	}
+	if err := s.Repo.SetApprovalPolicy(ctx, accountID, threshold, approverIDs, entry); err != nil {
+		config.Log.Errorf("Ошибка настройки одобрения переводов счёта %d: %v", accountID, err)
+		return accountError(err)
+	}
+	config.Log.Warnf("Оператор %s: порог одобрения счёта %d %.2f, одобряющие %v, причина: %s", actor, accountID, threshold, approvers, reason)
+	return nil
+}
+
+// accountError заменяет sql.ErrNoRows на ErrAccountNotFound. Внутренний ID
+// счёта в ошибку не попадает: клиенты знают счёт только по номеру.
+func accountError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAccountNotFound
+	}
+	return err
+}
+
+func checkActor(actor, reason string) error {
+	if strings.TrimSpace(actor) == "" {
+		return ErrActorRequired
+	}
+	if strings.TrimSpace(reason) == "" {
+		return ErrReasonRequired
+	}
+	return nil
+}
+
+// AuditLog возвращает журнал по счёту (весь при accountID == 0).
+func (s *AdminService) AuditLog(ctx context.Context, accountID int64, limit int) (entries []models.AuditEntry, err error) {
+	ctx, span := startSpan(ctx, "AdminService.AuditLog")
+	defer func() { endSpan(span, err) }()
+
+	if limit == 0 {
+		limit = DefaultAuditLimit
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("%w: limit не может быть отрицательным", ErrInvalidPagination)
+	}
+	return s.Repo.ListAuditLog(ctx, accountID, limit)
+}
+
+// Reconcile сверяет балансы с историей движений и проверяет сохранение денег.
+// Результат запоминается (см. LastReconciliation) и отдаётся в метриках.
+func (s *AdminService) Reconcile(ctx context.Context) (report *models.Reconciliation, err error) {
+	ctx, span := startSpan(ctx, "AdminService.Reconcile")
+	defer func() { endSpan(span, err) }()
+
+	report, err = s.Repo.Reconcile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.CheckedAt = time.Now().UTC()
+	if !report.OK() {
+		config.Log.Errorf("Сверка выявила нарушения: %+v", report)
+	}
+	metrics.ObserveReconciliation(report.CheckedAt, len(report.BalanceMismatches), len(report.NegativeBalances),
+		len(report.InvalidTransactions), report.Imbalance)
+
+	s.mu.Lock()
+	s.reconciliation = report
+	s.mu.Unlock()
+	return report, nil
+}
+
+// LastReconciliation возвращает результат последней сверки или nil, если
+// сверка ещё не запускалась.
+func (s *AdminService) LastReconciliation() *models.Reconciliation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reconciliation
+}
@@ -0,0 +1,174 @@
+package service_test
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/service"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAdminFindUser(t *testing.T) {
+	e := newEnv()
+	alice := e.register(t, "alice")
+	ctx := context.Background()
+
+	for _, query := range []string{"alice", "alice@example.com", "1"} {
+		user, err := e.admin.FindUser(ctx, query)
+		if err != nil || user.ID != alice.ID {
+			t.Errorf("FindUser(%q) = %+v, %v", query, user, err)
+			continue
+		}
+		if user.PasswordHash != "" {
+			t.Errorf("FindUser(%q) вернул хеш пароля", query)
+		}
+	}
+	if _, err := e.admin.FindUser(ctx, "nobody"); !errors.Is(err, service.ErrUserNotFound) {
+		t.Errorf("ожидалась ErrUserNotFound, получено %v", err)
+	}
+}
+
+func TestAdminFreeze(t *testing.T) {
+	e := newEnv()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	from := e.account(t, alice.ID, 100)
+	to := e.account(t, bob.ID, 0)
+	ctx := context.Background()
+
+	if err := e.admin.Freeze(ctx, "support", from, ""); !errors.Is(err, service.ErrReasonRequired) {
+		t.Errorf("заморозка без причины: %v", err)
+	}
+	if err := e.admin.Freeze(ctx, "", from, "жалоба"); !errors.Is(err, service.ErrActorRequired) {
+		t.Errorf("заморозка без оператора: %v", err)
+	}
+	if err := e.admin.Freeze(ctx, "support", from, "жалоба"); err != nil {
+		t.Fatalf("Freeze: %v", err)
+	}
+	if err := e.accounts.TransferFunds(ctx, alice.ID, from, to, 10, ""); !errors.Is(err, repository.ErrAccountFrozen) {
+		t.Errorf("перевод с замороженного счёта: %v", err)
+	}
+	if err := e.admin.Unfreeze(ctx, "support", from, "проверка пройдена"); err != nil {
+		t.Fatalf("Unfreeze: %v", err)
+	}
+	if err := e.accounts.TransferFunds(ctx, alice.ID, from, to, 10, ""); err != nil {
+		t.Errorf("перевод после разморозки: %v", err)
+	}
+
+	log, err := e.admin.AuditLog(ctx, from, 0)
+	if err != nil || len(log) != 2 || log[0].Action != models.AuditUnfreeze || log[0].Reason != "проверка пройдена" {
+		t.Errorf("журнал = %+v, %v", log, err)
+	}
+}
+
+func TestAdminAdjust(t *testing.T) {
+	e := newEnv()
+	alice := e.register(t, "alice")
+	acc := e.account(t, alice.ID, 10)
+	ctx := context.Background()
+
+	if _, err := e.admin.Adjust(ctx, "support", acc, 0, "ошибка"); !errors.Is(err, service.ErrInvalidAmount) {
+		t.Errorf("нулевая корректировка: %v", err)
+	}
+	if _, err := e.admin.Adjust(ctx, "support", acc, -20, "возврат"); !errors.Is(err, repository.ErrInsufficientFunds) {
+		t.Errorf("списание сверх баланса: %v", err)
+	}
+	tr, err := e.admin.Adjust(ctx, "support", acc, -4, "комиссия")
+	if err != nil || tr.Kind != models.KindAdjustment || tr.FromAccountID != acc {
+		t.Fatalf("Adjust = %+v, %v", tr, err)
+	}
+
+	history, err := e.admin.ListTransactions(ctx, acc, 0, 0)
+	if err != nil || len(history) != 2 || history[0].ID != tr.ID || history[1].Kind != models.KindTopUp {
+		t.Errorf("история = %+v, %v", history, err)
+	}
+	accounts, err := e.admin.ListAccounts(ctx, alice.ID)
+	if err != nil || len(accounts) != 1 || accounts[0].Balance != 6 {
+		t.Errorf("счета = %+v, %v", accounts, err)
+	}
+	if e.admin.LastReconciliation() != nil {
+		t.Error("результат сверки до её запуска")
+	}
+	report, err := e.admin.Reconcile(ctx)
+	if err != nil || !report.OK() || report.TotalBalance != 6 || report.CheckedAt.IsZero() {
+		t.Errorf("сверка = %+v, %v", report, err)
+	}
+	if e.admin.LastReconciliation() != report {
+		t.Error("последняя сверка не запомнена")
+	}
+}
+
+func TestAdminUnknownAccount(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+
+	if err := e.admin.Freeze(ctx, "support", 42, "жалоба"); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("заморозка несуществующего счёта: %v", err)
+	}
+	if _, err := e.admin.Adjust(ctx, "support", 42, 10, "бонус"); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("корректировка несуществующего счёта: %v", err)
+	}
+	if _, err := e.admin.ListTransactions(ctx, 42, 0, 0); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("история несуществующего счёта: %v", err)
+	}
+}
+
+func TestAdminSetOverdraft(t *testing.T) {
+	e := newEnv()
+	alice := e.register(t, "alice")
+	acc := e.account(t, alice.ID, 0)
+	ctx := context.Background()
+
+	if err := e.admin.SetOverdraft(ctx, "ops", acc, models.Overdraft{Limit: -1}, "заявка"); !errors.Is(err, service.ErrInvalidAmount) {
+		t.Errorf("отрицательный лимит: %v", err)
+	}
+	if err := e.admin.SetOverdraft(ctx, "ops", acc, models.Overdraft{Limit: 100, Rate: 120}, "заявка"); !errors.Is(err, service.ErrInvalidRate) {
+		t.Errorf("ставка больше 100%%: %v", err)
+	}
+	if err := e.admin.SetOverdraft(ctx, "ops", acc, models.Overdraft{Limit: 100}, ""); !errors.Is(err, service.ErrReasonRequired) {
+		t.Errorf("без причины: %v", err)
+	}
+	if err := e.admin.SetOverdraft(ctx, "ops", 42, models.Overdraft{Limit: 100}, "заявка"); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("несуществующий счёт: %v", err)
+	}
+	if err := e.admin.SetOverdraft(ctx, "ops", acc, models.Overdraft{Limit: 100, Rate: 20, Fee: 3}, "заявка"); err != nil {
+		t.Fatalf("SetOverdraft: %v", err)
+	}
+	accounts, err := e.admin.ListAccounts(ctx, alice.ID)
+	if err != nil || len(accounts) != 1 || accounts[0].OverdraftLimit != 100 || accounts[0].OverdraftRate != 20 || accounts[0].OverdraftFee != 3 {
+		t.Errorf("счета = %+v, %v", accounts, err)
+	}
+}
+
+func TestAdminReverse(t *testing.T) {
+	e := newEnv()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	from := e.account(t, alice.ID, 100)
+	to := e.account(t, bob.ID, 0)
+	ctx := context.Background()
+	if err := e.accounts.TransferFunds(ctx, alice.ID, from, to, 30, ""); err != nil {
+		t.Fatal(err)
+	}
+	history, _ := e.accounts.GetTransactions(ctx, alice.ID, from, 1, 0)
+
+	if _, err := e.admin.Reverse(ctx, "support", history[0].ID, "typo", "ошибка"); !errors.Is(err, service.ErrInvalidReasonCode) {
+		t.Errorf("неизвестный код причины: %v", err)
+	}
+	if _, err := e.admin.Reverse(ctx, "support", history[0].ID, models.ReversalFraud, ""); !errors.Is(err, service.ErrReasonRequired) {
+		t.Errorf("сторно без причины: %v", err)
+	}
+	if _, err := e.admin.Reverse(ctx, "support", history[0].ID+100, models.ReversalFraud, "жалоба"); !errors.Is(err, service.ErrTransactionNotFound) {
+		t.Errorf("сторно несуществующего перевода: %v", err)
+	}
+	reversal, err := e.admin.Reverse(ctx, "support", history[0].ID, models.ReversalFraud, "жалоба")
+	if err != nil || reversal.Kind != models.KindReversal || reversal.Amount != 30 || reversal.ReasonCode != models.ReversalFraud {
+		t.Fatalf("Reverse = %+v, %v", reversal, err)
+	}
+
+	log, err := e.admin.AuditLog(ctx, from, 0)
+	if err != nil || len(log) != 1 || log[0].Action != models.AuditReversal || log[0].Amount != 30 {
+		t.Errorf("журнал = %+v, %v", log, err)
+	}
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"banking-api/internal/config"
+	"banking-api/internal/metrics"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"time"
+)
+
+// Параметры одобрения переводов
+const (
+	DefaultApprovalTTL   = 48 * time.Hour
+	MaxApprovalReasonLen = 200
+)
+
+// ApprovalPendingError — перевод больше порога одобрения счёта сохранён и
+// ждёт решения одобряющего. errors.Is(err, ErrApprovalPending) == true.
+type ApprovalPendingError struct {
+	Approval *models.TransferApproval
+}
+
+func (e *ApprovalPendingError) Error() string {
+	return fmt.Sprintf("%v: перевод %d", ErrApprovalPending, e.Approval.ID)
+}
+
+func (e *ApprovalPendingError) Unwrap() error { return ErrApprovalPending }
+
+// ApprovalService — одобрение крупных переводов (maker-checker): перевод со
+// счёта больше его порога не выполняется сразу, а ждёт решения одного из
+// назначенных банком одобряющих. Одобряющие получают уведомление о новом
+// переводе, автор — о решении или истечении срока.
+type ApprovalService struct {
+	Repo         repository.ApprovalRepository
+	UserRepo     repository.UserRepository
+	EmailService Mailer
+
+	// TTL — срок, в который перевод нужно одобрить.
+	TTL time.Duration
+}
+
+func NewApprovalService(repo repository.ApprovalRepository, userRepo repository.UserRepository, email Mailer) *ApprovalService {
+	return &ApprovalService{Repo: repo, UserRepo: userRepo, EmailService: email, TTL: DefaultApprovalTTL}
+}
+
+// Request ставит перевод amount со счёта fromID пользователя makerID на счёт
+// toID на одобрение. Повтор с тем же непустым idempotencyKey возвращает уже
+// созданный перевод, если совпадают параметры, иначе ErrIdempotencyConflict.
+func (s *ApprovalService) Request(ctx context.Context, makerID, fromID, toID int64, amount float64, idempotencyKey string) (approval *models.TransferApproval, err error) {
+	ctx, span := startSpan(ctx, "ApprovalService.Request")
+	defer func() { endSpan(span, err) }()
+
+	approval = &models.TransferApproval{
+		FromAccountID:  fromID,
+		ToAccountID:    toID,
+		Amount:         amount,
+		MakerID:        makerID,
+		IdempotencyKey: idempotencyKey,
+		ExpiresAt:      time.Now().UTC().Add(s.TTL),
+	}
+	err = s.Repo.CreateApproval(ctx, approval)
+	if errors.Is(err, repository.ErrDuplicate) {
+		existing, err := s.Repo.GetApprovalByKey(ctx, fromID, idempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+		if existing.MakerID != makerID || existing.ToAccountID != toID || existing.Amount != amount {
+			return nil, repository.ErrIdempotencyConflict
+		}
+		return existing, nil
+	}
+	if err != nil {
+		config.Log.Errorf("Ошибка постановки перевода на одобрение со счёта %d: %v", fromID, err)
+		return nil, accountError(err)
+	}
+	metrics.ObserveTransfer(metrics.TransferPendingApproval, amount)
+	config.Log.Infof("Перевод %.2f со счёта %d на счёт %d ждёт одобрения (%d)", approval.Amount, fromID, toID, approval.ID)
+
+	approvers, err := s.Repo.Approvers(ctx, fromID)
+	if err != nil {
+		config.Log.Warnf("Не удалось получить одобряющих счёта %d: %v", fromID, err)
+	}
+	body := fmt.Sprintf("<h3>%s просит одобрить перевод %.2f RUB со счёта %s на счёт %s</h3><p>Перевод нужно рассмотреть до %s UTC</p>",
+		html.EscapeString(approval.Maker), approval.Amount, approval.FromAccount, approval.ToAccount, approval.ExpiresAt.Format("2006-01-02 15:04"))
+	for _, userID := range approvers {
+		s.notify(ctx, userID, "Перевод ждёт одобрения", body)
+	}
+	return approval, nil
+}
+
+// List возвращает переводы, которые userID создал или может рассмотреть;
+// непустой status оставляет переводы в этом статусе.
+func (s *ApprovalService) List(ctx context.Context, userID int64, status string) (approvals []models.TransferApproval, err error) {
+	ctx, span := startSpan(ctx, "ApprovalService.List")
+	defer func() { endSpan(span, err) }()
+
+	switch status {
+	case "", models.ApprovalPending, models.ApprovalApproved, models.ApprovalRejected, models.ApprovalExpired:
+	default:
+		return nil, fmt.Errorf("%w: неизвестный статус %q", ErrInvalidApproval, status)
+	}
+	return s.Repo.ListApprovals(ctx, userID, status)
+}
+
+// Get возвращает перевод, который userID создал или может рассмотреть.
+func (s *ApprovalService) Get(ctx context.Context, userID, approvalID int64) (approval *models.TransferApproval, err error) {
+	ctx, span := startSpan(ctx, "ApprovalService.Get")
+	defer func() { endSpan(span, err) }()
+
+	approval, err = s.Repo.GetApproval(ctx, approvalID, userID)
+	if err != nil {
+		return nil, approvalError(err, approvalID)
+	}
+	return approval, nil
+}
+
+// Approve одобряет и выполняет перевод. Одобрить может только одобряющий
+// счёта списания, но не автор перевода.
+func (s *ApprovalService) Approve(ctx context.Context, checkerID, approvalID int64) (approval *models.TransferApproval, err error) {
+	ctx, span := startSpan(ctx, "ApprovalService.Approve")
+	defer func() { endSpan(span, err) }()
+
+	approval, err = s.Repo.ApproveTransfer(ctx, approvalID, checkerID, time.Now().UTC())
+	if err != nil {
+		config.Log.Errorf("Ошибка одобрения перевода %d: %v", approvalID, err)
+		return nil, approvalError(err, approvalID)
+	}
+	metrics.ObserveTransfer(metrics.TransferSuccess, approval.Amount)
+	config.Log.Infof("Перевод %d одобрен пользователем %s и выполнен (%d)", approval.ID, approval.Checker, approval.TransactionID)
+
+	body := fmt.Sprintf("<h3>%s одобрил ваш перевод %.2f RUB на счёт %s</h3>",
+		html.EscapeString(approval.Checker), approval.Amount, approval.ToAccount)
+	s.notify(ctx, approval.MakerID, "Перевод одобрен", body)
+	return approval, nil
+}
+
+// Reject отклоняет перевод с необязательной причиной.
+func (s *ApprovalService) Reject(ctx context.Context, checkerID, approvalID int64, reason string) (approval *models.TransferApproval, err error) {
+	ctx, span := startSpan(ctx, "ApprovalService.Reject")
+	defer func() { endSpan(span, err) }()
+
+	if len([]rune(reason)) > MaxApprovalReasonLen {
+		return nil, fmt.Errorf("%w: причина длиннее %d символов", ErrInvalidApproval, MaxApprovalReasonLen)
+	}
+	approval, err = s.Repo.RejectTransfer(ctx, approvalID, checkerID, reason, time.Now().UTC())
+	if err != nil {
+		return nil, approvalError(err, approvalID)
+	}
+	config.Log.Infof("Перевод %d отклонён пользователем %s", approval.ID, approval.Checker)
+
+	body := fmt.Sprintf("<h3>%s отклонил ваш перевод %.2f RUB на счёт %s</h3>",
+		html.EscapeString(approval.Checker), approval.Amount, approval.ToAccount)
+	if approval.Reason != "" {
+		body += fmt.Sprintf("<p>%s</p>", html.EscapeString(approval.Reason))
+	}
+	s.notify(ctx, approval.MakerID, "Перевод отклонён", body)
+	return approval, nil
+}
+
+// Expire закрывает не рассмотренные к now переводы и уведомляет авторов.
+// Возвращает число истёкших переводов.
+func (s *ApprovalService) Expire(ctx context.Context, now time.Time) (n int, err error) {
+	ctx, span := startSpan(ctx, "ApprovalService.Expire")
+	defer func() { endSpan(span, err) }()
+
+	expired, err := s.Repo.ExpireApprovals(ctx, now)
+	if err != nil {
+		config.Log.Errorf("Ошибка закрытия истёкших переводов на одобрении: %v", err)
+		return 0, err
+	}
+	for _, approval := range expired {
+		body := fmt.Sprintf("<h3>Ваш перевод %.2f RUB на счёт %s не одобрен вовремя и не выполнен</h3>", approval.Amount, approval.ToAccount)
+		s.notify(ctx, approval.MakerID, "Срок одобрения перевода истёк", body)
+	}
+	if len(expired) > 0 {
+		config.Log.Infof("Истёк срок одобрения %d переводов", len(expired))
+	}
+	return len(expired), nil
+}
+
+// notify отправляет письмо пользователю userID; ошибки отправки только логируются.
+func (s *ApprovalService) notify(ctx context.Context, userID int64, subject, body string) {
+	if s.EmailService == nil {
+		return
+	}
+	user, err := s.UserRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return
+	}
+	if err := s.EmailService.SendEmail(ctx, user.Email, subject, body); err != nil {
+		config.Log.Warnf("Не удалось отправить уведомление %q пользователю %d: %v", subject, userID, err)
+	}
+}
+
+func approvalError(err error, approvalID int64) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %d", ErrApprovalNotFound, approvalID)
+	}
+	return err
+}
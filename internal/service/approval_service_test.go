@@ -0,0 +1,175 @@
+package service_test
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/service"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApprovalPolicy(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	e.register(t, "bob")
+	aliceAcc := e.account(t, alice.ID, 0)
+
+	invalid := []struct {
+		name      string
+		threshold float64
+		approvers []string
+		want      error
+	}{
+		{"отрицательный порог", -1, []string{"bob"}, service.ErrInvalidAmount},
+		{"порог без одобряющих", 100, nil, service.ErrInvalidApproval},
+		{"одобряющие без порога", 0, []string{"bob"}, service.ErrInvalidApproval},
+		{"неизвестный одобряющий", 100, []string{"dave"}, service.ErrUserNotFound},
+		{"владелец счёта", 100, []string{"alice"}, service.ErrInvalidApproval},
+	}
+	for _, tt := range invalid {
+		if err := e.admin.SetApprovalPolicy(ctx, "ops", aliceAcc, tt.threshold, tt.approvers, "заявка"); !errors.Is(err, tt.want) {
+			t.Errorf("%s: %v, ожидалось %v", tt.name, err, tt.want)
+		}
+	}
+	if err := e.admin.SetApprovalPolicy(ctx, "ops", aliceAcc, 100, []string{"bob"}, ""); !errors.Is(err, service.ErrReasonRequired) {
+		t.Errorf("без причины: %v", err)
+	}
+	if err := e.admin.SetApprovalPolicy(ctx, "ops", aliceAcc+100, 100, []string{"bob"}, "заявка"); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("несуществующий счёт: %v", err)
+	}
+	if err := e.admin.SetApprovalPolicy(ctx, "ops", aliceAcc, 100, []string{"bob"}, "заявка"); err != nil {
+		t.Fatalf("SetApprovalPolicy: %v", err)
+	}
+	log, err := e.admin.AuditLog(ctx, aliceAcc, 10)
+	if err != nil || len(log) != 1 || log[0].Action != models.AuditApprovals || log[0].Amount != 100 || !strings.Contains(log[0].Reason, "bob") {
+		t.Errorf("журнал = %+v, %v", log, err)
+	}
+}
+
+func TestApprovalFlow(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	carol := e.register(t, "carol")
+	aliceAcc := e.account(t, alice.ID, 1000)
+	carolAcc := e.account(t, carol.ID, 0)
+	if err := e.admin.SetApprovalPolicy(ctx, "ops", aliceAcc, 100, []string{"bob"}, "заявка"); err != nil {
+		t.Fatal(err)
+	}
+
+	// До порога перевод выполняется сразу
+	if err := e.accounts.TransferFunds(ctx, alice.ID, aliceAcc, carolAcc, 100, ""); err != nil {
+		t.Fatalf("перевод до порога: %v", err)
+	}
+	e.mailer.sent = nil
+	err := e.accounts.TransferFunds(ctx, alice.ID, aliceAcc, carolAcc, 300, "key-1")
+	var pending *service.ApprovalPendingError
+	if !errors.As(err, &pending) || !errors.Is(err, service.ErrApprovalPending) || pending.Approval.Status != models.ApprovalPending {
+		t.Fatalf("перевод сверх порога: %v", err)
+	}
+	approval := pending.Approval
+	if len(e.mailer.sent) != 1 || e.mailer.sent[0].To != "bob@example.com" || e.mailer.sent[0].Subject != "Перевод ждёт одобрения" {
+		t.Errorf("уведомление одобряющему = %+v", e.mailer.sent)
+	}
+	if !approval.ExpiresAt.After(time.Now().Add(47 * time.Hour)) {
+		t.Errorf("срок одобрения = %s", approval.ExpiresAt)
+	}
+
+	// Повтор с тем же ключом возвращает тот же перевод, с другими параметрами — конфликт
+	err = e.accounts.TransferFunds(ctx, alice.ID, aliceAcc, carolAcc, 300, "key-1")
+	if !errors.As(err, &pending) || pending.Approval.ID != approval.ID || len(e.mailer.sent) != 1 {
+		t.Errorf("повтор перевода: %v", err)
+	}
+	if err := e.accounts.TransferFunds(ctx, alice.ID, aliceAcc, carolAcc, 400, "key-1"); !errors.Is(err, repository.ErrIdempotencyConflict) {
+		t.Errorf("повтор с другой суммой: %v", err)
+	}
+
+	if _, err := e.approvals.List(ctx, bob.ID, "unknown"); !errors.Is(err, service.ErrInvalidApproval) {
+		t.Errorf("неизвестный статус: %v", err)
+	}
+	if list, err := e.approvals.List(ctx, bob.ID, models.ApprovalPending); err != nil || len(list) != 1 || list[0].ID != approval.ID {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+	if _, err := e.approvals.Get(ctx, carol.ID, approval.ID); !errors.Is(err, service.ErrApprovalNotFound) {
+		t.Errorf("перевод глазами постороннего: %v", err)
+	}
+	if _, err := e.approvals.Approve(ctx, alice.ID, approval.ID); !errors.Is(err, service.ErrApprovalNotFound) {
+		t.Errorf("одобрение автором: %v", err)
+	}
+
+	approved, err := e.approvals.Approve(ctx, bob.ID, approval.ID)
+	if err != nil || approved.Status != models.ApprovalApproved || approved.Checker != "bob" || approved.TransactionID == 0 {
+		t.Fatalf("Approve = %+v, %v", approved, err)
+	}
+	if last := e.mailer.sent[len(e.mailer.sent)-1]; last.To != "alice@example.com" || last.Subject != "Перевод одобрен" {
+		t.Errorf("уведомление автору = %+v", last)
+	}
+	if accounts, err := e.admin.ListAccounts(ctx, carol.ID); err != nil || accounts[0].Balance != 400 {
+		t.Errorf("счета получателя = %+v, %v", accounts, err)
+	}
+	if _, err := e.approvals.Reject(ctx, bob.ID, approval.ID, ""); !errors.Is(err, repository.ErrApprovalClosed) {
+		t.Errorf("отклонение одобренного: %v", err)
+	}
+	// Повтор выполненного перевода ничего не меняет
+	if err := e.accounts.TransferFunds(ctx, alice.ID, aliceAcc, carolAcc, 300, "key-1"); err != nil {
+		t.Errorf("повтор одобренного перевода: %v", err)
+	}
+}
+
+func TestApprovalRejectExpire(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	carol := e.register(t, "carol")
+	aliceAcc := e.account(t, alice.ID, 1000)
+	carolAcc := e.account(t, carol.ID, 0)
+	if err := e.admin.SetApprovalPolicy(ctx, "ops", aliceAcc, 100, []string{"bob"}, "заявка"); err != nil {
+		t.Fatal(err)
+	}
+	request := func(amount float64) *models.TransferApproval {
+		t.Helper()
+		var pending *service.ApprovalPendingError
+		if err := e.accounts.TransferFunds(ctx, alice.ID, aliceAcc, carolAcc, amount, ""); !errors.As(err, &pending) {
+			t.Fatalf("TransferFunds: %v", err)
+		}
+		return pending.Approval
+	}
+
+	rejected := request(200)
+	if _, err := e.approvals.Reject(ctx, bob.ID, rejected.ID, strings.Repeat("я", service.MaxApprovalReasonLen+1)); !errors.Is(err, service.ErrInvalidApproval) {
+		t.Errorf("длинная причина: %v", err)
+	}
+	rejected, err := e.approvals.Reject(ctx, bob.ID, rejected.ID, "<b>нет договора</b>")
+	if err != nil || rejected.Status != models.ApprovalRejected {
+		t.Fatalf("Reject = %+v, %v", rejected, err)
+	}
+	if last := e.mailer.sent[len(e.mailer.sent)-1]; last.To != "alice@example.com" || !strings.Contains(last.Body, "&lt;b&gt;нет договора") {
+		t.Errorf("уведомление об отклонении = %+v", last)
+	}
+
+	stale := request(300)
+	if n, err := e.approvals.Expire(ctx, time.Now()); err != nil || n != 0 {
+		t.Errorf("Expire до срока = %d, %v", n, err)
+	}
+	if n, err := e.approvals.Expire(ctx, stale.ExpiresAt); err != nil || n != 1 {
+		t.Fatalf("Expire = %d, %v", n, err)
+	}
+	if last := e.mailer.sent[len(e.mailer.sent)-1]; last.To != "alice@example.com" || last.Subject != "Срок одобрения перевода истёк" {
+		t.Errorf("уведомление об истечении = %+v", last)
+	}
+	if accounts, err := e.admin.ListAccounts(ctx, carol.ID); err != nil || accounts[0].Balance != 0 {
+		t.Errorf("счета получателя = %+v, %v", accounts, err)
+	}
+
+	// Без сервиса одобрения перевод сверх порога отклоняется
+	e.accounts.Approvals = nil
+	if err := e.accounts.TransferFunds(ctx, alice.ID, aliceAcc, carolAcc, 300, ""); !errors.Is(err, repository.ErrApprovalRequired) {
+		t.Errorf("перевод без сервиса одобрения: %v", err)
+	}
+}
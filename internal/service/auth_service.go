@@ -2,44 +2,53 @@ package service
 
 import (
 	"banking-api/internal/config"
+	"banking-api/internal/metrics"
 	"banking-api/internal/models"
 	"banking-api/internal/repository"
+	"context"
 	"errors"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
-	UserRepo *repository.UserRepository
+	UserRepo repository.UserRepository
 }
 
-func NewAuthService(userRepo *repository.UserRepository) *AuthService {
+func NewAuthService(userRepo repository.UserRepository) *AuthService {
 	return &AuthService{UserRepo: userRepo}
 }
 
-func (s *AuthService) RegisterUser(req *models.RegisterRequest) (*models.User, error) {
-	exists, err := s.UserRepo.IsEmailOrUsernameTaken(req.Email, req.Username)
+func (s *AuthService) RegisterUser(ctx context.Context, req *models.RegisterRequest) (user *models.User, err error) {
+	ctx, span := startSpan(ctx, "AuthService.RegisterUser")
+	defer func() { endSpan(span, err) }()
+
+	exists, err := s.UserRepo.IsEmailOrUsernameTaken(ctx, req.Email, req.Username)
 	if err != nil {
 		config.Log.Errorf("Ошибка проверки уникальности: %v", err)
 		return nil, err
 	}
 	if exists {
-		return nil, errors.New("email или username уже используется")
+		return nil, ErrUserExists
 	}
 
-	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashed, err := hashPassword(ctx, req.Password)
 	if err != nil {
 		config.Log.Errorf("Ошибка хеширования пароля: %v", err)
 		return nil, err
 	}
 
-	user := &models.User{
+	user = &models.User{
 		Email:        req.Email,
 		Username:     req.Username,
 		PasswordHash: string(hashed),
 	}
 
-	if err := s.UserRepo.CreateUser(user); err != nil {
+	if err := s.UserRepo.CreateUser(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			// Параллельная регистрация с тем же email или username
+			return nil, ErrUserExists
+		}
 		config.Log.Errorf("Ошибка создания пользователя: %v", err)
 		return nil, err
 	}
@@ -48,25 +57,47 @@ func (s *AuthService) RegisterUser(req *models.RegisterRequest) (*models.User, e
 	return user, nil
 }
 
-func (s *AuthService) LoginUser(req *models.LoginRequest, jwtSecret string) (string, error) {
-	user, err := s.UserRepo.GetUserByEmail(req.Email)
+func (s *AuthService) LoginUser(ctx context.Context, req *models.LoginRequest, jwtSecret string) (token string, err error) {
+	ctx, span := startSpan(ctx, "AuthService.LoginUser")
+	defer func() { endSpan(span, err) }()
+
+	user, err := s.UserRepo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
+		metrics.Logins.WithLabelValues("unknown_email").Inc()
 		config.Log.Warnf("Ошибка входа (email не найден): %s", req.Email)
-		return "", errors.New("неверный email или пароль")
+		return "", ErrInvalidCredentials
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
+	err = checkPassword(ctx, user.PasswordHash, req.Password)
 	if err != nil {
+		metrics.Logins.WithLabelValues("wrong_password").Inc()
 		config.Log.Warnf("Ошибка входа (неверный пароль): %s", req.Email)
-		return "", errors.New("неверный email или пароль")
+		return "", ErrInvalidCredentials
 	}
 
-	token, err := GenerateJWT(user.ID, jwtSecret)
+	token, err = GenerateJWT(user.ID, jwtSecret)
 	if err != nil {
+		metrics.Logins.WithLabelValues("error").Inc()
 		config.Log.Errorf("Ошибка генерации токена: %v", err)
 		return "", err
 	}
 
+	metrics.Logins.WithLabelValues("success").Inc()
 	config.Log.Infof("Пользователь вошёл: %s", user.Email)
 	return token, nil
 }
+
+// hashPassword и checkPassword выделены в отдельные спаны: bcrypt намеренно медленный.
+func hashPassword(ctx context.Context, password string) (hash []byte, err error) {
+	_, span := startSpan(ctx, "bcrypt.GenerateFromPassword")
+	defer func() { endSpan(span, err) }()
+
+	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+}
+
+func checkPassword(ctx context.Context, hash, password string) (err error) {
+	_, span := startSpan(ctx, "bcrypt.CompareHashAndPassword")
+	defer func() { endSpan(span, err) }()
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+}
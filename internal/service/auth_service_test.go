@@ -0,0 +1,53 @@
+package service_test
+
+import (
+	"banking-api/internal/models"
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRegisterUserDuplicate(t *testing.T) {
+	e := newEnv()
+	e.register(t, "alice")
+
+	_, err := e.auth.RegisterUser(context.Background(), &models.RegisterRequest{
+		Email:    "other@example.com",
+		Username: "alice",
+		Password: "secret",
+	})
+	if err == nil {
+		t.Fatal("ожидалась ошибка для занятого username")
+	}
+}
+
+func TestLoginUser(t *testing.T) {
+	e := newEnv()
+	user := e.register(t, "alice")
+	ctx := context.Background()
+
+	token, err := e.auth.LoginUser(ctx, &models.LoginRequest{Email: "alice@example.com", Password: "secret"}, "jwt-secret")
+	if err != nil {
+		t.Fatalf("LoginUser: %v", err)
+	}
+	claims := &jwt.RegisteredClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte("jwt-secret"), nil
+	}); err != nil {
+		t.Fatalf("невалидный токен: %v", err)
+	}
+	if claims.Subject != strconv.FormatInt(user.ID, 10) {
+		t.Errorf("subject = %q, ожидался ID пользователя %d", claims.Subject, user.ID)
+	}
+
+	for _, req := range []models.LoginRequest{
+		{Email: "alice@example.com", Password: "wrong"},
+		{Email: "nobody@example.com", Password: "secret"},
+	} {
+		if _, err := e.auth.LoginUser(ctx, &req, "jwt-secret"); err == nil {
+			t.Errorf("LoginUser(%s, %s): ожидалась ошибка", req.Email, req.Password)
+		}
+	}
+}
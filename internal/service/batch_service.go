@@ -0,0 +1,283 @@
+package service
+
+import (
+	"banking-api/internal/config"
+	"banking-api/internal/metrics"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Ограничения пакета переводов
+const (
+	MaxBatchLines         = 1000
+	MaxBatchReferenceLen  = 140
+	MaxEndToEndIDLen      = 35
+	DefaultBatchSyncLimit = 50
+
+	// maxBatchProblems — сколько ошибок проверки перечисляется в ответе.
+	maxBatchProblems = 20
+)
+
+// BatchService — пакетные переводы: список получателей (по username или
+// номеру счёта) с суммами проверяется целиком до выполнения. Небольшие пакеты
+// выполняются сразу, большие — в фоне, их статус и отчёт по строкам
+// доступны через Get.
+type BatchService struct {
+	Repo        repository.BatchRepository
+	AccountRepo repository.AccountRepository
+	UserRepo    repository.UserRepository
+
+	// SyncLimit — пакеты не больше стольких строк выполняются сразу.
+	SyncLimit int
+}
+
+func NewBatchService(repo repository.BatchRepository, accountRepo repository.AccountRepository, userRepo repository.UserRepository) *BatchService {
+	return &BatchService{
+		Repo:        repo,
+		AccountRepo: accountRepo,
+		UserRepo:    userRepo,
+		SyncLimit:   DefaultBatchSyncLimit,
+	}
+}
+
+// Submit проверяет пакет lines со счёта fromAccountID пользователя userID и
+// сохраняет его. Если хоть одна строка невалидна, пакет отклоняется целиком
+// с перечнем ошибок по строкам. Пакет не больше SyncLimit строк выполняется
+// сразу, больший — или тот, что не удалось выполнить сразу, — остаётся в
+// статусе pending до ProcessPending.
+func (s *BatchService) Submit(ctx context.Context, userID, fromAccountID int64, allOrNothing bool, lines []models.BatchLine) (batch *models.Batch, err error) {
+	ctx, span := startSpan(ctx, "BatchService.Submit")
+	defer func() { endSpan(span, err) }()
+
+	batch = &models.Batch{UserID: userID, FromAccountID: fromAccountID, AllOrNothing: allOrNothing}
+	if err := s.prepare(ctx, batch, lines); err != nil {
+		return nil, err
+	}
+	return s.create(ctx, batch)
+}
+
+// SubmitMessage проверяет пакеты из платежей одного сообщения pain.001 и
+// сохраняет их одной транзакцией: если какой-то платёж невалиден или уже
+// загружен пользователем (ErrBatchExists), не сохраняется ни один. Небольшие
+// пакеты выполняются после сохранения всех; пакет, который не удалось
+// выполнить сразу, остаётся в очереди фоновой обработки.
+func (s *BatchService) SubmitMessage(ctx context.Context, userID int64, requests []models.BatchRequest) (batches []*models.Batch, err error) {
+	ctx, span := startSpan(ctx, "BatchService.SubmitMessage")
+	defer func() { endSpan(span, err) }()
+
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("%w: в сообщении нет платежей", ErrInvalidBatch)
+	}
+	for _, req := range requests {
+		fromAccountID, err := accountIDByNumber(ctx, s.AccountRepo, req.FromAccount)
+		if err != nil {
+			return nil, fmt.Errorf("платёж %s: %w", req.PaymentInfoID, err)
+		}
+		batch := &models.Batch{UserID: userID, FromAccountID: fromAccountID, AllOrNothing: req.AllOrNothing,
+			MessageID: req.MessageID, PaymentInfoID: req.PaymentInfoID}
+		if err := s.prepare(ctx, batch, req.Lines); err != nil {
+			return nil, fmt.Errorf("платёж %s: %w", req.PaymentInfoID, err)
+		}
+		batches = append(batches, batch)
+	}
+	if err := s.save(ctx, batches...); err != nil {
+		return nil, err
+	}
+	for i, batch := range batches {
+		if batch.Total > s.SyncLimit {
+			continue
+		}
+		// Ошибка уже записана в журнал, пакет выполнит ProcessPending
+		if executed, err := s.execute(ctx, batch.ID, time.Now().UTC()); err == nil {
+			batches[i] = executed
+		}
+	}
+	return batches, nil
+}
+
+// prepare проверяет строки lines пакета со счёта batch.FromAccountID и
+// заполняет batch.Items.
+func (s *BatchService) prepare(ctx context.Context, batch *models.Batch, lines []models.BatchLine) error {
+	if len(lines) == 0 {
+		return fmt.Errorf("%w: пакет пуст", ErrInvalidBatch)
+	}
+	if len(lines) > MaxBatchLines {
+		return fmt.Errorf("%w: не больше %d строк в пакете", ErrInvalidBatch, MaxBatchLines)
+	}
+	from, err := s.AccountRepo.GetAccount(ctx, batch.FromAccountID, batch.UserID)
+	if err != nil {
+		return accountError(err)
+	}
+
+	var problems []string
+	resolved := map[string]int64{}
+	for i, line := range lines {
+		item, err := s.resolveLine(ctx, line, resolved)
+		if err == nil && item.ToAccountID == batch.FromAccountID {
+			err = ErrSelfTransfer
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("строка %d: %v", i+1, err))
+			continue
+		}
+		item.Line = i + 1
+		batch.Items = append(batch.Items, item)
+	}
+	if len(problems) > 0 {
+		if len(problems) > maxBatchProblems {
+			problems = append(problems[:maxBatchProblems], fmt.Sprintf("и ещё %d", len(problems)-maxBatchProblems))
+		}
+		return fmt.Errorf("%w: %s", ErrInvalidBatch, strings.Join(problems, "; "))
+	}
+	batch.Tally()
+	// Пакет «всё или ничего» заведомо не пройдёт, если сумма больше доступного остатка
+	if batch.AllOrNothing && batch.Amount > from.Available() {
+		return fmt.Errorf("%w: сумма пакета %.2f больше доступного остатка %.2f", repository.ErrInsufficientFunds, batch.Amount, from.Available())
+	}
+	return nil
+}
+
+// create сохраняет проверенный пакет и выполняет его, если он не больше
+// SyncLimit строк. Пакет, который не удалось выполнить сразу, уже сохранён:
+// он возвращается ожидающим, и его выполнит ProcessPending.
+func (s *BatchService) create(ctx context.Context, batch *models.Batch) (*models.Batch, error) {
+	if err := s.save(ctx, batch); err != nil {
+		return nil, err
+	}
+	if batch.Total > s.SyncLimit {
+		return batch, nil
+	}
+	// Ошибка уже записана в журнал
+	if executed, err := s.execute(ctx, batch.ID, time.Now().UTC()); err == nil {
+		return executed, nil
+	}
+	return batch, nil
+}
+
+// save сохраняет проверенные пакеты одной транзакцией.
+func (s *BatchService) save(ctx context.Context, batches ...*models.Batch) error {
+	err := s.Repo.CreateBatches(ctx, batches)
+	switch {
+	case errors.Is(err, repository.ErrDuplicate):
+		return fmt.Errorf("%w: сообщение %s", ErrBatchExists, batches[0].MessageID)
+	case err != nil:
+		config.Log.Errorf("Ошибка сохранения пакета переводов со счёта %d: %v", batches[0].FromAccountID, err)
+		return accountError(err)
+	}
+	for _, batch := range batches {
+		config.Log.Infof("Пакет переводов %d: %d строк на %.2f со счёта %d", batch.ID, batch.Total, batch.Amount, batch.FromAccountID)
+	}
+	return nil
+}
+
+// resolveLine проверяет строку пакета и находит счёт получателя. resolved
+// запоминает счета уже найденных по username получателей.
+func (s *BatchService) resolveLine(ctx context.Context, line models.BatchLine, resolved map[string]int64) (models.BatchItem, error) {
+	item := models.BatchItem{ToUsername: line.ToUsername, ToAccount: line.ToAccount, Amount: line.Amount,
+		Reference: line.Reference, EndToEndID: line.EndToEndID}
+	if line.Amount <= 0 {
+		return item, ErrInvalidAmount
+	}
+	if utf8.RuneCountInString(line.Reference) > MaxBatchReferenceLen {
+		return item, fmt.Errorf("назначение платежа длиннее %d символов", MaxBatchReferenceLen)
+	}
+	if utf8.RuneCountInString(line.EndToEndID) > MaxEndToEndIDLen {
+		return item, fmt.Errorf("end_to_end_id длиннее %d символов", MaxEndToEndIDLen)
+	}
+	switch {
+	case (line.ToUsername == "") == (line.ToAccount == ""):
+		return item, errors.New("нужно указать ровно одно из to_username и to_account")
+	case line.ToAccount != "":
+		accountID, err := accountIDByNumber(ctx, s.AccountRepo, line.ToAccount)
+		if err != nil {
+			return item, err
+		}
+		item.ToAccountID = accountID
+	default:
+		accountID, ok := resolved[line.ToUsername]
+		if !ok {
+			userID, err := s.UserRepo.GetUserIDByUsername(ctx, line.ToUsername)
+			if err != nil {
+				return item, fmt.Errorf("%w: %s", ErrUserNotFound, line.ToUsername)
+			}
+			if accountID, err = s.AccountRepo.GetFirstAccountByUserID(ctx, userID); err != nil {
+				return item, fmt.Errorf("%w: у пользователя %s нет счёта", ErrAccountNotFound, line.ToUsername)
+			}
+			resolved[line.ToUsername] = accountID
+		}
+		item.ToAccountID = accountID
+	}
+	return item, nil
+}
+
+// Get возвращает пакет пользователя с результатами по строкам.
+func (s *BatchService) Get(ctx context.Context, userID, batchID int64) (batch *models.Batch, err error) {
+	ctx, span := startSpan(ctx, "BatchService.Get")
+	defer func() { endSpan(span, err) }()
+
+	batch, err = s.Repo.GetBatch(ctx, batchID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %d", ErrBatchNotFound, batchID)
+	}
+	return batch, err
+}
+
+// List возвращает пакеты пользователя со сводкой, начиная с последних.
+func (s *BatchService) List(ctx context.Context, userID int64) (batches []models.Batch, err error) {
+	ctx, span := startSpan(ctx, "BatchService.List")
+	defer func() { endSpan(span, err) }()
+
+	return s.Repo.ListBatches(ctx, userID)
+}
+
+// ProcessPending выполняет пакеты, ожидающие фоновой обработки. Возвращает
+// число выполненных пакетов.
+func (s *BatchService) ProcessPending(ctx context.Context, now time.Time) (n int, err error) {
+	ctx, span := startSpan(ctx, "BatchService.ProcessPending")
+	defer func() { endSpan(span, err) }()
+
+	ids, err := s.Repo.PendingBatches(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		_, err := s.execute(ctx, id, now)
+		switch {
+		case errors.Is(err, repository.ErrBatchClosed):
+			// Пакет выполнил другой экземпляр
+		case err != nil:
+			return n, err
+		default:
+			n++
+		}
+	}
+	return n, nil
+}
+
+// execute выполняет пакет и учитывает его строки в метриках переводов.
+func (s *BatchService) execute(ctx context.Context, batchID int64, now time.Time) (*models.Batch, error) {
+	batch, err := s.Repo.ExecuteBatch(ctx, batchID, now)
+	if err != nil {
+		config.Log.Errorf("Ошибка выполнения пакета переводов %d: %v", batchID, err)
+		return nil, err
+	}
+	for _, item := range batch.Items {
+		switch {
+		case item.Status == models.BatchItemSucceeded:
+			metrics.ObserveTransfer(metrics.TransferSuccess, item.Amount)
+		case item.Error == repository.ErrInsufficientFunds.Error():
+			metrics.ObserveTransfer(metrics.TransferInsufficientFunds, item.Amount)
+		case item.Status == models.BatchItemFailed:
+			metrics.ObserveTransfer(metrics.TransferError, item.Amount)
+		}
+	}
+	config.Log.Infof("Пакет переводов %d: %s, выполнено %d из %d", batch.ID, batch.Status, batch.Succeeded, batch.Total)
+	return batch, nil
+}
@@ -0,0 +1,183 @@
+package service_test
+
+import (
+	"banking-api/internal/accountno"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBatchValidation(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	e.register(t, "carol") // без счёта
+	bob := e.register(t, "bob")
+	aliceAcc := e.account(t, alice.ID, 100)
+	bobAcc := e.account(t, bob.ID, 0)
+
+	if _, err := e.batches.Submit(ctx, alice.ID, aliceAcc, false, nil); !errors.Is(err, service.ErrInvalidBatch) {
+		t.Errorf("пустой пакет: %v", err)
+	}
+	if _, err := e.batches.Submit(ctx, bob.ID, aliceAcc, false, []models.BatchLine{{ToUsername: "bob", Amount: 1}}); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("пакет с чужого счёта: %v", err)
+	}
+
+	_, err := e.batches.Submit(ctx, alice.ID, aliceAcc, false, []models.BatchLine{
+		{ToUsername: "bob", Amount: 10},
+		{ToUsername: "nobody", Amount: 10},
+		{ToUsername: "carol", Amount: 10},
+		{ToAccount: accountno.New(), Amount: 10},
+		{ToUsername: "bob", ToAccount: e.number(t, bobAcc), Amount: 10},
+		{ToAccount: e.number(t, bobAcc), Amount: 0},
+		{ToAccount: e.number(t, aliceAcc), Amount: 10},
+		{ToAccount: e.number(t, bobAcc), Amount: 10, Reference: strings.Repeat("я", service.MaxBatchReferenceLen+1)},
+	})
+	if !errors.Is(err, service.ErrInvalidBatch) {
+		t.Fatalf("невалидные строки: %v", err)
+	}
+	for line := 2; line <= 8; line++ {
+		if !strings.Contains(err.Error(), fmt.Sprintf("строка %d:", line)) {
+			t.Errorf("в ошибке нет строки %d: %v", line, err)
+		}
+	}
+	if strings.Contains(err.Error(), "строка 1:") {
+		t.Errorf("валидная строка в ошибке: %v", err)
+	}
+	// Ни одна строка невалидного пакета не выполнена
+	if batches, _ := e.batches.List(ctx, alice.ID); len(batches) != 0 {
+		t.Errorf("сохранён невалидный пакет: %+v", batches)
+	}
+
+	_, err = e.batches.Submit(ctx, alice.ID, aliceAcc, true, []models.BatchLine{{ToUsername: "bob", Amount: 60}, {ToAccount: e.number(t, bobAcc), Amount: 60}})
+	if !errors.Is(err, repository.ErrInsufficientFunds) {
+		t.Errorf("пакет all_or_nothing больше остатка: %v", err)
+	}
+}
+
+func TestBatchExecution(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	aliceAcc := e.account(t, alice.ID, 100)
+	bobAcc := e.account(t, bob.ID, 0)
+
+	batch, err := e.batches.Submit(ctx, alice.ID, aliceAcc, false, []models.BatchLine{
+		{ToUsername: "bob", Amount: 30, Reference: "аренда"},
+		{ToAccount: e.number(t, bobAcc), Amount: 80},
+		{ToAccount: e.number(t, bobAcc), Amount: 20},
+	})
+	if err != nil || batch.Status != models.BatchCompleted || batch.Succeeded != 2 || batch.Failed != 1 {
+		t.Fatalf("Submit = %+v, %v", batch, err)
+	}
+	if item := batch.Items[1]; item.Status != models.BatchItemFailed || item.Error != repository.ErrInsufficientFunds.Error() {
+		t.Errorf("строка 2 = %+v", item)
+	}
+	if item := batch.Items[0]; item.ToAccountID != bobAcc || item.TransactionID == 0 || item.Reference != "аренда" {
+		t.Errorf("строка 1 = %+v", item)
+	}
+	if balance, _ := e.accounts.Balance(ctx, bob.ID, bobAcc); balance.Ledger != 50 {
+		t.Errorf("баланс получателя = %+v", balance)
+	}
+	if _, err := e.batches.Get(ctx, bob.ID, batch.ID); !errors.Is(err, service.ErrBatchNotFound) {
+		t.Errorf("чужой пакет: %v", err)
+	}
+
+	// Большой пакет ставится в очередь и выполняется фоном
+	e.batches.SyncLimit = 1
+	queued, err := e.batches.Submit(ctx, alice.ID, aliceAcc, true, []models.BatchLine{
+		{ToAccount: e.number(t, bobAcc), Amount: 10},
+		{ToAccount: e.number(t, bobAcc), Amount: 10},
+	})
+	if err != nil || queued.Status != models.BatchPending || queued.Succeeded != 0 {
+		t.Fatalf("Submit в очередь = %+v, %v", queued, err)
+	}
+	if n, err := e.batches.ProcessPending(ctx, time.Now()); err != nil || n != 1 {
+		t.Fatalf("ProcessPending = %d, %v", n, err)
+	}
+	if n, err := e.batches.ProcessPending(ctx, time.Now()); err != nil || n != 0 {
+		t.Errorf("повторный ProcessPending = %d, %v", n, err)
+	}
+	got, err := e.batches.Get(ctx, alice.ID, queued.ID)
+	if err != nil || got.Status != models.BatchCompleted || got.Succeeded != 2 || got.CompletedAt == nil {
+		t.Errorf("Get = %+v, %v", got, err)
+	}
+	if batches, err := e.batches.List(ctx, alice.ID); err != nil || len(batches) != 2 || batches[0].ID != queued.ID {
+		t.Errorf("List = %+v, %v", batches, err)
+	}
+}
+
+func TestBatchMessageAtomic(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	aliceAcc := e.account(t, alice.ID, 100)
+	bobAcc := e.account(t, bob.ID, 0)
+	payment := func(id string, amount float64) models.BatchRequest {
+		return models.BatchRequest{MessageID: "MSG-1", PaymentInfoID: id, FromAccount: e.number(t, aliceAcc),
+			Lines: []models.BatchLine{{ToAccount: e.number(t, bobAcc), Amount: amount, EndToEndID: "E2E-" + id}}}
+	}
+
+	if _, err := e.batches.SubmitMessage(ctx, alice.ID, []models.BatchRequest{payment("PMT-2", 20)}); err != nil {
+		t.Fatalf("первая загрузка: %v", err)
+	}
+	// Второй платёж уже загружен: первый не сохраняется и не выполняется
+	_, err := e.batches.SubmitMessage(ctx, alice.ID, []models.BatchRequest{payment("PMT-1", 10), payment("PMT-2", 20)})
+	if !errors.Is(err, service.ErrBatchExists) {
+		t.Fatalf("повтор платежа: %v", err)
+	}
+	if batches, _ := e.batches.List(ctx, alice.ID); len(batches) != 1 {
+		t.Errorf("пакетов %d, ожидался 1: %+v", len(batches), batches)
+	}
+	if balance, _ := e.accounts.Balance(ctx, bob.ID, bobAcc); balance.Ledger != 20 {
+		t.Errorf("баланс получателя = %+v", balance)
+	}
+	// Без повторного платежа остаток сообщения загружается
+	batches, err := e.batches.SubmitMessage(ctx, alice.ID, []models.BatchRequest{payment("PMT-1", 10)})
+	if err != nil || len(batches) != 1 || batches[0].Status != models.BatchCompleted {
+		t.Fatalf("загрузка без повтора = %+v, %v", batches, err)
+	}
+}
+
+// executeFailure — хранилище пакетов, которое сохраняет пакеты, но не выполняет их.
+type executeFailure struct {
+	repository.BatchRepository
+}
+
+func (executeFailure) ExecuteBatch(context.Context, int64, time.Time) (*models.Batch, error) {
+	return nil, errors.New("база недоступна")
+}
+
+func TestBatchExecuteFailureKeepsPending(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	aliceAcc := e.account(t, alice.ID, 100)
+	bobAcc := e.account(t, bob.ID, 0)
+	repo := e.batches.Repo
+	e.batches.Repo = executeFailure{repo}
+
+	// Пакет уже сохранён: клиент получает его ожидающим, а не ошибку, после
+	// которой повторил бы загрузку и заплатил дважды
+	batch, err := e.batches.Submit(ctx, alice.ID, aliceAcc, false, []models.BatchLine{{ToAccount: e.number(t, bobAcc), Amount: 10}})
+	if err != nil || batch.ID == 0 || batch.Status != models.BatchPending {
+		t.Fatalf("Submit = %+v, %v", batch, err)
+	}
+
+	e.batches.Repo = repo
+	if n, err := e.batches.ProcessPending(ctx, time.Now()); err != nil || n != 1 {
+		t.Fatalf("ProcessPending = %d, %v", n, err)
+	}
+	if balance, _ := e.accounts.Balance(ctx, bob.ID, bobAcc); balance.Ledger != 10 {
+		t.Errorf("баланс получателя = %+v", balance)
+	}
+}
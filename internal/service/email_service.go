@@ -1,12 +1,21 @@
 package service
 
 import (
+	"banking-api/internal/metrics"
+	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
+	"strconv"
 
 	mail "github.com/go-mail/mail/v2"
 )
 
+// Mailer отправляет email-уведомления. Реализуется EmailService; в тестах подменяется.
+type Mailer interface {
+	SendEmail(ctx context.Context, to string, subject string, body string) error
+}
+
 type EmailService struct {
 	Dialer *mail.Dialer
 	From   string
@@ -24,7 +33,10 @@ func NewEmailService(host string, port int, user, pass string) *EmailService {
 	}
 }
 
-func (s *EmailService) SendEmail(to string, subject string, body string) error {
+func (s *EmailService) SendEmail(ctx context.Context, to string, subject string, body string) (err error) {
+	_, span := startSpan(ctx, "EmailService.SendEmail")
+	defer func() { endSpan(span, err) }()
+
 	m := mail.NewMessage()
 	m.SetHeader("From", s.From)
 	m.SetHeader("To", to)
@@ -32,7 +44,19 @@ func (s *EmailService) SendEmail(to string, subject string, body string) error {
 	m.SetBody("text/html", body)
 
 	if err := s.Dialer.DialAndSend(m); err != nil {
+		metrics.EmailsSent.WithLabelValues("failed").Inc()
 		return fmt.Errorf("не удалось отправить email: %w", err)
 	}
+	metrics.EmailsSent.WithLabelValues("sent").Inc()
 	return nil
 }
+
+// Ping проверяет, что SMTP-сервер принимает TCP-соединения.
+func (s *EmailService) Ping(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(s.Dialer.Host, strconv.Itoa(s.Dialer.Port)))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,43 @@
+package service
+
+import "errors"
+
+// Ошибки бизнес-правил. Транспортные слои (REST, gRPC) сопоставляют их
+// с кодами ошибок через errors.Is, поэтому уточнения добавляются обёрткой %w.
+var (
+	ErrInvalidAmount          = errors.New("сумма должна быть положительной")
+	ErrSelfTransfer           = errors.New("нельзя переводить самому себе")
+	ErrSenderMismatch         = errors.New("отправитель не совпадает с пользователем токена")
+	ErrUserExists             = errors.New("email или username уже используется")
+	ErrInvalidCredentials     = errors.New("неверный email или пароль")
+	ErrUserNotFound           = errors.New("пользователь не найден")
+	ErrAccountNotFound        = errors.New("счёт не найден")
+	ErrInvalidAccountNumber   = errors.New("некорректный номер счёта")
+	ErrInvalidPagination      = errors.New("некорректные параметры страницы")
+	ErrStatementNotReady      = errors.New("выписка формируется только за завершившиеся дни")
+	ErrInvalidAccountType     = errors.New("неизвестный тип счёта: ожидается checking, savings или credit")
+	ErrActorRequired          = errors.New("не указан оператор")
+	ErrReasonRequired         = errors.New("не указана причина")
+	ErrInvalidRate            = errors.New("ставка должна быть от 0 до 100% годовых")
+	ErrLoanNotFound           = errors.New("кредит не найден")
+	ErrInvalidLoanTerms       = errors.New("некорректные условия кредита")
+	ErrPaymentRequestNotFound = errors.New("запрос денег не найден")
+	ErrInvalidPaymentRequest  = errors.New("некорректный запрос денег")
+	ErrHoldNotFound           = errors.New("блокировка не найдена")
+	ErrInvalidHold            = errors.New("некорректная блокировка")
+	ErrTransactionNotFound    = errors.New("перевод не найден")
+	ErrInvalidReasonCode      = errors.New("неизвестный код причины сторно")
+	ErrBatchNotFound          = errors.New("пакет переводов не найден")
+	ErrInvalidBatch           = errors.New("некорректный пакет переводов")
+	ErrBatchExists            = errors.New("платёж из этого сообщения уже загружен")
+	ErrApprovalNotFound       = errors.New("перевод на одобрении не найден")
+	ErrInvalidApproval        = errors.New("некорректный запрос одобрения")
+	ErrApprovalPending        = errors.New("перевод ждёт одобрения")
+	ErrAccessNotFound         = errors.New("доступ к счёту или приглашение не найдены")
+	ErrAccessExists           = errors.New("у пользователя уже есть доступ или приглашение к счёту")
+	ErrInvalidAccess          = errors.New("некорректный доступ к счёту")
+	ErrPayeeNotFound          = errors.New("получатель не найден в адресной книге")
+	ErrPayeeExists            = errors.New("получатель с таким именем уже есть в адресной книге")
+	ErrInvalidPayee           = errors.New("некорректный получатель")
+	ErrPayeeCoolingOff        = errors.New("крупные переводы новому получателю пока недоступны")
+)
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"banking-api/internal/config"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultHoldTTL — срок блокировки по умолчанию.
+const DefaultHoldTTL = 7 * 24 * time.Hour
+
+// HoldService — блокировки средств (двухфазные платежи): владелец счёта
+// резервирует сумму в пользу счёта получателя (мерчанта), не переводя деньги.
+// Получатель списывает всю сумму или её часть либо снимает блокировку; не
+// закрытая за TTL блокировка истекает, и средства снова становятся доступны.
+type HoldService struct {
+	Repo repository.HoldRepository
+
+	// TTL — срок блокировки.
+	TTL time.Duration
+}
+
+func NewHoldService(repo repository.HoldRepository) *HoldService {
+	return &HoldService{Repo: repo, TTL: DefaultHoldTTL}
+}
+
+// Authorize блокирует amount на счёте accountID пользователя userID в пользу счёта toAccountID.
+func (s *HoldService) Authorize(ctx context.Context, userID, accountID, toAccountID int64, amount float64) (hold *models.Hold, err error) {
+	ctx, span := startSpan(ctx, "HoldService.Authorize")
+	defer func() { endSpan(span, err) }()
+
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if accountID == toAccountID {
+		return nil, ErrSelfTransfer
+	}
+	hold = &models.Hold{
+		AccountID:   accountID,
+		ToAccountID: toAccountID,
+		Amount:      amount,
+		ExpiresAt:   time.Now().UTC().Add(s.TTL),
+	}
+	if err := s.Repo.CreateHold(ctx, hold, userID); err != nil {
+		config.Log.Errorf("Ошибка блокировки %.2f на счёте %d: %v", amount, accountID, err)
+		return nil, accountError(err)
+	}
+	config.Log.Infof("Блокировка %d: %.2f на счёте %d в пользу счёта %d", hold.ID, hold.Amount, hold.AccountID, hold.ToAccountID)
+	return hold, nil
+}
+
+// List возвращает блокировки на счетах пользователя и в пользу его счетов,
+// непустой status оставляет блокировки в этом статусе.
+func (s *HoldService) List(ctx context.Context, userID int64, status string) (holds []models.Hold, err error) {
+	ctx, span := startSpan(ctx, "HoldService.List")
+	defer func() { endSpan(span, err) }()
+
+	switch status {
+	case "", models.HoldActive, models.HoldCaptured, models.HoldReleased, models.HoldExpired:
+	default:
+		return nil, fmt.Errorf("%w: неизвестный статус %q", ErrInvalidHold, status)
+	}
+	return s.Repo.ListHolds(ctx, userID, status)
+}
+
+// Get возвращает блокировку, в которой userID владеет одним из счетов.
+func (s *HoldService) Get(ctx context.Context, userID, holdID int64) (hold *models.Hold, err error) {
+	ctx, span := startSpan(ctx, "HoldService.Get")
+	defer func() { endSpan(span, err) }()
+
+	hold, err = s.Repo.GetHold(ctx, holdID, userID)
+	if err != nil {
+		return nil, holdError(err, holdID)
+	}
+	return hold, nil
+}
+
+// Capture списывает amount (0 — всю сумму) с блокировки на счёт получателя userID.
+func (s *HoldService) Capture(ctx context.Context, userID, holdID int64, amount float64) (hold *models.Hold, err error) {
+	ctx, span := startSpan(ctx, "HoldService.Capture")
+	defer func() { endSpan(span, err) }()
+
+	if amount < 0 {
+		return nil, ErrInvalidAmount
+	}
+	if amount == 0 {
+		hold, err = s.Repo.GetHold(ctx, holdID, userID)
+		if err != nil {
+			return nil, holdError(err, holdID)
+		}
+		amount = hold.Amount
+	}
+	hold, err = s.Repo.CaptureHold(ctx, holdID, userID, amount, time.Now().UTC())
+	if err != nil {
+		config.Log.Errorf("Ошибка списания блокировки %d: %v", holdID, err)
+		return nil, holdError(err, holdID)
+	}
+	config.Log.Infof("Блокировка %d списана: %.2f из %.2f переводом %d", hold.ID, hold.CapturedAmount, hold.Amount, hold.TransactionID)
+	return hold, nil
+}
+
+// Release снимает блокировку без списания по решению получателя userID.
+func (s *HoldService) Release(ctx context.Context, userID, holdID int64) (hold *models.Hold, err error) {
+	ctx, span := startSpan(ctx, "HoldService.Release")
+	defer func() { endSpan(span, err) }()
+
+	hold, err = s.Repo.ReleaseHold(ctx, holdID, userID, time.Now().UTC())
+	if err != nil {
+		return nil, holdError(err, holdID)
+	}
+	config.Log.Infof("Блокировка %d снята", hold.ID)
+	return hold, nil
+}
+
+// Expire снимает блокировки, срок которых истёк к now. Возвращает их число.
+func (s *HoldService) Expire(ctx context.Context, now time.Time) (n int, err error) {
+	ctx, span := startSpan(ctx, "HoldService.Expire")
+	defer func() { endSpan(span, err) }()
+
+	expired, err := s.Repo.ExpireHolds(ctx, now)
+	if err != nil {
+		config.Log.Errorf("Ошибка снятия истёкших блокировок: %v", err)
+		return 0, err
+	}
+	if len(expired) > 0 {
+		config.Log.Infof("Истёк срок %d блокировок", len(expired))
+	}
+	return len(expired), nil
+}
+
+func holdError(err error, holdID int64) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %d", ErrHoldNotFound, holdID)
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/service"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHoldCapture(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	shop := e.register(t, "shop")
+	aliceAcc := e.account(t, alice.ID, 100)
+	shopAcc := e.account(t, shop.ID, 0)
+
+	if _, err := e.holds.Authorize(ctx, alice.ID, aliceAcc, shopAcc, 0); !errors.Is(err, service.ErrInvalidAmount) {
+		t.Errorf("нулевая сумма: %v", err)
+	}
+	if _, err := e.holds.Authorize(ctx, alice.ID, aliceAcc, aliceAcc, 10); !errors.Is(err, service.ErrSelfTransfer) {
+		t.Errorf("блокировка в пользу того же счёта: %v", err)
+	}
+	if _, err := e.holds.Authorize(ctx, shop.ID, aliceAcc, shopAcc, 10); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("блокировка на чужом счёте: %v", err)
+	}
+
+	hold, err := e.holds.Authorize(ctx, alice.ID, aliceAcc, shopAcc, 60)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if !hold.ExpiresAt.After(time.Now().Add(6 * 24 * time.Hour)) {
+		t.Errorf("срок блокировки = %s", hold.ExpiresAt)
+	}
+	balance, err := e.accounts.Balance(ctx, alice.ID, aliceAcc)
+	if err != nil || balance.Ledger != 100 || balance.Held != 60 || balance.Available != 40 {
+		t.Errorf("Balance = %+v, %v", balance, err)
+	}
+	if err := e.accounts.TransferFunds(ctx, alice.ID, aliceAcc, shopAcc, 50, ""); !errors.Is(err, repository.ErrInsufficientFunds) {
+		t.Errorf("перевод заблокированных средств: %v", err)
+	}
+
+	if _, err := e.holds.Capture(ctx, alice.ID, hold.ID, 0); !errors.Is(err, service.ErrHoldNotFound) {
+		t.Errorf("списание плательщиком: %v", err)
+	}
+	if _, err := e.holds.Capture(ctx, shop.ID, hold.ID, -1); !errors.Is(err, service.ErrInvalidAmount) {
+		t.Errorf("отрицательная сумма: %v", err)
+	}
+	captured, err := e.holds.Capture(ctx, shop.ID, hold.ID, 0)
+	if err != nil || captured.Status != models.HoldCaptured || captured.CapturedAmount != 60 {
+		t.Fatalf("Capture = %+v, %v", captured, err)
+	}
+	balance, err = e.accounts.Balance(ctx, alice.ID, aliceAcc)
+	if err != nil || balance.Ledger != 40 || balance.Held != 0 || balance.Available != 40 {
+		t.Errorf("Balance после списания = %+v, %v", balance, err)
+	}
+	if _, err := e.holds.Release(ctx, shop.ID, hold.ID); !errors.Is(err, repository.ErrHoldClosed) {
+		t.Errorf("снятие списанной: %v", err)
+	}
+}
+
+func TestHoldReleaseExpire(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	shop := e.register(t, "shop")
+	aliceAcc := e.account(t, alice.ID, 100)
+	shopAcc := e.account(t, shop.ID, 0)
+	e.holds.TTL = time.Hour
+
+	released, _ := e.holds.Authorize(ctx, alice.ID, aliceAcc, shopAcc, 10)
+	stale, _ := e.holds.Authorize(ctx, alice.ID, aliceAcc, shopAcc, 20)
+	if _, err := e.holds.Release(ctx, alice.ID, released.ID); !errors.Is(err, service.ErrHoldNotFound) {
+		t.Errorf("снятие плательщиком: %v", err)
+	}
+	if _, err := e.holds.Release(ctx, shop.ID, released.ID); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	if n, err := e.holds.Expire(ctx, time.Now()); err != nil || n != 0 {
+		t.Errorf("Expire до срока = %d, %v", n, err)
+	}
+	if n, err := e.holds.Expire(ctx, stale.ExpiresAt); err != nil || n != 1 {
+		t.Fatalf("Expire = %d, %v", n, err)
+	}
+	if balance, err := e.accounts.Balance(ctx, alice.ID, aliceAcc); err != nil || balance.Held != 0 || balance.Available != 100 {
+		t.Errorf("Balance = %+v, %v", balance, err)
+	}
+
+	if _, err := e.holds.List(ctx, alice.ID, "void"); !errors.Is(err, service.ErrInvalidHold) {
+		t.Errorf("неизвестный статус: %v", err)
+	}
+	holds, err := e.holds.List(ctx, shop.ID, "")
+	if err != nil || len(holds) != 2 || holds[0].Status != models.HoldExpired || holds[1].Status != models.HoldReleased {
+		t.Errorf("блокировки = %+v, %v", holds, err)
+	}
+}
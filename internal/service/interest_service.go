@@ -0,0 +1,158 @@
+package service
+
+import (
+	"banking-api/internal/config"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"fmt"
+	"time"
+)
+
+// InterestService ведёт расписание ставок, ежедневно начисляет проценты на
+// остаток на конец дня и в последний день месяца капитализирует их.
+type InterestService struct {
+	Repo        repository.InterestRepository
+	AccountRepo repository.AccountRepository
+}
+
+func NewInterestService(repo repository.InterestRepository, accountRepo repository.AccountRepository) *InterestService {
+	return &InterestService{Repo: repo, AccountRepo: accountRepo}
+}
+
+// SetRate устанавливает годовую ставку rate (%) для счетов типа accountType с дня from.
+func (s *InterestService) SetRate(ctx context.Context, actor, accountType string, rate float64, from time.Time, reason string) (err error) {
+	ctx, span := startSpan(ctx, "InterestService.SetRate")
+	defer func() { endSpan(span, err) }()
+
+	switch accountType {
+	case models.AccountChecking, models.AccountSavings, models.AccountCredit:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidAccountType, accountType)
+	}
+	if rate < 0 || rate > 100 {
+		return fmt.Errorf("%w: %v", ErrInvalidRate, rate)
+	}
+	if err := checkActor(actor, reason); err != nil {
+		return err
+	}
+
+	day := Day(from)
+	entry := models.AuditEntry{
+		Actor:  actor,
+		Action: models.AuditSetRate,
+		Reason: fmt.Sprintf("%s %.4g%% с %s: %s", accountType, rate, day.Format(repository.DayLayout), reason),
+	}
+	if err := s.Repo.SetRate(ctx, models.InterestRate{AccountType: accountType, AnnualRate: rate, EffectiveFrom: day}, entry); err != nil {
+		config.Log.Errorf("Ошибка установки ставки %s: %v", accountType, err)
+		return err
+	}
+	config.Log.Warnf("Оператор %s: ставка %s %.4g%% с %s", actor, accountType, rate, day.Format(repository.DayLayout))
+	return nil
+}
+
+func (s *InterestService) Rates(ctx context.Context) (rates []models.InterestRate, err error) {
+	ctx, span := startSpan(ctx, "InterestService.Rates")
+	defer func() { endSpan(span, err) }()
+
+	return s.Repo.ListRates(ctx)
+}
+
+// RunDay начисляет проценты за день day, а если это последний день месяца —
+// капитализирует месяц. Повторный запуск за тот же день ничего не меняет.
+func (s *InterestService) RunDay(ctx context.Context, day time.Time) (accrued int, capitalization *models.Capitalization, err error) {
+	ctx, span := startSpan(ctx, "InterestService.RunDay")
+	defer func() { endSpan(span, err) }()
+
+	day = Day(day)
+	accrued, err = s.Repo.AccrueInterest(ctx, day)
+	if err != nil {
+		config.Log.Errorf("Ошибка начисления процентов за %s: %v", day.Format(repository.DayLayout), err)
+		return 0, nil, err
+	}
+	config.Log.Infof("Начислены проценты за %s: %d счетов", day.Format(repository.DayLayout), accrued)
+
+	if next := day.AddDate(0, 0, 1); next.Day() == 1 {
+		capitalization, err = s.Capitalize(ctx, day)
+		if err != nil {
+			return accrued, nil, err
+		}
+	}
+	return accrued, capitalization, nil
+}
+
+// MaxInterestCatchUp — на сколько дней назад CatchUp досчитывает пропущенные
+// начисления; более ранние пропуски восстанавливаются через bankctl interest.
+const MaxInterestCatchUp = 31
+
+// CatchUp запускает RunDay за каждый день от последнего дня с начислениями до
+// through включительно, чтобы досчитать дни, пропущенные из-за ошибки или
+// остановки сервера. Последний день повторяется: если в конце месяца
+// начисление прошло, а капитализация нет, она выполнится. Если начислений ещё
+// не было, запускается только through. Возвращает число обработанных дней.
+func (s *InterestService) CatchUp(ctx context.Context, through time.Time) (days int, err error) {
+	ctx, span := startSpan(ctx, "InterestService.CatchUp")
+	defer func() { endSpan(span, err) }()
+
+	through = Day(through)
+	last, err := s.Repo.LastAccrualDay(ctx)
+	if err != nil {
+		config.Log.Errorf("Ошибка поиска последнего дня начисления процентов: %v", err)
+		return 0, err
+	}
+	from := through
+	if !last.IsZero() && last.Before(through) {
+		from = last
+		if oldest := through.AddDate(0, 0, -MaxInterestCatchUp); from.Before(oldest) {
+			config.Log.Warnf("Проценты не начислялись с %s: досчитываются дни с %s, более ранние — через bankctl interest",
+				last.Format(repository.DayLayout), oldest.Format(repository.DayLayout))
+			from = oldest
+		}
+	}
+	for day := from; !day.After(through); day = day.AddDate(0, 0, 1) {
+		if _, _, err := s.RunDay(ctx, day); err != nil {
+			return days, err
+		}
+		days++
+	}
+	return days, nil
+}
+
+// Capitalize переводит проценты, начисленные за месяц, содержащий month, на счета клиентов.
+func (s *InterestService) Capitalize(ctx context.Context, month time.Time) (capitalization *models.Capitalization, err error) {
+	ctx, span := startSpan(ctx, "InterestService.Capitalize")
+	defer func() { endSpan(span, err) }()
+
+	month = Month(month)
+	capitalization, err = s.Repo.CapitalizeInterest(ctx, month)
+	if err != nil {
+		config.Log.Errorf("Ошибка капитализации процентов за %s: %v", month.Format("2006-01"), err)
+		return nil, err
+	}
+	config.Log.Infof("Капитализированы проценты за %s: %d счетов, %.2f", month.Format("2006-01"), capitalization.Accounts, capitalization.Total)
+	return capitalization, nil
+}
+
+// Accruals возвращает начисления по счёту пользователя за месяц, содержащий month.
+func (s *InterestService) Accruals(ctx context.Context, userID, accountID int64, month time.Time) (accruals []models.InterestAccrual, err error) {
+	ctx, span := startSpan(ctx, "InterestService.Accruals")
+	defer func() { endSpan(span, err) }()
+
+	if _, err := s.AccountRepo.GetAccount(ctx, accountID, userID); err != nil {
+		return nil, accountError(err)
+	}
+	month = Month(month)
+	return s.Repo.ListAccruals(ctx, accountID, month, month.AddDate(0, 1, 0))
+}
+
+// Day возвращает начало дня t по UTC: дни начислений считаются в UTC.
+func Day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Month возвращает первый день месяца t по UTC.
+func Month(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
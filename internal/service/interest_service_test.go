@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/service"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInterestSetRate(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	from := time.Date(2025, time.March, 1, 15, 0, 0, 0, time.UTC)
+
+	if err := e.interest.SetRate(ctx, "ops", "deposit", 5, from, "тариф"); !errors.Is(err, service.ErrInvalidAccountType) {
+		t.Errorf("неизвестный тип счёта: %v", err)
+	}
+	if err := e.interest.SetRate(ctx, "ops", models.AccountSavings, -1, from, "тариф"); !errors.Is(err, service.ErrInvalidRate) {
+		t.Errorf("отрицательная ставка: %v", err)
+	}
+	if err := e.interest.SetRate(ctx, "ops", models.AccountSavings, 5, from, ""); !errors.Is(err, service.ErrReasonRequired) {
+		t.Errorf("ставка без причины: %v", err)
+	}
+	if err := e.interest.SetRate(ctx, "ops", models.AccountSavings, 5, from, "тариф"); err != nil {
+		t.Fatalf("SetRate: %v", err)
+	}
+
+	rates, err := e.interest.Rates(ctx)
+	if err != nil || len(rates) != 1 || !rates[0].EffectiveFrom.Equal(service.Day(from)) {
+		t.Errorf("Rates = %+v, %v", rates, err)
+	}
+	log, err := e.admin.AuditLog(ctx, 0, 0)
+	if err != nil || len(log) != 1 || log[0].Action != models.AuditSetRate || log[0].Actor != "ops" {
+		t.Errorf("журнал = %+v, %v", log, err)
+	}
+}
+
+func TestInterestRunDay(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	march30 := time.Date(2025, time.March, 30, 0, 0, 0, 0, time.UTC)
+	march31 := march30.AddDate(0, 0, 1)
+	now := march30.Add(10 * time.Hour)
+	e.store.Now = func() time.Time { return now }
+
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	savings, err := e.accounts.CreateAccount(ctx, alice.ID, models.AccountSavings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checking := e.account(t, alice.ID, 0)
+	if err := e.accounts.TopUp(ctx, alice.ID, savings.ID, 3650); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.interest.SetRate(ctx, "ops", models.AccountSavings, 10, march30.AddDate(0, -1, 0), "тариф"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Перевод 31-го не влияет на остаток на конец 30-го, даже если начисление за 30-е запущено позже
+	now = march31.Add(12 * time.Hour)
+	if err := e.accounts.TransferFunds(ctx, alice.ID, savings.ID, checking, 1825, ""); err != nil {
+		t.Fatal(err)
+	}
+	accrued, capitalization, err := e.interest.RunDay(ctx, march30)
+	if err != nil || accrued != 1 || capitalization != nil {
+		t.Fatalf("RunDay(30 марта) = %d, %+v, %v", accrued, capitalization, err)
+	}
+
+	accrued, capitalization, err = e.interest.RunDay(ctx, march31)
+	if err != nil || accrued != 1 || capitalization == nil || capitalization.Accounts != 1 || capitalization.Total != 1.5 {
+		t.Fatalf("RunDay(31 марта) = %d, %+v, %v", accrued, capitalization, err)
+	}
+	accrued, capitalization, err = e.interest.RunDay(ctx, march31)
+	if err != nil || accrued != 0 || capitalization.Accounts != 0 {
+		t.Errorf("повторный RunDay(31 марта) = %d, %+v, %v", accrued, capitalization, err)
+	}
+
+	accruals, err := e.interest.Accruals(ctx, alice.ID, savings.ID, march30)
+	if err != nil || len(accruals) != 2 {
+		t.Fatalf("Accruals = %+v, %v", accruals, err)
+	}
+	if accruals[0].Balance != 3650 || accruals[0].Amount != 1 || accruals[1].Balance != 1825 || accruals[1].Amount != 0.5 || !accruals[1].Capitalized {
+		t.Errorf("начисления = %+v", accruals)
+	}
+	if _, err := e.interest.Accruals(ctx, bob.ID, savings.ID, march30); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("начисления по чужому счёту: %v", err)
+	}
+
+	history, err := e.accounts.GetTransactions(ctx, alice.ID, savings.ID, 0, 0)
+	if err != nil || len(history) != 3 || history[0].Kind != models.KindInterest || history[0].Amount != 1.5 {
+		t.Errorf("история = %+v, %v", history, err)
+	}
+	if report, err := e.admin.Reconcile(ctx); err != nil || !report.OK() || report.TotalBalance != 3650 {
+		t.Errorf("сверка = %+v, %v", report, err)
+	}
+}
+
+func TestInterestCatchUp(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	march28 := time.Date(2025, time.March, 28, 0, 0, 0, 0, time.UTC)
+	april2 := time.Date(2025, time.April, 2, 0, 0, 0, 0, time.UTC)
+	now := march28.Add(10 * time.Hour)
+	e.store.Now = func() time.Time { return now }
+
+	alice := e.register(t, "alice")
+	savings, err := e.accounts.CreateAccount(ctx, alice.ID, models.AccountSavings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.accounts.TopUp(ctx, alice.ID, savings.ID, 3650); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.interest.SetRate(ctx, "ops", models.AccountSavings, 10, march28.AddDate(0, -1, 0), "тариф"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Без начислений досчитывать нечего: обрабатывается только запрошенный день
+	if days, err := e.interest.CatchUp(ctx, march28); err != nil || days != 1 {
+		t.Fatalf("CatchUp(28 марта) = %d, %v", days, err)
+	}
+
+	// Сервер простоял до 3 апреля: досчитываются дни с 28 марта, включая капитализацию за март
+	now = april2.Add(24*time.Hour + time.Hour)
+	if days, err := e.interest.CatchUp(ctx, april2); err != nil || days != 6 {
+		t.Fatalf("CatchUp(2 апреля) = %d, %v", days, err)
+	}
+	march, err := e.interest.Accruals(ctx, alice.ID, savings.ID, march28)
+	if err != nil || len(march) != 4 || !march[3].Capitalized {
+		t.Errorf("начисления за март = %+v, %v", march, err)
+	}
+	april, err := e.interest.Accruals(ctx, alice.ID, savings.ID, april2)
+	if err != nil || len(april) != 2 {
+		t.Errorf("начисления за апрель = %+v, %v", april, err)
+	}
+	history, err := e.accounts.GetTransactions(ctx, alice.ID, savings.ID, 0, 0)
+	if err != nil || len(history) != 2 || history[0].Kind != models.KindInterest || history[0].Amount != 4 {
+		t.Errorf("история = %+v, %v", history, err)
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"banking-api/internal/config"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxLoanTermMonths — максимальный срок кредита.
+const MaxLoanTermMonths = 360
+
+// LoanService выдаёт кредиты, ежедневно списывает платежи по графику и
+// принимает досрочные погашения.
+type LoanService struct {
+	Repo        repository.LoanRepository
+	AccountRepo repository.AccountRepository
+}
+
+func NewLoanService(repo repository.LoanRepository, accountRepo repository.AccountRepository) *LoanService {
+	return &LoanService{Repo: repo, AccountRepo: accountRepo}
+}
+
+// Issue выдаёт кредит владельцу счёта accountID и зачисляет сумму на этот счёт.
+// Платежи списываются с него же ежемесячно, начиная через месяц после выдачи.
+func (s *LoanService) Issue(ctx context.Context, actor string, accountID int64, terms models.LoanTerms, reason string) (loan *models.Loan, err error) {
+	ctx, span := startSpan(ctx, "LoanService.Issue")
+	defer func() { endSpan(span, err) }()
+
+	if terms.Principal <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if terms.AnnualRate < 0 || terms.AnnualRate > 100 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRate, terms.AnnualRate)
+	}
+	if terms.Method == "" {
+		terms.Method = models.LoanAnnuity
+	}
+	switch {
+	case terms.Method != models.LoanAnnuity && terms.Method != models.LoanLinear:
+		return nil, fmt.Errorf("%w: способ погашения %q, ожидается annuity или linear", ErrInvalidLoanTerms, terms.Method)
+	case terms.TermMonths < 1 || terms.TermMonths > MaxLoanTermMonths:
+		return nil, fmt.Errorf("%w: срок должен быть от 1 до %d месяцев", ErrInvalidLoanTerms, MaxLoanTermMonths)
+	case terms.LateFee < 0:
+		return nil, fmt.Errorf("%w: пени не могут быть отрицательными", ErrInvalidLoanTerms)
+	}
+	if err := checkActor(actor, reason); err != nil {
+		return nil, err
+	}
+
+	userID, err := s.AccountRepo.GetUserIDByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, accountError(err)
+	}
+	loan = &models.Loan{UserID: userID, AccountID: accountID, LoanTerms: terms, StartDate: Day(time.Now())}
+	entry := models.AuditEntry{
+		Actor:     actor,
+		Action:    models.AuditLoan,
+		AccountID: accountID,
+		Amount:    terms.Principal,
+		Reason:    fmt.Sprintf("%s %.4g%% на %d мес.: %s", terms.Method, terms.AnnualRate, terms.TermMonths, reason),
+	}
+	if err := s.Repo.CreateLoan(ctx, loan, entry); err != nil {
+		config.Log.Errorf("Ошибка выдачи кредита на счёт %d: %v", accountID, err)
+		return nil, accountError(err)
+	}
+	config.Log.Warnf("Оператор %s: выдан кредит %d на %.2f на счёт %d", actor, loan.ID, terms.Principal, accountID)
+	return loan, nil
+}
+
+// Loans возвращает кредиты пользователя.
+func (s *LoanService) Loans(ctx context.Context, userID int64) (loans []models.Loan, err error) {
+	ctx, span := startSpan(ctx, "LoanService.Loans")
+	defer func() { endSpan(span, err) }()
+
+	return s.Repo.ListLoans(ctx, userID)
+}
+
+// Loan возвращает кредит пользователя с графиком платежей.
+func (s *LoanService) Loan(ctx context.Context, userID, loanID int64) (loan *models.Loan, err error) {
+	ctx, span := startSpan(ctx, "LoanService.Loan")
+	defer func() { endSpan(span, err) }()
+
+	loan, err = s.Repo.GetLoan(ctx, loanID, userID)
+	if err != nil {
+		return nil, loanError(err, loanID)
+	}
+	return loan, nil
+}
+
+// Repay досрочно погашает кредит со счёта кредита: сначала наступившие и
+// просроченные платежи, остаток — в основной долг с пересчётом графика.
+func (s *LoanService) Repay(ctx context.Context, userID, loanID int64, amount float64) (repayment *models.LoanRepayment, err error) {
+	ctx, span := startSpan(ctx, "LoanService.Repay")
+	defer func() { endSpan(span, err) }()
+
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	repayment, err = s.Repo.RepayLoan(ctx, loanID, userID, amount, Day(time.Now()))
+	if err != nil {
+		config.Log.Errorf("Ошибка погашения кредита %d: %v", loanID, err)
+		return nil, loanError(err, loanID)
+	}
+	config.Log.Infof("Досрочное погашение кредита %d: %.2f, остаток долга %.2f", loanID, amount, repayment.Outstanding)
+	return repayment, nil
+}
+
+// Collect списывает платежи, наступившие к дню day. Повторный запуск за тот же
+// день безопасен: он только повторяет попытку списать просрочку.
+func (s *LoanService) Collect(ctx context.Context, day time.Time) (collection *models.LoanCollection, err error) {
+	ctx, span := startSpan(ctx, "LoanService.Collect")
+	defer func() { endSpan(span, err) }()
+
+	day = Day(day)
+	collection, err = s.Repo.CollectLoanPayments(ctx, day)
+	if err != nil {
+		config.Log.Errorf("Ошибка списания платежей по кредитам за %s: %v", day.Format(repository.DayLayout), err)
+		return nil, err
+	}
+	config.Log.Infof("Платежи по кредитам за %s: списано %.2f по %d кредитам, новых просрочек %d, погашено %d",
+		day.Format(repository.DayLayout), collection.Amount, collection.Loans, collection.Overdue, collection.Closed)
+	return collection, nil
+}
+
+func loanError(err error, loanID int64) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %d", ErrLoanNotFound, loanID)
+	}
+	return err
+}
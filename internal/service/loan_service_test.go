@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/service"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLoanIssue(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	acc := e.account(t, alice.ID, 0)
+	terms := models.LoanTerms{Principal: 1000, AnnualRate: 10, TermMonths: 12}
+
+	invalid := []struct {
+		name  string
+		terms models.LoanTerms
+		want  error
+	}{
+		{"нулевая сумма", models.LoanTerms{AnnualRate: 10, TermMonths: 12}, service.ErrInvalidAmount},
+		{"ставка больше 100%", models.LoanTerms{Principal: 1000, AnnualRate: 120, TermMonths: 12}, service.ErrInvalidRate},
+		{"нулевой срок", models.LoanTerms{Principal: 1000, AnnualRate: 10}, service.ErrInvalidLoanTerms},
+		{"неизвестный способ", models.LoanTerms{Principal: 1000, TermMonths: 12, Method: "balloon"}, service.ErrInvalidLoanTerms},
+		{"отрицательные пени", models.LoanTerms{Principal: 1000, TermMonths: 12, LateFee: -1}, service.ErrInvalidLoanTerms},
+	}
+	for _, tt := range invalid {
+		if _, err := e.loans.Issue(ctx, "ops", acc, tt.terms, "заявка"); !errors.Is(err, tt.want) {
+			t.Errorf("%s: %v, ожидалось %v", tt.name, err, tt.want)
+		}
+	}
+	if _, err := e.loans.Issue(ctx, "ops", acc, terms, ""); !errors.Is(err, service.ErrReasonRequired) {
+		t.Errorf("кредит без причины: %v", err)
+	}
+	if _, err := e.loans.Issue(ctx, "ops", 999, terms, "заявка"); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("кредит на несуществующий счёт: %v", err)
+	}
+
+	loan, err := e.loans.Issue(ctx, "ops", acc, terms, "заявка")
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if loan.UserID != alice.ID || loan.Method != models.LoanAnnuity || loan.StartDate.IsZero() {
+		t.Errorf("Issue = %+v", loan)
+	}
+	if accounts, err := e.admin.ListAccounts(ctx, alice.ID); err != nil || accounts[0].Balance != 1000 {
+		t.Errorf("счета после выдачи = %+v, %v", accounts, err)
+	}
+	log, err := e.admin.AuditLog(ctx, acc, 0)
+	if err != nil || len(log) != 1 || log[0].Action != models.AuditLoan || log[0].Amount != 1000 {
+		t.Errorf("журнал = %+v, %v", log, err)
+	}
+}
+
+func TestLoanRepayAndCollect(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	acc := e.account(t, alice.ID, 0)
+	loan, err := e.loans.Issue(ctx, "ops", acc, models.LoanTerms{Principal: 600, AnnualRate: 0, TermMonths: 6, Method: models.LoanLinear}, "заявка")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := e.loans.Loan(ctx, bob.ID, loan.ID); !errors.Is(err, service.ErrLoanNotFound) {
+		t.Errorf("чужой кредит: %v", err)
+	}
+	if _, err := e.loans.Repay(ctx, alice.ID, loan.ID, 0); !errors.Is(err, service.ErrInvalidAmount) {
+		t.Errorf("нулевое погашение: %v", err)
+	}
+
+	// Досрочно гасим половину: все шесть платежей уменьшаются со 100 до 50
+	repayment, err := e.loans.Repay(ctx, alice.ID, loan.ID, 300)
+	if err != nil || repayment.Principal != 300 || repayment.Outstanding != 300 {
+		t.Fatalf("Repay = %+v, %v", repayment, err)
+	}
+	got, err := e.loans.Loan(ctx, alice.ID, loan.ID)
+	if err != nil || len(got.Schedule) != 6 || got.Schedule[0].Principal != 50 || got.Schedule[5].Principal != 50 {
+		t.Fatalf("Loan = %+v, %v", got, err)
+	}
+
+	collection, err := e.loans.Collect(ctx, got.Schedule[0].DueDate)
+	if err != nil || collection.Amount != 50 || collection.Overdue != 0 {
+		t.Fatalf("Collect = %+v, %v", collection, err)
+	}
+	loans, err := e.loans.Loans(ctx, alice.ID)
+	if err != nil || len(loans) != 1 || loans[0].Outstanding != 250 {
+		t.Errorf("Loans = %+v, %v", loans, err)
+	}
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"banking-api/internal/config"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Параметры адресной книги
+const (
+	DefaultPayeeCoolingOff       = 24 * time.Hour
+	DefaultPayeeCoolingOffAmount = 1000
+	MaxPayeeNicknameLen          = 50
+	MaxPayeeReferenceLen         = 140
+)
+
+// PayeeService — адресная книга: пользователь сохраняет получателей под
+// своими именами и переводит им, не вводя username или счёт каждый раз.
+// Новый получатель или получатель со сменённым счётом CoolingOff не может
+// получить в сумме больше CoolingOffAmount; о каждом новом получателе
+// пользователь получает письмо.
+type PayeeService struct {
+	Repo         repository.PayeeRepository
+	UserRepo     repository.UserRepository
+	Accounts     *AccountService
+	EmailService Mailer
+
+	// CoolingOff — срок после добавления получателя, в течение которого
+	// переводы ему ограничены суммой CoolingOffAmount; 0 отключает ограничение.
+	CoolingOff       time.Duration
+	CoolingOffAmount float64
+}
+
+func NewPayeeService(repo repository.PayeeRepository, userRepo repository.UserRepository, accounts *AccountService, email Mailer) *PayeeService {
+	return &PayeeService{
+		Repo:         repo,
+		UserRepo:     userRepo,
+		Accounts:     accounts,
+		EmailService: email,
+
+		CoolingOff:       DefaultPayeeCoolingOff,
+		CoolingOffAmount: DefaultPayeeCoolingOffAmount,
+	}
+}
+
+// Create добавляет получателя в адресную книгу userID.
+func (s *PayeeService) Create(ctx context.Context, userID int64, req models.PayeeRequest) (payee *models.Payee, err error) {
+	ctx, span := startSpan(ctx, "PayeeService.Create")
+	defer func() { endSpan(span, err) }()
+
+	payee, err = s.payee(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	payee.CoolingOffUntil = time.Now().UTC().Add(s.CoolingOff)
+	if err := s.Repo.CreatePayee(ctx, payee); err != nil {
+		return nil, s.saveError(err, req)
+	}
+	config.Log.Infof("Пользователь ID=%d добавил получателя %d (%s)", userID, payee.ID, payee.Nickname)
+	s.notify(ctx, userID, "В адресную книгу добавлен получатель", payee)
+	return payee, nil
+}
+
+// List возвращает адресную книгу userID.
+func (s *PayeeService) List(ctx context.Context, userID int64) (payees []models.Payee, err error) {
+	ctx, span := startSpan(ctx, "PayeeService.List")
+	defer func() { endSpan(span, err) }()
+
+	return s.Repo.ListPayees(ctx, userID)
+}
+
+func (s *PayeeService) Get(ctx context.Context, userID, payeeID int64) (payee *models.Payee, err error) {
+	ctx, span := startSpan(ctx, "PayeeService.Get")
+	defer func() { endSpan(span, err) }()
+
+	payee, err = s.Repo.GetPayee(ctx, payeeID, userID)
+	if err != nil {
+		return nil, payeeError(err, payeeID)
+	}
+	return payee, nil
+}
+
+// Update заменяет получателя payeeID. Смена пользователя или счёта получателя
+// заново начинает срок CoolingOff, как у нового получателя.
+func (s *PayeeService) Update(ctx context.Context, userID, payeeID int64, req models.PayeeRequest) (payee *models.Payee, err error) {
+	ctx, span := startSpan(ctx, "PayeeService.Update")
+	defer func() { endSpan(span, err) }()
+
+	old, err := s.Repo.GetPayee(ctx, payeeID, userID)
+	if err != nil {
+		return nil, payeeError(err, payeeID)
+	}
+	payee, err = s.payee(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	payee.ID = payeeID
+	retargeted := payee.ToUserID != old.ToUserID || payee.AccountID != old.AccountID
+	payee.CoolingOffUntil = old.CoolingOffUntil
+	if retargeted {
+		payee.CoolingOffUntil = time.Now().UTC().Add(s.CoolingOff)
+	}
+	if err := s.Repo.UpdatePayee(ctx, payee); err != nil {
+		return nil, s.saveError(err, req)
+	}
+	config.Log.Infof("Пользователь ID=%d изменил получателя %d (%s)", userID, payee.ID, payee.Nickname)
+	if retargeted {
+		s.notify(ctx, userID, "Получатель в адресной книге изменён", payee)
+	}
+	return payee, nil
+}
+
+func (s *PayeeService) Delete(ctx context.Context, userID, payeeID int64) (err error) {
+	ctx, span := startSpan(ctx, "PayeeService.Delete")
+	defer func() { endSpan(span, err) }()
+
+	if err := s.Repo.DeletePayee(ctx, payeeID, userID); err != nil {
+		return payeeError(err, payeeID)
+	}
+	config.Log.Infof("Пользователь ID=%d удалил получателя %d", userID, payeeID)
+	return nil
+}
+
+// Transfer переводит amount со счёта fromAccountID получателю payeeID по
+// правилам TransferFunds. Пустое назначение reference заменяется назначением
+// получателя. До окончания CoolingOff переводы получателю в сумме не могут
+// превышать CoolingOffAmount, иначе — ErrPayeeCoolingOff.
+func (s *PayeeService) Transfer(ctx context.Context, userID, payeeID, fromAccountID int64, amount float64, reference, idempotencyKey string) (err error) {
+	ctx, span := startSpan(ctx, "PayeeService.Transfer")
+	defer func() { endSpan(span, err) }()
+
+	payee, err := s.Repo.GetPayee(ctx, payeeID, userID)
+	if err != nil {
+		return payeeError(err, payeeID)
+	}
+	if reference == "" {
+		reference = payee.Reference
+	}
+	if utf8.RuneCountInString(reference) > MaxPayeeReferenceLen {
+		return fmt.Errorf("%w: назначение длиннее %d символов", ErrInvalidPayee, MaxPayeeReferenceLen)
+	}
+
+	toAccountID := payee.AccountID
+	if toAccountID == 0 {
+		toAccountID, err = s.Accounts.Repo.GetFirstAccountByUserID(ctx, payee.ToUserID)
+		if err != nil {
+			return fmt.Errorf("%w: у получателя нет счёта", ErrAccountNotFound)
+		}
+	}
+	if s.CoolingOffAmount > 0 && time.Now().Before(payee.CoolingOffUntil) {
+		if err := s.checkCoolingOff(ctx, userID, toAccountID, payee, amount); err != nil {
+			return err
+		}
+	}
+	return s.Accounts.transfer(ctx, userID, fromAccountID, toAccountID, amount, idempotencyKey, reference)
+}
+
+// checkCoolingOff проверяет, что переводы получателю payee за период
+// ограничения вместе с amount не больше CoolingOffAmount: крупную сумму нельзя
+// перевести новому получателю и частями.
+func (s *PayeeService) checkCoolingOff(ctx context.Context, userID, toAccountID int64, payee *models.Payee, amount float64) error {
+	since := payee.CoolingOffUntil.Add(-s.CoolingOff)
+	if since.Before(payee.CreatedAt) {
+		since = payee.CreatedAt
+	}
+	sent, err := s.Repo.SentToAccount(ctx, userID, toAccountID, since)
+	if err != nil {
+		return err
+	}
+	if math.Round((sent+amount)*100) > math.Round(s.CoolingOffAmount*100) {
+		return fmt.Errorf("%w: до %s получателю %q можно перевести не больше %.2f RUB, уже переведено %.2f",
+			ErrPayeeCoolingOff, payee.CoolingOffUntil.UTC().Format(time.RFC3339), payee.Nickname, s.CoolingOffAmount, sent)
+	}
+	return nil
+}
+
+// payee проверяет запрос и возвращает получателя без ID и CoolingOffUntil.
+func (s *PayeeService) payee(ctx context.Context, userID int64, req models.PayeeRequest) (*models.Payee, error) {
+	nickname := strings.TrimSpace(req.Nickname)
+	switch {
+	case nickname == "":
+		return nil, fmt.Errorf("%w: не указано имя получателя", ErrInvalidPayee)
+	case utf8.RuneCountInString(nickname) > MaxPayeeNicknameLen:
+		return nil, fmt.Errorf("%w: имя получателя длиннее %d символов", ErrInvalidPayee, MaxPayeeNicknameLen)
+	case (req.Username == "") == (req.Account == ""):
+		return nil, fmt.Errorf("%w: укажите username или номер счёта получателя", ErrInvalidPayee)
+	case utf8.RuneCountInString(req.Reference) > MaxPayeeReferenceLen:
+		return nil, fmt.Errorf("%w: назначение длиннее %d символов", ErrInvalidPayee, MaxPayeeReferenceLen)
+	}
+
+	payee := &models.Payee{UserID: userID, Nickname: nickname, Reference: req.Reference}
+	if req.Account != "" {
+		accountID, err := s.Accounts.AccountID(ctx, req.Account)
+		if err != nil {
+			return nil, err
+		}
+		payee.AccountID = accountID
+	}
+	if req.Username != "" {
+		toUserID, err := s.UserRepo.GetUserIDByUsername(ctx, req.Username)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, req.Username)
+		}
+		if toUserID == userID {
+			return nil, ErrSelfTransfer
+		}
+		payee.ToUserID = toUserID
+	}
+	return payee, nil
+}
+
+// saveError переводит ошибки сохранения получателя в ошибки сервиса.
+func (s *PayeeService) saveError(err error, req models.PayeeRequest) error {
+	switch {
+	case errors.Is(err, repository.ErrDuplicate):
+		return fmt.Errorf("%w: %s", ErrPayeeExists, strings.TrimSpace(req.Nickname))
+	case errors.Is(err, sql.ErrNoRows) && req.Account != "":
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, req.Account)
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("%w: %s", ErrUserNotFound, req.Username)
+	}
+	return err
+}
+
+// notify сообщает пользователю userID о новом получателе в адресной книге;
+// ошибки отправки только логируются.
+func (s *PayeeService) notify(ctx context.Context, userID int64, subject string, payee *models.Payee) {
+	if s.EmailService == nil {
+		return
+	}
+	user, err := s.UserRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return
+	}
+	target := "счёт " + payee.Account
+	if payee.Username != "" {
+		target = "пользователь " + payee.Username
+	}
+	body := fmt.Sprintf("<h3>Получатель «%s»: %s</h3><p>Если это были не вы, удалите получателя и смените пароль</p>",
+		html.EscapeString(payee.Nickname), html.EscapeString(target))
+	if s.CoolingOff > 0 && s.CoolingOffAmount > 0 {
+		body += fmt.Sprintf("<p>Переводы больше %.2f RUB этому получателю будут доступны с %s</p>",
+			s.CoolingOffAmount, payee.CoolingOffUntil.UTC().Format("02.01.2006 15:04 UTC"))
+	}
+	if err := s.EmailService.SendEmail(ctx, user.Email, subject, body); err != nil {
+		config.Log.Warnf("Не удалось отправить уведомление %q пользователю %d: %v", subject, userID, err)
+	}
+}
+
+func payeeError(err error, payeeID int64) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: ID %d", ErrPayeeNotFound, payeeID)
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"banking-api/internal/accountno"
+	"banking-api/internal/models"
+	"banking-api/internal/service"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPayeeFlow(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	carol := e.register(t, "carol")
+	aliceAcc := e.account(t, alice.ID, 5000)
+	bobAcc := e.account(t, bob.ID, 0)
+	carolAcc := e.account(t, carol.ID, 0)
+
+	invalid := []struct {
+		name string
+		req  models.PayeeRequest
+		want error
+	}{
+		{"без имени", models.PayeeRequest{Nickname: " ", Username: "bob"}, service.ErrInvalidPayee},
+		{"без получателя", models.PayeeRequest{Nickname: "боб"}, service.ErrInvalidPayee},
+		{"username и счёт", models.PayeeRequest{Nickname: "боб", Username: "bob", Account: e.number(t, bobAcc)}, service.ErrInvalidPayee},
+		{"неизвестный пользователь", models.PayeeRequest{Nickname: "боб", Username: "dave"}, service.ErrUserNotFound},
+		{"сам себя", models.PayeeRequest{Nickname: "я", Username: "alice"}, service.ErrSelfTransfer},
+		{"несуществующий счёт", models.PayeeRequest{Nickname: "счёт", Account: accountno.New()}, service.ErrAccountNotFound},
+	}
+	for _, tt := range invalid {
+		if _, err := e.payees.Create(ctx, alice.ID, tt.req); !errors.Is(err, tt.want) {
+			t.Errorf("%s: %v, ожидалось %v", tt.name, err, tt.want)
+		}
+	}
+
+	e.mailer.sent = nil
+	bobPayee, err := e.payees.Create(ctx, alice.ID, models.PayeeRequest{Nickname: "боб", Username: "bob", Reference: "долг"})
+	if err != nil || bobPayee.Username != "bob" || bobPayee.CoolingOffUntil.IsZero() {
+		t.Fatalf("Create = %+v, %v", bobPayee, err)
+	}
+	if len(e.mailer.sent) != 1 || e.mailer.sent[0].To != "alice@example.com" || e.mailer.sent[0].Subject != "В адресную книгу добавлен получатель" {
+		t.Errorf("уведомление о получателе = %+v", e.mailer.sent)
+	}
+	if _, err := e.payees.Create(ctx, alice.ID, models.PayeeRequest{Nickname: "боб", Account: e.number(t, carolAcc)}); !errors.Is(err, service.ErrPayeeExists) {
+		t.Errorf("повторное имя: %v", err)
+	}
+
+	// Новому получателю — только суммы до порога
+	if err := e.payees.Transfer(ctx, alice.ID, bobPayee.ID, aliceAcc, 1500, "", ""); !errors.Is(err, service.ErrPayeeCoolingOff) {
+		t.Errorf("крупный перевод новому получателю: %v", err)
+	}
+	e.mailer.sent = nil
+	if err := e.payees.Transfer(ctx, alice.ID, bobPayee.ID, aliceAcc, 300, "", ""); err != nil {
+		t.Fatalf("Transfer: %v", err)
+	}
+	if balance, _ := e.accounts.Balance(ctx, bob.ID, bobAcc); balance.Ledger != 300 {
+		t.Errorf("баланс получателя = %+v", balance)
+	}
+	if len(e.mailer.sent) != 1 || e.mailer.sent[0].To != "bob@example.com" || !strings.Contains(e.mailer.sent[0].Body, "Назначение: долг") {
+		t.Errorf("уведомление получателю = %+v", e.mailer.sent)
+	}
+	if err := e.payees.Transfer(ctx, bob.ID, bobPayee.ID, bobAcc, 10, "", ""); !errors.Is(err, service.ErrPayeeNotFound) {
+		t.Errorf("перевод чужому получателю: %v", err)
+	}
+
+	// Без срока ограничения крупный перевод проходит сразу
+	e.payees.CoolingOff = 0
+	carolPayee, err := e.payees.Create(ctx, alice.ID, models.PayeeRequest{Nickname: "кэрол", Account: e.number(t, carolAcc)})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := e.payees.Transfer(ctx, alice.ID, carolPayee.ID, aliceAcc, 1500, "подарок", ""); err != nil {
+		t.Errorf("крупный перевод без срока: %v", err)
+	}
+
+	// Смена счёта получателя снова ограничивает крупные переводы
+	e.payees.CoolingOff = service.DefaultPayeeCoolingOff
+	renamed, err := e.payees.Update(ctx, alice.ID, carolPayee.ID, models.PayeeRequest{Nickname: "карол", Account: e.number(t, carolAcc)})
+	if err != nil || !renamed.CoolingOffUntil.Equal(carolPayee.CoolingOffUntil) {
+		t.Fatalf("Update без смены счёта = %+v, %v", renamed, err)
+	}
+	e.mailer.sent = nil
+	if _, err := e.payees.Update(ctx, alice.ID, carolPayee.ID, models.PayeeRequest{Nickname: "карол", Account: e.number(t, bobAcc)}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if len(e.mailer.sent) != 1 || e.mailer.sent[0].Subject != "Получатель в адресной книге изменён" {
+		t.Errorf("уведомление о смене счёта = %+v", e.mailer.sent)
+	}
+	if err := e.payees.Transfer(ctx, alice.ID, carolPayee.ID, aliceAcc, 1500, "", ""); !errors.Is(err, service.ErrPayeeCoolingOff) {
+		t.Errorf("крупный перевод после смены счёта: %v", err)
+	}
+
+	if payees, err := e.payees.List(ctx, alice.ID); err != nil || len(payees) != 2 {
+		t.Errorf("List = %+v, %v", payees, err)
+	}
+	if err := e.payees.Delete(ctx, alice.ID, bobPayee.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := e.payees.Get(ctx, alice.ID, bobPayee.ID); !errors.Is(err, service.ErrPayeeNotFound) {
+		t.Errorf("Get удалённого: %v", err)
+	}
+}
+
+func TestPayeeCoolingOffSplit(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	aliceAcc := e.account(t, alice.ID, 5000)
+	bobAcc := e.account(t, bob.ID, 0)
+	payee, err := e.payees.Create(ctx, alice.ID, models.PayeeRequest{Nickname: "боб", Account: e.number(t, bobAcc)})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Крупную сумму нельзя перевести новому получателю и частями
+	for i := 0; i < 2; i++ {
+		if err := e.payees.Transfer(ctx, alice.ID, payee.ID, aliceAcc, 400, "", ""); err != nil {
+			t.Fatalf("перевод %d: %v", i+1, err)
+		}
+	}
+	if err := e.payees.Transfer(ctx, alice.ID, payee.ID, aliceAcc, 400, "", ""); !errors.Is(err, service.ErrPayeeCoolingOff) {
+		t.Errorf("перевод сверх порога частями: %v", err)
+	}
+	// Прямые переводы на тот же счёт тоже учитываются
+	if err := e.accounts.TransferFunds(ctx, alice.ID, aliceAcc, bobAcc, 150, ""); err != nil {
+		t.Fatalf("TransferFunds: %v", err)
+	}
+	if err := e.payees.Transfer(ctx, alice.ID, payee.ID, aliceAcc, 100, "", ""); !errors.Is(err, service.ErrPayeeCoolingOff) {
+		t.Errorf("перевод после прямого перевода: %v", err)
+	}
+	if err := e.payees.Transfer(ctx, alice.ID, payee.ID, aliceAcc, 50, "", ""); err != nil {
+		t.Errorf("перевод до порога: %v", err)
+	}
+	if balance, _ := e.accounts.Balance(ctx, bob.ID, bobAcc); balance.Ledger != 1000 {
+		t.Errorf("баланс получателя = %+v", balance)
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"banking-api/internal/config"
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"time"
+)
+
+// Параметры запросов денег
+const (
+	DefaultPaymentRequestTTL = 7 * 24 * time.Hour
+	MaxPaymentRequestNote    = 200
+)
+
+// PaymentRequestService — запросы денег: пользователь просит другого
+// пользователя (по username) перевести сумму на свой счёт. Плательщик
+// принимает запрос (перевод со своего счёта), отклоняет его или даёт истечь;
+// запросивший может отменить запрос. Обе стороны получают уведомления по email.
+type PaymentRequestService struct {
+	Repo         repository.PaymentRequestRepository
+	UserRepo     repository.UserRepository
+	EmailService Mailer
+
+	// TTL — срок ответа на запрос.
+	TTL time.Duration
+}
+
+func NewPaymentRequestService(repo repository.PaymentRequestRepository, userRepo repository.UserRepository, email Mailer) *PaymentRequestService {
+	return &PaymentRequestService{Repo: repo, UserRepo: userRepo, EmailService: email, TTL: DefaultPaymentRequestTTL}
+}
+
+// Request создаёт запрос от requesterID к пользователю payerUsername на
+// перевод amount на счёт toAccountID запросившего.
+func (s *PaymentRequestService) Request(ctx context.Context, requesterID int64, payerUsername string, toAccountID int64, amount float64, note string) (req *models.PaymentRequest, err error) {
+	ctx, span := startSpan(ctx, "PaymentRequestService.Request")
+	defer func() { endSpan(span, err) }()
+
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if len([]rune(note)) > MaxPaymentRequestNote {
+		return nil, fmt.Errorf("%w: комментарий длиннее %d символов", ErrInvalidPaymentRequest, MaxPaymentRequestNote)
+	}
+	if payerUsername == "" {
+		return nil, fmt.Errorf("%w: плательщик не указан", ErrUserNotFound)
+	}
+	payerID, err := s.UserRepo.GetUserIDByUsername(ctx, payerUsername)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, payerUsername)
+	}
+	if payerID == requesterID {
+		return nil, ErrSelfTransfer
+	}
+
+	req = &models.PaymentRequest{
+		RequesterID: requesterID,
+		PayerID:     payerID,
+		ToAccountID: toAccountID,
+		Amount:      amount,
+		Note:        note,
+		ExpiresAt:   time.Now().UTC().Add(s.TTL),
+	}
+	if err := s.Repo.CreatePaymentRequest(ctx, req); err != nil {
+		config.Log.Errorf("Ошибка создания запроса денег: %v", err)
+		return nil, accountError(err)
+	}
+	config.Log.Infof("Запрос денег %d: %s просит %.2f у %s", req.ID, req.Requester, req.Amount, req.Payer)
+
+	body := fmt.Sprintf("<h3>%s просит перевести %.2f RUB</h3>", html.EscapeString(req.Requester), req.Amount)
+	if req.Note != "" {
+		body += fmt.Sprintf("<p>%s</p>", html.EscapeString(req.Note))
+	}
+	body += fmt.Sprintf("<p>Запрос действует до %s UTC</p>", req.ExpiresAt.Format("2006-01-02 15:04"))
+	s.notify(ctx, req.PayerID, "Запрос денег", body)
+	return req, nil
+}
+
+// List возвращает входящие (incoming) или исходящие запросы пользователя,
+// непустой status оставляет запросы в этом статусе.
+func (s *PaymentRequestService) List(ctx context.Context, userID int64, incoming bool, status string) (requests []models.PaymentRequest, err error) {
+	ctx, span := startSpan(ctx, "PaymentRequestService.List")
+	defer func() { endSpan(span, err) }()
+
+	switch status {
+	case "", models.PaymentRequestPending, models.PaymentRequestAccepted, models.PaymentRequestDeclined,
+		models.PaymentRequestCancelled, models.PaymentRequestExpired:
+	default:
+		return nil, fmt.Errorf("%w: неизвестный статус %q", ErrInvalidPaymentRequest, status)
+	}
+	return s.Repo.ListPaymentRequests(ctx, userID, incoming, status)
+}
+
+// Get возвращает запрос, в котором userID — одна из сторон.
+func (s *PaymentRequestService) Get(ctx context.Context, userID, requestID int64) (req *models.PaymentRequest, err error) {
+	ctx, span := startSpan(ctx, "PaymentRequestService.Get")
+	defer func() { endSpan(span, err) }()
+
+	req, err = s.Repo.GetPaymentRequest(ctx, requestID, userID)
+	if err != nil {
+		return nil, paymentRequestError(err, requestID)
+	}
+	return req, nil
+}
+
+// Accept оплачивает запрос переводом со счёта fromAccountID плательщика.
+func (s *PaymentRequestService) Accept(ctx context.Context, payerID, requestID, fromAccountID int64) (req *models.PaymentRequest, err error) {
+	ctx, span := startSpan(ctx, "PaymentRequestService.Accept")
+	defer func() { endSpan(span, err) }()
+
+	// Сначала убеждаемся, что запрос адресован payerID: sql.ErrNoRows при оплате
+	// означает уже не найденный запрос, а чужой или несуществующий счёт
+	req, err = s.Repo.GetPaymentRequest(ctx, requestID, payerID)
+	if err == nil && req.PayerID != payerID {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, paymentRequestError(err, requestID)
+	}
+	req, err = s.Repo.AcceptPaymentRequest(ctx, requestID, payerID, fromAccountID, time.Now().UTC())
+	if err != nil {
+		config.Log.Errorf("Ошибка оплаты запроса денег %d со счёта %d: %v", requestID, fromAccountID, err)
+		return nil, accountError(err)
+	}
+	config.Log.Infof("Запрос денег %d оплачен переводом %d", req.ID, req.TransactionID)
+
+	body := fmt.Sprintf("<h3>%s перевёл %.2f RUB по вашему запросу</h3>", html.EscapeString(req.Payer), req.Amount)
+	s.notify(ctx, req.RequesterID, "Запрос денег оплачен", body)
+	return req, nil
+}
+
+// Decline отклоняет входящий запрос.
+func (s *PaymentRequestService) Decline(ctx context.Context, payerID, requestID int64) (req *models.PaymentRequest, err error) {
+	ctx, span := startSpan(ctx, "PaymentRequestService.Decline")
+	defer func() { endSpan(span, err) }()
+
+	req, err = s.Repo.ResolvePaymentRequest(ctx, requestID, payerID, models.PaymentRequestDeclined, time.Now().UTC())
+	if err != nil {
+		return nil, paymentRequestError(err, requestID)
+	}
+	config.Log.Infof("Запрос денег %d отклонён", req.ID)
+
+	body := fmt.Sprintf("<h3>%s отклонил ваш запрос на %.2f RUB</h3>", html.EscapeString(req.Payer), req.Amount)
+	s.notify(ctx, req.RequesterID, "Запрос денег отклонён", body)
+	return req, nil
+}
+
+// Cancel отменяет исходящий запрос.
+func (s *PaymentRequestService) Cancel(ctx context.Context, requesterID, requestID int64) (req *models.PaymentRequest, err error) {
+	ctx, span := startSpan(ctx, "PaymentRequestService.Cancel")
+	defer func() { endSpan(span, err) }()
+
+	req, err = s.Repo.ResolvePaymentRequest(ctx, requestID, requesterID, models.PaymentRequestCancelled, time.Now().UTC())
+	if err != nil {
+		return nil, paymentRequestError(err, requestID)
+	}
+	config.Log.Infof("Запрос денег %d отменён", req.ID)
+
+	body := fmt.Sprintf("<h3>%s отменил запрос на %.2f RUB</h3>", html.EscapeString(req.Requester), req.Amount)
+	s.notify(ctx, req.PayerID, "Запрос денег отменён", body)
+	return req, nil
+}
+
+// Expire закрывает запросы, срок которых истёк к now, и уведомляет запросивших.
+// Возвращает число истёкших запросов.
+func (s *PaymentRequestService) Expire(ctx context.Context, now time.Time) (n int, err error) {
+	ctx, span := startSpan(ctx, "PaymentRequestService.Expire")
+	defer func() { endSpan(span, err) }()
+
+	expired, err := s.Repo.ExpirePaymentRequests(ctx, now)
+	if err != nil {
+		config.Log.Errorf("Ошибка закрытия истёкших запросов денег: %v", err)
+		return 0, err
+	}
+	for _, req := range expired {
+		body := fmt.Sprintf("<h3>%s не ответил на ваш запрос на %.2f RUB, срок запроса истёк</h3>", html.EscapeString(req.Payer), req.Amount)
+		s.notify(ctx, req.RequesterID, "Срок запроса денег истёк", body)
+	}
+	if len(expired) > 0 {
+		config.Log.Infof("Истёк срок %d запросов денег", len(expired))
+	}
+	return len(expired), nil
+}
+
+// notify отправляет письмо пользователю userID; ошибки отправки только логируются.
+func (s *PaymentRequestService) notify(ctx context.Context, userID int64, subject, body string) {
+	if s.EmailService == nil {
+		return
+	}
+	user, err := s.UserRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return
+	}
+	if err := s.EmailService.SendEmail(ctx, user.Email, subject, body); err != nil {
+		config.Log.Warnf("Не удалось отправить уведомление %q пользователю %d: %v", subject, userID, err)
+	}
+}
+
+func paymentRequestError(err error, requestID int64) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %d", ErrPaymentRequestNotFound, requestID)
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+package service_test
+
+import (
+	"banking-api/internal/models"
+	"banking-api/internal/repository"
+	"banking-api/internal/service"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPaymentRequestAccept(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	carol := e.register(t, "carol")
+	aliceAcc := e.account(t, alice.ID, 0)
+	bobAcc := e.account(t, bob.ID, 100)
+	carolAcc := e.account(t, carol.ID, 0)
+
+	invalid := []struct {
+		name   string
+		payer  string
+		amount float64
+		note   string
+		want   error
+	}{
+		{"нулевая сумма", "bob", 0, "", service.ErrInvalidAmount},
+		{"неизвестный плательщик", "dave", 10, "", service.ErrUserNotFound},
+		{"самому себе", "alice", 10, "", service.ErrSelfTransfer},
+		{"длинный комментарий", "bob", 10, strings.Repeat("я", service.MaxPaymentRequestNote+1), service.ErrInvalidPaymentRequest},
+	}
+	for _, tt := range invalid {
+		if _, err := e.requests.Request(ctx, alice.ID, tt.payer, aliceAcc, tt.amount, tt.note); !errors.Is(err, tt.want) {
+			t.Errorf("%s: %v, ожидалось %v", tt.name, err, tt.want)
+		}
+	}
+	if _, err := e.requests.Request(ctx, alice.ID, "bob", bobAcc, 10, ""); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("запрос на чужой счёт: %v", err)
+	}
+
+	req, err := e.requests.Request(ctx, alice.ID, "bob", aliceAcc, 30, "<b>кино</b>")
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if len(e.mailer.sent) != 1 || e.mailer.sent[0].To != "bob@example.com" || !strings.Contains(e.mailer.sent[0].Body, "&lt;b&gt;кино") {
+		t.Errorf("уведомление плательщику = %+v", e.mailer.sent)
+	}
+	if !req.ExpiresAt.After(time.Now().Add(6 * 24 * time.Hour)) {
+		t.Errorf("срок запроса = %s", req.ExpiresAt)
+	}
+
+	if _, err := e.requests.Accept(ctx, carol.ID, req.ID, carolAcc); !errors.Is(err, service.ErrPaymentRequestNotFound) {
+		t.Errorf("оплата посторонним: %v", err)
+	}
+	if _, err := e.requests.Accept(ctx, alice.ID, req.ID, aliceAcc); !errors.Is(err, service.ErrPaymentRequestNotFound) {
+		t.Errorf("оплата запросившим: %v", err)
+	}
+	if _, err := e.requests.Accept(ctx, bob.ID, req.ID, aliceAcc); !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("оплата с чужого счёта: %v", err)
+	}
+	accepted, err := e.requests.Accept(ctx, bob.ID, req.ID, bobAcc)
+	if err != nil || accepted.Status != models.PaymentRequestAccepted {
+		t.Fatalf("Accept = %+v, %v", accepted, err)
+	}
+	if last := e.mailer.sent[len(e.mailer.sent)-1]; last.To != "alice@example.com" || last.Subject != "Запрос денег оплачен" {
+		t.Errorf("уведомление запросившему = %+v", last)
+	}
+	if _, err := e.requests.Cancel(ctx, alice.ID, req.ID); !errors.Is(err, repository.ErrPaymentRequestClosed) {
+		t.Errorf("отмена оплаченного: %v", err)
+	}
+	if accounts, err := e.admin.ListAccounts(ctx, alice.ID); err != nil || accounts[0].Balance != 30 {
+		t.Errorf("счета после оплаты = %+v, %v", accounts, err)
+	}
+}
+
+func TestPaymentRequestDeclineCancelExpire(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	alice := e.register(t, "alice")
+	bob := e.register(t, "bob")
+	aliceAcc := e.account(t, alice.ID, 0)
+	e.requests.TTL = time.Hour
+
+	declined, _ := e.requests.Request(ctx, alice.ID, "bob", aliceAcc, 10, "")
+	cancelled, _ := e.requests.Request(ctx, alice.ID, "bob", aliceAcc, 20, "")
+	stale, _ := e.requests.Request(ctx, alice.ID, "bob", aliceAcc, 30, "")
+
+	if _, err := e.requests.Decline(ctx, bob.ID, declined.ID); err != nil {
+		t.Fatalf("Decline: %v", err)
+	}
+	if _, err := e.requests.Cancel(ctx, bob.ID, cancelled.ID); !errors.Is(err, service.ErrPaymentRequestNotFound) {
+		t.Errorf("отмена плательщиком: %v", err)
+	}
+	if _, err := e.requests.Cancel(ctx, alice.ID, cancelled.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	sent := len(e.mailer.sent)
+	if n, err := e.requests.Expire(ctx, time.Now()); err != nil || n != 0 {
+		t.Errorf("Expire до срока = %d, %v", n, err)
+	}
+	if n, err := e.requests.Expire(ctx, stale.ExpiresAt); err != nil || n != 1 {
+		t.Fatalf("Expire = %d, %v", n, err)
+	}
+	if len(e.mailer.sent) != sent+1 || e.mailer.sent[sent].To != "alice@example.com" {
+		t.Errorf("уведомление об истечении = %+v", e.mailer.sent[sent:])
+	}
+
+	if _, err := e.requests.List(ctx, bob.ID, true, "paid"); !errors.Is(err, service.ErrInvalidPaymentRequest) {
+		t.Errorf("неизвестный статус: %v", err)
+	}
+	incoming, err := e.requests.List(ctx, bob.ID, true, "")
+	if err != nil || len(incoming) != 3 || incoming[0].Status != models.PaymentRequestExpired ||
+		incoming[1].Status != models.PaymentRequestCancelled || incoming[2].Status != models.PaymentRequestDeclined {
+		t.Errorf("входящие = %+v, %v", incoming, err)
+	}
+}
@@ -0,0 +1,115 @@
+package service_test
+
+import (
+	"banking-api/internal/config"
+	"banking-api/internal/models"
+	"banking-api/internal/repository/memory"
+	"banking-api/internal/service"
+	"context"
+	"io"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	config.InitLogger()
+	config.Log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+type sentEmail struct {
+	To, Subject, Body string
+}
+
+// fakeMailer запоминает отправленные письма.
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []sentEmail
+}
+
+func (m *fakeMailer) SendEmail(ctx context.Context, to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, sentEmail{To: to, Subject: subject, Body: body})
+	return nil
+}
+
+type env struct {
+	auth      *service.AuthService
+	accounts  *service.AccountService
+	admin     *service.AdminService
+	interest  *service.InterestService
+	loans     *service.LoanService
+	requests  *service.PaymentRequestService
+	holds     *service.HoldService
+	batches   *service.BatchService
+	approvals *service.ApprovalService
+	access    *service.AccessService
+	payees    *service.PayeeService
+	mailer    *fakeMailer
+	store     *memory.Store
+}
+
+func newEnv() *env {
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+	accounts := memory.NewAccountRepository(store)
+	mailer := &fakeMailer{}
+	approvals := service.NewApprovalService(memory.NewApprovalRepository(store), users, mailer)
+	accountService := service.NewAccountService(accounts, users, mailer)
+	accountService.Approvals = approvals
+	return &env{
+		auth:      service.NewAuthService(users),
+		accounts:  accountService,
+		admin:     service.NewAdminService(memory.NewAdminRepository(store), accounts, users),
+		interest:  service.NewInterestService(memory.NewInterestRepository(store), accounts),
+		loans:     service.NewLoanService(memory.NewLoanRepository(store), accounts),
+		requests:  service.NewPaymentRequestService(memory.NewPaymentRequestRepository(store), users, mailer),
+		holds:     service.NewHoldService(memory.NewHoldRepository(store)),
+		batches:   service.NewBatchService(memory.NewBatchRepository(store), accounts, users),
+		approvals: approvals,
+		access:    service.NewAccessService(memory.NewAccessRepository(store), users, mailer),
+		payees:    service.NewPayeeService(memory.NewPayeeRepository(store), users, accountService, mailer),
+		mailer:    mailer,
+		store:     store,
+	}
+}
+
+func (e *env) register(t *testing.T, username string) *models.User {
+	t.Helper()
+	user, err := e.auth.RegisterUser(context.Background(), &models.RegisterRequest{
+		Email:    username + "@example.com",
+		Username: username,
+		Password: "secret",
+	})
+	if err != nil {
+		t.Fatalf("RegisterUser(%s): %v", username, err)
+	}
+	return user
+}
+
+func (e *env) account(t *testing.T, userID int64, balance float64) int64 {
+	t.Helper()
+	ctx := context.Background()
+	acc, err := e.accounts.CreateAccount(ctx, userID, "")
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	if balance > 0 {
+		if err := e.accounts.TopUp(ctx, userID, acc.ID, balance); err != nil {
+			t.Fatalf("TopUp: %v", err)
+		}
+	}
+	return acc.ID
+}
+
+// number возвращает номер счёта accountID.
+func (e *env) number(t *testing.T, accountID int64) string {
+	t.Helper()
+	acc, err := memory.NewAdminRepository(e.store).GetAccountByID(context.Background(), accountID)
+	if err != nil {
+		t.Fatalf("GetAccountByID(%d): %v", accountID, err)
+	}
+	return acc.Number
+}
@@ -0,0 +1,24 @@
+// Package migrations содержит SQL-миграции схемы, встроенные в бинарник.
+//
+// Для каждого поддерживаемого драйвера БД миграции лежат в своей папке
+// (postgres, sqlite) и именуются как NNNN_name.up.sql / NNNN_name.down.sql,
+// где NNNN — номер версии схемы. Версии в разных папках должны совпадать.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// For возвращает миграции для драйвера БД ("postgres" или "sqlite").
+func For(driver string) (fs.FS, error) {
+	switch driver {
+	case "postgres", "sqlite":
+		return fs.Sub(files, driver)
+	}
+	return nil, fmt.Errorf("нет миграций для драйвера %q", driver)
+}
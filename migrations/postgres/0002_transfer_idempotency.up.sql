@@ -0,0 +1,5 @@
+-- Ключ идемпотентности перевода: повтор запроса с тем же ключом не создаёт второй перевод
+ALTER TABLE transactions ADD COLUMN idempotency_key TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_idempotency_key
+    ON transactions (from_account_id, idempotency_key);
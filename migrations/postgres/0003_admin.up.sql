@@ -0,0 +1,18 @@
+-- Заморозка счёта: замороженный счёт не участвует в пополнениях и переводах
+ALTER TABLE accounts ADD COLUMN frozen BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Вид движения: перевод между счетами или ручная корректировка баланса
+ALTER TABLE transactions ADD COLUMN kind TEXT NOT NULL DEFAULT 'transfer';
+
+-- Журнал действий администраторов
+CREATE TABLE IF NOT EXISTS audit_log (
+    id SERIAL PRIMARY KEY,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL,
+    amount NUMERIC(12, 2),
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_account_id ON audit_log (account_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS transactions_from_account_created_at;
+ALTER TABLE accounts DROP COLUMN monthly_transfer_limit;
+ALTER TABLE accounts DROP COLUMN credit_limit;
+ALTER TABLE accounts DROP COLUMN type;
@@ -0,0 +1,11 @@
+-- Тип счёта: checking (расчётный), savings (сберегательный), credit (кредитный)
+ALTER TABLE accounts ADD COLUMN type TEXT NOT NULL DEFAULT 'checking';
+
+-- Допустимый минус для кредитного счёта (0 — уходить в минус нельзя)
+ALTER TABLE accounts ADD COLUMN credit_limit NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+-- Лимит исходящих переводов в календарный месяц (0 — без лимита)
+ALTER TABLE accounts ADD COLUMN monthly_transfer_limit INTEGER NOT NULL DEFAULT 0;
+
+-- Подсчёт исходящих переводов счёта за месяц
+CREATE INDEX IF NOT EXISTS transactions_from_account_created_at ON transactions (from_account_id, created_at);
@@ -0,0 +1,29 @@
+-- Счета банка (процентный расход и т. п.): владельца нет, тип internal
+CREATE TABLE IF NOT EXISTS system_accounts (
+    name TEXT PRIMARY KEY,
+    account_id INTEGER NOT NULL REFERENCES accounts(id)
+);
+
+-- Расписание процентных ставок по типам счетов: ставка действует с effective_from
+-- (YYYY-MM-DD) до следующей записи того же типа
+CREATE TABLE IF NOT EXISTS interest_rates (
+    account_type TEXT NOT NULL,
+    effective_from TEXT NOT NULL,
+    annual_rate NUMERIC(7, 4) NOT NULL,
+    PRIMARY KEY (account_type, effective_from)
+);
+
+-- Ежедневные начисления: одна строка на счёт и день, поэтому повторный запуск
+-- начисления за день ничего не меняет
+CREATE TABLE IF NOT EXISTS interest_accruals (
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    day TEXT NOT NULL,
+    balance NUMERIC(12, 2) NOT NULL,
+    annual_rate NUMERIC(7, 4) NOT NULL,
+    amount NUMERIC(18, 6) NOT NULL,
+    capitalized BOOLEAN NOT NULL DEFAULT FALSE,
+    transaction_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL,
+    PRIMARY KEY (account_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS interest_accruals_day ON interest_accruals (day);
@@ -0,0 +1,38 @@
+-- Кредиты: сумма выдаётся со счёта банка на счёт account_id, с него же
+-- списываются платежи. start_date (YYYY-MM-DD) — день выдачи, от него
+-- отсчитываются даты платежей. outstanding (остаток основного долга) и arrears
+-- (просроченная задолженность) пересчитываются по графику при каждом платеже
+CREATE TABLE IF NOT EXISTS loans (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    principal NUMERIC(12, 2) NOT NULL,
+    annual_rate NUMERIC(7, 4) NOT NULL,
+    term_months INTEGER NOT NULL,
+    method TEXT NOT NULL,
+    late_fee NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    start_date TEXT NOT NULL,
+    outstanding NUMERIC(12, 2) NOT NULL,
+    arrears NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS loans_user_id ON loans (user_id);
+
+-- График платежей. paid_interest — оплаченные проценты и пени, paid_principal —
+-- оплаченный основной долг
+CREATE TABLE IF NOT EXISTS loan_installments (
+    loan_id INTEGER NOT NULL REFERENCES loans(id) ON DELETE CASCADE,
+    number INTEGER NOT NULL,
+    due_date TEXT NOT NULL,
+    principal NUMERIC(12, 2) NOT NULL,
+    interest NUMERIC(12, 2) NOT NULL,
+    late_fee NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    paid_principal NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    paid_interest NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'pending',
+    PRIMARY KEY (loan_id, number)
+);
+
+CREATE INDEX IF NOT EXISTS loan_installments_due_date ON loan_installments (due_date);
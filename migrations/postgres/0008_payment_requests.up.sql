@@ -0,0 +1,20 @@
+-- Запросы денег: requester просит payer перевести amount на свой счёт
+-- to_account_id. Принятый запрос исполняется переводом transaction_id;
+-- запрос, не закрытый до expires_at, истекает
+CREATE TABLE IF NOT EXISTS payment_requests (
+    id SERIAL PRIMARY KEY,
+    requester_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    payer_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    amount NUMERIC(12, 2) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    transaction_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS payment_requests_requester_id ON payment_requests (requester_id);
+CREATE INDEX IF NOT EXISTS payment_requests_payer_id ON payment_requests (payer_id);
+CREATE INDEX IF NOT EXISTS payment_requests_pending ON payment_requests (status, expires_at);
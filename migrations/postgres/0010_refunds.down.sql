@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS transactions_original_transaction_id;
+ALTER TABLE transactions DROP COLUMN reason_code;
+ALTER TABLE transactions DROP COLUMN original_transaction_id;
@@ -0,0 +1,6 @@
+-- Возвраты и сторно записываются новыми движениями со ссылкой на исходный
+-- перевод; у сторно — код причины
+ALTER TABLE transactions ADD COLUMN original_transaction_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL;
+ALTER TABLE transactions ADD COLUMN reason_code TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS transactions_original_transaction_id ON transactions (original_transaction_id);
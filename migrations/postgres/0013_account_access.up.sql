@@ -0,0 +1,19 @@
+-- Доступ пользователей к счетам: владелец (accounts.user_id) и совладельцы
+-- с уровнем view, transact или manage. Приглашение ждёт принятия в статусе
+-- pending, проверки прав учитывают только активный доступ
+CREATE TABLE IF NOT EXISTS account_access (
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    level TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active',
+    invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    accepted_at TIMESTAMP,
+    PRIMARY KEY (account_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS account_access_user_id ON account_access (user_id);
+
+-- Владельцы существующих счетов получают полный доступ
+INSERT INTO account_access (account_id, user_id, level, status, created_at, accepted_at)
+SELECT id, user_id, 'manage', 'active', created_at, created_at FROM accounts WHERE user_id IS NOT NULL;
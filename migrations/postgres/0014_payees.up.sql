@@ -0,0 +1,15 @@
+-- Адресная книга: получатели пользователя user_id под своими nickname —
+-- пользователь to_user_id (перевод на его первый счёт) или счёт to_account_id.
+-- До cooling_off_until крупные переводы получателю не выполняются
+CREATE TABLE IF NOT EXISTS payees (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    nickname TEXT NOT NULL,
+    to_user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    to_account_id INTEGER REFERENCES accounts(id) ON DELETE CASCADE,
+    reference TEXT NOT NULL DEFAULT '',
+    cooling_off_until TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS payees_nickname ON payees (user_id, nickname);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS accounts_number;
+ALTER TABLE accounts DROP COLUMN number;
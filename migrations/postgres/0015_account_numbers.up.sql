@@ -0,0 +1,12 @@
+-- Номер счёта в формате, похожем на IBAN: RU, две контрольные цифры mod-97,
+-- код банка GOBK и 12 случайных цифр. Внутренний id наружу не выдаётся
+ALTER TABLE accounts ADD COLUMN number TEXT;
+
+-- Существующие счета получают номера по схеме по умолчанию: сначала
+-- случайные 12 цифр, затем контрольные цифры по ISO 7064 (60 и 27 —
+-- остатки mod 97 постоянных частей числа GOBK<цифры>RU00)
+UPDATE accounts SET number = lpad(floor(random() * 1000000000000)::bigint::text, 12, '0') WHERE number IS NULL;
+UPDATE accounts SET number = 'RU' || lpad((98 - (60 + (number::bigint % 97) * 27) % 97)::text, 2, '0') || 'GOBK' || number
+WHERE length(number) = 12;
+
+CREATE UNIQUE INDEX IF NOT EXISTS accounts_number ON accounts (number);
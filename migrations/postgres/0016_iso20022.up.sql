@@ -0,0 +1,9 @@
+-- Пакеты из сообщений ISO 20022 pain.001: идентификаторы сообщения (MsgId) и
+-- платежа (PmtInfId) пакета и EndToEndId строк нужны для отчёта pain.002 и
+-- выписки camt.053. Повторная загрузка того же платежа отклоняется
+ALTER TABLE batches ADD COLUMN message_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE batches ADD COLUMN payment_info_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE batch_items ADD COLUMN end_to_end_id TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS batches_message ON batches (user_id, message_id, payment_info_id) WHERE message_id <> '';
+CREATE INDEX IF NOT EXISTS batch_items_transaction_id ON batch_items (transaction_id);
@@ -0,0 +1,2 @@
+-- Пополнения, записанные после миграции, — настоящая история и не удаляются
+DELETE FROM transactions WHERE kind = 'topup' AND idempotency_key LIKE 'reconciliation-backfill-%';
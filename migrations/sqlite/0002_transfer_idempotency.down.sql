@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS transactions_idempotency_key;
+ALTER TABLE transactions DROP COLUMN idempotency_key;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS audit_log;
+ALTER TABLE transactions DROP COLUMN kind;
+ALTER TABLE accounts DROP COLUMN frozen;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS interest_accruals;
+DROP TABLE IF EXISTS interest_rates;
+-- Счета банка — единственные счета типа internal; system_accounts ссылается на них
+DROP TABLE IF EXISTS system_accounts;
+DELETE FROM accounts WHERE type = 'internal';
@@ -0,0 +1,4 @@
+ALTER TABLE accounts DROP COLUMN low_balance_threshold;
+ALTER TABLE accounts DROP COLUMN overdraft_fee;
+ALTER TABLE accounts DROP COLUMN overdraft_rate;
+ALTER TABLE accounts DROP COLUMN overdraft_limit;
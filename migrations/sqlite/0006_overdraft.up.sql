@@ -0,0 +1,8 @@
+-- Согласованный овердрафт расчётного счёта: лимит, годовая ставка на отрицательный
+-- остаток и комиссия за уход в минус (0 — овердрафта нет)
+ALTER TABLE accounts ADD COLUMN overdraft_limit NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN overdraft_rate NUMERIC(7, 4) NOT NULL DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN overdraft_fee NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+-- Порог уведомления о низком остатке (0 — уведомление отключено)
+ALTER TABLE accounts ADD COLUMN low_balance_threshold NUMERIC(12, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS loan_installments;
+DROP TABLE IF EXISTS loans;
+DELETE FROM system_accounts WHERE name = 'loans';
+DELETE FROM accounts WHERE type = 'internal' AND id NOT IN (SELECT account_id FROM system_accounts);
@@ -0,0 +1,23 @@
+-- Сумма активных блокировок счёта: уменьшает доступный остаток, не меняя баланс
+ALTER TABLE accounts ADD COLUMN held NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+-- Блокировки (двухфазные платежи): amount зарезервирован на счёте account_id
+-- в пользу счёта to_account_id. Получатель списывает всю сумму или её часть
+-- переводом transaction_id либо снимает блокировку; блокировка, не закрытая
+-- до expires_at, истекает
+CREATE TABLE IF NOT EXISTS holds (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    to_account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    amount NUMERIC(12, 2) NOT NULL,
+    captured_amount NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'active',
+    transaction_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS holds_account_id ON holds (account_id);
+CREATE INDEX IF NOT EXISTS holds_to_account_id ON holds (to_account_id);
+CREATE INDEX IF NOT EXISTS holds_active ON holds (status, expires_at);
@@ -0,0 +1,7 @@
+-- Возвраты и сторно записываются новыми движениями со ссылкой на исходный
+-- перевод; у сторно — код причины. Без REFERENCES: SQLite не удаляет столбцы
+-- внешних ключей, а движения не удаляются
+ALTER TABLE transactions ADD COLUMN original_transaction_id INTEGER;
+ALTER TABLE transactions ADD COLUMN reason_code TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS transactions_original_transaction_id ON transactions (original_transaction_id);
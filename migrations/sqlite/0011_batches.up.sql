@@ -0,0 +1,29 @@
+-- Пакетные переводы: строки пакета переводятся со счёта from_account_id его
+-- владельца. В режиме all_or_nothing пакет выполняется одной транзакцией и
+-- при первой ошибке не выполняется целиком
+CREATE TABLE IF NOT EXISTS batches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    all_or_nothing BOOLEAN NOT NULL DEFAULT FALSE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+-- Строки пакета с результатом: перевод transaction_id или текст ошибки
+CREATE TABLE IF NOT EXISTS batch_items (
+    batch_id INTEGER NOT NULL REFERENCES batches(id) ON DELETE CASCADE,
+    line INTEGER NOT NULL,
+    to_username TEXT NOT NULL DEFAULT '',
+    to_account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    amount NUMERIC(12, 2) NOT NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    error TEXT NOT NULL DEFAULT '',
+    transaction_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL,
+    PRIMARY KEY (batch_id, line)
+);
+
+CREATE INDEX IF NOT EXISTS batches_user_id ON batches (user_id);
+CREATE INDEX IF NOT EXISTS batches_status ON batches (status);
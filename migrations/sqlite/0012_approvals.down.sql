@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS transfer_approvals;
+DROP TABLE IF EXISTS account_approvers;
+ALTER TABLE accounts DROP COLUMN approval_threshold;
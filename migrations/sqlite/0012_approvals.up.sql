@@ -0,0 +1,36 @@
+-- Порог одобрения: перевод со счёта на сумму больше порога выполняется только
+-- после одобрения вторым пользователем (maker-checker), 0 — без одобрения
+ALTER TABLE accounts ADD COLUMN approval_threshold NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+-- Пользователи, которые одобряют переводы со счёта
+CREATE TABLE IF NOT EXISTS account_approvers (
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (account_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS account_approvers_user_id ON account_approvers (user_id);
+
+-- Переводы выше порога: maker_id создал перевод, checker_id одобрил его
+-- (перевод transaction_id) или отклонил; не рассмотренный до expires_at
+-- перевод истекает. Ключ идемпотентности переходит в выполненный перевод
+CREATE TABLE IF NOT EXISTS transfer_approvals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    from_account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    to_account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    amount NUMERIC(12, 2) NOT NULL,
+    maker_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    checker_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    reason TEXT NOT NULL DEFAULT '',
+    idempotency_key TEXT,
+    transaction_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS transfer_approvals_from_account_id ON transfer_approvals (from_account_id);
+CREATE INDEX IF NOT EXISTS transfer_approvals_pending ON transfer_approvals (status, expires_at);
+CREATE UNIQUE INDEX IF NOT EXISTS transfer_approvals_idempotency_key
+    ON transfer_approvals (from_account_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Номер счёта в формате, похожем на IBAN: RU, две контрольные цифры mod-97,
+-- код банка GOBK и 12 случайных цифр. Внутренний id наружу не выдаётся
+ALTER TABLE accounts ADD COLUMN number TEXT;
+
+-- Существующие счета получают номера по схеме по умолчанию: сначала
+-- случайные 12 цифр, затем контрольные цифры по ISO 7064 (60 и 27 —
+-- остатки mod 97 постоянных частей числа GOBK<цифры>RU00)
+UPDATE accounts SET number = substr('000000000000' || (abs(random()) % 1000000000000), -12) WHERE number IS NULL;
+UPDATE accounts SET number = 'RU' || substr('0' || (98 - (60 + (CAST(number AS INTEGER) % 97) * 27) % 97), -2) || 'GOBK' || number
+WHERE length(number) = 12;
+
+CREATE UNIQUE INDEX IF NOT EXISTS accounts_number ON accounts (number);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS batch_items_transaction_id;
+DROP INDEX IF EXISTS batches_message;
+ALTER TABLE batch_items DROP COLUMN end_to_end_id;
+ALTER TABLE batches DROP COLUMN payment_info_id;
+ALTER TABLE batches DROP COLUMN message_id;
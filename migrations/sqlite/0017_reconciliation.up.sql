@@ -0,0 +1,13 @@
+-- Пополнения раньше меняли баланс без записи в transactions. Чтобы сверка
+-- балансов с историей движений сходилась на старых данных, недостающие
+-- зачисления восстанавливаются пополнениями на дату открытия счёта.
+-- Расхождения в другую сторону не исправляются — их покажет сверка.
+-- Восстановленные движения помечены ключом reconciliation-backfill-<счёт>,
+-- чтобы откат удалил только их
+INSERT INTO transactions (kind, to_account_id, amount, created_at, idempotency_key)
+SELECT 'topup', a.id, ROUND(a.balance - (COALESCE(i.amount, 0) - COALESCE(o.amount, 0)), 2), a.created_at,
+    'reconciliation-backfill-' || a.id
+FROM accounts a
+LEFT JOIN (SELECT to_account_id AS id, SUM(amount) AS amount FROM transactions GROUP BY to_account_id) i ON i.id = a.id
+LEFT JOIN (SELECT from_account_id AS id, SUM(amount) AS amount FROM transactions GROUP BY from_account_id) o ON o.id = a.id
+WHERE ROUND(a.balance - (COALESCE(i.amount, 0) - COALESCE(o.amount, 0)), 2) > 0;